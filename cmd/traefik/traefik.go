@@ -123,7 +123,21 @@ func runCmd(staticConfiguration *static.Configuration, configFile string) error
 	}
 
 	serverEntryPointsTCP := make(server.TCPEntryPoints)
+	serverEntryPointsUDP := make(server.UDPEntryPoints)
 	for entryPointName, config := range staticConfiguration.EntryPoints {
+		protocol, err := config.GetProtocol()
+		if err != nil {
+			return fmt.Errorf("error while building entryPoint %s: %v", entryPointName, err)
+		}
+
+		if protocol == "udp" {
+			serverEntryPointsUDP[entryPointName], err = server.NewUDPEntryPoint(config)
+			if err != nil {
+				return fmt.Errorf("error while building entryPoint %s: %v", entryPointName, err)
+			}
+			continue
+		}
+
 		ctx := log.With(context.Background(), log.Str(log.EntryPointName, entryPointName))
 		serverEntryPointsTCP[entryPointName], err = server.NewTCPEntryPoint(ctx, config)
 		if err != nil {
@@ -144,7 +158,7 @@ func runCmd(staticConfiguration *static.Configuration, configFile string) error
 		}
 	}
 
-	svr := server.NewServer(*staticConfiguration, providerAggregator, serverEntryPointsTCP, tlsManager)
+	svr := server.NewServer(*staticConfiguration, providerAggregator, serverEntryPointsTCP, serverEntryPointsUDP, tlsManager)
 
 	if acmeProvider != nil && acmeProvider.OnHostRule {
 		acmeProvider.SetConfigListenerChan(make(chan config.Configuration))
@@ -31,11 +31,12 @@ type serviceInfoRepresentation struct {
 
 // RunTimeRepresentation is the configuration information exposed by the API handler.
 type RunTimeRepresentation struct {
-	Routers     map[string]*config.RouterInfo         `json:"routers,omitempty"`
-	Middlewares map[string]*config.MiddlewareInfo     `json:"middlewares,omitempty"`
-	Services    map[string]*serviceInfoRepresentation `json:"services,omitempty"`
-	TCPRouters  map[string]*config.TCPRouterInfo      `json:"tcpRouters,omitempty"`
-	TCPServices map[string]*config.TCPServiceInfo     `json:"tcpServices,omitempty"`
+	Routers        map[string]*config.RouterInfo         `json:"routers,omitempty"`
+	Middlewares    map[string]*config.MiddlewareInfo     `json:"middlewares,omitempty"`
+	Services       map[string]*serviceInfoRepresentation `json:"services,omitempty"`
+	TCPRouters     map[string]*config.TCPRouterInfo      `json:"tcpRouters,omitempty"`
+	TCPMiddlewares map[string]*config.TCPMiddlewareInfo  `json:"tcpMiddlewares,omitempty"`
+	TCPServices    map[string]*config.TCPServiceInfo     `json:"tcpServices,omitempty"`
 }
 
 type routerRepresentation struct {
@@ -63,12 +64,20 @@ type tcpRouterRepresentation struct {
 	Provider string `json:"provider,omitempty"`
 }
 
-type tcpServiceRepresentation struct {
-	*config.TCPServiceInfo
+type tcpMiddlewareRepresentation struct {
+	*config.TCPMiddlewareInfo
 	Name     string `json:"name,omitempty"`
 	Provider string `json:"provider,omitempty"`
 }
 
+type tcpServiceRepresentation struct {
+	*config.TCPServiceInfo
+	ServerStatus      map[string]string `json:"serverStatus,omitempty"`
+	ServerConnections map[string]int    `json:"serverConnections,omitempty"`
+	Name              string            `json:"name,omitempty"`
+	Provider          string            `json:"provider,omitempty"`
+}
+
 type pageInfo struct {
 	startIndex int
 	endIndex   int
@@ -123,6 +132,8 @@ func (h Handler) Append(router *mux.Router) {
 	router.Methods(http.MethodGet).Path("/api/tcp/routers/{routerID}").HandlerFunc(h.getTCPRouter)
 	router.Methods(http.MethodGet).Path("/api/tcp/services").HandlerFunc(h.getTCPServices)
 	router.Methods(http.MethodGet).Path("/api/tcp/services/{serviceID}").HandlerFunc(h.getTCPService)
+	router.Methods(http.MethodGet).Path("/api/tcp/middlewares").HandlerFunc(h.getTCPMiddlewares)
+	router.Methods(http.MethodGet).Path("/api/tcp/middlewares/{middlewareID}").HandlerFunc(h.getTCPMiddleware)
 
 	// FIXME stats
 	// health route
@@ -198,7 +209,7 @@ func (h Handler) getServices(rw http.ResponseWriter, request *http.Request) {
 			ServiceInfo:  si,
 			Name:         name,
 			Provider:     getProviderName(name),
-			ServerStatus: si.GetAllStatus(),
+			ServerStatus: h.runtimeConfiguration.GetServiceStatus(name),
 		})
 	}
 
@@ -235,7 +246,7 @@ func (h Handler) getService(rw http.ResponseWriter, request *http.Request) {
 		ServiceInfo:  service,
 		Name:         serviceID,
 		Provider:     getProviderName(serviceID),
-		ServerStatus: service.GetAllStatus(),
+		ServerStatus: h.runtimeConfiguration.GetServiceStatus(serviceID),
 	}
 
 	rw.Header().Add("Content-Type", "application/json")
@@ -362,9 +373,11 @@ func (h Handler) getTCPServices(rw http.ResponseWriter, request *http.Request) {
 
 	for name, si := range h.runtimeConfiguration.TCPServices {
 		results = append(results, tcpServiceRepresentation{
-			TCPServiceInfo: si,
-			Name:           name,
-			Provider:       getProviderName(name),
+			TCPServiceInfo:    si,
+			ServerStatus:      si.GetAllStatus(),
+			ServerConnections: si.GetAllConnections(),
+			Name:              name,
+			Provider:          getProviderName(name),
 		})
 	}
 
@@ -398,9 +411,66 @@ func (h Handler) getTCPService(rw http.ResponseWriter, request *http.Request) {
 	}
 
 	result := tcpServiceRepresentation{
-		TCPServiceInfo: service,
-		Name:           serviceID,
-		Provider:       getProviderName(serviceID),
+		TCPServiceInfo:    service,
+		ServerStatus:      service.GetAllStatus(),
+		ServerConnections: service.GetAllConnections(),
+		Name:              serviceID,
+		Provider:          getProviderName(serviceID),
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+
+	err := json.NewEncoder(rw).Encode(result)
+	if err != nil {
+		log.FromContext(request.Context()).Error(err)
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (h Handler) getTCPMiddlewares(rw http.ResponseWriter, request *http.Request) {
+	results := make([]tcpMiddlewareRepresentation, 0, len(h.runtimeConfiguration.TCPMiddlewares))
+
+	for name, mi := range h.runtimeConfiguration.TCPMiddlewares {
+		results = append(results, tcpMiddlewareRepresentation{
+			TCPMiddlewareInfo: mi,
+			Name:              name,
+			Provider:          getProviderName(name),
+		})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Name < results[j].Name
+	})
+
+	pageInfo, err := pagination(request, len(results))
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	rw.Header().Set(nextPageHeader, strconv.Itoa(pageInfo.nextPage))
+
+	err = json.NewEncoder(rw).Encode(results[pageInfo.startIndex:pageInfo.endIndex])
+	if err != nil {
+		log.FromContext(request.Context()).Error(err)
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (h Handler) getTCPMiddleware(rw http.ResponseWriter, request *http.Request) {
+	middlewareID := mux.Vars(request)["middlewareID"]
+
+	middleware, ok := h.runtimeConfiguration.TCPMiddlewares[middlewareID]
+	if !ok {
+		http.NotFound(rw, request)
+		return
+	}
+
+	result := tcpMiddlewareRepresentation{
+		TCPMiddlewareInfo: middleware,
+		Name:              middlewareID,
+		Provider:          getProviderName(middlewareID),
 	}
 
 	rw.Header().Set("Content-Type", "application/json")
@@ -417,16 +487,17 @@ func (h Handler) getRuntimeConfiguration(rw http.ResponseWriter, request *http.R
 	for k, v := range h.runtimeConfiguration.Services {
 		siRepr[k] = &serviceInfoRepresentation{
 			ServiceInfo:  v,
-			ServerStatus: v.GetAllStatus(),
+			ServerStatus: h.runtimeConfiguration.GetServiceStatus(k),
 		}
 	}
 
 	result := RunTimeRepresentation{
-		Routers:     h.runtimeConfiguration.Routers,
-		Middlewares: h.runtimeConfiguration.Middlewares,
-		Services:    siRepr,
-		TCPRouters:  h.runtimeConfiguration.TCPRouters,
-		TCPServices: h.runtimeConfiguration.TCPServices,
+		Routers:        h.runtimeConfiguration.Routers,
+		Middlewares:    h.runtimeConfiguration.Middlewares,
+		Services:       siRepr,
+		TCPRouters:     h.runtimeConfiguration.TCPRouters,
+		TCPMiddlewares: h.runtimeConfiguration.TCPMiddlewares,
+		TCPServices:    h.runtimeConfiguration.TCPServices,
 	}
 
 	rw.Header().Set("Content-Type", "application/json")
@@ -19,6 +19,12 @@ import (
 
 var updateExpected = flag.Bool("update_expected", false, "Update expected files in testdata")
 
+type connCounter map[string]int
+
+func (c connCounter) Connections() map[string]int {
+	return c
+}
+
 func TestHandlerTCP_API(t *testing.T) {
 	type expected struct {
 		statusCode int
@@ -114,6 +120,7 @@ func TestHandlerTCP_API(t *testing.T) {
 					"bar@myprovider": {
 						TCPRouter: &config.TCPRouter{
 							EntryPoints: []string{"web"},
+							Middlewares: []string{"whitelist"},
 							Service:     "foo-service@myprovider",
 							Rule:        "Host(`foo.bar`)",
 						},
@@ -251,18 +258,23 @@ func TestHandlerTCP_API(t *testing.T) {
 			path: "/api/tcp/services/bar@myprovider",
 			conf: config.RuntimeConfiguration{
 				TCPServices: map[string]*config.TCPServiceInfo{
-					"bar@myprovider": {
-						TCPService: &config.TCPService{
-							LoadBalancer: &config.TCPLoadBalancerService{
-								Servers: []config.TCPServer{
-									{
-										Address: "127.0.0.1:2345",
+					"bar@myprovider": func() *config.TCPServiceInfo {
+						si := &config.TCPServiceInfo{
+							TCPService: &config.TCPService{
+								LoadBalancer: &config.TCPLoadBalancerService{
+									Servers: []config.TCPServer{
+										{
+											Address: "127.0.0.1:2345",
+										},
 									},
 								},
 							},
-						},
-						UsedBy: []string{"foo@myprovider", "test@myprovider"},
-					},
+							UsedBy: []string{"foo@myprovider", "test@myprovider"},
+						}
+						si.UpdateStatus("127.0.0.1:2345", "UP")
+						si.SetConnCounter(connCounter{"127.0.0.1:2345": 3})
+						return si
+					}(),
 				},
 			},
 			expected: expected{
@@ -301,6 +313,128 @@ func TestHandlerTCP_API(t *testing.T) {
 				statusCode: http.StatusNotFound,
 			},
 		},
+		{
+			desc: "all tcp middlewares, but no config",
+			path: "/api/tcp/middlewares",
+			conf: config.RuntimeConfiguration{},
+			expected: expected{
+				statusCode: http.StatusOK,
+				nextPage:   "1",
+				jsonFile:   "testdata/tcpmiddlewares-empty.json",
+			},
+		},
+		{
+			desc: "all tcp middlewares",
+			path: "/api/tcp/middlewares",
+			conf: config.RuntimeConfiguration{
+				TCPMiddlewares: map[string]*config.TCPMiddlewareInfo{
+					"whitelist@myprovider": {
+						TCPMiddleware: &config.TCPMiddleware{
+							IPWhiteList: &config.TCPIPWhiteList{
+								SourceRange: []string{"10.0.0.0/8"},
+							},
+						},
+						UsedBy: []string{"bar@myprovider", "test@myprovider"},
+					},
+					"inflight@anotherprovider": {
+						TCPMiddleware: &config.TCPMiddleware{
+							InFlightConn: &config.TCPInFlightConn{
+								Amount: 10,
+							},
+						},
+						UsedBy: []string{"bar@myprovider"},
+					},
+				},
+			},
+			expected: expected{
+				statusCode: http.StatusOK,
+				nextPage:   "1",
+				jsonFile:   "testdata/tcpmiddlewares.json",
+			},
+		},
+		{
+			desc: "all tcp middlewares, 1 res per page, want page 2",
+			path: "/api/tcp/middlewares?page=2&per_page=1",
+			conf: config.RuntimeConfiguration{
+				TCPMiddlewares: map[string]*config.TCPMiddlewareInfo{
+					"whitelist@myprovider": {
+						TCPMiddleware: &config.TCPMiddleware{
+							IPWhiteList: &config.TCPIPWhiteList{
+								SourceRange: []string{"10.0.0.0/8"},
+							},
+						},
+						UsedBy: []string{"bar@myprovider", "test@myprovider"},
+					},
+					"inflight@anotherprovider": {
+						TCPMiddleware: &config.TCPMiddleware{
+							InFlightConn: &config.TCPInFlightConn{
+								Amount: 10,
+							},
+						},
+						UsedBy: []string{"bar@myprovider"},
+					},
+					"inflight@myprovider": {
+						TCPMiddleware: &config.TCPMiddleware{
+							InFlightConn: &config.TCPInFlightConn{
+								Amount: 20,
+							},
+						},
+						UsedBy: []string{"test@myprovider"},
+					},
+				},
+			},
+			expected: expected{
+				statusCode: http.StatusOK,
+				nextPage:   "3",
+				jsonFile:   "testdata/tcpmiddlewares-page2.json",
+			},
+		},
+		{
+			desc: "one tcp middleware by id",
+			path: "/api/tcp/middlewares/whitelist@myprovider",
+			conf: config.RuntimeConfiguration{
+				TCPMiddlewares: map[string]*config.TCPMiddlewareInfo{
+					"whitelist@myprovider": {
+						TCPMiddleware: &config.TCPMiddleware{
+							IPWhiteList: &config.TCPIPWhiteList{
+								SourceRange: []string{"10.0.0.0/8"},
+							},
+						},
+						UsedBy: []string{"bar@myprovider", "test@myprovider"},
+					},
+				},
+			},
+			expected: expected{
+				statusCode: http.StatusOK,
+				jsonFile:   "testdata/tcpmiddleware-whitelist.json",
+			},
+		},
+		{
+			desc: "one tcp middleware by id, that does not exist",
+			path: "/api/tcp/middlewares/foo@myprovider",
+			conf: config.RuntimeConfiguration{
+				TCPMiddlewares: map[string]*config.TCPMiddlewareInfo{
+					"whitelist@myprovider": {
+						TCPMiddleware: &config.TCPMiddleware{
+							IPWhiteList: &config.TCPIPWhiteList{
+								SourceRange: []string{"10.0.0.0/8"},
+							},
+						},
+					},
+				},
+			},
+			expected: expected{
+				statusCode: http.StatusNotFound,
+			},
+		},
+		{
+			desc: "one tcp middleware by id, but no config",
+			path: "/api/tcp/middlewares/foo@myprovider",
+			conf: config.RuntimeConfiguration{},
+			expected: expected{
+				statusCode: http.StatusNotFound,
+			},
+		},
 	}
 
 	for _, test := range testCases {
@@ -647,7 +781,9 @@ func TestHandlerHTTP_API(t *testing.T) {
 								LoadBalancer: &config.LoadBalancerService{
 									Servers: []config.Server{
 										{
-											URL: "http://127.0.0.1",
+											URL:    "http://127.0.0.1",
+											Weight: 2,
+											Labels: map[string]string{"zone": "eu"},
 										},
 									},
 								},
@@ -985,6 +1121,7 @@ func TestHandler_Configuration(t *testing.T) {
 					"tcpbar@myprovider": {
 						TCPRouter: &config.TCPRouter{
 							EntryPoints: []string{"web"},
+							Middlewares: []string{"whitelist"},
 							Service:     "tcpfoo-service@myprovider",
 							Rule:        "HostSNI(`foo.bar`)",
 						},
@@ -997,6 +1134,15 @@ func TestHandler_Configuration(t *testing.T) {
 						},
 					},
 				},
+				TCPMiddlewares: map[string]*config.TCPMiddlewareInfo{
+					"whitelist@myprovider": {
+						TCPMiddleware: &config.TCPMiddleware{
+							IPWhiteList: &config.TCPIPWhiteList{
+								SourceRange: []string{"10.0.0.0/8"},
+							},
+						},
+					},
+				},
 			},
 			expected: expected{
 				statusCode: http.StatusOK,
@@ -9,6 +9,7 @@ import (
 	"reflect"
 
 	traefiktls "github.com/containous/traefik/pkg/tls"
+	"github.com/containous/traefik/pkg/types"
 )
 
 // Router holds the router configuration.
@@ -29,8 +30,10 @@ type RouterTLSConfig struct {
 // TCPRouter holds the router configuration.
 type TCPRouter struct {
 	EntryPoints []string            `json:"entryPoints"`
+	Middlewares []string            `json:"middlewares,omitempty" toml:",omitempty"`
 	Service     string              `json:"service,omitempty" toml:",omitempty"`
 	Rule        string              `json:"rule,omitempty" toml:",omitempty"`
+	Priority    int                 `json:"priority,omitempty" toml:"priority,omitzero"`
 	TLS         *RouterTCPTLSConfig `json:"tls,omitempty" toml:"tls,omitzero" label:"allowEmpty"`
 }
 
@@ -40,18 +43,179 @@ type RouterTCPTLSConfig struct {
 	Options     string `json:"options,omitempty" toml:"options,omitzero"`
 }
 
+// UDPRouter holds the UDP router configuration.
+// As a UDP datagram does not carry any routing information, an entry point has at most one UDP router.
+type UDPRouter struct {
+	EntryPoints []string `json:"entryPoints"`
+	Service     string   `json:"service,omitempty" toml:",omitempty"`
+}
+
+// Load-balancing strategies of a LoadBalancerService, and of a TCPLoadBalancerService
+// for roundRobin, leastConnections and randomTwoChoices.
+const (
+	StrategyRoundRobin       = "roundRobin"
+	StrategyLeastConnections = "leastConnections"
+	StrategyEWMA             = "ewma"
+	StrategyConsistentHash   = "consistentHash"
+	StrategyRandomTwoChoices = "randomTwoChoices"
+)
+
 // LoadBalancerService holds the LoadBalancerService configuration.
 type LoadBalancerService struct {
+	// Strategy is the load-balancing strategy used to pick a server for each request:
+	// roundRobin (default), leastConnections, ewma or consistentHash.
+	Strategy           string              `json:"strategy,omitempty" toml:",omitempty"`
+	ConsistentHash     *ConsistentHash     `json:"consistentHash,omitempty" toml:",omitempty"`
 	Stickiness         *Stickiness         `json:"stickiness,omitempty" toml:",omitempty" label:"allowEmpty"`
 	Servers            []Server            `json:"servers,omitempty" toml:",omitempty" label-slice-as-struct:"server"`
+	ServersTransport   string              `json:"serversTransport,omitempty" toml:",omitempty"`
 	HealthCheck        *HealthCheck        `json:"healthCheck,omitempty" toml:",omitempty"`
+	PassiveHealthCheck *PassiveHealthCheck `json:"passiveHealthCheck,omitempty" toml:",omitempty" label:"allowEmpty"`
 	PassHostHeader     bool                `json:"passHostHeader" toml:",omitempty"`
 	ResponseForwarding *ResponseForwarding `json:"forwardingResponse,omitempty" toml:",omitempty"`
+	SlowStart          string              `json:"slowStart,omitempty" toml:",omitempty"`
+	Hedging            *Hedging            `json:"hedging,omitempty" toml:",omitempty" label:"allowEmpty"`
+	DNSDiscovery       *DNSDiscovery       `json:"dnsDiscovery,omitempty" toml:",omitempty" label:"allowEmpty"`
+}
+
+// DNSDiscovery holds the DNS discovery configuration.
+// The host names of the servers are periodically resolved, and each of their addresses becomes a server of the service.
+// The servers with a srv:// URL, e.g. srv://_http._tcp.api.internal, are discovered from the SRV records of the name,
+// and imply the DNS discovery.
+type DNSDiscovery struct {
+	// ResolvConfig is the resolver configuration file (default: /etc/resolv.conf).
+	ResolvConfig string `json:"resolvConfig,omitempty" toml:",omitempty"`
+	// MinRefreshInterval and MaxRefreshInterval bound the interval between two resolutions,
+	// which is otherwise the lowest TTL of the records.
+	MinRefreshInterval string `json:"minRefreshInterval,omitempty" toml:",omitempty"`
+	MaxRefreshInterval string `json:"maxRefreshInterval,omitempty" toml:",omitempty"`
+}
+
+// Hedging holds the request hedging configuration.
+// A request which got no response headers after the delay is sent again to the service,
+// and the first response is forwarded while the other requests are canceled.
+type Hedging struct {
+	// Delay is the time to wait for the response headers before sending a hedged request.
+	// It defaults to the 95th percentile of the latencies of the service.
+	Delay     string   `json:"delay,omitempty" toml:",omitempty"`
+	MaxHedges int      `json:"maxHedges,omitempty" toml:",omitempty,omitzero"`
+	Methods   []string `json:"methods,omitempty" toml:",omitempty"`
+}
+
+// ConsistentHash holds the configuration of the consistentHash load-balancing strategy.
+// Exactly one part of the request must be chosen as the key hashed to pick a server.
+type ConsistentHash struct {
+	Header     string      `json:"header,omitempty" toml:",omitempty"`
+	Cookie     string      `json:"cookie,omitempty" toml:",omitempty"`
+	Path       bool        `json:"path,omitempty" toml:",omitempty"`
+	IPStrategy *IPStrategy `json:"ipStrategy,omitempty" toml:",omitempty" label:"allowEmpty"`
+}
+
+// WeightedService holds the WeightedService configuration.
+// It balances the requests between other services, proportionally to their weight.
+type WeightedService struct {
+	Services []WeightedServiceRef `json:"services,omitempty" toml:",omitempty"`
+}
+
+// WeightedServiceRef holds a reference to a service, and its weight in a WeightedService.
+type WeightedServiceRef struct {
+	Name   string `json:"name"`
+	Weight int    `json:"weight"`
+}
+
+// MirroringService holds the MirroringService configuration.
+// It forwards the requests to a main service, and sends a copy of a percentage of them to mirror services.
+type MirroringService struct {
+	Service string `json:"service,omitempty" toml:",omitempty"`
+	// MaxBodySize is the maximum size (in bytes) of the request bodies buffered to be mirrored.
+	// The requests with a larger body are not mirrored.
+	MaxBodySize int64 `json:"maxBodySize,omitempty" toml:",omitempty"`
+	// MaxInFlight is the maximum number of mirrored requests being sent at once (default: 100).
+	// The requests which would be mirrored beyond this limit are not.
+	MaxInFlight int `json:"maxInFlight,omitempty" toml:",omitempty"`
+	// Timeout is the maximum duration of a mirrored request (default: 30s).
+	Timeout string          `json:"timeout,omitempty" toml:",omitempty"`
+	Mirrors []MirrorService `json:"mirrors,omitempty" toml:",omitempty"`
+}
+
+// MirrorService holds the MirrorService configuration.
+type MirrorService struct {
+	Name    string `json:"name"`
+	Percent int    `json:"percent"`
+}
+
+// FailoverService holds the FailoverService configuration.
+// It forwards the requests to a main service, and to a fallback service when the main service has no healthy servers,
+// or when its response status code is one of the given status codes.
+type FailoverService struct {
+	Service  string `json:"service,omitempty" toml:",omitempty"`
+	Fallback string `json:"fallback,omitempty" toml:",omitempty"`
+	// StatusCodes are the status codes (like "503") or ranges of status codes (like "500-599")
+	// of the responses of the main service for which the request is sent to the fallback service.
+	StatusCodes []string `json:"statusCodes,omitempty" toml:",omitempty"`
+	// MaxBodySize is the maximum size (in bytes) of the request bodies buffered to be replayed to the fallback service.
+	// The requests with a larger body are not sent to the fallback service because of the status code of the main one.
+	MaxBodySize int64 `json:"maxBodySize,omitempty" toml:",omitempty"`
 }
 
 // TCPLoadBalancerService holds the LoadBalancerService configuration.
 type TCPLoadBalancerService struct {
-	Servers []TCPServer `json:"servers,omitempty" toml:",omitempty" label-slice-as-struct:"server"`
+	// Strategy is the load-balancing strategy used to pick a server for each connection:
+	// roundRobin (default), leastConnections or randomTwoChoices.
+	Strategy    string          `json:"strategy,omitempty" toml:",omitempty"`
+	Servers     []TCPServer     `json:"servers,omitempty" toml:",omitempty" label-slice-as-struct:"server"`
+	HealthCheck *TCPHealthCheck `json:"healthCheck,omitempty" toml:",omitempty" label:"allowEmpty"`
+	// ProxyProtocol, if defined, sends the address of the client in a PROXY protocol header on each connection to the servers.
+	ProxyProtocol *ProxyProtocol `json:"proxyProtocol,omitempty" toml:",omitempty" label:"allowEmpty"`
+	// TerminationDelay is how long a connection is kept open, once one side closed its half of it,
+	// for the other side to close its half too (100ms by default, a negative duration meaning forever).
+	TerminationDelay string `json:"terminationDelay,omitempty" toml:",omitempty"`
+	// IdleTimeout is how long a connection can stay open without any byte being exchanged (no timeout by default).
+	IdleTimeout string `json:"idleTimeout,omitempty" toml:",omitempty"`
+	// TLS, if defined, encrypts the connections to the servers with TLS,
+	// so that the connections terminated by a TLS router stay encrypted up to the servers.
+	TLS *TCPServersTLS `json:"tls,omitempty" toml:",omitempty" label:"allowEmpty"`
+}
+
+// TCPServersTLS holds the TLS configuration of the connections to the servers of a TCP service.
+type TCPServersTLS struct {
+	// ServerName overrides the server name used to verify the certificate of the servers (and sent with SNI),
+	// which is by default the host of the server address.
+	ServerName         string                     `json:"serverName,omitempty" toml:",omitempty"`
+	InsecureSkipVerify bool                       `json:"insecureSkipVerify,omitempty" toml:",omitempty"`
+	RootCAs            []traefiktls.FileOrContent `json:"rootCAs,omitempty" toml:",omitempty"`
+	// Certificates are the client certificates presented to the servers requiring mutual TLS.
+	Certificates traefiktls.Certificates `json:"certificates,omitempty" toml:",omitempty"`
+}
+
+// ProxyProtocol holds the configuration of the PROXY protocol header sent to the servers.
+type ProxyProtocol struct {
+	// Version is the version of the PROXY protocol: 1 (text header) or 2 (binary header, default).
+	Version int `json:"version,omitempty" toml:",omitempty,omitzero"`
+}
+
+// SetDefaults Default values for a ProxyProtocol.
+func (p *ProxyProtocol) SetDefaults() {
+	p.Version = 2
+}
+
+// GetVersion returns the version of the PROXY protocol, 2 if not set.
+func (p *ProxyProtocol) GetVersion() int {
+	if p.Version == 0 {
+		return 2
+	}
+	return p.Version
+}
+
+// TCPHealthCheck holds the TCP HealthCheck configuration.
+// Without Send and Expect, a server is healthy as soon as a connection to it can be established.
+type TCPHealthCheck struct {
+	Interval string `json:"interval,omitempty" toml:",omitempty"`
+	Timeout  string `json:"timeout,omitempty" toml:",omitempty"`
+	// Send is the payload written to the server once connected.
+	Send string `json:"send,omitempty" toml:",omitempty"`
+	// Expect is the payload the server must respond with, e.g. "+PONG" for a Redis "PING".
+	Expect string `json:"expect,omitempty" toml:",omitempty"`
 }
 
 // Mergeable tells if the given service is mergeable.
@@ -98,22 +262,71 @@ type ResponseForwarding struct {
 	FlushInterval string `json:"flushInterval,omitempty" toml:",omitempty"`
 }
 
+// Fallbacks of the sticky sessions, when the pinned server is unavailable.
+const (
+	StickinessFallbackRebalance = "rebalance"
+	StickinessFallbackReject    = "reject"
+)
+
 // Stickiness holds the stickiness configuration.
+// By default, the requests are pinned to a server with a cookie set by Traefik.
+// With SessionHeader or SessionCookie, they are pinned according to an existing session identifier instead.
 type Stickiness struct {
 	CookieName     string `json:"cookieName,omitempty" toml:",omitempty"`
 	SecureCookie   bool   `json:"secureCookie,omitempty" toml:",omitempty"`
 	HTTPOnlyCookie bool   `json:"httpOnlyCookie,omitempty" toml:",omitempty"`
+	// Secret is the key of the HMAC identifying the server in the cookie set by Traefik, generated when Traefik starts if empty.
+	Secret        string `json:"secret,omitempty" toml:",omitempty"`
+	SessionHeader string `json:"sessionHeader,omitempty" toml:",omitempty"`
+	SessionCookie string `json:"sessionCookie,omitempty" toml:",omitempty"`
+	// Fallback is the behavior when the pinned server is unavailable: rebalance (default) or reject.
+	Fallback string `json:"fallback,omitempty" toml:",omitempty"`
 }
 
 // Server holds the server configuration.
 type Server struct {
-	URL    string `json:"url" label:"-"`
-	Scheme string `toml:"-" json:"-"`
-	Port   string `toml:"-" json:"-"`
+	URL string `json:"url" label:"-"`
+	// Weight is the weight of the server in the load balancer (default: 1).
+	Weight int `json:"weight,omitempty" toml:",omitempty,omitzero"`
+	// Labels are arbitrary key/value pairs describing the server, e.g. its zone or version.
+	Labels map[string]string `json:"labels,omitempty" toml:",omitempty"`
+	Scheme string            `toml:"-" json:"-"`
+	Port   string            `toml:"-" json:"-"`
 }
 
 // TCPServer holds a TCP Server configuration
 type TCPServer struct {
+	Address string `json:"address" label:"-"`
+	// Weight is the weight of the server in the load balancer (default: 1).
+	Weight int    `json:"weight,omitempty" toml:",omitempty,omitzero"`
+	Port   string `toml:"-" json:"-"`
+}
+
+// UDPLoadBalancerService holds the UDP LoadBalancerService configuration.
+// The sessions of the clients are balanced in round robin between the servers.
+type UDPLoadBalancerService struct {
+	Servers []UDPServer `json:"servers,omitempty" toml:",omitempty" label-slice-as-struct:"server"`
+}
+
+// Mergeable tells if the given service is mergeable.
+func (l *UDPLoadBalancerService) Mergeable(loadBalancer *UDPLoadBalancerService) bool {
+	savedServers := l.Servers
+	defer func() {
+		l.Servers = savedServers
+	}()
+	l.Servers = nil
+
+	savedServersLB := loadBalancer.Servers
+	defer func() {
+		loadBalancer.Servers = savedServersLB
+	}()
+	loadBalancer.Servers = nil
+
+	return reflect.DeepEqual(l, loadBalancer)
+}
+
+// UDPServer holds a UDP Server configuration
+type UDPServer struct {
 	Address string `json:"address" label:"-"`
 	Port    string `toml:"-" json:"-"`
 }
@@ -134,6 +347,50 @@ type HealthCheck struct {
 	Timeout  string            `json:"timeout,omitempty" toml:",omitempty"`
 	Hostname string            `json:"hostname,omitempty" toml:",omitempty"`
 	Headers  map[string]string `json:"headers,omitempty" toml:",omitempty"`
+	// Mode is either "http" (the default) or "grpc", to call the grpc.health.v1.Health/Check method of the servers.
+	Mode   string `json:"mode,omitempty" toml:",omitempty"`
+	Method string `json:"method,omitempty" toml:",omitempty"`
+	// StatusCodes are the status codes, or ranges of status codes, of the healthy responses (default: 200-399).
+	StatusCodes  []string `json:"statusCodes,omitempty" toml:",omitempty"`
+	BodyContains string   `json:"bodyContains,omitempty" toml:",omitempty"`
+	BodyRegexp   string   `json:"bodyRegexp,omitempty" toml:",omitempty"`
+	// GRPCServiceName is the service name sent in the gRPC health checks.
+	GRPCServiceName  string `json:"grpcServiceName,omitempty" toml:",omitempty"`
+	SuccessThreshold int    `json:"successThreshold,omitempty" toml:",omitempty,omitzero"`
+	FailureThreshold int    `json:"failureThreshold,omitempty" toml:",omitempty,omitzero"`
+}
+
+// ServersTransport holds the configuration of the transport used to forward the requests to the servers of a service.
+type ServersTransport struct {
+	// ServerName overrides the server name used to verify the certificate of the servers (and sent with SNI).
+	ServerName         string                     `json:"serverName,omitempty" toml:",omitempty"`
+	InsecureSkipVerify bool                       `json:"insecureSkipVerify,omitempty" toml:",omitempty"`
+	RootCAs            []traefiktls.FileOrContent `json:"rootCAs,omitempty" toml:",omitempty"`
+	// Certificates are the client certificates presented to the servers requiring mutual TLS.
+	Certificates        traefiktls.Certificates `json:"certificates,omitempty" toml:",omitempty"`
+	MaxIdleConnsPerHost int                     `json:"maxIdleConnsPerHost,omitempty" toml:",omitempty"`
+	ForwardingTimeouts  *ForwardingTimeouts     `json:"forwardingTimeouts,omitempty" toml:",omitempty"`
+	DisableHTTP2        bool                    `json:"disableHTTP2,omitempty" toml:",omitempty"`
+	// ProxyProtocol, if defined, sends the address of the client in a PROXY protocol header on each connection to the servers.
+	// As a connection then carries the address of a single client, the connections to the servers are not reused.
+	ProxyProtocol *ProxyProtocol `json:"proxyProtocol,omitempty" toml:",omitempty" label:"allowEmpty"`
+}
+
+// ForwardingTimeouts holds the timeouts of the requests forwarded to the servers.
+type ForwardingTimeouts struct {
+	DialTimeout           types.Duration `json:"dialTimeout,omitempty" toml:",omitempty"`
+	ResponseHeaderTimeout types.Duration `json:"responseHeaderTimeout,omitempty" toml:",omitempty"`
+	IdleConnTimeout       types.Duration `json:"idleConnTimeout,omitempty" toml:",omitempty"`
+}
+
+// PassiveHealthCheck holds the PassiveHealthCheck configuration.
+// It temporarily ejects the servers returning too many consecutive errors from the load-balancer.
+type PassiveHealthCheck struct {
+	MaxConsecutiveErrors int    `json:"maxConsecutiveErrors,omitempty" toml:",omitempty"`
+	Window               string `json:"window,omitempty" toml:",omitempty"`
+	BaseEjectionTime     string `json:"baseEjectionTime,omitempty" toml:",omitempty"`
+	MaxEjectionTime      string `json:"maxEjectionTime,omitempty" toml:",omitempty"`
+	MaxEjectionPercent   int    `json:"maxEjectionPercent,omitempty" toml:",omitempty"`
 }
 
 // CreateTLSConfig creates a TLS config from ClientTLS structures.
@@ -215,6 +472,7 @@ type Message struct {
 type Configuration struct {
 	HTTP       *HTTPConfiguration
 	TCP        *TCPConfiguration
+	UDP        *UDPConfiguration
 	TLS        []*traefiktls.Configuration `json:"-" label:"-"`
 	TLSOptions map[string]traefiktls.TLS
 	TLSStores  map[string]traefiktls.Store
@@ -225,23 +483,57 @@ type Configurations map[string]*Configuration
 
 // HTTPConfiguration FIXME better name?
 type HTTPConfiguration struct {
-	Routers     map[string]*Router     `json:"routers,omitempty" toml:",omitempty"`
-	Middlewares map[string]*Middleware `json:"middlewares,omitempty" toml:",omitempty"`
-	Services    map[string]*Service    `json:"services,omitempty" toml:",omitempty"`
+	Routers           map[string]*Router           `json:"routers,omitempty" toml:",omitempty"`
+	Middlewares       map[string]*Middleware       `json:"middlewares,omitempty" toml:",omitempty"`
+	Services          map[string]*Service          `json:"services,omitempty" toml:",omitempty"`
+	ServersTransports map[string]*ServersTransport `json:"serversTransports,omitempty" toml:",omitempty"`
 }
 
 // TCPConfiguration FIXME better name?
 type TCPConfiguration struct {
-	Routers  map[string]*TCPRouter  `json:"routers,omitempty" toml:",omitempty"`
-	Services map[string]*TCPService `json:"services,omitempty" toml:",omitempty"`
+	Routers     map[string]*TCPRouter     `json:"routers,omitempty" toml:",omitempty"`
+	Middlewares map[string]*TCPMiddleware `json:"middlewares,omitempty" toml:",omitempty"`
+	Services    map[string]*TCPService    `json:"services,omitempty" toml:",omitempty"`
+}
+
+// UDPConfiguration holds the UDP routers and services.
+type UDPConfiguration struct {
+	Routers  map[string]*UDPRouter  `json:"routers,omitempty" toml:",omitempty"`
+	Services map[string]*UDPService `json:"services,omitempty" toml:",omitempty"`
+}
+
+// TCPMiddleware holds the TCP middleware configuration (can only be of one type at the same time).
+type TCPMiddleware struct {
+	IPWhiteList  *TCPIPWhiteList  `json:"ipWhiteList,omitempty" toml:",omitempty"`
+	InFlightConn *TCPInFlightConn `json:"inFlightConn,omitempty" toml:",omitempty"`
+}
+
+// TCPIPWhiteList holds the TCP ip white list configuration.
+type TCPIPWhiteList struct {
+	// SourceRange defines the allowed IPs (or ranges of allowed IPs by using CIDR notation).
+	SourceRange []string `json:"sourceRange,omitempty" toml:",omitempty"`
+}
+
+// TCPInFlightConn holds the TCP in flight connection configuration.
+type TCPInFlightConn struct {
+	// Amount defines the maximum amount of simultaneous connections from a single source IP.
+	Amount int64 `json:"amount,omitempty" toml:",omitempty,omitzero"`
 }
 
 // Service holds a service configuration (can only be of one type at the same time).
 type Service struct {
 	LoadBalancer *LoadBalancerService `json:"loadbalancer,omitempty" toml:",omitempty,omitzero"`
+	Weighted     *WeightedService     `json:"weighted,omitempty" toml:",omitempty,omitzero"`
+	Mirroring    *MirroringService    `json:"mirroring,omitempty" toml:",omitempty,omitzero"`
+	Failover     *FailoverService     `json:"failover,omitempty" toml:",omitempty,omitzero"`
 }
 
 // TCPService holds a tcp service configuration (can only be of one type at the same time).
 type TCPService struct {
 	LoadBalancer *TCPLoadBalancerService `json:"loadbalancer,omitempty" toml:",omitempty,omitzero"`
 }
+
+// UDPService holds a udp service configuration (can only be of one type at the same time).
+type UDPService struct {
+	LoadBalancer *UDPLoadBalancerService `json:"loadbalancer,omitempty" toml:",omitempty,omitzero"`
+}
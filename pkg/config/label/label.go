@@ -11,9 +11,10 @@ func DecodeConfiguration(labels map[string]string) (*config.Configuration, error
 	conf := &config.Configuration{
 		HTTP: &config.HTTPConfiguration{},
 		TCP:  &config.TCPConfiguration{},
+		UDP:  &config.UDPConfiguration{},
 	}
 
-	err := parser.Decode(labels, conf, parser.DefaultRootName, "traefik.http", "traefik.tcp")
+	err := parser.Decode(labels, conf, parser.DefaultRootName, "traefik.http", "traefik.tcp", "traefik.udp")
 	if err != nil {
 		return nil, err
 	}
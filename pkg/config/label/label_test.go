@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/containous/traefik/pkg/config"
+	traefiktls "github.com/containous/traefik/pkg/tls"
 	"github.com/containous/traefik/pkg/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -114,6 +115,13 @@ func TestDecodeConfiguration(t *testing.T) {
 		"traefik.http.middlewares.Middleware15.replacepathregex.regex":                         "foobar",
 		"traefik.http.middlewares.Middleware15.replacepathregex.replacement":                   "foobar",
 		"traefik.http.middlewares.Middleware16.retry.attempts":                                 "42",
+		"traefik.http.middlewares.Middleware16.retry.initialinterval":                          "foobar",
+		"traefik.http.middlewares.Middleware16.retry.maxinterval":                              "foobar",
+		"traefik.http.middlewares.Middleware16.retry.statuscodes":                              "foobar, fiibar",
+		"traefik.http.middlewares.Middleware16.retry.retrynonidempotent":                       "true",
+		"traefik.http.middlewares.Middleware16.retry.maxbodysize":                              "42",
+		"traefik.http.middlewares.Middleware16.retry.budget.percent":                           "42",
+		"traefik.http.middlewares.Middleware16.retry.budget.minconcurrency":                    "42",
 		"traefik.http.middlewares.Middleware17.stripprefix.prefixes":                           "foobar, fiibar",
 		"traefik.http.middlewares.Middleware18.stripprefixregex.regex":                         "foobar, fiibar",
 		"traefik.http.middlewares.Middleware19.compress":                                       "true",
@@ -130,46 +138,123 @@ func TestDecodeConfiguration(t *testing.T) {
 		"traefik.http.routers.Router1.rule":        "foobar",
 		"traefik.http.routers.Router1.service":     "foobar",
 
-		"traefik.http.services.Service0.loadbalancer.healthcheck.headers.name0":        "foobar",
-		"traefik.http.services.Service0.loadbalancer.healthcheck.headers.name1":        "foobar",
-		"traefik.http.services.Service0.loadbalancer.healthcheck.hostname":             "foobar",
-		"traefik.http.services.Service0.loadbalancer.healthcheck.interval":             "foobar",
-		"traefik.http.services.Service0.loadbalancer.healthcheck.path":                 "foobar",
-		"traefik.http.services.Service0.loadbalancer.healthcheck.port":                 "42",
-		"traefik.http.services.Service0.loadbalancer.healthcheck.scheme":               "foobar",
-		"traefik.http.services.Service0.loadbalancer.healthcheck.timeout":              "foobar",
-		"traefik.http.services.Service0.loadbalancer.passhostheader":                   "true",
-		"traefik.http.services.Service0.loadbalancer.responseforwarding.flushinterval": "foobar",
-		"traefik.http.services.Service0.loadbalancer.server.scheme":                    "foobar",
-		"traefik.http.services.Service0.loadbalancer.server.port":                      "8080",
-		"traefik.http.services.Service0.loadbalancer.stickiness.cookiename":            "foobar",
-		"traefik.http.services.Service0.loadbalancer.stickiness.securecookie":          "true",
-		"traefik.http.services.Service1.loadbalancer.healthcheck.headers.name0":        "foobar",
-		"traefik.http.services.Service1.loadbalancer.healthcheck.headers.name1":        "foobar",
-		"traefik.http.services.Service1.loadbalancer.healthcheck.hostname":             "foobar",
-		"traefik.http.services.Service1.loadbalancer.healthcheck.interval":             "foobar",
-		"traefik.http.services.Service1.loadbalancer.healthcheck.path":                 "foobar",
-		"traefik.http.services.Service1.loadbalancer.healthcheck.port":                 "42",
-		"traefik.http.services.Service1.loadbalancer.healthcheck.scheme":               "foobar",
-		"traefik.http.services.Service1.loadbalancer.healthcheck.timeout":              "foobar",
-		"traefik.http.services.Service1.loadbalancer.passhostheader":                   "true",
-		"traefik.http.services.Service1.loadbalancer.responseforwarding.flushinterval": "foobar",
-		"traefik.http.services.Service1.loadbalancer.server.scheme":                    "foobar",
-		"traefik.http.services.Service1.loadbalancer.server.port":                      "8080",
-		"traefik.http.services.Service1.loadbalancer.stickiness":                       "false",
-		"traefik.http.services.Service1.loadbalancer.stickiness.cookiename":            "fui",
-		"traefik.tcp.routers.Router0.rule":                                             "foobar",
-		"traefik.tcp.routers.Router0.entrypoints":                                      "foobar, fiibar",
-		"traefik.tcp.routers.Router0.service":                                          "foobar",
-		"traefik.tcp.routers.Router0.tls.passthrough":                                  "false",
-		"traefik.tcp.routers.Router0.tls.options":                                      "foo",
-		"traefik.tcp.routers.Router1.rule":                                             "foobar",
-		"traefik.tcp.routers.Router1.entrypoints":                                      "foobar, fiibar",
-		"traefik.tcp.routers.Router1.service":                                          "foobar",
-		"traefik.tcp.routers.Router1.tls.options":                                      "foo",
-		"traefik.tcp.routers.Router1.tls.passthrough":                                  "false",
-		"traefik.tcp.services.Service0.loadbalancer.server.Port":                       "42",
-		"traefik.tcp.services.Service1.loadbalancer.server.Port":                       "42",
+		"traefik.http.services.Service0.loadbalancer.healthcheck.headers.name0":               "foobar",
+		"traefik.http.services.Service0.loadbalancer.healthcheck.headers.name1":               "foobar",
+		"traefik.http.services.Service0.loadbalancer.healthcheck.hostname":                    "foobar",
+		"traefik.http.services.Service0.loadbalancer.healthcheck.interval":                    "foobar",
+		"traefik.http.services.Service0.loadbalancer.healthcheck.path":                        "foobar",
+		"traefik.http.services.Service0.loadbalancer.healthcheck.port":                        "42",
+		"traefik.http.services.Service0.loadbalancer.healthcheck.scheme":                      "foobar",
+		"traefik.http.services.Service0.loadbalancer.healthcheck.timeout":                     "foobar",
+		"traefik.http.services.Service0.loadbalancer.healthcheck.mode":                        "foobar",
+		"traefik.http.services.Service0.loadbalancer.healthcheck.method":                      "foobar",
+		"traefik.http.services.Service0.loadbalancer.healthcheck.statuscodes":                 "foobar, fiibar",
+		"traefik.http.services.Service0.loadbalancer.healthcheck.bodycontains":                "foobar",
+		"traefik.http.services.Service0.loadbalancer.healthcheck.bodyregexp":                  "foobar",
+		"traefik.http.services.Service0.loadbalancer.healthcheck.grpcservicename":             "foobar",
+		"traefik.http.services.Service0.loadbalancer.healthcheck.successthreshold":            "42",
+		"traefik.http.services.Service0.loadbalancer.healthcheck.failurethreshold":            "42",
+		"traefik.http.services.Service0.loadbalancer.passivehealthcheck.maxconsecutiveerrors": "5",
+		"traefik.http.services.Service0.loadbalancer.passivehealthcheck.window":               "foobar",
+		"traefik.http.services.Service0.loadbalancer.passivehealthcheck.baseejectiontime":     "foobar",
+		"traefik.http.services.Service0.loadbalancer.passivehealthcheck.maxejectiontime":      "foobar",
+		"traefik.http.services.Service0.loadbalancer.passivehealthcheck.maxejectionpercent":   "42",
+		"traefik.http.services.Service0.loadbalancer.hedging.delay":                           "foobar",
+		"traefik.http.services.Service0.loadbalancer.hedging.maxhedges":                       "42",
+		"traefik.http.services.Service0.loadbalancer.hedging.methods":                         "GET, HEAD",
+		"traefik.http.services.Service0.loadbalancer.dnsdiscovery.resolvconfig":               "foobar",
+		"traefik.http.services.Service0.loadbalancer.dnsdiscovery.minrefreshinterval":         "foobar",
+		"traefik.http.services.Service0.loadbalancer.dnsdiscovery.maxrefreshinterval":         "foobar",
+		"traefik.http.services.Service0.loadbalancer.passhostheader":                          "true",
+		"traefik.http.services.Service0.loadbalancer.responseforwarding.flushinterval":        "foobar",
+		"traefik.http.services.Service0.loadbalancer.server.scheme":                           "foobar",
+		"traefik.http.services.Service0.loadbalancer.server.port":                             "8080",
+		"traefik.http.services.Service0.loadbalancer.server.weight":                           "42",
+		"traefik.http.services.Service0.loadbalancer.server.labels.name0":                     "foobar",
+		"traefik.http.services.Service0.loadbalancer.server.labels.name1":                     "foobar",
+		"traefik.http.services.Service0.loadbalancer.serverstransport":                        "foobar",
+		"traefik.http.services.Service0.loadbalancer.slowstart":                               "foobar",
+		"traefik.http.services.Service0.loadbalancer.stickiness.cookiename":                   "foobar",
+		"traefik.http.services.Service0.loadbalancer.stickiness.securecookie":                 "true",
+		"traefik.http.services.Service0.loadbalancer.stickiness.secret":                       "foobar",
+		"traefik.http.services.Service0.loadbalancer.stickiness.sessionheader":                "foobar",
+		"traefik.http.services.Service0.loadbalancer.stickiness.sessioncookie":                "foobar",
+		"traefik.http.services.Service0.loadbalancer.stickiness.fallback":                     "foobar",
+		"traefik.http.services.Service0.loadbalancer.strategy":                                "leastConnections",
+		"traefik.http.services.Service1.loadbalancer.consistenthash.header":                   "foobar",
+		"traefik.http.services.Service1.loadbalancer.strategy":                                "consistentHash",
+		"traefik.http.services.Service1.loadbalancer.healthcheck.headers.name0":               "foobar",
+		"traefik.http.services.Service1.loadbalancer.healthcheck.headers.name1":               "foobar",
+		"traefik.http.services.Service1.loadbalancer.healthcheck.hostname":                    "foobar",
+		"traefik.http.services.Service1.loadbalancer.healthcheck.interval":                    "foobar",
+		"traefik.http.services.Service1.loadbalancer.healthcheck.path":                        "foobar",
+		"traefik.http.services.Service1.loadbalancer.healthcheck.port":                        "42",
+		"traefik.http.services.Service1.loadbalancer.healthcheck.scheme":                      "foobar",
+		"traefik.http.services.Service1.loadbalancer.healthcheck.timeout":                     "foobar",
+		"traefik.http.services.Service1.loadbalancer.healthcheck.mode":                        "foobar",
+		"traefik.http.services.Service1.loadbalancer.healthcheck.method":                      "foobar",
+		"traefik.http.services.Service1.loadbalancer.healthcheck.statuscodes":                 "foobar, fiibar",
+		"traefik.http.services.Service1.loadbalancer.healthcheck.bodycontains":                "foobar",
+		"traefik.http.services.Service1.loadbalancer.healthcheck.bodyregexp":                  "foobar",
+		"traefik.http.services.Service1.loadbalancer.healthcheck.grpcservicename":             "foobar",
+		"traefik.http.services.Service1.loadbalancer.healthcheck.successthreshold":            "42",
+		"traefik.http.services.Service1.loadbalancer.healthcheck.failurethreshold":            "42",
+		"traefik.http.services.Service1.loadbalancer.passhostheader":                          "true",
+		"traefik.http.services.Service1.loadbalancer.responseforwarding.flushinterval":        "foobar",
+		"traefik.http.services.Service1.loadbalancer.server.scheme":                           "foobar",
+		"traefik.http.services.Service1.loadbalancer.server.port":                             "8080",
+		"traefik.http.services.Service1.loadbalancer.server.weight":                           "42",
+		"traefik.http.services.Service1.loadbalancer.server.labels.name0":                     "foobar",
+		"traefik.http.services.Service1.loadbalancer.server.labels.name1":                     "foobar",
+		"traefik.http.services.Service1.loadbalancer.stickiness":                              "false",
+		"traefik.http.services.Service1.loadbalancer.stickiness.cookiename":                   "fui",
+		"traefik.http.services.Service2.weighted.services[0].name":                            "Service0",
+		"traefik.http.services.Service2.weighted.services[0].weight":                          "95",
+		"traefik.http.services.Service2.weighted.services[1].name":                            "Service1",
+		"traefik.http.services.Service2.weighted.services[1].weight":                          "5",
+		"traefik.http.services.Service3.mirroring.service":                                    "Service0",
+		"traefik.http.services.Service3.mirroring.maxbodysize":                                "42",
+		"traefik.http.services.Service3.mirroring.maxinflight":                                "42",
+		"traefik.http.services.Service3.mirroring.timeout":                                    "foobar",
+		"traefik.http.services.Service3.mirroring.mirrors[0].name":                            "Service1",
+		"traefik.http.services.Service3.mirroring.mirrors[0].percent":                         "10",
+		"traefik.http.services.Service4.failover.service":                                     "Service0",
+		"traefik.http.services.Service4.failover.fallback":                                    "Service1",
+		"traefik.http.services.Service4.failover.statuscodes":                                 "500-599, 404",
+		"traefik.http.services.Service4.failover.maxbodysize":                                 "42",
+		"traefik.tcp.routers.Router0.priority":                                                "42",
+		"traefik.tcp.routers.Router0.rule":                                                    "foobar",
+		"traefik.tcp.routers.Router0.entrypoints":                                             "foobar, fiibar",
+		"traefik.tcp.routers.Router0.service":                                                 "foobar",
+		"traefik.tcp.routers.Router0.middlewares":                                             "foobar, fiibar",
+		"traefik.tcp.routers.Router0.tls.passthrough":                                         "false",
+		"traefik.tcp.routers.Router0.tls.options":                                             "foo",
+		"traefik.tcp.routers.Router1.priority":                                                "42",
+		"traefik.tcp.routers.Router1.rule":                                                    "foobar",
+		"traefik.tcp.routers.Router1.entrypoints":                                             "foobar, fiibar",
+		"traefik.tcp.routers.Router1.service":                                                 "foobar",
+		"traefik.tcp.routers.Router1.tls.options":                                             "foo",
+		"traefik.tcp.routers.Router1.tls.passthrough":                                         "false",
+		"traefik.tcp.middlewares.Middleware0.ipwhitelist.sourcerange":                         "foobar, fiibar",
+		"traefik.tcp.middlewares.Middleware1.inflightconn.amount":                             "42",
+		"traefik.tcp.services.Service0.loadbalancer.server.Port":                              "42",
+		"traefik.tcp.services.Service0.loadbalancer.server.weight":                            "42",
+		"traefik.tcp.services.Service0.loadbalancer.strategy":                                 "leastConnections",
+		"traefik.tcp.services.Service0.loadbalancer.healthcheck.interval":                     "foobar",
+		"traefik.tcp.services.Service0.loadbalancer.healthcheck.timeout":                      "foobar",
+		"traefik.tcp.services.Service0.loadbalancer.healthcheck.send":                         "foobar",
+		"traefik.tcp.services.Service0.loadbalancer.healthcheck.expect":                       "foobar",
+		"traefik.tcp.services.Service0.loadbalancer.proxyprotocol.version":                    "1",
+		"traefik.tcp.services.Service0.loadbalancer.terminationdelay":                         "foobar",
+		"traefik.tcp.services.Service0.loadbalancer.idletimeout":                              "foobar",
+		"traefik.tcp.services.Service0.loadbalancer.tls.servername":                           "foobar",
+		"traefik.tcp.services.Service0.loadbalancer.tls.insecureskipverify":                   "true",
+		"traefik.tcp.services.Service0.loadbalancer.tls.rootcas":                              "foobar, fiibar",
+		"traefik.tcp.services.Service1.loadbalancer.server.Port":                              "42",
+		"traefik.tcp.services.Service1.loadbalancer.server.weight":                            "42",
+		"traefik.udp.routers.Router0.entrypoints":                                             "foobar, fiibar",
+		"traefik.udp.routers.Router0.service":                                                 "foobar",
+		"traefik.udp.services.Service0.loadbalancer.server.Port":                              "42",
 	}
 
 	configuration, err := DecodeConfiguration(labels)
@@ -183,8 +268,13 @@ func TestDecodeConfiguration(t *testing.T) {
 						"foobar",
 						"fiibar",
 					},
-					Service: "foobar",
-					Rule:    "foobar",
+					Middlewares: []string{
+						"foobar",
+						"fiibar",
+					},
+					Service:  "foobar",
+					Rule:     "foobar",
+					Priority: 42,
 					TLS: &config.RouterTCPTLSConfig{
 						Passthrough: false,
 						Options:     "foo",
@@ -195,27 +285,81 @@ func TestDecodeConfiguration(t *testing.T) {
 						"foobar",
 						"fiibar",
 					},
-					Service: "foobar",
-					Rule:    "foobar",
+					Service:  "foobar",
+					Rule:     "foobar",
+					Priority: 42,
 					TLS: &config.RouterTCPTLSConfig{
 						Passthrough: false,
 						Options:     "foo",
 					},
 				},
 			},
+			Middlewares: map[string]*config.TCPMiddleware{
+				"Middleware0": {
+					IPWhiteList: &config.TCPIPWhiteList{
+						SourceRange: []string{"foobar", "fiibar"},
+					},
+				},
+				"Middleware1": {
+					InFlightConn: &config.TCPInFlightConn{
+						Amount: 42,
+					},
+				},
+			},
 			Services: map[string]*config.TCPService{
 				"Service0": {
 					LoadBalancer: &config.TCPLoadBalancerService{
+						Strategy: "leastConnections",
 						Servers: []config.TCPServer{
 							{
-								Port: "42",
+								Port:   "42",
+								Weight: 42,
 							},
 						},
+						HealthCheck: &config.TCPHealthCheck{
+							Interval: "foobar",
+							Timeout:  "foobar",
+							Send:     "foobar",
+							Expect:   "foobar",
+						},
+						ProxyProtocol: &config.ProxyProtocol{
+							Version: 1,
+						},
+						TerminationDelay: "foobar",
+						IdleTimeout:      "foobar",
+						TLS: &config.TCPServersTLS{
+							ServerName:         "foobar",
+							InsecureSkipVerify: true,
+							RootCAs:            []traefiktls.FileOrContent{"foobar", "fiibar"},
+						},
 					},
 				},
 				"Service1": {
 					LoadBalancer: &config.TCPLoadBalancerService{
 						Servers: []config.TCPServer{
+							{
+								Port:   "42",
+								Weight: 42,
+							},
+						},
+					},
+				},
+			},
+		},
+		UDP: &config.UDPConfiguration{
+			Routers: map[string]*config.UDPRouter{
+				"Router0": {
+					EntryPoints: []string{
+						"foobar",
+						"fiibar",
+					},
+					Service: "foobar",
+				},
+			},
+			Services: map[string]*config.UDPService{
+				"Service0": {
+					LoadBalancer: &config.UDPLoadBalancerService{
+						Servers: []config.UDPServer{
 							{
 								Port: "42",
 							},
@@ -350,7 +494,16 @@ func TestDecodeConfiguration(t *testing.T) {
 				},
 				"Middleware16": {
 					Retry: &config.Retry{
-						Attempts: 42,
+						Attempts:           42,
+						InitialInterval:    "foobar",
+						MaxInterval:        "foobar",
+						StatusCodes:        []string{"foobar", "fiibar"},
+						RetryNonIdempotent: true,
+						MaxBodySize:        42,
+						Budget: &config.RetryBudget{
+							Percent:        42,
+							MinConcurrency: 42,
+						},
 					},
 				},
 				"Middleware17": {
@@ -509,17 +662,39 @@ func TestDecodeConfiguration(t *testing.T) {
 			Services: map[string]*config.Service{
 				"Service0": {
 					LoadBalancer: &config.LoadBalancerService{
+						Strategy: "leastConnections",
 						Stickiness: &config.Stickiness{
 							CookieName:     "foobar",
 							SecureCookie:   true,
 							HTTPOnlyCookie: false,
+							Secret:         "foobar",
+							SessionHeader:  "foobar",
+							SessionCookie:  "foobar",
+							Fallback:       "foobar",
 						},
 						Servers: []config.Server{
 							{
 								Scheme: "foobar",
 								Port:   "8080",
+								Weight: 42,
+								Labels: map[string]string{
+									"name0": "foobar",
+									"name1": "foobar",
+								},
 							},
 						},
+						ServersTransport: "foobar",
+						SlowStart:        "foobar",
+						Hedging: &config.Hedging{
+							Delay:     "foobar",
+							MaxHedges: 42,
+							Methods:   []string{"GET", "HEAD"},
+						},
+						DNSDiscovery: &config.DNSDiscovery{
+							ResolvConfig:       "foobar",
+							MinRefreshInterval: "foobar",
+							MaxRefreshInterval: "foobar",
+						},
 						HealthCheck: &config.HealthCheck{
 							Scheme:   "foobar",
 							Path:     "foobar",
@@ -531,6 +706,21 @@ func TestDecodeConfiguration(t *testing.T) {
 								"name0": "foobar",
 								"name1": "foobar",
 							},
+							Mode:             "foobar",
+							Method:           "foobar",
+							StatusCodes:      []string{"foobar", "fiibar"},
+							BodyContains:     "foobar",
+							BodyRegexp:       "foobar",
+							GRPCServiceName:  "foobar",
+							SuccessThreshold: 42,
+							FailureThreshold: 42,
+						},
+						PassiveHealthCheck: &config.PassiveHealthCheck{
+							MaxConsecutiveErrors: 5,
+							Window:               "foobar",
+							BaseEjectionTime:     "foobar",
+							MaxEjectionTime:      "foobar",
+							MaxEjectionPercent:   42,
 						},
 						PassHostHeader: true,
 						ResponseForwarding: &config.ResponseForwarding{
@@ -540,10 +730,19 @@ func TestDecodeConfiguration(t *testing.T) {
 				},
 				"Service1": {
 					LoadBalancer: &config.LoadBalancerService{
+						Strategy: "consistentHash",
+						ConsistentHash: &config.ConsistentHash{
+							Header: "foobar",
+						},
 						Servers: []config.Server{
 							{
 								Scheme: "foobar",
 								Port:   "8080",
+								Weight: 42,
+								Labels: map[string]string{
+									"name0": "foobar",
+									"name1": "foobar",
+								},
 							},
 						},
 						HealthCheck: &config.HealthCheck{
@@ -557,6 +756,14 @@ func TestDecodeConfiguration(t *testing.T) {
 								"name0": "foobar",
 								"name1": "foobar",
 							},
+							Mode:             "foobar",
+							Method:           "foobar",
+							StatusCodes:      []string{"foobar", "fiibar"},
+							BodyContains:     "foobar",
+							BodyRegexp:       "foobar",
+							GRPCServiceName:  "foobar",
+							SuccessThreshold: 42,
+							FailureThreshold: 42,
 						},
 						PassHostHeader: true,
 						ResponseForwarding: &config.ResponseForwarding{
@@ -564,6 +771,33 @@ func TestDecodeConfiguration(t *testing.T) {
 						},
 					},
 				},
+				"Service2": {
+					Weighted: &config.WeightedService{
+						Services: []config.WeightedServiceRef{
+							{Name: "Service0", Weight: 95},
+							{Name: "Service1", Weight: 5},
+						},
+					},
+				},
+				"Service3": {
+					Mirroring: &config.MirroringService{
+						Service:     "Service0",
+						MaxBodySize: 42,
+						MaxInFlight: 42,
+						Timeout:     "foobar",
+						Mirrors: []config.MirrorService{
+							{Name: "Service1", Percent: 10},
+						},
+					},
+				},
+				"Service4": {
+					Failover: &config.FailoverService{
+						Service:     "Service0",
+						Fallback:    "Service1",
+						StatusCodes: []string{"500-599", "404"},
+						MaxBodySize: 42,
+					},
+				},
 			},
 		},
 	}
@@ -580,8 +814,13 @@ func TestEncodeConfiguration(t *testing.T) {
 						"foobar",
 						"fiibar",
 					},
-					Service: "foobar",
-					Rule:    "foobar",
+					Middlewares: []string{
+						"foobar",
+						"fiibar",
+					},
+					Service:  "foobar",
+					Rule:     "foobar",
+					Priority: 42,
 					TLS: &config.RouterTCPTLSConfig{
 						Passthrough: false,
 						Options:     "foo",
@@ -592,27 +831,81 @@ func TestEncodeConfiguration(t *testing.T) {
 						"foobar",
 						"fiibar",
 					},
-					Service: "foobar",
-					Rule:    "foobar",
+					Service:  "foobar",
+					Rule:     "foobar",
+					Priority: 42,
 					TLS: &config.RouterTCPTLSConfig{
 						Passthrough: false,
 						Options:     "foo",
 					},
 				},
 			},
+			Middlewares: map[string]*config.TCPMiddleware{
+				"Middleware0": {
+					IPWhiteList: &config.TCPIPWhiteList{
+						SourceRange: []string{"foobar", "fiibar"},
+					},
+				},
+				"Middleware1": {
+					InFlightConn: &config.TCPInFlightConn{
+						Amount: 42,
+					},
+				},
+			},
 			Services: map[string]*config.TCPService{
 				"Service0": {
 					LoadBalancer: &config.TCPLoadBalancerService{
+						Strategy: "leastConnections",
 						Servers: []config.TCPServer{
 							{
-								Port: "42",
+								Port:   "42",
+								Weight: 42,
 							},
 						},
+						HealthCheck: &config.TCPHealthCheck{
+							Interval: "foobar",
+							Timeout:  "foobar",
+							Send:     "foobar",
+							Expect:   "foobar",
+						},
+						ProxyProtocol: &config.ProxyProtocol{
+							Version: 1,
+						},
+						TerminationDelay: "foobar",
+						IdleTimeout:      "foobar",
+						TLS: &config.TCPServersTLS{
+							ServerName:         "foobar",
+							InsecureSkipVerify: true,
+							RootCAs:            []traefiktls.FileOrContent{"foobar", "fiibar"},
+						},
 					},
 				},
 				"Service1": {
 					LoadBalancer: &config.TCPLoadBalancerService{
 						Servers: []config.TCPServer{
+							{
+								Port:   "42",
+								Weight: 42,
+							},
+						},
+					},
+				},
+			},
+		},
+		UDP: &config.UDPConfiguration{
+			Routers: map[string]*config.UDPRouter{
+				"Router0": {
+					EntryPoints: []string{
+						"foobar",
+						"fiibar",
+					},
+					Service: "foobar",
+				},
+			},
+			Services: map[string]*config.UDPService{
+				"Service0": {
+					LoadBalancer: &config.UDPLoadBalancerService{
+						Servers: []config.UDPServer{
 							{
 								Port: "42",
 							},
@@ -746,7 +1039,16 @@ func TestEncodeConfiguration(t *testing.T) {
 				},
 				"Middleware16": {
 					Retry: &config.Retry{
-						Attempts: 42,
+						Attempts:           42,
+						InitialInterval:    "foobar",
+						MaxInterval:        "foobar",
+						StatusCodes:        []string{"foobar", "fiibar"},
+						RetryNonIdempotent: true,
+						MaxBodySize:        42,
+						Budget: &config.RetryBudget{
+							Percent:        42,
+							MinConcurrency: 42,
+						},
 					},
 				},
 				"Middleware17": {
@@ -905,16 +1207,38 @@ func TestEncodeConfiguration(t *testing.T) {
 			Services: map[string]*config.Service{
 				"Service0": {
 					LoadBalancer: &config.LoadBalancerService{
+						Strategy: "leastConnections",
 						Stickiness: &config.Stickiness{
 							CookieName:     "foobar",
 							HTTPOnlyCookie: true,
+							Secret:         "foobar",
+							SessionHeader:  "foobar",
+							SessionCookie:  "foobar",
+							Fallback:       "foobar",
 						},
 						Servers: []config.Server{
 							{
 								Scheme: "foobar",
 								Port:   "8080",
+								Weight: 42,
+								Labels: map[string]string{
+									"name0": "foobar",
+									"name1": "foobar",
+								},
 							},
 						},
+						ServersTransport: "foobar",
+						SlowStart:        "foobar",
+						Hedging: &config.Hedging{
+							Delay:     "foobar",
+							MaxHedges: 42,
+							Methods:   []string{"GET", "HEAD"},
+						},
+						DNSDiscovery: &config.DNSDiscovery{
+							ResolvConfig:       "foobar",
+							MinRefreshInterval: "foobar",
+							MaxRefreshInterval: "foobar",
+						},
 						HealthCheck: &config.HealthCheck{
 							Scheme:   "foobar",
 							Path:     "foobar",
@@ -926,6 +1250,21 @@ func TestEncodeConfiguration(t *testing.T) {
 								"name0": "foobar",
 								"name1": "foobar",
 							},
+							Mode:             "foobar",
+							Method:           "foobar",
+							StatusCodes:      []string{"foobar", "fiibar"},
+							BodyContains:     "foobar",
+							BodyRegexp:       "foobar",
+							GRPCServiceName:  "foobar",
+							SuccessThreshold: 42,
+							FailureThreshold: 42,
+						},
+						PassiveHealthCheck: &config.PassiveHealthCheck{
+							MaxConsecutiveErrors: 5,
+							Window:               "foobar",
+							BaseEjectionTime:     "foobar",
+							MaxEjectionTime:      "foobar",
+							MaxEjectionPercent:   42,
 						},
 						PassHostHeader: true,
 						ResponseForwarding: &config.ResponseForwarding{
@@ -935,10 +1274,19 @@ func TestEncodeConfiguration(t *testing.T) {
 				},
 				"Service1": {
 					LoadBalancer: &config.LoadBalancerService{
+						Strategy: "consistentHash",
+						ConsistentHash: &config.ConsistentHash{
+							Header: "foobar",
+						},
 						Servers: []config.Server{
 							{
 								Scheme: "foobar",
 								Port:   "8080",
+								Weight: 42,
+								Labels: map[string]string{
+									"name0": "foobar",
+									"name1": "foobar",
+								},
 							},
 						},
 						HealthCheck: &config.HealthCheck{
@@ -952,6 +1300,14 @@ func TestEncodeConfiguration(t *testing.T) {
 								"name0": "foobar",
 								"name1": "foobar",
 							},
+							Mode:             "foobar",
+							Method:           "foobar",
+							StatusCodes:      []string{"foobar", "fiibar"},
+							BodyContains:     "foobar",
+							BodyRegexp:       "foobar",
+							GRPCServiceName:  "foobar",
+							SuccessThreshold: 42,
+							FailureThreshold: 42,
 						},
 						PassHostHeader: true,
 						ResponseForwarding: &config.ResponseForwarding{
@@ -959,6 +1315,33 @@ func TestEncodeConfiguration(t *testing.T) {
 						},
 					},
 				},
+				"Service2": {
+					Weighted: &config.WeightedService{
+						Services: []config.WeightedServiceRef{
+							{Name: "Service0", Weight: 95},
+							{Name: "Service1", Weight: 5},
+						},
+					},
+				},
+				"Service3": {
+					Mirroring: &config.MirroringService{
+						Service:     "Service0",
+						MaxBodySize: 42,
+						MaxInFlight: 42,
+						Timeout:     "foobar",
+						Mirrors: []config.MirrorService{
+							{Name: "Service1", Percent: 10},
+						},
+					},
+				},
+				"Service4": {
+					Failover: &config.FailoverService{
+						Service:     "Service0",
+						Fallback:    "Service1",
+						StatusCodes: []string{"500-599", "404"},
+						MaxBodySize: 42,
+					},
+				},
 			},
 		},
 	}
@@ -1068,6 +1451,13 @@ func TestEncodeConfiguration(t *testing.T) {
 		"traefik.HTTP.Middlewares.Middleware15.ReplacePathRegex.Regex":                         "foobar",
 		"traefik.HTTP.Middlewares.Middleware15.ReplacePathRegex.Replacement":                   "foobar",
 		"traefik.HTTP.Middlewares.Middleware16.Retry.Attempts":                                 "42",
+		"traefik.HTTP.Middlewares.Middleware16.Retry.InitialInterval":                          "foobar",
+		"traefik.HTTP.Middlewares.Middleware16.Retry.MaxInterval":                              "foobar",
+		"traefik.HTTP.Middlewares.Middleware16.Retry.StatusCodes":                              "foobar, fiibar",
+		"traefik.HTTP.Middlewares.Middleware16.Retry.RetryNonIdempotent":                       "true",
+		"traefik.HTTP.Middlewares.Middleware16.Retry.MaxBodySize":                              "42",
+		"traefik.HTTP.Middlewares.Middleware16.Retry.Budget.Percent":                           "42",
+		"traefik.HTTP.Middlewares.Middleware16.Retry.Budget.MinConcurrency":                    "42",
 		"traefik.HTTP.Middlewares.Middleware17.StripPrefix.Prefixes":                           "foobar, fiibar",
 		"traefik.HTTP.Middlewares.Middleware18.StripPrefixRegex.Regex":                         "foobar, fiibar",
 		"traefik.HTTP.Middlewares.Middleware19.Compress":                                       "true",
@@ -1084,46 +1474,124 @@ func TestEncodeConfiguration(t *testing.T) {
 		"traefik.HTTP.Routers.Router1.Rule":        "foobar",
 		"traefik.HTTP.Routers.Router1.Service":     "foobar",
 
-		"traefik.HTTP.Services.Service0.LoadBalancer.HealthCheck.Headers.name1":        "foobar",
-		"traefik.HTTP.Services.Service0.LoadBalancer.HealthCheck.Hostname":             "foobar",
-		"traefik.HTTP.Services.Service0.LoadBalancer.HealthCheck.Interval":             "foobar",
-		"traefik.HTTP.Services.Service0.LoadBalancer.HealthCheck.Path":                 "foobar",
-		"traefik.HTTP.Services.Service0.LoadBalancer.HealthCheck.Port":                 "42",
-		"traefik.HTTP.Services.Service0.LoadBalancer.HealthCheck.Scheme":               "foobar",
-		"traefik.HTTP.Services.Service0.LoadBalancer.HealthCheck.Timeout":              "foobar",
-		"traefik.HTTP.Services.Service0.LoadBalancer.PassHostHeader":                   "true",
-		"traefik.HTTP.Services.Service0.LoadBalancer.ResponseForwarding.FlushInterval": "foobar",
-		"traefik.HTTP.Services.Service0.LoadBalancer.server.Port":                      "8080",
-		"traefik.HTTP.Services.Service0.LoadBalancer.server.Scheme":                    "foobar",
-		"traefik.HTTP.Services.Service0.LoadBalancer.Stickiness.CookieName":            "foobar",
-		"traefik.HTTP.Services.Service0.LoadBalancer.Stickiness.HTTPOnlyCookie":        "true",
-		"traefik.HTTP.Services.Service0.LoadBalancer.Stickiness.SecureCookie":          "false",
-		"traefik.HTTP.Services.Service1.LoadBalancer.HealthCheck.Headers.name0":        "foobar",
-		"traefik.HTTP.Services.Service1.LoadBalancer.HealthCheck.Headers.name1":        "foobar",
-		"traefik.HTTP.Services.Service1.LoadBalancer.HealthCheck.Hostname":             "foobar",
-		"traefik.HTTP.Services.Service1.LoadBalancer.HealthCheck.Interval":             "foobar",
-		"traefik.HTTP.Services.Service1.LoadBalancer.HealthCheck.Path":                 "foobar",
-		"traefik.HTTP.Services.Service1.LoadBalancer.HealthCheck.Port":                 "42",
-		"traefik.HTTP.Services.Service1.LoadBalancer.HealthCheck.Scheme":               "foobar",
-		"traefik.HTTP.Services.Service1.LoadBalancer.HealthCheck.Timeout":              "foobar",
-		"traefik.HTTP.Services.Service1.LoadBalancer.PassHostHeader":                   "true",
-		"traefik.HTTP.Services.Service1.LoadBalancer.ResponseForwarding.FlushInterval": "foobar",
-		"traefik.HTTP.Services.Service1.LoadBalancer.server.Port":                      "8080",
-		"traefik.HTTP.Services.Service1.LoadBalancer.server.Scheme":                    "foobar",
-		"traefik.HTTP.Services.Service0.LoadBalancer.HealthCheck.Headers.name0":        "foobar",
+		"traefik.HTTP.Services.Service0.LoadBalancer.HealthCheck.Headers.name1":               "foobar",
+		"traefik.HTTP.Services.Service0.LoadBalancer.HealthCheck.Hostname":                    "foobar",
+		"traefik.HTTP.Services.Service0.LoadBalancer.HealthCheck.Interval":                    "foobar",
+		"traefik.HTTP.Services.Service0.LoadBalancer.HealthCheck.Path":                        "foobar",
+		"traefik.HTTP.Services.Service0.LoadBalancer.HealthCheck.Port":                        "42",
+		"traefik.HTTP.Services.Service0.LoadBalancer.HealthCheck.Scheme":                      "foobar",
+		"traefik.HTTP.Services.Service0.LoadBalancer.HealthCheck.Timeout":                     "foobar",
+		"traefik.HTTP.Services.Service0.LoadBalancer.HealthCheck.Mode":                        "foobar",
+		"traefik.HTTP.Services.Service0.LoadBalancer.HealthCheck.Method":                      "foobar",
+		"traefik.HTTP.Services.Service0.LoadBalancer.HealthCheck.StatusCodes":                 "foobar, fiibar",
+		"traefik.HTTP.Services.Service0.LoadBalancer.HealthCheck.BodyContains":                "foobar",
+		"traefik.HTTP.Services.Service0.LoadBalancer.HealthCheck.BodyRegexp":                  "foobar",
+		"traefik.HTTP.Services.Service0.LoadBalancer.HealthCheck.GRPCServiceName":             "foobar",
+		"traefik.HTTP.Services.Service0.LoadBalancer.HealthCheck.SuccessThreshold":            "42",
+		"traefik.HTTP.Services.Service0.LoadBalancer.HealthCheck.FailureThreshold":            "42",
+		"traefik.HTTP.Services.Service0.LoadBalancer.PassiveHealthCheck.MaxConsecutiveErrors": "5",
+		"traefik.HTTP.Services.Service0.LoadBalancer.PassiveHealthCheck.Window":               "foobar",
+		"traefik.HTTP.Services.Service0.LoadBalancer.PassiveHealthCheck.BaseEjectionTime":     "foobar",
+		"traefik.HTTP.Services.Service0.LoadBalancer.PassiveHealthCheck.MaxEjectionTime":      "foobar",
+		"traefik.HTTP.Services.Service0.LoadBalancer.PassiveHealthCheck.MaxEjectionPercent":   "42",
+		"traefik.HTTP.Services.Service0.LoadBalancer.Hedging.Delay":                           "foobar",
+		"traefik.HTTP.Services.Service0.LoadBalancer.Hedging.MaxHedges":                       "42",
+		"traefik.HTTP.Services.Service0.LoadBalancer.Hedging.Methods":                         "GET, HEAD",
+		"traefik.HTTP.Services.Service0.LoadBalancer.DNSDiscovery.ResolvConfig":               "foobar",
+		"traefik.HTTP.Services.Service0.LoadBalancer.DNSDiscovery.MinRefreshInterval":         "foobar",
+		"traefik.HTTP.Services.Service0.LoadBalancer.DNSDiscovery.MaxRefreshInterval":         "foobar",
+		"traefik.HTTP.Services.Service0.LoadBalancer.PassHostHeader":                          "true",
+		"traefik.HTTP.Services.Service0.LoadBalancer.ResponseForwarding.FlushInterval":        "foobar",
+		"traefik.HTTP.Services.Service0.LoadBalancer.server.Port":                             "8080",
+		"traefik.HTTP.Services.Service0.LoadBalancer.ServersTransport":                        "foobar",
+		"traefik.HTTP.Services.Service0.LoadBalancer.SlowStart":                               "foobar",
+		"traefik.HTTP.Services.Service0.LoadBalancer.server.Scheme":                           "foobar",
+		"traefik.HTTP.Services.Service0.LoadBalancer.server.Weight":                           "42",
+		"traefik.HTTP.Services.Service0.LoadBalancer.server.Labels.name0":                     "foobar",
+		"traefik.HTTP.Services.Service0.LoadBalancer.server.Labels.name1":                     "foobar",
+		"traefik.HTTP.Services.Service0.LoadBalancer.Stickiness.CookieName":                   "foobar",
+		"traefik.HTTP.Services.Service0.LoadBalancer.Stickiness.HTTPOnlyCookie":               "true",
+		"traefik.HTTP.Services.Service0.LoadBalancer.Stickiness.SecureCookie":                 "false",
+		"traefik.HTTP.Services.Service0.LoadBalancer.Stickiness.Secret":                       "foobar",
+		"traefik.HTTP.Services.Service0.LoadBalancer.Stickiness.SessionHeader":                "foobar",
+		"traefik.HTTP.Services.Service0.LoadBalancer.Stickiness.SessionCookie":                "foobar",
+		"traefik.HTTP.Services.Service0.LoadBalancer.Stickiness.Fallback":                     "foobar",
+		"traefik.HTTP.Services.Service0.LoadBalancer.Strategy":                                "leastConnections",
+		"traefik.HTTP.Services.Service1.LoadBalancer.ConsistentHash.Header":                   "foobar",
+		"traefik.HTTP.Services.Service1.LoadBalancer.ConsistentHash.Path":                     "false",
+		"traefik.HTTP.Services.Service1.LoadBalancer.HealthCheck.Headers.name0":               "foobar",
+		"traefik.HTTP.Services.Service1.LoadBalancer.HealthCheck.Headers.name1":               "foobar",
+		"traefik.HTTP.Services.Service1.LoadBalancer.Strategy":                                "consistentHash",
+		"traefik.HTTP.Services.Service1.LoadBalancer.HealthCheck.Hostname":                    "foobar",
+		"traefik.HTTP.Services.Service1.LoadBalancer.HealthCheck.Interval":                    "foobar",
+		"traefik.HTTP.Services.Service1.LoadBalancer.HealthCheck.Path":                        "foobar",
+		"traefik.HTTP.Services.Service1.LoadBalancer.HealthCheck.Port":                        "42",
+		"traefik.HTTP.Services.Service1.LoadBalancer.HealthCheck.Scheme":                      "foobar",
+		"traefik.HTTP.Services.Service1.LoadBalancer.HealthCheck.Timeout":                     "foobar",
+		"traefik.HTTP.Services.Service1.LoadBalancer.HealthCheck.Mode":                        "foobar",
+		"traefik.HTTP.Services.Service1.LoadBalancer.HealthCheck.Method":                      "foobar",
+		"traefik.HTTP.Services.Service1.LoadBalancer.HealthCheck.StatusCodes":                 "foobar, fiibar",
+		"traefik.HTTP.Services.Service1.LoadBalancer.HealthCheck.BodyContains":                "foobar",
+		"traefik.HTTP.Services.Service1.LoadBalancer.HealthCheck.BodyRegexp":                  "foobar",
+		"traefik.HTTP.Services.Service1.LoadBalancer.HealthCheck.GRPCServiceName":             "foobar",
+		"traefik.HTTP.Services.Service1.LoadBalancer.HealthCheck.SuccessThreshold":            "42",
+		"traefik.HTTP.Services.Service1.LoadBalancer.HealthCheck.FailureThreshold":            "42",
+		"traefik.HTTP.Services.Service1.LoadBalancer.PassHostHeader":                          "true",
+		"traefik.HTTP.Services.Service1.LoadBalancer.ResponseForwarding.FlushInterval":        "foobar",
+		"traefik.HTTP.Services.Service1.LoadBalancer.server.Port":                             "8080",
+		"traefik.HTTP.Services.Service1.LoadBalancer.server.Scheme":                           "foobar",
+		"traefik.HTTP.Services.Service1.LoadBalancer.server.Weight":                           "42",
+		"traefik.HTTP.Services.Service1.LoadBalancer.server.Labels.name0":                     "foobar",
+		"traefik.HTTP.Services.Service1.LoadBalancer.server.Labels.name1":                     "foobar",
+		"traefik.HTTP.Services.Service2.Weighted.Services[0].Name":                            "Service0",
+		"traefik.HTTP.Services.Service2.Weighted.Services[0].Weight":                          "95",
+		"traefik.HTTP.Services.Service2.Weighted.Services[1].Name":                            "Service1",
+		"traefik.HTTP.Services.Service2.Weighted.Services[1].Weight":                          "5",
+		"traefik.HTTP.Services.Service3.Mirroring.Service":                                    "Service0",
+		"traefik.HTTP.Services.Service3.Mirroring.MaxBodySize":                                "42",
+		"traefik.HTTP.Services.Service3.Mirroring.MaxInFlight":                                "42",
+		"traefik.HTTP.Services.Service3.Mirroring.Timeout":                                    "foobar",
+		"traefik.HTTP.Services.Service3.Mirroring.Mirrors[0].Name":                            "Service1",
+		"traefik.HTTP.Services.Service3.Mirroring.Mirrors[0].Percent":                         "10",
+		"traefik.HTTP.Services.Service4.Failover.Service":                                     "Service0",
+		"traefik.HTTP.Services.Service4.Failover.Fallback":                                    "Service1",
+		"traefik.HTTP.Services.Service4.Failover.StatusCodes":                                 "500-599, 404",
+		"traefik.HTTP.Services.Service4.Failover.MaxBodySize":                                 "42",
+		"traefik.HTTP.Services.Service0.LoadBalancer.HealthCheck.Headers.name0":               "foobar",
 
-		"traefik.TCP.Routers.Router0.Rule":                       "foobar",
-		"traefik.TCP.Routers.Router0.EntryPoints":                "foobar, fiibar",
-		"traefik.TCP.Routers.Router0.Service":                    "foobar",
-		"traefik.TCP.Routers.Router0.TLS.Passthrough":            "false",
-		"traefik.TCP.Routers.Router0.TLS.Options":                "foo",
-		"traefik.TCP.Routers.Router1.Rule":                       "foobar",
-		"traefik.TCP.Routers.Router1.EntryPoints":                "foobar, fiibar",
-		"traefik.TCP.Routers.Router1.Service":                    "foobar",
-		"traefik.TCP.Routers.Router1.TLS.Passthrough":            "false",
-		"traefik.TCP.Routers.Router1.TLS.Options":                "foo",
-		"traefik.TCP.Services.Service0.LoadBalancer.server.Port": "42",
-		"traefik.TCP.Services.Service1.LoadBalancer.server.Port": "42",
+		"traefik.TCP.Routers.Router0.Priority":                              "42",
+		"traefik.TCP.Routers.Router0.Rule":                                  "foobar",
+		"traefik.TCP.Routers.Router0.EntryPoints":                           "foobar, fiibar",
+		"traefik.TCP.Routers.Router0.Service":                               "foobar",
+		"traefik.TCP.Routers.Router0.Middlewares":                           "foobar, fiibar",
+		"traefik.TCP.Routers.Router0.TLS.Passthrough":                       "false",
+		"traefik.TCP.Routers.Router0.TLS.Options":                           "foo",
+		"traefik.TCP.Routers.Router1.Priority":                              "42",
+		"traefik.TCP.Routers.Router1.Rule":                                  "foobar",
+		"traefik.TCP.Routers.Router1.EntryPoints":                           "foobar, fiibar",
+		"traefik.TCP.Routers.Router1.Service":                               "foobar",
+		"traefik.TCP.Routers.Router1.TLS.Passthrough":                       "false",
+		"traefik.TCP.Routers.Router1.TLS.Options":                           "foo",
+		"traefik.TCP.Middlewares.Middleware0.IPWhiteList.SourceRange":       "foobar, fiibar",
+		"traefik.TCP.Middlewares.Middleware1.InFlightConn.Amount":           "42",
+		"traefik.TCP.Services.Service0.LoadBalancer.server.Port":            "42",
+		"traefik.TCP.Services.Service0.LoadBalancer.server.Weight":          "42",
+		"traefik.TCP.Services.Service0.LoadBalancer.Strategy":               "leastConnections",
+		"traefik.TCP.Services.Service0.LoadBalancer.HealthCheck.Interval":   "foobar",
+		"traefik.TCP.Services.Service0.LoadBalancer.HealthCheck.Timeout":    "foobar",
+		"traefik.TCP.Services.Service0.LoadBalancer.HealthCheck.Send":       "foobar",
+		"traefik.TCP.Services.Service0.LoadBalancer.HealthCheck.Expect":     "foobar",
+		"traefik.TCP.Services.Service0.LoadBalancer.ProxyProtocol.Version":  "1",
+		"traefik.TCP.Services.Service0.LoadBalancer.TerminationDelay":       "foobar",
+		"traefik.TCP.Services.Service0.LoadBalancer.IdleTimeout":            "foobar",
+		"traefik.TCP.Services.Service0.LoadBalancer.TLS.ServerName":         "foobar",
+		"traefik.TCP.Services.Service0.LoadBalancer.TLS.InsecureSkipVerify": "true",
+		"traefik.TCP.Services.Service0.LoadBalancer.TLS.RootCAs":            "foobar, fiibar",
+		"traefik.TCP.Services.Service1.LoadBalancer.server.Port":            "42",
+		"traefik.TCP.Services.Service1.LoadBalancer.server.Weight":          "42",
+		"traefik.UDP.Routers.Router0.EntryPoints":                           "foobar, fiibar",
+		"traefik.UDP.Routers.Router0.Service":                               "foobar",
+		"traefik.UDP.Services.Service0.LoadBalancer.server.Port":            "42",
 	}
 
 	for key, val := range expected {
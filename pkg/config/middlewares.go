@@ -330,6 +330,29 @@ type ReplacePathRegex struct {
 // Retry holds the retry configuration.
 type Retry struct {
 	Attempts int `description:"Number of attempts" export:"true"`
+	// InitialInterval is the duration waited before the first retry, doubled for each next retry and randomized (no wait by default).
+	InitialInterval string `json:"initialInterval,omitempty"`
+	// MaxInterval caps the duration waited before a retry.
+	MaxInterval string `json:"maxInterval,omitempty"`
+	// StatusCodes are the status codes of the responses for which the request is retried, e.g. "502-504".
+	StatusCodes []string `json:"statusCodes,omitempty"`
+	// RetryNonIdempotent allows to retry the requests with a non-idempotent method (e.g. POST)
+	// once they were sent to a server.
+	RetryNonIdempotent bool `json:"retryNonIdempotent,omitempty"`
+	// MaxBodySize is the maximum size (in bytes) of the request bodies buffered to be replayed to the servers.
+	// The requests with a larger body are not retried because of the status code of the response.
+	MaxBodySize int64        `json:"maxBodySize,omitempty"`
+	Budget      *RetryBudget `json:"budget,omitempty" label:"allowEmpty"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// RetryBudget limits the number of concurrent retries, to prevent retry storms.
+type RetryBudget struct {
+	// Percent is the maximum number of concurrent retries, as a percentage of the requests in flight (default: 20).
+	Percent int `json:"percent,omitempty"`
+	// MinConcurrency is the number of concurrent retries always allowed, whatever the number of requests in flight (default: 3).
+	MinConcurrency int `json:"minConcurrency,omitempty"`
 }
 
 // +k8s:deepcopy-gen=true
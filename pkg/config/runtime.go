@@ -5,22 +5,31 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 
 	"github.com/containous/traefik/pkg/log"
 )
 
+const (
+	statusUp   = "UP"
+	statusDown = "DOWN"
+)
+
 // RuntimeConfiguration holds the information about the currently running traefik instance.
 type RuntimeConfiguration struct {
-	Routers     map[string]*RouterInfo     `json:"routers,omitempty"`
-	Middlewares map[string]*MiddlewareInfo `json:"middlewares,omitempty"`
-	Services    map[string]*ServiceInfo    `json:"services,omitempty"`
-	TCPRouters  map[string]*TCPRouterInfo  `json:"tcpRouters,omitempty"`
-	TCPServices map[string]*TCPServiceInfo `json:"tcpServices,omitempty"`
+	Routers        map[string]*RouterInfo        `json:"routers,omitempty"`
+	Middlewares    map[string]*MiddlewareInfo    `json:"middlewares,omitempty"`
+	Services       map[string]*ServiceInfo       `json:"services,omitempty"`
+	TCPRouters     map[string]*TCPRouterInfo     `json:"tcpRouters,omitempty"`
+	TCPMiddlewares map[string]*TCPMiddlewareInfo `json:"tcpMiddlewares,omitempty"`
+	TCPServices    map[string]*TCPServiceInfo    `json:"tcpServices,omitempty"`
+	UDPRouters     map[string]*UDPRouterInfo     `json:"udpRouters,omitempty"`
+	UDPServices    map[string]*UDPServiceInfo    `json:"udpServices,omitempty"`
 }
 
 // NewRuntimeConfig returns a RuntimeConfiguration initialized with the given conf. It never returns nil.
 func NewRuntimeConfig(conf Configuration) *RuntimeConfiguration {
-	if conf.HTTP == nil && conf.TCP == nil {
+	if conf.HTTP == nil && conf.TCP == nil && conf.UDP == nil {
 		return &RuntimeConfiguration{}
 	}
 
@@ -66,6 +75,29 @@ func NewRuntimeConfig(conf Configuration) *RuntimeConfiguration {
 				runtimeConfig.TCPServices[k] = &TCPServiceInfo{TCPService: v}
 			}
 		}
+
+		if len(conf.TCP.Middlewares) > 0 {
+			runtimeConfig.TCPMiddlewares = make(map[string]*TCPMiddlewareInfo, len(conf.TCP.Middlewares))
+			for k, v := range conf.TCP.Middlewares {
+				runtimeConfig.TCPMiddlewares[k] = &TCPMiddlewareInfo{TCPMiddleware: v}
+			}
+		}
+	}
+
+	if conf.UDP != nil {
+		if len(conf.UDP.Routers) > 0 {
+			runtimeConfig.UDPRouters = make(map[string]*UDPRouterInfo, len(conf.UDP.Routers))
+			for k, v := range conf.UDP.Routers {
+				runtimeConfig.UDPRouters[k] = &UDPRouterInfo{UDPRouter: v}
+			}
+		}
+
+		if len(conf.UDP.Services) > 0 {
+			runtimeConfig.UDPServices = make(map[string]*UDPServiceInfo, len(conf.UDP.Services))
+			for k, v := range conf.UDP.Services {
+				runtimeConfig.UDPServices[k] = &UDPServiceInfo{UDPService: v}
+			}
+		}
 	}
 
 	return runtimeConfig
@@ -117,6 +149,14 @@ func (r *RuntimeConfiguration) PopulateUsedBy() {
 			continue
 		}
 
+		for _, midName := range routerInfo.TCPRouter.Middlewares {
+			fullMidName := getQualifiedName(providerName, midName)
+			if _, ok := r.TCPMiddlewares[fullMidName]; !ok {
+				continue
+			}
+			r.TCPMiddlewares[fullMidName].UsedBy = append(r.TCPMiddlewares[fullMidName].UsedBy, routerName)
+		}
+
 		serviceName := getQualifiedName(providerName, routerInfo.TCPRouter.Service)
 		if _, ok := r.TCPServices[serviceName]; !ok {
 			continue
@@ -127,6 +167,28 @@ func (r *RuntimeConfiguration) PopulateUsedBy() {
 	for k := range r.TCPServices {
 		sort.Strings(r.TCPServices[k].UsedBy)
 	}
+
+	for k := range r.TCPMiddlewares {
+		sort.Strings(r.TCPMiddlewares[k].UsedBy)
+	}
+
+	for routerName, routerInfo := range r.UDPRouters {
+		providerName := getProviderName(routerName)
+		if providerName == "" {
+			logger.WithField(log.RouterName, routerName).Error("udp router name is not fully qualified")
+			continue
+		}
+
+		serviceName := getQualifiedName(providerName, routerInfo.UDPRouter.Service)
+		if _, ok := r.UDPServices[serviceName]; !ok {
+			continue
+		}
+		r.UDPServices[serviceName].UsedBy = append(r.UDPServices[serviceName].UsedBy, routerName)
+	}
+
+	for k := range r.UDPServices {
+		sort.Strings(r.UDPServices[k].UsedBy)
+	}
 }
 
 func contains(entryPoints []string, entryPointName string) bool {
@@ -197,6 +259,34 @@ func (r *RuntimeConfiguration) GetTCPRoutersByEntrypoints(ctx context.Context, e
 	return entryPointsRouters
 }
 
+// GetUDPRoutersByEntrypoints returns all the udp routers by entrypoints name and routers name
+func (r *RuntimeConfiguration) GetUDPRoutersByEntrypoints(ctx context.Context, entryPoints []string) map[string]map[string]*UDPRouterInfo {
+	entryPointsRouters := make(map[string]map[string]*UDPRouterInfo)
+
+	for rtName, rt := range r.UDPRouters {
+		eps := rt.EntryPoints
+		if len(eps) == 0 {
+			eps = entryPoints
+		}
+
+		for _, entryPointName := range eps {
+			if !contains(entryPoints, entryPointName) {
+				log.FromContext(log.With(ctx, log.Str(log.EntryPointName, entryPointName))).
+					Errorf("entryPoint %q doesn't exist", entryPointName)
+				continue
+			}
+
+			if _, ok := entryPointsRouters[entryPointName]; !ok {
+				entryPointsRouters[entryPointName] = make(map[string]*UDPRouterInfo)
+			}
+
+			entryPointsRouters[entryPointName][rtName] = rt
+		}
+	}
+
+	return entryPointsRouters
+}
+
 // RouterInfo holds information about a currently running HTTP router
 type RouterInfo struct {
 	*Router        // dynamic configuration
@@ -209,6 +299,12 @@ type TCPRouterInfo struct {
 	Err        string `json:"error,omitempty"` // initialization error
 }
 
+// UDPRouterInfo holds information about a currently running UDP router
+type UDPRouterInfo struct {
+	*UDPRouter        // dynamic configuration
+	Err        string `json:"error,omitempty"` // initialization error
+}
+
 // MiddlewareInfo holds information about a currently running middleware
 type MiddlewareInfo struct {
 	*Middleware          // dynamic configuration
@@ -216,6 +312,13 @@ type MiddlewareInfo struct {
 	UsedBy      []string `json:"usedBy,omitempty"` // list of routers and services using that middleware
 }
 
+// TCPMiddlewareInfo holds information about a currently running TCP middleware
+type TCPMiddlewareInfo struct {
+	*TCPMiddleware          // dynamic configuration
+	Err            error    `json:"error,omitempty"`  // initialization error
+	UsedBy         []string `json:"usedBy,omitempty"` // list of TCP routers using that middleware
+}
+
 // ServiceInfo holds information about a currently running service
 type ServiceInfo struct {
 	*Service          // dynamic configuration
@@ -224,6 +327,8 @@ type ServiceInfo struct {
 
 	statusMu sync.RWMutex
 	status   map[string]string // keyed by server URL
+	// serversUp is the number of servers with the "UP" status, read without locking.
+	serversUp int32
 }
 
 // UpdateStatus sets the status of the server in the ServiceInfo.
@@ -235,9 +340,36 @@ func (s *ServiceInfo) UpdateStatus(server string, status string) {
 	if s.status == nil {
 		s.status = make(map[string]string)
 	}
+	s.countServerUp(s.status[server], status)
 	s.status[server] = status
 }
 
+// RemoveStatus forgets the status of the server, which is no longer part of the service.
+// It is the responsibility of the caller to check that s is not nil.
+func (s *ServiceInfo) RemoveStatus(server string) {
+	s.statusMu.Lock()
+	defer s.statusMu.Unlock()
+
+	s.countServerUp(s.status[server], "")
+	delete(s.status, server)
+}
+
+// countServerUp updates the number of servers up, for a server going from the previous status to the given one.
+// It must be called with the status lock held.
+func (s *ServiceInfo) countServerUp(previous, status string) {
+	switch {
+	case previous != statusUp && status == statusUp:
+		atomic.AddInt32(&s.serversUp, 1)
+	case previous == statusUp && status != statusUp:
+		atomic.AddInt32(&s.serversUp, -1)
+	}
+}
+
+// hasServerUp returns whether at least one of the servers of the service is up.
+func (s *ServiceInfo) hasServerUp() bool {
+	return atomic.LoadInt32(&s.serversUp) > 0
+}
+
 // GetAllStatus returns all the statuses of all the servers in ServiceInfo.
 // It is the responsibility of the caller to check that s is not nil
 func (s *ServiceInfo) GetAllStatus() map[string]string {
@@ -255,11 +387,222 @@ func (s *ServiceInfo) GetAllStatus() map[string]string {
 	return allStatus
 }
 
+// GetServiceStatus returns the statuses of the servers of the given service.
+// For a service referencing other services (weighted, mirroring, failover), it returns the status of each of these services,
+// which is "UP" as long as at least one of their servers is up.
+func (r *RuntimeConfiguration) GetServiceStatus(serviceName string) map[string]string {
+	return r.getServiceStatus(serviceName, make(map[string]struct{}))
+}
+
+func (r *RuntimeConfiguration) getServiceStatus(serviceName string, visited map[string]struct{}) map[string]string {
+	service, ok := r.Services[serviceName]
+	if !ok {
+		return nil
+	}
+
+	children := service.children()
+	if children == nil {
+		return service.GetAllStatus()
+	}
+
+	if _, ok := visited[serviceName]; ok {
+		return nil
+	}
+	visited[serviceName] = struct{}{}
+	defer delete(visited, serviceName)
+
+	providerName := getProviderName(serviceName)
+
+	allStatus := make(map[string]string, len(children))
+	for _, child := range children {
+		childName := child
+		if providerName != "" {
+			childName = getQualifiedName(providerName, child)
+		}
+
+		allStatus[childName] = statusDown
+		for _, status := range r.getServiceStatus(childName, visited) {
+			if status == statusUp {
+				allStatus[childName] = statusUp
+				break
+			}
+		}
+	}
+
+	return allStatus
+}
+
+// IsServiceUp returns whether the given service has at least one server up.
+// A service referencing other services is up as long as one of these services is up.
+func (r *RuntimeConfiguration) IsServiceUp(serviceName string) bool {
+	return r.ServiceUpFunc(serviceName)()
+}
+
+// ServiceUpFunc returns a function telling whether the given service is up, as IsServiceUp does.
+// The services referenced by the service are resolved once, so the function can be called for each request:
+// it neither locks nor allocates.
+func (r *RuntimeConfiguration) ServiceUpFunc(serviceName string) func() bool {
+	services := r.getLoadBalancerServices(serviceName, make(map[string]struct{}))
+
+	return func() bool {
+		for _, service := range services {
+			if service.hasServerUp() {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// getLoadBalancerServices returns the services with servers the given service forwards its requests to, directly or not,
+// or the service itself if it does not reference other services.
+// The mirrors of a mirroring service and the services of a weighted service with a zero weight are left out,
+// as they do not serve the requests.
+func (r *RuntimeConfiguration) getLoadBalancerServices(serviceName string, visited map[string]struct{}) []*ServiceInfo {
+	service, ok := r.Services[serviceName]
+	if !ok {
+		return nil
+	}
+
+	children := service.servingChildren()
+	if children == nil {
+		return []*ServiceInfo{service}
+	}
+
+	if _, ok := visited[serviceName]; ok {
+		return nil
+	}
+	visited[serviceName] = struct{}{}
+	defer delete(visited, serviceName)
+
+	providerName := getProviderName(serviceName)
+
+	var services []*ServiceInfo
+	for _, child := range children {
+		childName := child
+		if providerName != "" {
+			childName = getQualifiedName(providerName, child)
+		}
+
+		services = append(services, r.getLoadBalancerServices(childName, visited)...)
+	}
+
+	return services
+}
+
+// children returns the names of the services referenced by the service,
+// or nil if the service does not reference other services.
+func (s *ServiceInfo) children() []string {
+	var children []string
+	switch {
+	case s.Weighted != nil:
+		children = []string{}
+		for _, child := range s.Weighted.Services {
+			children = append(children, child.Name)
+		}
+	case s.Mirroring != nil:
+		children = []string{s.Mirroring.Service}
+		for _, child := range s.Mirroring.Mirrors {
+			children = append(children, child.Name)
+		}
+	case s.Failover != nil:
+		children = []string{s.Failover.Service, s.Failover.Fallback}
+	}
+	return children
+}
+
+// servingChildren returns the names of the services referenced by the service which serve its requests,
+// or nil if the service does not reference other services.
+func (s *ServiceInfo) servingChildren() []string {
+	switch {
+	case s.Weighted != nil:
+		children := []string{}
+		for _, child := range s.Weighted.Services {
+			if child.Weight > 0 {
+				children = append(children, child.Name)
+			}
+		}
+		return children
+	case s.Mirroring != nil:
+		return []string{s.Mirroring.Service}
+	default:
+		return s.children()
+	}
+}
+
 // TCPServiceInfo holds information about a currently running TCP service
 type TCPServiceInfo struct {
 	*TCPService          // dynamic configuration
 	Err         error    `json:"error,omitempty"`  // initialization error
 	UsedBy      []string `json:"usedBy,omitempty"` // list of routers using that service
+
+	statusMu sync.RWMutex
+	status   map[string]string // keyed by server address
+
+	connCounter ConnCounter
+}
+
+// ConnCounter counts the open connections of the servers of a TCP service.
+type ConnCounter interface {
+	// Connections returns the number of open connections of the servers, keyed by server address.
+	Connections() map[string]int
+}
+
+// SetConnCounter sets the counter of the open connections of the servers of the TCPServiceInfo.
+// It is the responsibility of the caller to check that s is not nil.
+func (s *TCPServiceInfo) SetConnCounter(counter ConnCounter) {
+	s.statusMu.Lock()
+	defer s.statusMu.Unlock()
+
+	s.connCounter = counter
+}
+
+// GetAllConnections returns the number of open connections of the servers of the TCPServiceInfo.
+// It is the responsibility of the caller to check that s is not nil.
+func (s *TCPServiceInfo) GetAllConnections() map[string]int {
+	s.statusMu.RLock()
+	defer s.statusMu.RUnlock()
+
+	if s.connCounter == nil {
+		return nil
+	}
+	return s.connCounter.Connections()
+}
+
+// UpdateStatus sets the status of the server in the TCPServiceInfo.
+// It is the responsibility of the caller to check that s is not nil.
+func (s *TCPServiceInfo) UpdateStatus(server string, status string) {
+	s.statusMu.Lock()
+	defer s.statusMu.Unlock()
+
+	if s.status == nil {
+		s.status = make(map[string]string)
+	}
+	s.status[server] = status
+}
+
+// GetAllStatus returns all the statuses of all the servers in TCPServiceInfo.
+// It is the responsibility of the caller to check that s is not nil.
+func (s *TCPServiceInfo) GetAllStatus() map[string]string {
+	s.statusMu.RLock()
+	defer s.statusMu.RUnlock()
+
+	if len(s.status) == 0 {
+		return nil
+	}
+
+	allStatus := make(map[string]string, len(s.status))
+	for k, v := range s.status {
+		allStatus[k] = v
+	}
+	return allStatus
+}
+
+// UDPServiceInfo holds information about a currently running UDP service
+type UDPServiceInfo struct {
+	*UDPService          // dynamic configuration
+	Err         error    `json:"error,omitempty"`  // initialization error
+	UsedBy      []string `json:"usedBy,omitempty"` // list of routers using that service
 }
 
 func getProviderName(elementName string) string {
@@ -661,6 +661,110 @@ func TestPopulateUsedby(t *testing.T) {
 				},
 			},
 		},
+		{
+			desc: "TCP, middlewares used by routers",
+			conf: &config.RuntimeConfiguration{
+				TCPServices: map[string]*config.TCPServiceInfo{
+					"foo-service@myprovider": {
+						TCPService: &config.TCPService{
+							LoadBalancer: &config.TCPLoadBalancerService{
+								Servers: []config.TCPServer{
+									{Address: "127.0.0.1:8085"},
+								},
+							},
+						},
+					},
+				},
+				TCPMiddlewares: map[string]*config.TCPMiddlewareInfo{
+					"whitelist@myprovider": {
+						TCPMiddleware: &config.TCPMiddleware{
+							IPWhiteList: &config.TCPIPWhiteList{SourceRange: []string{"10.0.0.0/8"}},
+						},
+					},
+					"inflight@otherprovider": {
+						TCPMiddleware: &config.TCPMiddleware{
+							InFlightConn: &config.TCPInFlightConn{Amount: 10},
+						},
+					},
+				},
+				TCPRouters: map[string]*config.TCPRouterInfo{
+					"foo@myprovider": {
+						TCPRouter: &config.TCPRouter{
+							EntryPoints: []string{"web"},
+							Middlewares: []string{"whitelist", "inflight@otherprovider"},
+							Service:     "foo-service",
+							Rule:        "HostSNI(`bar.foo`)",
+						},
+					},
+					"bar@myprovider": {
+						TCPRouter: &config.TCPRouter{
+							EntryPoints: []string{"web"},
+							Middlewares: []string{"whitelist@myprovider"},
+							Service:     "foo-service",
+							Rule:        "HostSNI(`foo.bar`)",
+						},
+					},
+				},
+			},
+			expected: config.RuntimeConfiguration{
+				TCPMiddlewares: map[string]*config.TCPMiddlewareInfo{
+					"whitelist@myprovider": {
+						UsedBy: []string{"bar@myprovider", "foo@myprovider"},
+					},
+					"inflight@otherprovider": {
+						UsedBy: []string{"foo@myprovider"},
+					},
+				},
+				TCPServices: map[string]*config.TCPServiceInfo{
+					"foo-service@myprovider": {
+						UsedBy: []string{"bar@myprovider", "foo@myprovider"},
+					},
+				},
+			},
+		},
+		{
+			desc: "UDP, one service used by two routers",
+			conf: &config.RuntimeConfiguration{
+				UDPServices: map[string]*config.UDPServiceInfo{
+					"foo-service@myprovider": {
+						UDPService: &config.UDPService{
+							LoadBalancer: &config.UDPLoadBalancerService{
+								Servers: []config.UDPServer{
+									{Address: "127.0.0.1:8085"},
+								},
+							},
+						},
+					},
+				},
+				UDPRouters: map[string]*config.UDPRouterInfo{
+					"foo@myprovider": {
+						UDPRouter: &config.UDPRouter{
+							EntryPoints: []string{"dns"},
+							Service:     "foo-service",
+						},
+					},
+					"bar@myprovider": {
+						UDPRouter: &config.UDPRouter{
+							EntryPoints: []string{"syslog"},
+							Service:     "foo-service@myprovider",
+						},
+					},
+					"baz@myprovider": {
+						UDPRouter: &config.UDPRouter{
+							EntryPoints: []string{"syslog"},
+							Service:     "unknown-service",
+						},
+					},
+				},
+			},
+			expected: config.RuntimeConfiguration{
+				UDPServices: map[string]*config.UDPServiceInfo{
+					"foo-service@myprovider": {
+						UsedBy: []string{"bar@myprovider", "foo@myprovider"},
+					},
+				},
+			},
+		},
 	}
 	for _, test := range testCases {
 		test := test
@@ -685,6 +789,16 @@ func TestPopulateUsedby(t *testing.T) {
 				require.NotNil(t, runtimeConf.TCPServices[key])
 				assert.Equal(t, expectedTCPService.UsedBy, runtimeConf.TCPServices[key].UsedBy)
 			}
+
+			for key, expectedTCPMiddleware := range test.expected.TCPMiddlewares {
+				require.NotNil(t, runtimeConf.TCPMiddlewares[key])
+				assert.Equal(t, expectedTCPMiddleware.UsedBy, runtimeConf.TCPMiddlewares[key].UsedBy)
+			}
+
+			for key, expectedUDPService := range test.expected.UDPServices {
+				require.NotNil(t, runtimeConf.UDPServices[key])
+				assert.Equal(t, expectedUDPService.UsedBy, runtimeConf.UDPServices[key].UsedBy)
+			}
 		})
 	}
 
@@ -1085,3 +1199,128 @@ func TestGetRoutersByEntrypoints(t *testing.T) {
 		})
 	}
 }
+
+func TestGetServiceStatus(t *testing.T) {
+	lbUp := &config.ServiceInfo{Service: &config.Service{LoadBalancer: &config.LoadBalancerService{}}}
+	lbUp.UpdateStatus("http://127.0.0.1:8085", "UP")
+	lbUp.UpdateStatus("http://127.0.0.1:8086", "DOWN")
+
+	lbDown := &config.ServiceInfo{Service: &config.Service{LoadBalancer: &config.LoadBalancerService{}}}
+	lbDown.UpdateStatus("http://127.0.0.1:8087", "DOWN")
+
+	rtConf := &config.RuntimeConfiguration{
+		Services: map[string]*config.ServiceInfo{
+			"v1@myprovider": lbUp,
+			"v2@myprovider": lbDown,
+			"canary@myprovider": {
+				Service: &config.Service{
+					Weighted: &config.WeightedService{
+						Services: []config.WeightedServiceRef{
+							{Name: "v1", Weight: 95},
+							{Name: "v2@myprovider", Weight: 5},
+							{Name: "v3", Weight: 1},
+						},
+					},
+				},
+			},
+			"mirrored@myprovider": {
+				Service: &config.Service{
+					Mirroring: &config.MirroringService{
+						Service: "v1",
+						Mirrors: []config.MirrorService{
+							{Name: "v2", Percent: 10},
+						},
+					},
+				},
+			},
+			"failover@myprovider": {
+				Service: &config.Service{
+					Failover: &config.FailoverService{
+						Service:  "v2",
+						Fallback: "v1",
+					},
+				},
+			},
+			"mirroredDown@myprovider": {
+				Service: &config.Service{
+					Mirroring: &config.MirroringService{
+						Service: "v2",
+						Mirrors: []config.MirrorService{
+							{Name: "v1", Percent: 10},
+						},
+					},
+				},
+			},
+			"unweighted@myprovider": {
+				Service: &config.Service{
+					Weighted: &config.WeightedService{
+						Services: []config.WeightedServiceRef{
+							{Name: "v1", Weight: 0},
+							{Name: "v2", Weight: 1},
+						},
+					},
+				},
+			},
+			"loop@myprovider": {
+				Service: &config.Service{
+					Weighted: &config.WeightedService{
+						Services: []config.WeightedServiceRef{
+							{Name: "loop", Weight: 1},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	assert.Equal(t, map[string]string{
+		"http://127.0.0.1:8085": "UP",
+		"http://127.0.0.1:8086": "DOWN",
+	}, rtConf.GetServiceStatus("v1@myprovider"))
+
+	assert.Equal(t, map[string]string{
+		"v1@myprovider": "UP",
+		"v2@myprovider": "DOWN",
+		"v3@myprovider": "DOWN",
+	}, rtConf.GetServiceStatus("canary@myprovider"))
+
+	assert.Equal(t, map[string]string{
+		"v1@myprovider": "UP",
+		"v2@myprovider": "DOWN",
+	}, rtConf.GetServiceStatus("mirrored@myprovider"))
+
+	assert.Equal(t, map[string]string{
+		"v2@myprovider": "DOWN",
+		"v1@myprovider": "UP",
+	}, rtConf.GetServiceStatus("failover@myprovider"))
+
+	assert.Equal(t, map[string]string{
+		"loop@myprovider": "DOWN",
+	}, rtConf.GetServiceStatus("loop@myprovider"))
+
+	assert.Nil(t, rtConf.GetServiceStatus("unknown@myprovider"))
+
+	assert.True(t, rtConf.IsServiceUp("v1@myprovider"))
+	assert.False(t, rtConf.IsServiceUp("v2@myprovider"))
+	assert.True(t, rtConf.IsServiceUp("canary@myprovider"))
+	assert.True(t, rtConf.IsServiceUp("failover@myprovider"))
+	// The mirrors, and the services with a zero weight, do not serve the requests.
+	assert.False(t, rtConf.IsServiceUp("mirroredDown@myprovider"))
+	assert.False(t, rtConf.IsServiceUp("unweighted@myprovider"))
+	assert.False(t, rtConf.IsServiceUp("loop@myprovider"))
+	assert.False(t, rtConf.IsServiceUp("unknown@myprovider"))
+
+	// The function follows the status changes of the servers.
+	isUp := rtConf.ServiceUpFunc("failover@myprovider")
+	assert.True(t, isUp())
+
+	rtConf.Services["v1@myprovider"].UpdateStatus("http://127.0.0.1:8085", "DOWN")
+	assert.False(t, isUp())
+
+	rtConf.Services["v2@myprovider"].UpdateStatus("http://127.0.0.1:8087", "UP")
+	rtConf.Services["v2@myprovider"].UpdateStatus("http://127.0.0.1:8087", "UP")
+	assert.True(t, isUp())
+
+	rtConf.Services["v2@myprovider"].RemoveStatus("http://127.0.0.1:8087")
+	assert.False(t, isUp())
+}
@@ -1,11 +1,23 @@
 package static
 
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/containous/traefik/pkg/types"
+)
+
+// DefaultUDPTimeout is the duration after which an idle UDP session is closed.
+const DefaultUDPTimeout = 3 * time.Second
+
 // EntryPoint holds the entry point configuration.
 type EntryPoint struct {
-	Address          string                `description:"Entry point address."`
+	Address          string                `description:"Entry point address, optionally followed by the protocol, e.g. ':53/udp' (default tcp)."`
 	Transport        *EntryPointsTransport `description:"Configures communication between clients and Traefik."`
 	ProxyProtocol    *ProxyProtocol        `description:"Proxy-Protocol configuration." label:"allowEmpty"`
 	ForwardedHeaders *ForwardedHeaders     `description:"Trust client forwarding headers."`
+	UDP              *UDPConfig            `description:"UDP configuration."`
 }
 
 // SetDefaults sets the default values.
@@ -13,6 +25,38 @@ func (e *EntryPoint) SetDefaults() {
 	e.Transport = &EntryPointsTransport{}
 	e.Transport.SetDefaults()
 	e.ForwardedHeaders = &ForwardedHeaders{}
+	e.UDP = &UDPConfig{}
+	e.UDP.SetDefaults()
+}
+
+// GetAddress returns the address of the entry point, without the protocol.
+func (e *EntryPoint) GetAddress() string {
+	return strings.Split(e.Address, "/")[0]
+}
+
+// GetProtocol returns the protocol of the entry point, tcp by default.
+func (e *EntryPoint) GetProtocol() (string, error) {
+	splitN := strings.SplitN(e.Address, "/", 2)
+	if len(splitN) < 2 {
+		return "tcp", nil
+	}
+
+	protocol := strings.ToLower(splitN[1])
+	if protocol == "tcp" || protocol == "udp" {
+		return protocol, nil
+	}
+
+	return "", fmt.Errorf("invalid protocol: %s", splitN[1])
+}
+
+// UDPConfig is the UDP configuration of an entry point.
+type UDPConfig struct {
+	Timeout types.Duration `description:"Timeout defines how long to wait on an idle session before releasing the related resources." export:"true"`
+}
+
+// SetDefaults sets the default values.
+func (u *UDPConfig) SetDefaults() {
+	u.Timeout = types.Duration(DefaultUDPTimeout)
 }
 
 // ForwardedHeaders Trust client forwarding headers.
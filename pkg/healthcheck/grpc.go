@@ -0,0 +1,175 @@
+package healthcheck
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+
+	"github.com/golang/protobuf/proto"
+)
+
+const grpcHealthCheckPath = "/grpc.health.v1.Health/Check"
+
+// grpcServing is the SERVING status of a grpc.health.v1.HealthCheckResponse.
+const grpcServing = 1
+
+var errInvalidGRPCResponse = errors.New("invalid gRPC health check response")
+
+func (b *BackendConfig) newGRPCRequest(serverURL *url.URL) (*http.Request, error) {
+	u, err := b.newURL(serverURL, grpcHealthCheckPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, u.String(), bytes.NewReader(encodeGRPCHealthRequest(b.GRPCServiceName)))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/grpc")
+	req.Header.Set("TE", "trailers")
+	return req, nil
+}
+
+// checkGRPCHealth calls the grpc.health.v1.Health/Check method of the server,
+// over TLS if its scheme is https, and over h2c otherwise.
+func checkGRPCHealth(serverURL *url.URL, backend *BackendConfig) error {
+	req, err := backend.newGRPCRequest(serverURL)
+	if err != nil {
+		return fmt.Errorf("failed to create gRPC request: %s", err)
+	}
+
+	req = backend.addHeadersAndHost(req)
+
+	transport := backend.h2cTransport
+	if req.URL.Scheme == "https" {
+		transport = backend.h2Transport
+	}
+
+	client := http.Client{
+		Timeout:   backend.Options.Timeout,
+		Transport: transport,
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("gRPC request failed: %s", err)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("received error status code: %v", resp.StatusCode)
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxBodySize))
+	if err != nil {
+		return fmt.Errorf("failed to read the gRPC response: %s", err)
+	}
+
+	// The gRPC status is sent in the trailers, or in the headers of a response without message.
+	status := resp.Trailer.Get("Grpc-Status")
+	message := resp.Trailer.Get("Grpc-Message")
+	if status == "" {
+		status = resp.Header.Get("Grpc-Status")
+		message = resp.Header.Get("Grpc-Message")
+	}
+
+	if status != "0" {
+		return fmt.Errorf("received gRPC status %q: %s", status, message)
+	}
+
+	servingStatus, err := decodeGRPCHealthResponse(body)
+	if err != nil {
+		return err
+	}
+
+	if servingStatus != grpcServing {
+		return fmt.Errorf("received gRPC serving status: %d", servingStatus)
+	}
+
+	return nil
+}
+
+// encodeGRPCHealthRequest returns the gRPC message of a grpc.health.v1.HealthCheckRequest for the given service.
+func encodeGRPCHealthRequest(service string) []byte {
+	buf := proto.NewBuffer(nil)
+	if service != "" {
+		_ = buf.EncodeVarint(1<<3 | proto.WireBytes)
+		_ = buf.EncodeStringBytes(service)
+	}
+
+	return encodeGRPCMessage(buf.Bytes())
+}
+
+// encodeGRPCMessage prefixes the message with the compressed flag and the length of the message, as sent over HTTP/2.
+func encodeGRPCMessage(msg []byte) []byte {
+	frame := make([]byte, 5+len(msg))
+	binary.BigEndian.PutUint32(frame[1:5], uint32(len(msg)))
+	copy(frame[5:], msg)
+	return frame
+}
+
+// decodeGRPCHealthResponse returns the serving status of the grpc.health.v1.HealthCheckResponse in the given gRPC message.
+func decodeGRPCHealthResponse(frame []byte) (uint64, error) {
+	if len(frame) < 5 {
+		return 0, errInvalidGRPCResponse
+	}
+
+	if frame[0] != 0 {
+		return 0, errors.New("compressed gRPC health check responses are not supported")
+	}
+
+	length := binary.BigEndian.Uint32(frame[1:5])
+	if uint64(len(frame)-5) < uint64(length) {
+		return 0, errInvalidGRPCResponse
+	}
+
+	var status uint64
+
+	msg := frame[5 : 5+length]
+	for len(msg) > 0 {
+		key, n := proto.DecodeVarint(msg)
+		if n == 0 {
+			return 0, errInvalidGRPCResponse
+		}
+		msg = msg[n:]
+
+		var size uint64
+		switch key & 7 {
+		case proto.WireVarint:
+			value, n := proto.DecodeVarint(msg)
+			if n == 0 {
+				return 0, errInvalidGRPCResponse
+			}
+			if key>>3 == 1 {
+				status = value
+			}
+			size = uint64(n)
+		case proto.WireBytes:
+			value, n := proto.DecodeVarint(msg)
+			if n == 0 {
+				return 0, errInvalidGRPCResponse
+			}
+			size = uint64(n) + value
+		case proto.WireFixed64:
+			size = 8
+		case proto.WireFixed32:
+			size = 4
+		default:
+			return 0, errInvalidGRPCResponse
+		}
+
+		if uint64(len(msg)) < size {
+			return 0, errInvalidGRPCResponse
+		}
+		msg = msg[size:]
+	}
+
+	return status, nil
+}
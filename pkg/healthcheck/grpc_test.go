@@ -0,0 +1,281 @@
+package healthcheck
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/pkg/testhelpers"
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/http2"
+)
+
+const (
+	grpcNotServing     = 2
+	grpcServiceUnknown = 3
+)
+
+// grpcHealthServer is a grpc.health.v1.Health server, answering with the serving status of the requested service.
+type grpcHealthServer map[string]uint64
+
+func (s grpcHealthServer) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	// gRPC is only served over HTTP/2.
+	if req.ProtoMajor != 2 {
+		rw.WriteHeader(http.StatusHTTPVersionNotSupported)
+		return
+	}
+
+	if req.URL.Path != grpcHealthCheckPath || req.Header.Get("Content-Type") != "application/grpc" {
+		rw.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil || len(body) < 5 {
+		rw.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	var service string
+	if msg := body[5:]; len(msg) > 2 {
+		service = string(msg[2:])
+	}
+
+	rw.Header().Set("Content-Type", "application/grpc")
+	rw.Header().Set("Trailer", "Grpc-Status")
+
+	status, ok := s[service]
+	if !ok {
+		// A trailers-only response.
+		rw.Header().Set("Grpc-Status", "5")
+		rw.Header().Set("Grpc-Message", "unknown service")
+		rw.WriteHeader(http.StatusOK)
+		return
+	}
+
+	msg := proto.NewBuffer(nil)
+	_ = msg.EncodeVarint(1<<3 | proto.WireVarint)
+	_ = msg.EncodeVarint(status)
+
+	rw.WriteHeader(http.StatusOK)
+	_, _ = rw.Write(encodeGRPCMessage(msg.Bytes()))
+	rw.Header().Set("Grpc-Status", "0")
+}
+
+func startH2CServer(t *testing.T, handler http.Handler) net.Listener {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go (&http2.Server{}).ServeConn(conn, &http2.ServeConnOpts{Handler: handler})
+		}
+	}()
+
+	return listener
+}
+
+func TestCheckGRPCHealth(t *testing.T) {
+	handler := grpcHealthServer{
+		"":        grpcServing,
+		"serving": grpcServing,
+		"sick":    grpcNotServing,
+	}
+
+	h2cListener := startH2CServer(t, handler)
+	defer h2cListener.Close()
+
+	tlsServer := httptest.NewUnstartedServer(handler)
+	tlsServer.EnableHTTP2 = true
+	tlsServer.StartTLS()
+	defer tlsServer.Close()
+
+	tlsConfig := tlsServer.Client().Transport.(*http.Transport).TLSClientConfig
+
+	testCases := []struct {
+		desc          string
+		serverURL     string
+		serviceName   string
+		expectedError bool
+	}{
+		{
+			desc:      "h2c server",
+			serverURL: "http://" + h2cListener.Addr().String(),
+		},
+		{
+			desc:        "h2c serving service",
+			serverURL:   "http://" + h2cListener.Addr().String(),
+			serviceName: "serving",
+		},
+		{
+			desc:          "h2c not serving service",
+			serverURL:     "http://" + h2cListener.Addr().String(),
+			serviceName:   "sick",
+			expectedError: true,
+		},
+		{
+			desc:          "h2c unknown service",
+			serverURL:     "http://" + h2cListener.Addr().String(),
+			serviceName:   "unknown",
+			expectedError: true,
+		},
+		{
+			desc:        "TLS serving service",
+			serverURL:   tlsServer.URL,
+			serviceName: "serving",
+		},
+		{
+			desc:          "TLS not serving service",
+			serverURL:     tlsServer.URL,
+			serviceName:   "sick",
+			expectedError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			backend := NewBackendConfig(Options{
+				Mode:            ModeGRPC,
+				GRPCServiceName: test.serviceName,
+				Timeout:         healthCheckTimeout,
+				// The transport of the service does not enable HTTP/2.
+				Transport: &http.Transport{TLSClientConfig: tlsConfig},
+				TLSConfig: tlsConfig,
+			}, "backendName")
+
+			err := checkHealth(testhelpers.MustParseURL(test.serverURL), backend)
+			if test.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestCheckGRPCHealth_closeIdleConnections(t *testing.T) {
+	served := make(chan struct{}, 1)
+	closed := make(chan struct{}, 1)
+
+	handler := grpcHealthServer{"": grpcServing}
+	tlsServer := httptest.NewUnstartedServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		handler.ServeHTTP(rw, req)
+		served <- struct{}{}
+	}))
+	tlsServer.EnableHTTP2 = true
+	tlsServer.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateClosed {
+			closed <- struct{}{}
+		}
+	}
+	tlsServer.StartTLS()
+	defer tlsServer.Close()
+
+	lb := &testLoadBalancer{RWMutex: &sync.RWMutex{}, servers: []*url.URL{testhelpers.MustParseURL(tlsServer.URL)}}
+	backend := NewBackendConfig(Options{
+		Mode:      ModeGRPC,
+		Interval:  time.Hour,
+		Timeout:   healthCheckTimeout,
+		LB:        lb,
+		TLSConfig: tlsServer.Client().Transport.(*http.Transport).TLSClientConfig,
+	}, "backendName")
+
+	check := HealthCheck{
+		Backends: make(map[string]*BackendConfig),
+		metrics:  testhelpers.NewCollectingHealthCheckMetrics(),
+	}
+
+	// The health checks are stopped, as done on reload, once the initial health check is done.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	check.execute(ctx, backend)
+
+	select {
+	case <-served:
+	default:
+		t.Fatal("the initial health check has not been sent")
+	}
+	assert.Equal(t, 0, lb.numRemovedServers)
+
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Fatal("the idle connection has not been closed")
+	}
+}
+
+func TestDecodeGRPCHealthResponse(t *testing.T) {
+	testCases := []struct {
+		desc           string
+		frame          []byte
+		expectedStatus uint64
+		expectedError  bool
+	}{
+		{
+			desc:           "serving",
+			frame:          encodeGRPCMessage([]byte{0x08, 0x01}),
+			expectedStatus: grpcServing,
+		},
+		{
+			desc:           "unknown fields are skipped",
+			frame:          encodeGRPCMessage([]byte{0x12, 0x03, 'f', 'o', 'o', 0x08, 0x03, 0x18, 0x2a}),
+			expectedStatus: grpcServiceUnknown,
+		},
+		{
+			desc:  "empty message",
+			frame: encodeGRPCMessage(nil),
+		},
+		{
+			desc:          "missing message",
+			frame:         []byte{0x00, 0x00},
+			expectedError: true,
+		},
+		{
+			desc:          "truncated message",
+			frame:         encodeGRPCMessage([]byte{0x08, 0x01})[:6],
+			expectedError: true,
+		},
+		{
+			desc:          "truncated field",
+			frame:         encodeGRPCMessage([]byte{0x12, 0x03, 'f'}),
+			expectedError: true,
+		},
+		{
+			desc:          "compressed message",
+			frame:         append([]byte{0x01}, encodeGRPCMessage([]byte{0x08, 0x01})[1:]...),
+			expectedError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			status, err := decodeGRPCHealthResponse(test.frame)
+			if test.expectedError {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedStatus, status)
+		})
+	}
+}
@@ -2,19 +2,26 @@ package healthcheck
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"net"
 	"net/http"
 	"net/url"
+	"regexp"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/containous/traefik/pkg/config"
 	"github.com/containous/traefik/pkg/log"
 	"github.com/containous/traefik/pkg/safe"
+	"github.com/containous/traefik/pkg/types"
 	"github.com/go-kit/kit/metrics"
 	"github.com/vulcand/oxy/roundrobin"
+	"golang.org/x/net/http2"
 )
 
 const (
@@ -22,6 +29,16 @@ const (
 	serverDown = "DOWN"
 )
 
+const (
+	// ModeHTTP is the health check mode sending an HTTP request to the servers.
+	ModeHTTP = "http"
+	// ModeGRPC is the health check mode calling the grpc.health.v1.Health/Check method of the servers.
+	ModeGRPC = "grpc"
+)
+
+// maxBodySize is the maximum size (in bytes) of the health check response bodies read to be matched.
+const maxBodySize = 1 << 20
+
 var singleton *HealthCheck
 var once sync.Once
 
@@ -31,12 +48,37 @@ type BalancerHandler interface {
 	Servers() []*url.URL
 	RemoveServer(u *url.URL) error
 	UpsertServer(u *url.URL, options ...roundrobin.ServerOption) error
+	ServerWeight(u *url.URL) (int, bool)
+}
+
+// ApplyServerOptions returns the weight of the server with the given weight, once the given options are applied,
+// for the implementations of BalancerHandler which are not oxy load balancers.
+// As the oxy server options can only be applied to the oxy servers,
+// they are applied to a server of a throwaway oxy round robin load balancer.
+func ApplyServerOptions(u *url.URL, weight int, options []roundrobin.ServerOption) (int, error) {
+	lb, err := roundrobin.New(nil)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := lb.UpsertServer(u, roundrobin.Weight(weight)); err != nil {
+		return 0, err
+	}
+
+	if err := lb.UpsertServer(u, options...); err != nil {
+		return 0, err
+	}
+
+	weight, _ = lb.ServerWeight(u)
+	return weight, nil
 }
 
 // metricsRegistry is a local interface in the health check package, exposing only the required metrics
 // necessary for the health check package. This makes it easier for the tests.
 type metricsRegistry interface {
 	BackendServerUpGauge() metrics.Gauge
+	BackendServerHealthCheckFailuresCounter() metrics.Counter
+	BackendServerHealthCheckDurationHistogram() metrics.Histogram
 }
 
 // Options are the public health check options.
@@ -50,21 +92,55 @@ type Options struct {
 	Interval  time.Duration
 	Timeout   time.Duration
 	LB        BalancerHandler
+	// Mode is either ModeHTTP (the default) or ModeGRPC.
+	Mode string
+	// Method is the method of the HTTP requests (default: GET).
+	Method string
+	// StatusCodes are the status codes of the healthy responses (default: 2XX and 3XX).
+	StatusCodes types.HTTPCodeRanges
+	// BodyContains is a string the body of the healthy responses must contain.
+	BodyContains string
+	// BodyRegexp is a regular expression the body of the healthy responses must match.
+	BodyRegexp *regexp.Regexp
+	// GRPCServiceName is the name of the service sent in the gRPC health checks.
+	// An empty name checks the overall health of the server.
+	GRPCServiceName string
+	// TLSConfig is the TLS configuration of the gRPC health checks of the servers over TLS,
+	// which are sent over HTTP/2 whatever the Transport.
+	TLSConfig *tls.Config
+	// SuccessThreshold is the number of consecutive successful checks after which an unhealthy server is healthy again.
+	SuccessThreshold int
+	// FailureThreshold is the number of consecutive failed checks after which a healthy server is unhealthy.
+	FailureThreshold int
 }
 
 func (opt Options) String() string {
-	return fmt.Sprintf("[Hostname: %s Headers: %v Path: %s Port: %d Interval: %s Timeout: %s]", opt.Hostname, opt.Headers, opt.Path, opt.Port, opt.Interval, opt.Timeout)
+	return fmt.Sprintf("[Mode: %s Hostname: %s Headers: %v Path: %s Port: %d Interval: %s Timeout: %s SuccessThreshold: %d FailureThreshold: %d]",
+		opt.Mode, opt.Hostname, opt.Headers, opt.Path, opt.Port, opt.Interval, opt.Timeout, opt.SuccessThreshold, opt.FailureThreshold)
+}
+
+// backendURL is a server removed from the balancer, with the weight it is put back with.
+type backendURL struct {
+	url    *url.URL
+	weight int
 }
 
 // BackendConfig HealthCheck configuration for a backend
 type BackendConfig struct {
 	Options
 	name         string
-	disabledURLs []*url.URL
+	disabledURLs []backendURL
+	// consecutive counts, for each server URL, the consecutive checks contradicting its current state:
+	// the failures of an enabled server, the successes of a disabled one.
+	consecutive map[string]int
+	// h2cTransport is used for the gRPC health checks of the servers without TLS.
+	h2cTransport http.RoundTripper
+	// h2Transport is used for the gRPC health checks of the servers over TLS.
+	h2Transport http.RoundTripper
 }
 
-func (b *BackendConfig) newRequest(serverURL *url.URL) (*http.Request, error) {
-	u, err := serverURL.Parse(b.Path)
+func (b *BackendConfig) newURL(serverURL *url.URL, path string) (*url.URL, error) {
+	u, err := serverURL.Parse(path)
 	if err != nil {
 		return nil, err
 	}
@@ -77,7 +153,35 @@ func (b *BackendConfig) newRequest(serverURL *url.URL) (*http.Request, error) {
 		u.Host = net.JoinHostPort(u.Hostname(), strconv.Itoa(b.Port))
 	}
 
-	return http.NewRequest(http.MethodGet, u.String(), http.NoBody)
+	return u, nil
+}
+
+func (b *BackendConfig) newRequest(serverURL *url.URL) (*http.Request, error) {
+	u, err := b.newURL(serverURL, b.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	method := b.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	return http.NewRequest(method, u.String(), http.NoBody)
+}
+
+// countConsecutive records a check contradicting the current state of the server,
+// and tells whether the given threshold is reached, in which case the count starts over.
+func (b *BackendConfig) countConsecutive(serverURL *url.URL, threshold int) (int, bool) {
+	b.consecutive[serverURL.String()]++
+
+	count := b.consecutive[serverURL.String()]
+	if count < threshold {
+		return count, false
+	}
+
+	delete(b.consecutive, serverURL.String())
+	return count, true
 }
 
 // this function adds additional http headers and hostname to http.request
@@ -110,98 +214,168 @@ func (hc *HealthCheck) SetBackendsConfiguration(parentCtx context.Context, backe
 
 	for _, backend := range backends {
 		currentBackend := backend
+		backendCtx := log.With(ctx, log.Str(log.ServiceName, backend.name))
 		safe.Go(func() {
-			hc.execute(ctx, currentBackend)
+			hc.execute(backendCtx, currentBackend)
 		})
 	}
 }
 
 func (hc *HealthCheck) execute(ctx context.Context, backend *BackendConfig) {
-	log.Debugf("Initial health check for backend: %q", backend.name)
-	hc.checkBackend(backend)
+	logger := log.FromContext(ctx)
+
+	logger.Debugf("Initial health check for backend: %q", backend.name)
+	hc.checkBackend(ctx, backend)
 	ticker := time.NewTicker(backend.Interval)
 	defer ticker.Stop()
 	for {
 		select {
 		case <-ctx.Done():
-			log.Debugf("Stopping current health check goroutines of backend: %s", backend.name)
+			logger.Debugf("Stopping current health check goroutines of backend: %s", backend.name)
+			// The backend is replaced by a new one when the health checks are relaunched, along with its transports.
+			backend.closeIdleConnections()
 			return
 		case <-ticker.C:
-			log.Debugf("Refreshing health check for backend: %s", backend.name)
-			hc.checkBackend(backend)
+			logger.Debugf("Refreshing health check for backend: %s", backend.name)
+			hc.checkBackend(ctx, backend)
 		}
 	}
 }
 
-func (hc *HealthCheck) checkBackend(backend *BackendConfig) {
+func (hc *HealthCheck) checkBackend(ctx context.Context, backend *BackendConfig) {
+	logger := log.FromContext(ctx)
+
 	enabledURLs := backend.LB.Servers()
-	var newDisabledURLs []*url.URL
-	// FIXME re enable metrics
-	for _, disableURL := range backend.disabledURLs {
-		// FIXME serverUpMetricValue := float64(0)
-		if err := checkHealth(disableURL, backend); err == nil {
-			log.Warnf("Health check up: Returning to server list. Backend: %q URL: %q", backend.name, disableURL.String())
-			if err = backend.LB.UpsertServer(disableURL, roundrobin.Weight(1)); err != nil {
-				log.Error(err)
-			}
-			// FIXME serverUpMetricValue = 1
+	var newDisabledURLs []backendURL
+	for _, disabled := range backend.disabledURLs {
+		disableURL := disabled.url
+		serverUpMetricValue := float64(0)
+		if err := hc.checkServer(disableURL, backend); err != nil {
+			logger.Warnf("Health check still failing. Backend: %q URL: %q Reason: %s", backend.name, disableURL.String(), err)
+			delete(backend.consecutive, disableURL.String())
+			newDisabledURLs = append(newDisabledURLs, disabled)
+		} else if count, ok := backend.countConsecutive(disableURL, backend.SuccessThreshold); !ok {
+			logger.Debugf("Health check succeeded (%d/%d). Backend: %q URL: %q", count, backend.SuccessThreshold, backend.name, disableURL.String())
+			newDisabledURLs = append(newDisabledURLs, disabled)
 		} else {
-			log.Warnf("Health check still failing. Backend: %q URL: %q Reason: %s", backend.name, disableURL.String(), err)
-			newDisabledURLs = append(newDisabledURLs, disableURL)
+			logger.Warnf("Health check up: Returning to server list. Backend: %q URL: %q", backend.name, disableURL.String())
+			if err = backend.LB.UpsertServer(disableURL, roundrobin.Weight(disabled.weight)); err != nil {
+				logger.Error(err)
+			} else {
+				serverUpMetricValue = 1
+			}
 		}
-		// FIXME labelValues := []string{"backend", backend.name, "url", disableURL.String()}
-		// FIXME hc.metrics.BackendServerUpGauge().With(labelValues...).Set(serverUpMetricValue)
+
+		labelValues := []string{"backend", backend.name, "url", disableURL.String()}
+		hc.metrics.BackendServerUpGauge().With(labelValues...).Set(serverUpMetricValue)
 	}
 	backend.disabledURLs = newDisabledURLs
 
-	// FIXME re enable metrics
 	for _, enableURL := range enabledURLs {
-		// FIXME serverUpMetricValue := float64(1)
-		if err := checkHealth(enableURL, backend); err != nil {
-			log.Warnf("Health check failed: Remove from server list. Backend: %q URL: %q Reason: %s", backend.name, enableURL.String(), err)
+		serverUpMetricValue := float64(1)
+		if err := hc.checkServer(enableURL, backend); err == nil {
+			delete(backend.consecutive, enableURL.String())
+		} else if count, ok := backend.countConsecutive(enableURL, backend.FailureThreshold); !ok {
+			logger.Warnf("Health check failed (%d/%d). Backend: %q URL: %q Reason: %s", count, backend.FailureThreshold, backend.name, enableURL.String(), err)
+		} else {
+			logger.Warnf("Health check failed: Remove from server list. Backend: %q URL: %q Reason: %s", backend.name, enableURL.String(), err)
+			weight, ok := backend.LB.ServerWeight(enableURL)
+			if !ok {
+				weight = 1
+			}
 			if err := backend.LB.RemoveServer(enableURL); err != nil {
-				log.Error(err)
+				logger.Error(err)
 			}
-			backend.disabledURLs = append(backend.disabledURLs, enableURL)
-			// FIXME serverUpMetricValue = 0
+			backend.disabledURLs = append(backend.disabledURLs, backendURL{url: enableURL, weight: weight})
+			serverUpMetricValue = 0
 		}
-		// FIXME labelValues := []string{"backend", backend.name, "url", enableURL.String()}
-		// FIXME hc.metrics.BackendServerUpGauge().With(labelValues...).Set(serverUpMetricValue)
+
+		labelValues := []string{"backend", backend.name, "url", enableURL.String()}
+		hc.metrics.BackendServerUpGauge().With(labelValues...).Set(serverUpMetricValue)
 	}
 }
 
-// FIXME re add metrics
-//func GetHealthCheck(metrics metricsRegistry) *HealthCheck {
+// checkServer checks the health of the server, and records the duration and the failures of the check.
+func (hc *HealthCheck) checkServer(serverURL *url.URL, backend *BackendConfig) error {
+	labelValues := []string{"backend", backend.name, "url", serverURL.String()}
+
+	start := time.Now()
+	err := checkHealth(serverURL, backend)
+	hc.metrics.BackendServerHealthCheckDurationHistogram().With(labelValues...).Observe(time.Since(start).Seconds())
+
+	if err != nil {
+		hc.metrics.BackendServerHealthCheckFailuresCounter().With(labelValues...).Add(1)
+	}
+	return err
+}
 
 // GetHealthCheck returns the health check which is guaranteed to be a singleton.
-func GetHealthCheck() *HealthCheck {
+// The metrics registry is only used on the first call.
+func GetHealthCheck(metrics metricsRegistry) *HealthCheck {
 	once.Do(func() {
-		singleton = newHealthCheck()
-		//singleton = newHealthCheck(metrics)
+		singleton = newHealthCheck(metrics)
 	})
 	return singleton
 }
 
-// FIXME re add metrics
-//func newHealthCheck(metrics metricsRegistry) *HealthCheck {
-func newHealthCheck() *HealthCheck {
+func newHealthCheck(metrics metricsRegistry) *HealthCheck {
 	return &HealthCheck{
 		Backends: make(map[string]*BackendConfig),
-		//metrics:  metrics,
+		metrics:  metrics,
 	}
 }
 
 // NewBackendConfig Instantiate a new BackendConfig
 func NewBackendConfig(options Options, backendName string) *BackendConfig {
-	return &BackendConfig{
-		Options: options,
-		name:    backendName,
+	if options.SuccessThreshold < 1 {
+		options.SuccessThreshold = 1
+	}
+	if options.FailureThreshold < 1 {
+		options.FailureThreshold = 1
+	}
+
+	backend := &BackendConfig{
+		Options:     options,
+		name:        backendName,
+		consecutive: make(map[string]int),
+	}
+
+	if options.Mode == ModeGRPC {
+		backend.h2cTransport = &http2.Transport{
+			AllowHTTP: true,
+			DialTLS: func(network, addr string, _ *tls.Config) (net.Conn, error) {
+				return net.DialTimeout(network, addr, options.Timeout)
+			},
+		}
+
+		backend.h2Transport = &http2.Transport{
+			TLSClientConfig: options.TLSConfig,
+			DialTLS: func(network, addr string, cfg *tls.Config) (net.Conn, error) {
+				dialer := &net.Dialer{Timeout: options.Timeout}
+				return tls.DialWithDialer(dialer, network, addr, cfg)
+			},
+		}
+	}
+
+	return backend
+}
+
+// closeIdleConnections closes the idle connections of the transports owned by the backend.
+func (b *BackendConfig) closeIdleConnections() {
+	for _, transport := range []http.RoundTripper{b.h2cTransport, b.h2Transport} {
+		if t, ok := transport.(interface{ CloseIdleConnections() }); ok {
+			t.CloseIdleConnections()
+		}
 	}
 }
 
 // checkHealth returns a nil error in case it was successful and otherwise
 // a non-nil error with a meaningful description why the health check failed.
 func checkHealth(serverURL *url.URL, backend *BackendConfig) error {
+	if backend.Mode == ModeGRPC {
+		return checkGRPCHealth(serverURL, backend)
+	}
+
 	req, err := backend.newRequest(serverURL)
 	if err != nil {
 		return fmt.Errorf("failed to create HTTP request: %s", err)
@@ -221,10 +395,31 @@ func checkHealth(serverURL *url.URL, backend *BackendConfig) error {
 
 	defer resp.Body.Close()
 
-	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusBadRequest {
+	if len(backend.StatusCodes) > 0 {
+		if !backend.StatusCodes.Contains(resp.StatusCode) {
+			return fmt.Errorf("received unexpected status code: %v", resp.StatusCode)
+		}
+	} else if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusBadRequest {
 		return fmt.Errorf("received error status code: %v", resp.StatusCode)
 	}
 
+	if backend.BodyContains == "" && backend.BodyRegexp == nil {
+		return nil
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxBodySize))
+	if err != nil {
+		return fmt.Errorf("failed to read the response body: %s", err)
+	}
+
+	if backend.BodyContains != "" && !strings.Contains(string(body), backend.BodyContains) {
+		return fmt.Errorf("response body does not contain %q", backend.BodyContains)
+	}
+
+	if backend.BodyRegexp != nil && !backend.BodyRegexp.Match(body) {
+		return fmt.Errorf("response body does not match %q", backend.BodyRegexp)
+	}
+
 	return nil
 }
 
@@ -245,9 +440,11 @@ type LbStatusUpdater struct {
 
 // RemoveServer removes the given server from the BalancerHandler,
 // and updates the status of the server to "DOWN".
+// The status is updated even if the server was not in the BalancerHandler, e.g. ejected by the passive health check,
+// as it is out of the BalancerHandler either way.
 func (lb *LbStatusUpdater) RemoveServer(u *url.URL) error {
 	err := lb.BalancerHandler.RemoveServer(u)
-	if err == nil && lb.serviceInfo != nil {
+	if lb.serviceInfo != nil {
 		lb.serviceInfo.UpdateStatus(u.String(), serverDown)
 	}
 	return err
@@ -5,12 +5,15 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"regexp"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/containous/traefik/pkg/config"
 	"github.com/containous/traefik/pkg/testhelpers"
+	"github.com/containous/traefik/pkg/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/vulcand/oxy/roundrobin"
@@ -115,7 +118,7 @@ func TestSetBackendsConfiguration(t *testing.T) {
 			if test.startHealthy {
 				lb.servers = append(lb.servers, serverURL)
 			} else {
-				backend.disabledURLs = append(backend.disabledURLs, serverURL)
+				backend.disabledURLs = append(backend.disabledURLs, backendURL{url: serverURL, weight: 1})
 			}
 
 			collectingMetrics := testhelpers.NewCollectingHealthCheckMetrics()
@@ -147,8 +150,9 @@ func TestSetBackendsConfiguration(t *testing.T) {
 
 			assert.Equal(t, test.expectedNumRemovedServers, lb.numRemovedServers, "removed servers")
 			assert.Equal(t, test.expectedNumUpsertedServers, lb.numUpsertedServers, "upserted servers")
-			// FIXME re add metrics
-			//assert.Equal(t, test.expectedGaugeValue, collectingMetrics.Gauge.GaugeValue, "ServerUp Gauge")
+			assert.Equal(t, test.expectedGaugeValue, collectingMetrics.Gauge.GaugeValue, "ServerUp Gauge")
+			assert.Equal(t, []string{"backend", "backendName", "url", serverURL.String()}, collectingMetrics.Gauge.LastLabelValues)
+			assert.Equal(t, len(test.healthSequence), collectingMetrics.Histogram.Observations, "health check durations")
 		})
 	}
 }
@@ -394,9 +398,22 @@ func (lb *testLoadBalancer) UpsertServer(u *url.URL, options ...roundrobin.Serve
 }
 
 func (lb *testLoadBalancer) Servers() []*url.URL {
+	lb.RLock()
+	defer lb.RUnlock()
 	return lb.servers
 }
 
+func (lb *testLoadBalancer) ServerWeight(u *url.URL) (int, bool) {
+	lb.RLock()
+	defer lb.RUnlock()
+	for _, serverURL := range lb.servers {
+		if *serverURL == *u {
+			return 1, true
+		}
+	}
+	return 0, false
+}
+
 func (lb *testLoadBalancer) Options() []roundrobin.ServerOption {
 	return lb.options
 }
@@ -469,3 +486,211 @@ func TestLBStatusUpdater(t *testing.T) {
 		break
 	}
 }
+
+func TestCheckHealth(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		options       Options
+		status        int
+		body          string
+		expectedError bool
+	}{
+		{
+			desc:    "default status codes",
+			options: Options{Path: "/health"},
+			status:  http.StatusFound,
+		},
+		{
+			desc:          "default status codes with error status",
+			options:       Options{Path: "/health"},
+			status:        http.StatusNotFound,
+			expectedError: true,
+		},
+		{
+			desc:    "expected status code",
+			options: Options{Path: "/health", StatusCodes: types.HTTPCodeRanges{{200, 200}, {401, 403}}},
+			status:  http.StatusUnauthorized,
+		},
+		{
+			desc:          "unexpected status code",
+			options:       Options{Path: "/health", StatusCodes: types.HTTPCodeRanges{{200, 200}, {401, 403}}},
+			status:        http.StatusNoContent,
+			expectedError: true,
+		},
+		{
+			desc:    "method",
+			options: Options{Path: "/health", Method: http.MethodPost},
+			status:  http.StatusOK,
+		},
+		{
+			desc:    "body containing the string",
+			options: Options{Path: "/health", BodyContains: "healthy"},
+			status:  http.StatusOK,
+			body:    `{"status":"healthy"}`,
+		},
+		{
+			desc:          "body not containing the string",
+			options:       Options{Path: "/health", BodyContains: "healthy"},
+			status:        http.StatusOK,
+			body:          `{"status":"sick"}`,
+			expectedError: true,
+		},
+		{
+			desc:    "body matching the regexp",
+			options: Options{Path: "/health", BodyRegexp: regexp.MustCompile(`^OK \d+$`)},
+			status:  http.StatusOK,
+			body:    "OK 42",
+		},
+		{
+			desc:          "body not matching the regexp",
+			options:       Options{Path: "/health", BodyRegexp: regexp.MustCompile(`^OK \d+$`)},
+			status:        http.StatusOK,
+			body:          "KO 42",
+			expectedError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			method := test.options.Method
+			if method == "" {
+				method = http.MethodGet
+			}
+
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				if req.Method != method {
+					rw.WriteHeader(http.StatusMethodNotAllowed)
+					return
+				}
+				rw.WriteHeader(test.status)
+				_, _ = rw.Write([]byte(test.body))
+			}))
+			defer server.Close()
+
+			test.options.Timeout = healthCheckTimeout
+			backend := NewBackendConfig(test.options, "backendName")
+
+			err := checkHealth(testhelpers.MustParseURL(server.URL), backend)
+			if test.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestCheckBackend_thresholds(t *testing.T) {
+	var healthy int32 = 1
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if atomic.LoadInt32(&healthy) == 0 {
+			rw.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	lb := &testLoadBalancer{RWMutex: &sync.RWMutex{}}
+	lb.servers = append(lb.servers, testhelpers.MustParseURL(server.URL))
+
+	backend := NewBackendConfig(Options{
+		Path:             "/health",
+		Timeout:          healthCheckTimeout,
+		LB:               lb,
+		SuccessThreshold: 2,
+		FailureThreshold: 3,
+	}, "backendName")
+
+	ctx := context.Background()
+	collectingMetrics := testhelpers.NewCollectingHealthCheckMetrics()
+	check := HealthCheck{
+		Backends: make(map[string]*BackendConfig),
+		metrics:  collectingMetrics,
+	}
+
+	atomic.StoreInt32(&healthy, 0)
+
+	// A success in between starts the count of failures over.
+	check.checkBackend(ctx, backend)
+	check.checkBackend(ctx, backend)
+	atomic.StoreInt32(&healthy, 1)
+	check.checkBackend(ctx, backend)
+	atomic.StoreInt32(&healthy, 0)
+	check.checkBackend(ctx, backend)
+	check.checkBackend(ctx, backend)
+	assert.Equal(t, 0, lb.numRemovedServers)
+	assert.Equal(t, float64(1), collectingMetrics.Gauge.GaugeValue)
+
+	check.checkBackend(ctx, backend)
+	assert.Equal(t, 1, lb.numRemovedServers)
+	assert.Len(t, backend.disabledURLs, 1)
+	assert.Equal(t, float64(0), collectingMetrics.Gauge.GaugeValue)
+	assert.Equal(t, float64(5), collectingMetrics.Counter.CounterValue)
+
+	atomic.StoreInt32(&healthy, 1)
+	check.checkBackend(ctx, backend)
+	assert.Equal(t, 0, lb.numUpsertedServers)
+
+	check.checkBackend(ctx, backend)
+	assert.Equal(t, 1, lb.numUpsertedServers)
+	assert.Empty(t, backend.disabledURLs)
+	assert.Equal(t, float64(1), collectingMetrics.Gauge.GaugeValue)
+	assert.Equal(t, 8, collectingMetrics.Histogram.Observations)
+}
+
+func TestCheckBackend_weight(t *testing.T) {
+	var healthy int32 = 1
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if atomic.LoadInt32(&healthy) == 0 {
+			rw.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	lb, err := roundrobin.New(nil)
+	require.NoError(t, err)
+
+	serverURL := testhelpers.MustParseURL(server.URL)
+	require.NoError(t, lb.UpsertServer(serverURL, roundrobin.Weight(3)))
+
+	backend := NewBackendConfig(Options{
+		Path:    "/health",
+		Timeout: healthCheckTimeout,
+		LB:      lb,
+	}, "backendName")
+
+	ctx := context.Background()
+	check := HealthCheck{
+		Backends: make(map[string]*BackendConfig),
+		metrics:  testhelpers.NewCollectingHealthCheckMetrics(),
+	}
+
+	atomic.StoreInt32(&healthy, 0)
+	check.checkBackend(ctx, backend)
+	assert.Empty(t, lb.Servers())
+
+	// The server is put back with its weight.
+	atomic.StoreInt32(&healthy, 1)
+	check.checkBackend(ctx, backend)
+
+	weight, ok := lb.ServerWeight(serverURL)
+	require.True(t, ok)
+	assert.Equal(t, 3, weight)
+}
+
+func TestApplyServerOptions(t *testing.T) {
+	u := testhelpers.MustParseURL("http://foo")
+
+	weight, err := ApplyServerOptions(u, 3, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 3, weight)
+
+	weight, err = ApplyServerOptions(u, 3, []roundrobin.ServerOption{roundrobin.Weight(5)})
+	require.NoError(t, err)
+	assert.Equal(t, 5, weight)
+
+	_, err = ApplyServerOptions(u, 3, []roundrobin.ServerOption{roundrobin.Weight(-1)})
+	assert.Error(t, err)
+}
@@ -0,0 +1,284 @@
+package healthcheck
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/containous/traefik/pkg/config"
+	"github.com/containous/traefik/pkg/log"
+	"github.com/go-kit/kit/metrics"
+	"github.com/vulcand/oxy/roundrobin"
+)
+
+const serverEjected = "EJECTED"
+
+// PassiveOptions are the passive health check options.
+type PassiveOptions struct {
+	// MaxConsecutiveErrors is the number of consecutive errors (5xx responses, including the ones due to connection errors)
+	// after which a server is ejected.
+	MaxConsecutiveErrors int
+	// Window is the period of time in which the consecutive errors must happen.
+	Window time.Duration
+	// BaseEjectionTime is the duration of the first ejection of a server, which doubles with each following ejection.
+	BaseEjectionTime time.Duration
+	// MaxEjectionTime caps the duration of an ejection.
+	MaxEjectionTime time.Duration
+	// MaxEjectionPercent is the maximum percentage of the servers that can be ejected at the same time.
+	MaxEjectionPercent int
+	// EjectedGauge is set to 1 when a server is ejected, and to 0 when it is returned to the balancer. It can be nil.
+	EjectedGauge metrics.Gauge
+}
+
+type passiveServer struct {
+	url *url.URL
+	// weight is the weight of the server in the balancer, restored at the end of an ejection.
+	weight int
+
+	errors     int
+	firstError time.Time
+
+	ejected bool
+	// ejections is the number of consecutive ejections, which sets the duration of the next one.
+	ejections       int
+	lastEjectionEnd time.Time
+	lastEjection    time.Duration
+	// restoreTimer returns the server to the balancer at the end of its ejection.
+	restoreTimer *time.Timer
+}
+
+// PassiveHealthCheck is an http.Handler, placed between a balancer and its servers,
+// which watches the responses of the servers,
+// and temporarily removes from the balancer the ones returning too many consecutive errors.
+type PassiveHealthCheck struct {
+	PassiveOptions
+	next        http.Handler
+	name        string
+	serviceInfo *config.ServiceInfo // can be nil
+	logger      log.Logger
+
+	mu      sync.Mutex
+	lb      BalancerHandler
+	servers map[string]*passiveServer
+	ejected int
+	stopped bool
+}
+
+// NewPassiveHealthCheck creates a new PassiveHealthCheck forwarding the requests to next.
+// The status of the servers is kept up to date in the given ServiceInfo.
+func NewPassiveHealthCheck(ctx context.Context, next http.Handler, options PassiveOptions, backendName string, serviceInfo *config.ServiceInfo) *PassiveHealthCheck {
+	return &PassiveHealthCheck{
+		PassiveOptions: options,
+		next:           next,
+		name:           backendName,
+		serviceInfo:    serviceInfo,
+		logger:         log.FromContext(ctx),
+		servers:        make(map[string]*passiveServer),
+	}
+}
+
+// SetBalancer sets the balancer the servers are ejected from.
+// It has to be called before the PassiveHealthCheck serves any request.
+func (p *PassiveHealthCheck) SetBalancer(lb BalancerHandler) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.lb = lb
+}
+
+// Stop stops the ejections in progress, once the PassiveHealthCheck is replaced by a new configuration:
+// the ejected servers are not returned to the balancer anymore, as it is replaced as well.
+func (p *PassiveHealthCheck) Stop() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.stopped = true
+	for _, srv := range p.servers {
+		if srv.restoreTimer != nil {
+			srv.restoreTimer.Stop()
+		}
+	}
+}
+
+func (p *PassiveHealthCheck) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	// The balancer sets the URL of the request to the URL of the server it picked.
+	u := req.URL
+
+	recorder := newStatusRecorder(rw)
+	p.next.ServeHTTP(recorder, req)
+
+	p.record(u, recorder.Status(), time.Now())
+}
+
+func (p *PassiveHealthCheck) record(u *url.URL, status int, now time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.lb == nil || p.stopped {
+		return
+	}
+
+	srv, ok := p.servers[u.String()]
+	if !ok {
+		srv = &passiveServer{url: u}
+		p.servers[u.String()] = srv
+	}
+
+	if srv.ejected {
+		return
+	}
+
+	if status < http.StatusInternalServerError {
+		srv.errors = 0
+		return
+	}
+
+	if srv.errors == 0 || now.Sub(srv.firstError) > p.Window {
+		srv.errors = 0
+		srv.firstError = now
+	}
+
+	srv.errors++
+	if srv.errors >= p.MaxConsecutiveErrors {
+		p.eject(srv, now)
+	}
+}
+
+func (p *PassiveHealthCheck) eject(srv *passiveServer, now time.Time) {
+	total := len(p.lb.Servers()) + p.ejected
+	if (p.ejected+1)*100 > total*p.MaxEjectionPercent {
+		p.logger.Debugf("Passive health check: not ejecting server %s, too many servers are already ejected", srv.url)
+		return
+	}
+
+	weight, ok := p.lb.ServerWeight(srv.url)
+	if !ok {
+		weight = 1
+	}
+
+	if err := p.lb.RemoveServer(srv.url); err != nil {
+		// The server may have already been removed by the active health check.
+		p.logger.Debugf("Passive health check: cannot eject server %s: %v", srv.url, err)
+		return
+	}
+
+	// A server staying healthy longer than its last ejection starts over with the base ejection time.
+	if now.Sub(srv.lastEjectionEnd) > srv.lastEjection {
+		srv.ejections = 0
+	}
+
+	duration := p.BaseEjectionTime
+	for i := 0; i < srv.ejections && duration < p.MaxEjectionTime; i++ {
+		duration *= 2
+	}
+	if duration > p.MaxEjectionTime {
+		duration = p.MaxEjectionTime
+	}
+
+	srv.ejected = true
+	srv.weight = weight
+	srv.errors = 0
+	srv.ejections++
+	srv.lastEjection = duration
+	srv.lastEjectionEnd = now.Add(duration)
+	p.ejected++
+
+	p.logger.Warnf("Passive health check: ejecting server for %s after %d consecutive errors. Backend: %q URL: %q", duration, p.MaxConsecutiveErrors, p.name, srv.url)
+	p.setStatus(srv.url, serverEjected, 1)
+
+	srv.restoreTimer = time.AfterFunc(duration, func() {
+		p.restore(srv)
+	})
+}
+
+func (p *PassiveHealthCheck) restore(srv *passiveServer) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.stopped {
+		return
+	}
+
+	srv.ejected = false
+	srv.restoreTimer = nil
+	p.ejected--
+
+	// The active health check, which does not check the ejected servers, may have found the server down in the meantime:
+	// it is then returned to the balancer by the active health check, once healthy again.
+	if p.serviceInfo != nil && p.serviceInfo.GetAllStatus()[srv.url.String()] == serverDown {
+		p.logger.Debugf("Passive health check: ejection time elapsed, but the server is down. Backend: %q URL: %q", p.name, srv.url)
+		return
+	}
+
+	p.logger.Warnf("Passive health check: ejection time elapsed, returning to server list. Backend: %q URL: %q", p.name, srv.url)
+	if err := p.lb.UpsertServer(srv.url, roundrobin.Weight(srv.weight)); err != nil {
+		p.logger.Error(err)
+		return
+	}
+
+	p.setStatus(srv.url, serverUp, 0)
+}
+
+func (p *PassiveHealthCheck) setStatus(u *url.URL, status string, ejectedValue float64) {
+	if p.serviceInfo != nil {
+		p.serviceInfo.UpdateStatus(u.String(), status)
+	}
+
+	if p.EjectedGauge != nil {
+		p.EjectedGauge.With("backend", p.name, "url", u.String()).Set(ejectedValue)
+	}
+}
+
+type statusRecorder interface {
+	http.ResponseWriter
+	Status() int
+}
+
+type statusRecorderWithoutCloseNotify struct {
+	http.ResponseWriter
+	status int
+}
+
+func newStatusRecorder(rw http.ResponseWriter) statusRecorder {
+	recorder := &statusRecorderWithoutCloseNotify{ResponseWriter: rw, status: http.StatusOK}
+	if _, ok := rw.(http.CloseNotifier); ok {
+		return &statusRecorderWithCloseNotify{recorder}
+	}
+	return recorder
+}
+
+// WriteHeader captures the status code for later retrieval.
+func (s *statusRecorderWithoutCloseNotify) WriteHeader(status int) {
+	s.status = status
+	s.ResponseWriter.WriteHeader(status)
+}
+
+// Status returns the status code of the response.
+func (s *statusRecorderWithoutCloseNotify) Status() int {
+	return s.status
+}
+
+// Hijack hijacks the connection.
+func (s *statusRecorderWithoutCloseNotify) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return s.ResponseWriter.(http.Hijacker).Hijack()
+}
+
+// Flush sends any buffered data to the client.
+func (s *statusRecorderWithoutCloseNotify) Flush() {
+	if flusher, ok := s.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+type statusRecorderWithCloseNotify struct {
+	*statusRecorderWithoutCloseNotify
+}
+
+// CloseNotify returns a channel that receives at most a single value (true) when the client connection has gone away.
+func (s *statusRecorderWithCloseNotify) CloseNotify() <-chan bool {
+	return s.ResponseWriter.(http.CloseNotifier).CloseNotify()
+}
@@ -0,0 +1,270 @@
+package healthcheck
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/pkg/config"
+	"github.com/containous/traefik/pkg/testhelpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newPassiveTestLoadBalancer(t *testing.T, rawURLs ...string) *testLoadBalancer {
+	t.Helper()
+
+	lb := &testLoadBalancer{RWMutex: &sync.RWMutex{}}
+	for _, rawURL := range rawURLs {
+		u, err := url.Parse(rawURL)
+		require.NoError(t, err)
+		lb.servers = append(lb.servers, u)
+	}
+	return lb
+}
+
+func TestPassiveHealthCheck_record(t *testing.T) {
+	testCases := []struct {
+		desc            string
+		statuses        []int
+		interval        time.Duration
+		expectedEjected bool
+	}{
+		{
+			desc:     "successful responses",
+			statuses: []int{200, 200, 200, 200},
+		},
+		{
+			desc:     "client errors are not counted",
+			statuses: []int{404, 400, 404, 499},
+		},
+		{
+			desc:            "consecutive errors",
+			statuses:        []int{500, 502, 503},
+			expectedEjected: true,
+		},
+		{
+			desc:     "errors interrupted by a success",
+			statuses: []int{500, 502, 200, 503},
+		},
+		{
+			desc:     "errors spread over more than the window",
+			statuses: []int{500, 502, 503, 504},
+			interval: 6 * time.Second,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			lb := newPassiveTestLoadBalancer(t, "http://a", "http://b")
+			serviceInfo := &config.ServiceInfo{}
+			gauge := &testhelpers.CollectingGauge{}
+
+			passive := NewPassiveHealthCheck(context.Background(), nil, PassiveOptions{
+				MaxConsecutiveErrors: 3,
+				Window:               10 * time.Second,
+				BaseEjectionTime:     time.Hour,
+				MaxEjectionTime:      time.Hour,
+				MaxEjectionPercent:   50,
+				EjectedGauge:         gauge,
+			}, "foobar", serviceInfo)
+			passive.SetBalancer(lb)
+
+			u := lb.Servers()[0]
+			now := time.Now()
+			for _, status := range test.statuses {
+				passive.record(u, status, now)
+				now = now.Add(test.interval)
+			}
+
+			if !test.expectedEjected {
+				assert.Equal(t, 0, lb.numRemovedServers)
+				assert.Len(t, lb.Servers(), 2)
+				assert.Empty(t, serviceInfo.GetAllStatus())
+				return
+			}
+
+			assert.Equal(t, 1, lb.numRemovedServers)
+			assert.Len(t, lb.Servers(), 1)
+			assert.Equal(t, map[string]string{"http://a": serverEjected}, serviceInfo.GetAllStatus())
+			assert.Equal(t, float64(1), gauge.GaugeValue)
+			assert.Equal(t, []string{"backend", "foobar", "url", "http://a"}, gauge.LastLabelValues)
+		})
+	}
+}
+
+func TestPassiveHealthCheck_restore(t *testing.T) {
+	lb := newPassiveTestLoadBalancer(t, "http://a", "http://b")
+	serviceInfo := &config.ServiceInfo{}
+	gauge := &testhelpers.CollectingGauge{}
+
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusBadGateway)
+	})
+
+	passive := NewPassiveHealthCheck(context.Background(), next, PassiveOptions{
+		MaxConsecutiveErrors: 2,
+		Window:               time.Minute,
+		BaseEjectionTime:     50 * time.Millisecond,
+		MaxEjectionTime:      time.Second,
+		MaxEjectionPercent:   50,
+		EjectedGauge:         gauge,
+	}, "foobar", serviceInfo)
+	passive.SetBalancer(lb)
+
+	for i := 0; i < 2; i++ {
+		recorder := httptest.NewRecorder()
+		passive.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://a", nil))
+		assert.Equal(t, http.StatusBadGateway, recorder.Code)
+	}
+
+	lb.RLock()
+	assert.Equal(t, 1, lb.numRemovedServers)
+	lb.RUnlock()
+	assert.Equal(t, map[string]string{"http://a": serverEjected}, serviceInfo.GetAllStatus())
+
+	time.Sleep(200 * time.Millisecond)
+
+	lb.RLock()
+	assert.Equal(t, 1, lb.numUpsertedServers)
+	lb.RUnlock()
+	assert.Len(t, lb.Servers(), 2)
+	assert.Equal(t, map[string]string{"http://a": serverUp}, serviceInfo.GetAllStatus())
+
+	passive.mu.Lock()
+	assert.Equal(t, float64(0), gauge.GaugeValue)
+	passive.mu.Unlock()
+}
+
+func TestPassiveHealthCheck_restoreServerDown(t *testing.T) {
+	lb := newPassiveTestLoadBalancer(t, "http://a", "http://b")
+	serviceInfo := &config.ServiceInfo{}
+
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusBadGateway)
+	})
+
+	passive := NewPassiveHealthCheck(context.Background(), next, PassiveOptions{
+		MaxConsecutiveErrors: 1,
+		Window:               time.Minute,
+		BaseEjectionTime:     50 * time.Millisecond,
+		MaxEjectionTime:      time.Second,
+		MaxEjectionPercent:   50,
+	}, "foobar", serviceInfo)
+	passive.SetBalancer(lb)
+
+	passive.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://a", nil))
+	assert.Len(t, lb.Servers(), 1)
+
+	// The active health check finds the ejected server down.
+	_ = NewLBStatusUpdater(lb, serviceInfo).RemoveServer(testhelpers.MustParseURL("http://a"))
+	assert.Equal(t, map[string]string{"http://a": serverDown}, serviceInfo.GetAllStatus())
+
+	time.Sleep(200 * time.Millisecond)
+
+	assert.Len(t, lb.Servers(), 1)
+	assert.Equal(t, map[string]string{"http://a": serverDown}, serviceInfo.GetAllStatus())
+}
+
+func TestPassiveHealthCheck_Stop(t *testing.T) {
+	lb := newPassiveTestLoadBalancer(t, "http://a", "http://b")
+	serviceInfo := &config.ServiceInfo{}
+
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusBadGateway)
+	})
+
+	passive := NewPassiveHealthCheck(context.Background(), next, PassiveOptions{
+		MaxConsecutiveErrors: 1,
+		Window:               time.Minute,
+		BaseEjectionTime:     50 * time.Millisecond,
+		MaxEjectionTime:      time.Second,
+		MaxEjectionPercent:   50,
+	}, "foobar", serviceInfo)
+	passive.SetBalancer(lb)
+
+	passive.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://a", nil))
+	assert.Len(t, lb.Servers(), 1)
+
+	// The configuration is replaced while the server is ejected.
+	passive.Stop()
+
+	time.Sleep(200 * time.Millisecond)
+
+	lb.RLock()
+	assert.Equal(t, 0, lb.numUpsertedServers)
+	lb.RUnlock()
+	assert.Equal(t, map[string]string{"http://a": serverEjected}, serviceInfo.GetAllStatus())
+}
+
+func TestPassiveHealthCheck_ejectionTime(t *testing.T) {
+	lb := newPassiveTestLoadBalancer(t, "http://a", "http://b")
+
+	passive := NewPassiveHealthCheck(context.Background(), nil, PassiveOptions{
+		MaxConsecutiveErrors: 1,
+		Window:               time.Minute,
+		BaseEjectionTime:     time.Hour,
+		MaxEjectionTime:      3 * time.Hour,
+		MaxEjectionPercent:   100,
+	}, "foobar", nil)
+	passive.SetBalancer(lb)
+
+	u := lb.Servers()[0]
+	now := time.Now()
+
+	var durations []time.Duration
+	for i := 0; i < 4; i++ {
+		passive.record(u, http.StatusInternalServerError, now)
+
+		srv := passive.servers[u.String()]
+		require.True(t, srv.ejected)
+		durations = append(durations, srv.lastEjection)
+
+		// The timer is not waited for, the server is restored right away.
+		passive.restore(srv)
+		now = now.Add(time.Minute)
+	}
+
+	// The ejection time doubles with each consecutive ejection, up to the max ejection time.
+	assert.Equal(t, []time.Duration{time.Hour, 2 * time.Hour, 3 * time.Hour, 3 * time.Hour}, durations)
+
+	// A server staying healthy longer than its last ejection starts over with the base ejection time.
+	now = now.Add(7 * time.Hour)
+	passive.record(u, http.StatusInternalServerError, now)
+	assert.Equal(t, time.Hour, passive.servers[u.String()].lastEjection)
+}
+
+func TestPassiveHealthCheck_maxEjectionPercent(t *testing.T) {
+	lb := newPassiveTestLoadBalancer(t, "http://a", "http://b", "http://c", "http://d")
+	serviceInfo := &config.ServiceInfo{}
+
+	passive := NewPassiveHealthCheck(context.Background(), nil, PassiveOptions{
+		MaxConsecutiveErrors: 1,
+		Window:               time.Minute,
+		BaseEjectionTime:     time.Hour,
+		MaxEjectionTime:      time.Hour,
+		MaxEjectionPercent:   50,
+	}, "foobar", serviceInfo)
+	passive.SetBalancer(lb)
+
+	servers := append([]*url.URL{}, lb.Servers()...)
+
+	now := time.Now()
+	for _, u := range servers {
+		passive.record(u, http.StatusInternalServerError, now)
+	}
+
+	// Only half of the servers can be ejected at the same time.
+	assert.Len(t, lb.Servers(), 2)
+	assert.Equal(t, map[string]string{
+		"http://a": serverEjected,
+		"http://b": serverEjected,
+	}, serviceInfo.GetAllStatus())
+}
@@ -0,0 +1,269 @@
+package healthcheck
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/containous/traefik/pkg/config"
+	"github.com/containous/traefik/pkg/log"
+	"github.com/containous/traefik/pkg/proxyprotocol"
+	"github.com/containous/traefik/pkg/safe"
+	"github.com/containous/traefik/pkg/tcp"
+)
+
+var tcpSingleton *TCPHealthCheck
+var tcpOnce sync.Once
+
+// TCPBalancerHandler includes functionality for TCP load-balancing management.
+type TCPBalancerHandler interface {
+	AddServer(address string, server tcp.Handler)
+	AddWeightedServer(address string, server tcp.Handler, weight int)
+	RemoveServer(address string) error
+	RestoreServer(address string) error
+}
+
+// TCPOptions are the TCP health check options.
+type TCPOptions struct {
+	Interval time.Duration
+	Timeout  time.Duration
+	// Send is the payload written to the server once connected.
+	Send string
+	// Expect is the payload the server must respond with.
+	Expect string
+	// ProxyProtocolVersion is the version of the PROXY protocol header sent to the server, 0 to send none.
+	ProxyProtocolVersion int
+	// TLSConfig, if not nil, is the configuration of the TLS connection to the server,
+	// the server name defaulting to the host of the address.
+	TLSConfig *tls.Config
+}
+
+func (opt TCPOptions) String() string {
+	return fmt.Sprintf("[Interval: %s Timeout: %s Send: %q Expect: %q ProxyProtocolVersion: %d TLS: %t]",
+		opt.Interval, opt.Timeout, opt.Send, opt.Expect, opt.ProxyProtocolVersion, opt.TLSConfig != nil)
+}
+
+// TCPServiceConfig is the TCP health check configuration of a service.
+type TCPServiceConfig struct {
+	TCPOptions
+	name      string
+	addresses []string
+	// balancers are all the balancers built for the service, the servers are removed from each of them.
+	balancers []TCPBalancerHandler
+	disabled  map[string]bool
+}
+
+// NewTCPServiceConfig instantiates a new TCPServiceConfig, checking the servers with the given addresses.
+func NewTCPServiceConfig(options TCPOptions, serviceName string, addresses []string, balancers []TCPBalancerHandler) *TCPServiceConfig {
+	return &TCPServiceConfig{
+		TCPOptions: options,
+		name:       serviceName,
+		addresses:  addresses,
+		balancers:  balancers,
+		disabled:   make(map[string]bool),
+	}
+}
+
+// TCPHealthCheck runs the health checks of the TCP services.
+type TCPHealthCheck struct {
+	Services map[string]*TCPServiceConfig
+	cancel   context.CancelFunc
+}
+
+// GetTCPHealthCheck returns the TCP health check which is guaranteed to be a singleton.
+func GetTCPHealthCheck() *TCPHealthCheck {
+	tcpOnce.Do(func() {
+		tcpSingleton = &TCPHealthCheck{
+			Services: make(map[string]*TCPServiceConfig),
+		}
+	})
+	return tcpSingleton
+}
+
+// SetServicesConfiguration sets the services configuration,
+// and stops the health checks of the previous configuration.
+func (hc *TCPHealthCheck) SetServicesConfiguration(parentCtx context.Context, services map[string]*TCPServiceConfig) {
+	hc.Services = services
+	if hc.cancel != nil {
+		hc.cancel()
+	}
+	ctx, cancel := context.WithCancel(parentCtx)
+	hc.cancel = cancel
+
+	for _, service := range services {
+		currentService := service
+		safe.Go(func() {
+			hc.execute(ctx, currentService)
+		})
+	}
+}
+
+func (hc *TCPHealthCheck) execute(ctx context.Context, service *TCPServiceConfig) {
+	log.Debugf("Initial health check for TCP service: %q", service.name)
+	hc.checkService(service)
+	ticker := time.NewTicker(service.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			log.Debugf("Stopping current health check goroutines of TCP service: %s", service.name)
+			return
+		case <-ticker.C:
+			log.Debugf("Refreshing health check for TCP service: %s", service.name)
+			hc.checkService(service)
+		}
+	}
+}
+
+func (hc *TCPHealthCheck) checkService(service *TCPServiceConfig) {
+	for _, address := range service.addresses {
+		err := checkTCPHealth(address, service.TCPOptions)
+
+		switch {
+		case err != nil && !service.disabled[address]:
+			log.Warnf("Health check failed: Remove from server list. TCP service: %q Address: %q Reason: %s", service.name, address, err)
+			for _, balancer := range service.balancers {
+				if err := balancer.RemoveServer(address); err != nil {
+					log.Error(err)
+				}
+			}
+			service.disabled[address] = true
+		case err != nil:
+			log.Warnf("Health check still failing. TCP service: %q Address: %q Reason: %s", service.name, address, err)
+		case service.disabled[address]:
+			log.Warnf("Health check up: Returning to server list. TCP service: %q Address: %q", service.name, address)
+			for _, balancer := range service.balancers {
+				if err := balancer.RestoreServer(address); err != nil {
+					log.Error(err)
+				}
+			}
+			delete(service.disabled, address)
+		}
+	}
+}
+
+// checkTCPHealth returns a nil error in case it was successful and otherwise
+// a non-nil error with a meaningful description why the health check failed.
+// The connection is opened the way the proxy does, with the PROXY protocol header and TLS.
+func checkTCPHealth(address string, options TCPOptions) error {
+	conn, err := net.DialTimeout("tcp", address, options.Timeout)
+	if err != nil {
+		return fmt.Errorf("connection failed: %s", err)
+	}
+	defer conn.Close()
+
+	if options.Send == "" && options.Expect == "" && options.ProxyProtocolVersion == 0 && options.TLSConfig == nil {
+		return nil
+	}
+
+	if err = conn.SetDeadline(time.Now().Add(options.Timeout)); err != nil {
+		return err
+	}
+
+	// The PROXY protocol header precedes the TLS handshake.
+	if options.ProxyProtocolVersion > 0 {
+		err = proxyprotocol.WriteHeader(conn, options.ProxyProtocolVersion, conn.LocalAddr(), conn.RemoteAddr())
+		if err != nil {
+			return fmt.Errorf("failed to send the PROXY protocol header: %s", err)
+		}
+	}
+
+	if options.TLSConfig != nil {
+		tlsConfig := options.TLSConfig
+		if tlsConfig.ServerName == "" {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+
+			tlsConfig = tlsConfig.Clone()
+			tlsConfig.ServerName = host
+		}
+
+		tlsConn := tls.Client(conn, tlsConfig)
+		if err = tlsConn.Handshake(); err != nil {
+			return fmt.Errorf("TLS handshake failed: %s", err)
+		}
+		conn = tlsConn
+	}
+
+	if options.Send != "" {
+		if _, err = conn.Write([]byte(options.Send)); err != nil {
+			return fmt.Errorf("failed to send the payload: %s", err)
+		}
+	}
+
+	if options.Expect == "" {
+		return nil
+	}
+
+	response := make([]byte, len(options.Expect))
+	n, err := io.ReadFull(conn, response)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return fmt.Errorf("failed to read the response: %s", err)
+	}
+
+	if !bytes.Equal(response[:n], []byte(options.Expect)) {
+		return fmt.Errorf("unexpected response: %q", response[:n])
+	}
+
+	return nil
+}
+
+// NewTCPLBStatusUpdater returns a new TCPLbStatusUpdater
+func NewTCPLBStatusUpdater(bh TCPBalancerHandler, svinfo *config.TCPServiceInfo) *TCPLbStatusUpdater {
+	return &TCPLbStatusUpdater{
+		TCPBalancerHandler: bh,
+		serviceInfo:        svinfo,
+	}
+}
+
+// TCPLbStatusUpdater wraps a TCPBalancerHandler and a TCPServiceInfo,
+// so it can keep track of the status of a server in the TCPServiceInfo.
+type TCPLbStatusUpdater struct {
+	TCPBalancerHandler
+	serviceInfo *config.TCPServiceInfo // can be nil
+}
+
+// AddServer adds the given server to the TCPBalancerHandler,
+// and updates the status of the server to "UP".
+func (lb *TCPLbStatusUpdater) AddServer(address string, server tcp.Handler) {
+	lb.TCPBalancerHandler.AddServer(address, server)
+	if lb.serviceInfo != nil {
+		lb.serviceInfo.UpdateStatus(address, serverUp)
+	}
+}
+
+// AddWeightedServer adds the given server with the given weight to the TCPBalancerHandler,
+// and updates the status of the server to "UP".
+func (lb *TCPLbStatusUpdater) AddWeightedServer(address string, server tcp.Handler, weight int) {
+	lb.TCPBalancerHandler.AddWeightedServer(address, server, weight)
+	if lb.serviceInfo != nil {
+		lb.serviceInfo.UpdateStatus(address, serverUp)
+	}
+}
+
+// RemoveServer removes the given server from the TCPBalancerHandler,
+// and updates the status of the server to "DOWN".
+func (lb *TCPLbStatusUpdater) RemoveServer(address string) error {
+	err := lb.TCPBalancerHandler.RemoveServer(address)
+	if err == nil && lb.serviceInfo != nil {
+		lb.serviceInfo.UpdateStatus(address, serverDown)
+	}
+	return err
+}
+
+// RestoreServer puts back the given server in the TCPBalancerHandler,
+// and updates the status of the server to "UP".
+func (lb *TCPLbStatusUpdater) RestoreServer(address string) error {
+	err := lb.TCPBalancerHandler.RestoreServer(address)
+	if err == nil && lb.serviceInfo != nil {
+		lb.serviceInfo.UpdateStatus(address, serverUp)
+	}
+	return err
+}
@@ -0,0 +1,237 @@
+package healthcheck
+
+import (
+	"bufio"
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/pkg/config"
+	"github.com/containous/traefik/pkg/tcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startTCPServer starts a server answering each line it reads with the given response.
+func startTCPServer(t *testing.T, response string) net.Listener {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go func() {
+				defer conn.Close()
+
+				if _, err := bufio.NewReader(conn).ReadString('\n'); err != nil {
+					return
+				}
+				_, _ = conn.Write([]byte(response))
+			}()
+		}
+	}()
+
+	return listener
+}
+
+func TestCheckTCPHealth(t *testing.T) {
+	listener := startTCPServer(t, "+PONG\r\n")
+	defer listener.Close()
+
+	closedListener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	closedAddress := closedListener.Addr().String()
+	require.NoError(t, closedListener.Close())
+
+	testCases := []struct {
+		desc          string
+		address       string
+		send          string
+		expect        string
+		expectedError bool
+	}{
+		{
+			desc:    "connection only",
+			address: listener.Addr().String(),
+		},
+		{
+			desc:          "connection refused",
+			address:       closedAddress,
+			expectedError: true,
+		},
+		{
+			desc:    "expected response",
+			address: listener.Addr().String(),
+			send:    "PING\r\n",
+			expect:  "+PONG",
+		},
+		{
+			desc:          "unexpected response",
+			address:       listener.Addr().String(),
+			send:          "PING\r\n",
+			expect:        "+OK",
+			expectedError: true,
+		},
+		{
+			desc:          "response shorter than expected",
+			address:       listener.Addr().String(),
+			send:          "PING\r\n",
+			expect:        "+PONG\r\n+PONG",
+			expectedError: true,
+		},
+		{
+			desc:          "no response",
+			address:       listener.Addr().String(),
+			expect:        "+PONG",
+			expectedError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			err := checkTCPHealth(test.address, TCPOptions{Timeout: healthCheckTimeout, Send: test.send, Expect: test.expect})
+			if test.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+// bufferedConn is a connection whose first bytes have been read in a buffered reader.
+type bufferedConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+func (c bufferedConn) Read(p []byte) (int, error) {
+	return c.reader.Read(p)
+}
+
+func TestCheckTCPHealth_proxyProtocolAndTLS(t *testing.T) {
+	// The test server only provides the certificate.
+	tlsServer := httptest.NewTLSServer(http.NotFoundHandler())
+	defer tlsServer.Close()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	headers := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		reader := bufio.NewReader(conn)
+		header, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		headers <- header
+
+		tlsConn := tls.Server(bufferedConn{Conn: conn, reader: reader}, tlsServer.TLS)
+		if _, err := bufio.NewReader(tlsConn).ReadString('\n'); err != nil {
+			return
+		}
+		_, _ = tlsConn.Write([]byte("+PONG\r\n"))
+	}()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(tlsServer.Certificate())
+
+	err = checkTCPHealth(listener.Addr().String(), TCPOptions{
+		Timeout:              healthCheckTimeout,
+		Send:                 "PING\r\n",
+		Expect:               "+PONG",
+		ProxyProtocolVersion: 1,
+		TLSConfig:            &tls.Config{RootCAs: roots},
+	})
+	require.NoError(t, err)
+
+	header := <-headers
+	assert.True(t, strings.HasPrefix(header, "PROXY TCP4 127.0.0.1 127.0.0.1 "), header)
+}
+
+func TestCheckTCPHealth_TLSHandshakeFailure(t *testing.T) {
+	listener := startTCPServer(t, "+PONG\r\n")
+	defer listener.Close()
+
+	// The server does not speak TLS.
+	err := checkTCPHealth(listener.Addr().String(), TCPOptions{Timeout: healthCheckTimeout, TLSConfig: &tls.Config{}})
+	assert.Error(t, err)
+}
+
+type addressHandler string
+
+func (h addressHandler) ServeTCP(conn net.Conn) {
+	_, _ = conn.Write([]byte(h))
+	conn.Close()
+}
+
+func serveTCP(t *testing.T, balancer tcp.Handler) string {
+	t.Helper()
+
+	client, server := net.Pipe()
+	go balancer.ServeTCP(server)
+
+	buf := make([]byte, 64)
+	require.NoError(t, client.SetReadDeadline(time.Now().Add(time.Second)))
+	n, _ := client.Read(buf)
+	return string(buf[:n])
+}
+
+func TestTCPHealthCheck_checkService(t *testing.T) {
+	listener := startTCPServer(t, "+PONG\r\n")
+	defer listener.Close()
+
+	healthyAddress := listener.Addr().String()
+
+	closedListener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	sickAddress := closedListener.Addr().String()
+	require.NoError(t, closedListener.Close())
+
+	serviceInfo := &config.TCPServiceInfo{}
+	balancer := tcp.NewWRRLoadBalancer(nil)
+	lbsu := NewTCPLBStatusUpdater(balancer, serviceInfo)
+	lbsu.AddServer(healthyAddress, addressHandler("healthy"))
+	lbsu.AddServer(sickAddress, addressHandler("sick"))
+
+	assert.Equal(t, map[string]string{healthyAddress: serverUp, sickAddress: serverUp}, serviceInfo.GetAllStatus())
+
+	service := NewTCPServiceConfig(TCPOptions{Timeout: healthCheckTimeout}, "foobar", []string{healthyAddress, sickAddress}, []TCPBalancerHandler{lbsu})
+	hc := GetTCPHealthCheck()
+
+	hc.checkService(service)
+
+	assert.Equal(t, map[string]string{healthyAddress: serverUp, sickAddress: serverDown}, serviceInfo.GetAllStatus())
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, "healthy", serveTCP(t, balancer))
+	}
+
+	// The sick server recovers.
+	recovered, err := net.Listen("tcp", sickAddress)
+	require.NoError(t, err)
+	defer recovered.Close()
+
+	hc.checkService(service)
+
+	assert.Equal(t, map[string]string{healthyAddress: serverUp, sickAddress: serverUp}, serviceInfo.GetAllStatus())
+	assert.ElementsMatch(t, []string{"healthy", "sick"}, []string{serveTCP(t, balancer), serveTCP(t, balancer)})
+}
@@ -0,0 +1,228 @@
+package hostresolver
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+const lookupTimeout = 5 * time.Second
+
+// ednsBufferSize is the size of the UDP responses advertised with EDNS0,
+// so that the servers can answer with more records before truncating the responses.
+const ednsBufferSize = 4096
+
+// SRV is a service record.
+type SRV struct {
+	Target   string
+	Port     uint16
+	Priority uint16
+	Weight   uint16
+	// IPs are the addresses of the target.
+	IPs []net.IP
+}
+
+// LookupHost returns the IPv4 and IPv6 addresses of the host, and the lowest TTL of their records.
+// A host without any address is not an error.
+func (hr *Resolver) LookupHost(host string) ([]net.IP, time.Duration, error) {
+	config, err := dns.ClientConfigFromFile(hr.ResolvConfig)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid resolver configuration file: %s", hr.ResolvConfig)
+	}
+
+	return lookupHost(newClient(), config, host)
+}
+
+// LookupSRV returns the SRV records of the name with the lowest priority, along with the addresses of their targets,
+// and the lowest TTL of these records.
+func (hr *Resolver) LookupSRV(name string) ([]SRV, time.Duration, error) {
+	config, err := dns.ClientConfigFromFile(hr.ResolvConfig)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid resolver configuration file: %s", hr.ResolvConfig)
+	}
+
+	return lookupSRV(newClient(), config, name)
+}
+
+func newClient() *dns.Client {
+	return &dns.Client{Timeout: lookupTimeout}
+}
+
+func lookupHost(client *dns.Client, config *dns.ClientConfig, host string) ([]net.IP, time.Duration, error) {
+	if ip := net.ParseIP(host); ip != nil {
+		return []net.IP{ip}, 0, nil
+	}
+
+	var ips []net.IP
+	var ttl ttlTracker
+	for _, qtype := range []uint16{dns.TypeA, dns.TypeAAAA} {
+		resp, err := exchange(client, config, host, qtype)
+		if err != nil {
+			return nil, 0, err
+		}
+		if resp == nil {
+			continue
+		}
+
+		for _, rr := range resp.Answer {
+			switch record := rr.(type) {
+			case *dns.A:
+				ips = append(ips, record.A)
+				ttl.add(record.Hdr.Ttl)
+			case *dns.AAAA:
+				ips = append(ips, record.AAAA)
+				ttl.add(record.Hdr.Ttl)
+			}
+		}
+	}
+
+	return ips, ttl.value(), nil
+}
+
+func lookupSRV(client *dns.Client, config *dns.ClientConfig, name string) ([]SRV, time.Duration, error) {
+	resp, err := exchange(client, config, name, dns.TypeSRV)
+	if err != nil {
+		return nil, 0, err
+	}
+	if resp == nil {
+		return nil, 0, nil
+	}
+
+	var ttl ttlTracker
+	var records []SRV
+	for _, rr := range resp.Answer {
+		record, ok := rr.(*dns.SRV)
+		if !ok {
+			continue
+		}
+
+		ttl.add(record.Hdr.Ttl)
+		records = append(records, SRV{
+			Target:   record.Target,
+			Port:     record.Port,
+			Priority: record.Priority,
+			Weight:   record.Weight,
+		})
+	}
+
+	if len(records) == 0 {
+		return nil, ttl.value(), nil
+	}
+
+	// Only the targets with the lowest priority are used, the others being backups.
+	sort.SliceStable(records, func(i, j int) bool { return records[i].Priority < records[j].Priority })
+	for i := range records {
+		if records[i].Priority != records[0].Priority {
+			records = records[:i]
+			break
+		}
+	}
+
+	for i, record := range records {
+		for _, rr := range resp.Extra {
+			switch extra := rr.(type) {
+			case *dns.A:
+				if extra.Hdr.Name == record.Target {
+					records[i].IPs = append(records[i].IPs, extra.A)
+					ttl.add(extra.Hdr.Ttl)
+				}
+			case *dns.AAAA:
+				if extra.Hdr.Name == record.Target {
+					records[i].IPs = append(records[i].IPs, extra.AAAA)
+					ttl.add(extra.Hdr.Ttl)
+				}
+			}
+		}
+
+		if len(records[i].IPs) > 0 {
+			continue
+		}
+
+		ips, targetTTL, err := lookupHost(client, config, record.Target)
+		if err != nil {
+			return nil, 0, err
+		}
+		records[i].IPs = ips
+		if len(ips) > 0 {
+			ttl.add(uint32(targetTTL / time.Second))
+		}
+	}
+
+	return records, ttl.value(), nil
+}
+
+// exchange sends the question to the servers of the configuration, for each name of the search list, until one answers.
+// It returns a nil response if the name does not exist.
+func exchange(client *dns.Client, config *dns.ClientConfig, name string, qtype uint16) (*dns.Msg, error) {
+	for _, fqdn := range config.NameList(name) {
+		m := &dns.Msg{}
+		m.SetQuestion(fqdn, qtype)
+		m.SetEdns0(ednsBufferSize, false)
+
+		var lastErr error
+		notFound := false
+		for _, server := range config.Servers {
+			resp, err := exchangeServer(client, m, net.JoinHostPort(server, config.Port))
+			if err != nil {
+				lastErr = fmt.Errorf("exchange error for server %s: %v", server, err)
+				continue
+			}
+
+			if resp.Rcode == dns.RcodeSuccess {
+				return resp, nil
+			}
+
+			if resp.Rcode == dns.RcodeNameError {
+				notFound = true
+				break
+			}
+
+			lastErr = fmt.Errorf("%s answer for server %s", dns.RcodeToString[resp.Rcode], server)
+		}
+
+		if !notFound {
+			if lastErr == nil {
+				lastErr = fmt.Errorf("no server to resolve %s", name)
+			}
+			return nil, fmt.Errorf("failed to resolve %s: %v", name, lastErr)
+		}
+	}
+
+	return nil, nil
+}
+
+// exchangeServer sends the message to the server, and sends it again over TCP if the response is truncated.
+func exchangeServer(client *dns.Client, m *dns.Msg, address string) (*dns.Msg, error) {
+	resp, _, err := client.Exchange(m, address)
+	if err != nil {
+		return nil, err
+	}
+
+	if !resp.Truncated || client.Net == "tcp" {
+		return resp, nil
+	}
+
+	tcpClient := &dns.Client{Net: "tcp", Timeout: client.Timeout}
+	resp, _, err = tcpClient.Exchange(m, address)
+	return resp, err
+}
+
+// ttlTracker tracks the lowest TTL of records.
+type ttlTracker struct {
+	ttl uint32
+	set bool
+}
+
+func (t *ttlTracker) add(ttl uint32) {
+	if !t.set || ttl < t.ttl {
+		t.ttl = ttl
+		t.set = true
+	}
+}
+
+func (t *ttlTracker) value() time.Duration {
+	return time.Duration(t.ttl) * time.Second
+}
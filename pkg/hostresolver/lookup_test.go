@@ -0,0 +1,142 @@
+package hostresolver
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startServer starts a DNS server answering with the given records, over UDP and TCP,
+// and returns the client configuration to query it, and a function shutting it down.
+func startServer(t *testing.T, records ...string) (*dns.ClientConfig, func()) {
+	t.Helper()
+
+	return startTruncatingServer(t, false, records...)
+}
+
+// startTruncatingServer starts a DNS server like startServer,
+// truncating all its UDP responses if truncate is true.
+func startTruncatingServer(t *testing.T, truncate bool, records ...string) (*dns.ClientConfig, func()) {
+	t.Helper()
+
+	zone := make(map[string][]dns.RR)
+	for _, record := range records {
+		rr, err := dns.NewRR(record)
+		require.NoError(t, err)
+
+		key := dns.TypeToString[rr.Header().Rrtype] + " " + rr.Header().Name
+		zone[key] = append(zone[key], rr)
+	}
+
+	handler := dns.HandlerFunc(func(rw dns.ResponseWriter, req *dns.Msg) {
+		resp := &dns.Msg{}
+		resp.SetReply(req)
+
+		question := req.Question[0]
+		resp.Answer = zone[dns.TypeToString[question.Qtype]+" "+question.Name]
+
+		if len(resp.Answer) == 0 && len(zone["A "+question.Name]) == 0 && len(zone["AAAA "+question.Name]) == 0 && len(zone["SRV "+question.Name]) == 0 {
+			resp.Rcode = dns.RcodeNameError
+		}
+
+		if truncate && rw.RemoteAddr().Network() == "udp" {
+			resp.Answer = nil
+			resp.Truncated = true
+		}
+
+		_ = rw.WriteMsg(resp)
+	})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	conn, err := net.ListenPacket("udp", listener.Addr().String())
+	require.NoError(t, err)
+
+	udpServer := &dns.Server{PacketConn: conn, Handler: handler}
+	go func() { _ = udpServer.ActivateAndServe() }()
+
+	tcpServer := &dns.Server{Listener: listener, Handler: handler}
+	go func() { _ = tcpServer.ActivateAndServe() }()
+
+	_, port, err := net.SplitHostPort(listener.Addr().String())
+	require.NoError(t, err)
+
+	config := &dns.ClientConfig{Servers: []string{"127.0.0.1"}, Port: port, Ndots: 1}
+	return config, func() {
+		_ = udpServer.Shutdown()
+		_ = tcpServer.Shutdown()
+	}
+}
+
+func TestLookupHost(t *testing.T) {
+	config, shutdown := startServer(t,
+		"api.internal. 30 IN A 10.0.0.1",
+		"api.internal. 20 IN A 10.0.0.2",
+		"api.internal. 60 IN AAAA ::1",
+	)
+	defer shutdown()
+
+	ips, ttl, err := lookupHost(newClient(), config, "api.internal")
+	require.NoError(t, err)
+
+	assert.Equal(t, []net.IP{net.ParseIP("10.0.0.1").To4(), net.ParseIP("10.0.0.2").To4(), net.ParseIP("::1")}, ips)
+	assert.Equal(t, 20*time.Second, ttl)
+
+	ips, _, err = lookupHost(newClient(), config, "unknown.internal")
+	require.NoError(t, err)
+	assert.Empty(t, ips)
+
+	ips, _, err = lookupHost(newClient(), config, "10.0.0.3")
+	require.NoError(t, err)
+	assert.Equal(t, []net.IP{net.ParseIP("10.0.0.3")}, ips)
+}
+
+func TestLookupHost_truncated(t *testing.T) {
+	config, shutdown := startTruncatingServer(t, true,
+		"api.internal. 30 IN A 10.0.0.1",
+		"api.internal. 30 IN A 10.0.0.2",
+	)
+	defer shutdown()
+
+	ips, _, err := lookupHost(newClient(), config, "api.internal")
+	require.NoError(t, err)
+
+	assert.Equal(t, []net.IP{net.ParseIP("10.0.0.1").To4(), net.ParseIP("10.0.0.2").To4()}, ips)
+}
+
+func TestLookupSRV(t *testing.T) {
+	config, shutdown := startServer(t,
+		"_http._tcp.api.internal. 30 IN SRV 10 1 8080 a.api.internal.",
+		"_http._tcp.api.internal. 30 IN SRV 10 3 8081 b.api.internal.",
+		"_http._tcp.api.internal. 30 IN SRV 20 1 8082 c.api.internal.",
+		"a.api.internal. 10 IN A 10.0.0.1",
+		"b.api.internal. 60 IN A 10.0.0.2",
+		"c.api.internal. 60 IN A 10.0.0.3",
+	)
+	defer shutdown()
+
+	records, ttl, err := lookupSRV(newClient(), config, "_http._tcp.api.internal")
+	require.NoError(t, err)
+
+	expected := []SRV{
+		{Target: "a.api.internal.", Port: 8080, Priority: 10, Weight: 1, IPs: []net.IP{net.ParseIP("10.0.0.1").To4()}},
+		{Target: "b.api.internal.", Port: 8081, Priority: 10, Weight: 3, IPs: []net.IP{net.ParseIP("10.0.0.2").To4()}},
+	}
+	assert.Equal(t, expected, records)
+	assert.Equal(t, 10*time.Second, ttl)
+}
+
+func TestLookupHost_serverFailure(t *testing.T) {
+	config := &dns.ClientConfig{Servers: []string{"127.0.0.1"}, Port: "1", Ndots: 1}
+
+	client := newClient()
+	client.Timeout = 100 * time.Millisecond
+
+	_, _, err := lookupHost(client, config, "api.internal")
+	assert.Error(t, err)
+}
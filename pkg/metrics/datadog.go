@@ -20,18 +20,32 @@ var datadogTicker *time.Ticker
 
 // Metric names consistent with https://github.com/DataDog/integrations-extras/pull/64
 const (
-	ddMetricsBackendReqsName      = "backend.request.total"
-	ddMetricsBackendLatencyName   = "backend.request.duration"
-	ddRetriesTotalName            = "backend.retries.total"
-	ddConfigReloadsName           = "config.reload.total"
-	ddConfigReloadsFailureTagName = "failure"
-	ddLastConfigReloadSuccessName = "config.reload.lastSuccessTimestamp"
-	ddLastConfigReloadFailureName = "config.reload.lastFailureTimestamp"
-	ddEntrypointReqsName          = "entrypoint.request.total"
-	ddEntrypointReqDurationName   = "entrypoint.request.duration"
-	ddEntrypointOpenConnsName     = "entrypoint.connections.open"
-	ddOpenConnsName               = "backend.connections.open"
-	ddServerUpName                = "backend.server.up"
+	ddMetricsBackendReqsName        = "backend.request.total"
+	ddMetricsBackendLatencyName     = "backend.request.duration"
+	ddRetriesTotalName              = "backend.retries.total"
+	ddConfigReloadsName             = "config.reload.total"
+	ddConfigReloadsFailureTagName   = "failure"
+	ddLastConfigReloadSuccessName   = "config.reload.lastSuccessTimestamp"
+	ddLastConfigReloadFailureName   = "config.reload.lastFailureTimestamp"
+	ddEntrypointReqsName            = "entrypoint.request.total"
+	ddEntrypointReqDurationName     = "entrypoint.request.duration"
+	ddEntrypointOpenConnsName       = "entrypoint.connections.open"
+	ddOpenConnsName                 = "backend.connections.open"
+	ddServerUpName                  = "backend.server.up"
+	ddServerEjectedName             = "backend.server.ejected"
+	ddServerHealthCheckFailuresName = "backend.server.healthcheck.failures.total"
+	ddServerHealthCheckDurationName = "backend.server.healthcheck.duration"
+	ddHedgesTotalName               = "backend.hedges.total"
+	ddHedgesWonTotalName            = "backend.hedges.won.total"
+	ddServerOpenConnsName           = "backend.server.open.connections"
+	ddTCPRouterOpenConnsName        = "tcp.router.connections.open"
+	ddTCPRouterConnDurationName     = "tcp.router.connection.duration"
+	ddTCPRouterBytesInTotalName     = "tcp.router.bytes.in.total"
+	ddTCPRouterBytesOutTotalName    = "tcp.router.bytes.out.total"
+	ddTCPServiceOpenConnsName       = "tcp.service.connections.open"
+	ddTCPServiceConnDurationName    = "tcp.service.connection.duration"
+	ddTCPServiceBytesInTotalName    = "tcp.service.bytes.in.total"
+	ddTCPServiceBytesOutTotalName   = "tcp.service.bytes.out.total"
 )
 
 // RegisterDatadog registers the metrics pusher if this didn't happen yet and creates a datadog Registry instance.
@@ -41,19 +55,33 @@ func RegisterDatadog(ctx context.Context, config *types.Datadog) Registry {
 	}
 
 	registry := &standardRegistry{
-		enabled:                        true,
-		configReloadsCounter:           datadogClient.NewCounter(ddConfigReloadsName, 1.0),
-		configReloadsFailureCounter:    datadogClient.NewCounter(ddConfigReloadsName, 1.0).With(ddConfigReloadsFailureTagName, "true"),
-		lastConfigReloadSuccessGauge:   datadogClient.NewGauge(ddLastConfigReloadSuccessName),
-		lastConfigReloadFailureGauge:   datadogClient.NewGauge(ddLastConfigReloadFailureName),
-		entrypointReqsCounter:          datadogClient.NewCounter(ddEntrypointReqsName, 1.0),
-		entrypointReqDurationHistogram: datadogClient.NewHistogram(ddEntrypointReqDurationName, 1.0),
-		entrypointOpenConnsGauge:       datadogClient.NewGauge(ddEntrypointOpenConnsName),
-		backendReqsCounter:             datadogClient.NewCounter(ddMetricsBackendReqsName, 1.0),
-		backendReqDurationHistogram:    datadogClient.NewHistogram(ddMetricsBackendLatencyName, 1.0),
-		backendRetriesCounter:          datadogClient.NewCounter(ddRetriesTotalName, 1.0),
-		backendOpenConnsGauge:          datadogClient.NewGauge(ddOpenConnsName),
-		backendServerUpGauge:           datadogClient.NewGauge(ddServerUpName),
+		enabled:                                   true,
+		configReloadsCounter:                      datadogClient.NewCounter(ddConfigReloadsName, 1.0),
+		configReloadsFailureCounter:               datadogClient.NewCounter(ddConfigReloadsName, 1.0).With(ddConfigReloadsFailureTagName, "true"),
+		lastConfigReloadSuccessGauge:              datadogClient.NewGauge(ddLastConfigReloadSuccessName),
+		lastConfigReloadFailureGauge:              datadogClient.NewGauge(ddLastConfigReloadFailureName),
+		entrypointReqsCounter:                     datadogClient.NewCounter(ddEntrypointReqsName, 1.0),
+		entrypointReqDurationHistogram:            datadogClient.NewHistogram(ddEntrypointReqDurationName, 1.0),
+		entrypointOpenConnsGauge:                  datadogClient.NewGauge(ddEntrypointOpenConnsName),
+		backendReqsCounter:                        datadogClient.NewCounter(ddMetricsBackendReqsName, 1.0),
+		backendReqDurationHistogram:               datadogClient.NewHistogram(ddMetricsBackendLatencyName, 1.0),
+		backendRetriesCounter:                     datadogClient.NewCounter(ddRetriesTotalName, 1.0),
+		backendOpenConnsGauge:                     datadogClient.NewGauge(ddOpenConnsName),
+		backendServerUpGauge:                      datadogClient.NewGauge(ddServerUpName),
+		backendServerEjectedGauge:                 datadogClient.NewGauge(ddServerEjectedName),
+		backendServerHealthCheckFailuresCounter:   datadogClient.NewCounter(ddServerHealthCheckFailuresName, 1.0),
+		backendServerHealthCheckDurationHistogram: datadogClient.NewHistogram(ddServerHealthCheckDurationName, 1.0),
+		backendHedgesCounter:                      datadogClient.NewCounter(ddHedgesTotalName, 1.0),
+		backendHedgesWonCounter:                   datadogClient.NewCounter(ddHedgesWonTotalName, 1.0),
+		backendServerOpenConnsGauge:               datadogClient.NewGauge(ddServerOpenConnsName),
+		tcpRouterOpenConnsGauge:                   datadogClient.NewGauge(ddTCPRouterOpenConnsName),
+		tcpRouterConnDurationHistogram:            datadogClient.NewHistogram(ddTCPRouterConnDurationName, 1.0),
+		tcpRouterBytesInCounter:                   datadogClient.NewCounter(ddTCPRouterBytesInTotalName, 1.0),
+		tcpRouterBytesOutCounter:                  datadogClient.NewCounter(ddTCPRouterBytesOutTotalName, 1.0),
+		tcpServiceOpenConnsGauge:                  datadogClient.NewGauge(ddTCPServiceOpenConnsName),
+		tcpServiceConnDurationHistogram:           datadogClient.NewHistogram(ddTCPServiceConnDurationName, 1.0),
+		tcpServiceBytesInCounter:                  datadogClient.NewCounter(ddTCPServiceBytesInTotalName, 1.0),
+		tcpServiceBytesOutCounter:                 datadogClient.NewCounter(ddTCPServiceBytesOutTotalName, 1.0),
 	}
 
 	return registry
@@ -35,6 +35,20 @@ func TestDatadog(t *testing.T) {
 		"traefik.entrypoint.request.duration:10000.000000|h|#entrypoint:test\n",
 		"traefik.entrypoint.connections.open:1.000000|g|#entrypoint:test\n",
 		"traefik.backend.server.up:1.000000|g|#backend:test,url:http://127.0.0.1,one:two\n",
+		"traefik.backend.server.ejected:1.000000|g|#backend:test,url:http://127.0.0.1\n",
+		"traefik.backend.server.healthcheck.failures.total:1.000000|c|#backend:test,url:http://127.0.0.1\n",
+		"traefik.backend.server.healthcheck.duration:10000.000000|h|#backend:test,url:http://127.0.0.1\n",
+		"traefik.backend.hedges.total:1.000000|c|#backend:test\n",
+		"traefik.backend.hedges.won.total:1.000000|c|#backend:test\n",
+		"traefik.backend.server.open.connections:1.000000|g|#backend:test,url:127.0.0.1:80\n",
+		"traefik.tcp.router.connections.open:1.000000|g|#router:test\n",
+		"traefik.tcp.router.connection.duration:10000.000000|h|#router:test\n",
+		"traefik.tcp.router.bytes.in.total:1.000000|c|#router:test\n",
+		"traefik.tcp.router.bytes.out.total:1.000000|c|#router:test\n",
+		"traefik.tcp.service.connections.open:1.000000|g|#service:test\n",
+		"traefik.tcp.service.connection.duration:10000.000000|h|#service:test\n",
+		"traefik.tcp.service.bytes.in.total:1.000000|c|#service:test\n",
+		"traefik.tcp.service.bytes.out.total:1.000000|c|#service:test\n",
 	}
 
 	udp.ShouldReceiveAll(t, expected, func() {
@@ -49,5 +63,19 @@ func TestDatadog(t *testing.T) {
 		datadogRegistry.EntrypointReqDurationHistogram().With("entrypoint", "test").Observe(10000)
 		datadogRegistry.EntrypointOpenConnsGauge().With("entrypoint", "test").Set(1)
 		datadogRegistry.BackendServerUpGauge().With("backend", "test", "url", "http://127.0.0.1", "one", "two").Set(1)
+		datadogRegistry.BackendServerEjectedGauge().With("backend", "test", "url", "http://127.0.0.1").Set(1)
+		datadogRegistry.BackendServerHealthCheckFailuresCounter().With("backend", "test", "url", "http://127.0.0.1").Add(1)
+		datadogRegistry.BackendServerHealthCheckDurationHistogram().With("backend", "test", "url", "http://127.0.0.1").Observe(10000)
+		datadogRegistry.BackendHedgesCounter().With("backend", "test").Add(1)
+		datadogRegistry.BackendHedgesWonCounter().With("backend", "test").Add(1)
+		datadogRegistry.BackendServerOpenConnsGauge().With("backend", "test", "url", "127.0.0.1:80").Set(1)
+		datadogRegistry.TCPRouterOpenConnsGauge().With("router", "test").Set(1)
+		datadogRegistry.TCPRouterConnDurationHistogram().With("router", "test").Observe(10000)
+		datadogRegistry.TCPRouterBytesInCounter().With("router", "test").Add(1)
+		datadogRegistry.TCPRouterBytesOutCounter().With("router", "test").Add(1)
+		datadogRegistry.TCPServiceOpenConnsGauge().With("service", "test").Set(1)
+		datadogRegistry.TCPServiceConnDurationHistogram().With("service", "test").Observe(10000)
+		datadogRegistry.TCPServiceBytesInCounter().With("service", "test").Add(1)
+		datadogRegistry.TCPServiceBytesOutCounter().With("service", "test").Add(1)
 	})
 }
@@ -26,18 +26,32 @@ type influxDBWriter struct {
 var influxDBTicker *time.Ticker
 
 const (
-	influxDBMetricsBackendReqsName      = "traefik.backend.requests.total"
-	influxDBMetricsBackendLatencyName   = "traefik.backend.request.duration"
-	influxDBRetriesTotalName            = "traefik.backend.retries.total"
-	influxDBConfigReloadsName           = "traefik.config.reload.total"
-	influxDBConfigReloadsFailureName    = influxDBConfigReloadsName + ".failure"
-	influxDBLastConfigReloadSuccessName = "traefik.config.reload.lastSuccessTimestamp"
-	influxDBLastConfigReloadFailureName = "traefik.config.reload.lastFailureTimestamp"
-	influxDBEntrypointReqsName          = "traefik.entrypoint.requests.total"
-	influxDBEntrypointReqDurationName   = "traefik.entrypoint.request.duration"
-	influxDBEntrypointOpenConnsName     = "traefik.entrypoint.connections.open"
-	influxDBOpenConnsName               = "traefik.backend.connections.open"
-	influxDBServerUpName                = "traefik.backend.server.up"
+	influxDBMetricsBackendReqsName        = "traefik.backend.requests.total"
+	influxDBMetricsBackendLatencyName     = "traefik.backend.request.duration"
+	influxDBRetriesTotalName              = "traefik.backend.retries.total"
+	influxDBConfigReloadsName             = "traefik.config.reload.total"
+	influxDBConfigReloadsFailureName      = influxDBConfigReloadsName + ".failure"
+	influxDBLastConfigReloadSuccessName   = "traefik.config.reload.lastSuccessTimestamp"
+	influxDBLastConfigReloadFailureName   = "traefik.config.reload.lastFailureTimestamp"
+	influxDBEntrypointReqsName            = "traefik.entrypoint.requests.total"
+	influxDBEntrypointReqDurationName     = "traefik.entrypoint.request.duration"
+	influxDBEntrypointOpenConnsName       = "traefik.entrypoint.connections.open"
+	influxDBOpenConnsName                 = "traefik.backend.connections.open"
+	influxDBServerUpName                  = "traefik.backend.server.up"
+	influxDBServerEjectedName             = "traefik.backend.server.ejected"
+	influxDBServerHealthCheckFailuresName = "traefik.backend.server.healthcheck.failures.total"
+	influxDBServerHealthCheckDurationName = "traefik.backend.server.healthcheck.duration"
+	influxDBHedgesTotalName               = "traefik.backend.hedges.total"
+	influxDBHedgesWonTotalName            = "traefik.backend.hedges.won.total"
+	influxDBServerOpenConnsName           = "traefik.backend.server.open.connections"
+	influxDBTCPRouterOpenConnsName        = "traefik.tcp.router.connections.open"
+	influxDBTCPRouterConnDurationName     = "traefik.tcp.router.connection.duration"
+	influxDBTCPRouterBytesInTotalName     = "traefik.tcp.router.bytes.in.total"
+	influxDBTCPRouterBytesOutTotalName    = "traefik.tcp.router.bytes.out.total"
+	influxDBTCPServiceOpenConnsName       = "traefik.tcp.service.connections.open"
+	influxDBTCPServiceConnDurationName    = "traefik.tcp.service.connection.duration"
+	influxDBTCPServiceBytesInTotalName    = "traefik.tcp.service.bytes.in.total"
+	influxDBTCPServiceBytesOutTotalName   = "traefik.tcp.service.bytes.out.total"
 )
 
 const (
@@ -55,19 +69,33 @@ func RegisterInfluxDB(ctx context.Context, config *types.InfluxDB) Registry {
 	}
 
 	return &standardRegistry{
-		enabled:                        true,
-		configReloadsCounter:           influxDBClient.NewCounter(influxDBConfigReloadsName),
-		configReloadsFailureCounter:    influxDBClient.NewCounter(influxDBConfigReloadsFailureName),
-		lastConfigReloadSuccessGauge:   influxDBClient.NewGauge(influxDBLastConfigReloadSuccessName),
-		lastConfigReloadFailureGauge:   influxDBClient.NewGauge(influxDBLastConfigReloadFailureName),
-		entrypointReqsCounter:          influxDBClient.NewCounter(influxDBEntrypointReqsName),
-		entrypointReqDurationHistogram: influxDBClient.NewHistogram(influxDBEntrypointReqDurationName),
-		entrypointOpenConnsGauge:       influxDBClient.NewGauge(influxDBEntrypointOpenConnsName),
-		backendReqsCounter:             influxDBClient.NewCounter(influxDBMetricsBackendReqsName),
-		backendReqDurationHistogram:    influxDBClient.NewHistogram(influxDBMetricsBackendLatencyName),
-		backendRetriesCounter:          influxDBClient.NewCounter(influxDBRetriesTotalName),
-		backendOpenConnsGauge:          influxDBClient.NewGauge(influxDBOpenConnsName),
-		backendServerUpGauge:           influxDBClient.NewGauge(influxDBServerUpName),
+		enabled:                                   true,
+		configReloadsCounter:                      influxDBClient.NewCounter(influxDBConfigReloadsName),
+		configReloadsFailureCounter:               influxDBClient.NewCounter(influxDBConfigReloadsFailureName),
+		lastConfigReloadSuccessGauge:              influxDBClient.NewGauge(influxDBLastConfigReloadSuccessName),
+		lastConfigReloadFailureGauge:              influxDBClient.NewGauge(influxDBLastConfigReloadFailureName),
+		entrypointReqsCounter:                     influxDBClient.NewCounter(influxDBEntrypointReqsName),
+		entrypointReqDurationHistogram:            influxDBClient.NewHistogram(influxDBEntrypointReqDurationName),
+		entrypointOpenConnsGauge:                  influxDBClient.NewGauge(influxDBEntrypointOpenConnsName),
+		backendReqsCounter:                        influxDBClient.NewCounter(influxDBMetricsBackendReqsName),
+		backendReqDurationHistogram:               influxDBClient.NewHistogram(influxDBMetricsBackendLatencyName),
+		backendRetriesCounter:                     influxDBClient.NewCounter(influxDBRetriesTotalName),
+		backendOpenConnsGauge:                     influxDBClient.NewGauge(influxDBOpenConnsName),
+		backendServerUpGauge:                      influxDBClient.NewGauge(influxDBServerUpName),
+		backendServerEjectedGauge:                 influxDBClient.NewGauge(influxDBServerEjectedName),
+		backendServerHealthCheckFailuresCounter:   influxDBClient.NewCounter(influxDBServerHealthCheckFailuresName),
+		backendServerHealthCheckDurationHistogram: influxDBClient.NewHistogram(influxDBServerHealthCheckDurationName),
+		backendHedgesCounter:                      influxDBClient.NewCounter(influxDBHedgesTotalName),
+		backendHedgesWonCounter:                   influxDBClient.NewCounter(influxDBHedgesWonTotalName),
+		backendServerOpenConnsGauge:               influxDBClient.NewGauge(influxDBServerOpenConnsName),
+		tcpRouterOpenConnsGauge:                   influxDBClient.NewGauge(influxDBTCPRouterOpenConnsName),
+		tcpRouterConnDurationHistogram:            influxDBClient.NewHistogram(influxDBTCPRouterConnDurationName),
+		tcpRouterBytesInCounter:                   influxDBClient.NewCounter(influxDBTCPRouterBytesInTotalName),
+		tcpRouterBytesOutCounter:                  influxDBClient.NewCounter(influxDBTCPRouterBytesOutTotalName),
+		tcpServiceOpenConnsGauge:                  influxDBClient.NewGauge(influxDBTCPServiceOpenConnsName),
+		tcpServiceConnDurationHistogram:           influxDBClient.NewHistogram(influxDBTCPServiceConnDurationName),
+		tcpServiceBytesInCounter:                  influxDBClient.NewCounter(influxDBTCPServiceBytesInTotalName),
+		tcpServiceBytesOutCounter:                 influxDBClient.NewCounter(influxDBTCPServiceBytesOutTotalName),
 	}
 }
 
@@ -35,6 +35,12 @@ func TestInfluxDB(t *testing.T) {
 		`(traefik\.config\.reload\.total(?:[a-z=0-9A-Z,]+)? count=1) [\d]{19}`,
 		`(traefik\.config\.reload\.total\.failure(?:[a-z=0-9A-Z,]+)? count=1) [\d]{19}`,
 		`(traefik\.backend\.server\.up,backend=test(?:[a-z=0-9A-Z,]+)?,url=http://127.0.0.1 value=1) [\d]{19}`,
+		`(traefik\.backend\.server\.ejected,backend=test(?:[a-z=0-9A-Z,]+)?,url=http://127.0.0.1 value=1) [\d]{19}`,
+		`(traefik\.backend\.server\.healthcheck\.failures\.total,backend=test,url=http://127.0.0.1 count=1) [\d]{19}`,
+		`(traefik\.backend\.server\.healthcheck\.duration,backend=test,url=http://127.0.0.1 p50=10000,p90=10000,p95=10000,p99=10000) [\d]{19}`,
+		`(traefik\.backend\.hedges\.total,backend=test count=1) [\d]{19}`,
+		`(traefik\.backend\.hedges\.won\.total,backend=test count=1) [\d]{19}`,
+		`(traefik\.backend\.server\.open\.connections,backend=test,url=127.0.0.1:80 value=1) [\d]{19}`,
 	}
 
 	msgBackend := udp.ReceiveString(t, func() {
@@ -46,6 +52,12 @@ func TestInfluxDB(t *testing.T) {
 		influxDBRegistry.ConfigReloadsCounter().Add(1)
 		influxDBRegistry.ConfigReloadsFailureCounter().Add(1)
 		influxDBRegistry.BackendServerUpGauge().With("backend", "test", "url", "http://127.0.0.1").Set(1)
+		influxDBRegistry.BackendServerEjectedGauge().With("backend", "test", "url", "http://127.0.0.1").Set(1)
+		influxDBRegistry.BackendServerHealthCheckFailuresCounter().With("backend", "test", "url", "http://127.0.0.1").Add(1)
+		influxDBRegistry.BackendServerHealthCheckDurationHistogram().With("backend", "test", "url", "http://127.0.0.1").Observe(10000)
+		influxDBRegistry.BackendHedgesCounter().With("backend", "test").Add(1)
+		influxDBRegistry.BackendHedgesWonCounter().With("backend", "test").Add(1)
+		influxDBRegistry.BackendServerOpenConnsGauge().With("backend", "test", "url", "127.0.0.1:80").Set(1)
 	})
 
 	assertMessage(t, msgBackend, expectedBackend)
@@ -64,6 +76,30 @@ func TestInfluxDB(t *testing.T) {
 	})
 
 	assertMessage(t, msgEntrypoint, expectedEntrypoint)
+
+	expectedTCP := []string{
+		`(traefik\.tcp\.router\.connections\.open,router=test value=1) [\d]{19}`,
+		`(traefik\.tcp\.router\.connection\.duration,router=test p50=10000,p90=10000,p95=10000,p99=10000) [\d]{19}`,
+		`(traefik\.tcp\.router\.bytes\.in\.total,router=test count=1) [\d]{19}`,
+		`(traefik\.tcp\.router\.bytes\.out\.total,router=test count=1) [\d]{19}`,
+		`(traefik\.tcp\.service\.connections\.open,service=test value=1) [\d]{19}`,
+		`(traefik\.tcp\.service\.connection\.duration,service=test p50=10000,p90=10000,p95=10000,p99=10000) [\d]{19}`,
+		`(traefik\.tcp\.service\.bytes\.in\.total,service=test count=1) [\d]{19}`,
+		`(traefik\.tcp\.service\.bytes\.out\.total,service=test count=1) [\d]{19}`,
+	}
+
+	msgTCP := udp.ReceiveString(t, func() {
+		influxDBRegistry.TCPRouterOpenConnsGauge().With("router", "test").Set(1)
+		influxDBRegistry.TCPRouterConnDurationHistogram().With("router", "test").Observe(10000)
+		influxDBRegistry.TCPRouterBytesInCounter().With("router", "test").Add(1)
+		influxDBRegistry.TCPRouterBytesOutCounter().With("router", "test").Add(1)
+		influxDBRegistry.TCPServiceOpenConnsGauge().With("service", "test").Set(1)
+		influxDBRegistry.TCPServiceConnDurationHistogram().With("service", "test").Observe(10000)
+		influxDBRegistry.TCPServiceBytesInCounter().With("service", "test").Add(1)
+		influxDBRegistry.TCPServiceBytesOutCounter().With("service", "test").Add(1)
+	})
+
+	assertMessage(t, msgTCP, expectedTCP)
 }
 
 func TestInfluxDBHTTP(t *testing.T) {
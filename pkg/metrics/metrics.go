@@ -27,6 +27,24 @@ type Registry interface {
 	BackendOpenConnsGauge() metrics.Gauge
 	BackendRetriesCounter() metrics.Counter
 	BackendServerUpGauge() metrics.Gauge
+	BackendServerEjectedGauge() metrics.Gauge
+	BackendServerHealthCheckFailuresCounter() metrics.Counter
+	BackendServerHealthCheckDurationHistogram() metrics.Histogram
+	BackendHedgesCounter() metrics.Counter
+	BackendHedgesWonCounter() metrics.Counter
+	BackendServerOpenConnsGauge() metrics.Gauge
+
+	// TCP router metrics
+	TCPRouterOpenConnsGauge() metrics.Gauge
+	TCPRouterConnDurationHistogram() metrics.Histogram
+	TCPRouterBytesInCounter() metrics.Counter
+	TCPRouterBytesOutCounter() metrics.Counter
+
+	// TCP service metrics
+	TCPServiceOpenConnsGauge() metrics.Gauge
+	TCPServiceConnDurationHistogram() metrics.Histogram
+	TCPServiceBytesInCounter() metrics.Counter
+	TCPServiceBytesOutCounter() metrics.Counter
 }
 
 // NewVoidRegistry is a noop implementation of metrics.Registry.
@@ -51,6 +69,20 @@ func NewMultiRegistry(registries []Registry) Registry {
 	var backendOpenConnsGauge []metrics.Gauge
 	var backendRetriesCounter []metrics.Counter
 	var backendServerUpGauge []metrics.Gauge
+	var backendServerEjectedGauge []metrics.Gauge
+	var backendServerHealthCheckFailuresCounter []metrics.Counter
+	var backendServerHealthCheckDurationHistogram []metrics.Histogram
+	var backendHedgesCounter []metrics.Counter
+	var backendHedgesWonCounter []metrics.Counter
+	var backendServerOpenConnsGauge []metrics.Gauge
+	var tcpRouterOpenConnsGauge []metrics.Gauge
+	var tcpRouterConnDurationHistogram []metrics.Histogram
+	var tcpRouterBytesInCounter []metrics.Counter
+	var tcpRouterBytesOutCounter []metrics.Counter
+	var tcpServiceOpenConnsGauge []metrics.Gauge
+	var tcpServiceConnDurationHistogram []metrics.Histogram
+	var tcpServiceBytesInCounter []metrics.Counter
+	var tcpServiceBytesOutCounter []metrics.Counter
 
 	for _, r := range registries {
 		if r.ConfigReloadsCounter() != nil {
@@ -89,39 +121,109 @@ func NewMultiRegistry(registries []Registry) Registry {
 		if r.BackendServerUpGauge() != nil {
 			backendServerUpGauge = append(backendServerUpGauge, r.BackendServerUpGauge())
 		}
+		if r.BackendServerEjectedGauge() != nil {
+			backendServerEjectedGauge = append(backendServerEjectedGauge, r.BackendServerEjectedGauge())
+		}
+		if r.BackendServerHealthCheckFailuresCounter() != nil {
+			backendServerHealthCheckFailuresCounter = append(backendServerHealthCheckFailuresCounter, r.BackendServerHealthCheckFailuresCounter())
+		}
+		if r.BackendServerHealthCheckDurationHistogram() != nil {
+			backendServerHealthCheckDurationHistogram = append(backendServerHealthCheckDurationHistogram, r.BackendServerHealthCheckDurationHistogram())
+		}
+		if r.BackendHedgesCounter() != nil {
+			backendHedgesCounter = append(backendHedgesCounter, r.BackendHedgesCounter())
+		}
+		if r.BackendHedgesWonCounter() != nil {
+			backendHedgesWonCounter = append(backendHedgesWonCounter, r.BackendHedgesWonCounter())
+		}
+		if r.BackendServerOpenConnsGauge() != nil {
+			backendServerOpenConnsGauge = append(backendServerOpenConnsGauge, r.BackendServerOpenConnsGauge())
+		}
+		if r.TCPRouterOpenConnsGauge() != nil {
+			tcpRouterOpenConnsGauge = append(tcpRouterOpenConnsGauge, r.TCPRouterOpenConnsGauge())
+		}
+		if r.TCPRouterConnDurationHistogram() != nil {
+			tcpRouterConnDurationHistogram = append(tcpRouterConnDurationHistogram, r.TCPRouterConnDurationHistogram())
+		}
+		if r.TCPRouterBytesInCounter() != nil {
+			tcpRouterBytesInCounter = append(tcpRouterBytesInCounter, r.TCPRouterBytesInCounter())
+		}
+		if r.TCPRouterBytesOutCounter() != nil {
+			tcpRouterBytesOutCounter = append(tcpRouterBytesOutCounter, r.TCPRouterBytesOutCounter())
+		}
+		if r.TCPServiceOpenConnsGauge() != nil {
+			tcpServiceOpenConnsGauge = append(tcpServiceOpenConnsGauge, r.TCPServiceOpenConnsGauge())
+		}
+		if r.TCPServiceConnDurationHistogram() != nil {
+			tcpServiceConnDurationHistogram = append(tcpServiceConnDurationHistogram, r.TCPServiceConnDurationHistogram())
+		}
+		if r.TCPServiceBytesInCounter() != nil {
+			tcpServiceBytesInCounter = append(tcpServiceBytesInCounter, r.TCPServiceBytesInCounter())
+		}
+		if r.TCPServiceBytesOutCounter() != nil {
+			tcpServiceBytesOutCounter = append(tcpServiceBytesOutCounter, r.TCPServiceBytesOutCounter())
+		}
 	}
 
 	return &standardRegistry{
-		enabled:                        len(registries) > 0,
-		configReloadsCounter:           multi.NewCounter(configReloadsCounter...),
-		configReloadsFailureCounter:    multi.NewCounter(configReloadsFailureCounter...),
-		lastConfigReloadSuccessGauge:   multi.NewGauge(lastConfigReloadSuccessGauge...),
-		lastConfigReloadFailureGauge:   multi.NewGauge(lastConfigReloadFailureGauge...),
-		entrypointReqsCounter:          multi.NewCounter(entrypointReqsCounter...),
-		entrypointReqDurationHistogram: multi.NewHistogram(entrypointReqDurationHistogram...),
-		entrypointOpenConnsGauge:       multi.NewGauge(entrypointOpenConnsGauge...),
-		backendReqsCounter:             multi.NewCounter(backendReqsCounter...),
-		backendReqDurationHistogram:    multi.NewHistogram(backendReqDurationHistogram...),
-		backendOpenConnsGauge:          multi.NewGauge(backendOpenConnsGauge...),
-		backendRetriesCounter:          multi.NewCounter(backendRetriesCounter...),
-		backendServerUpGauge:           multi.NewGauge(backendServerUpGauge...),
+		enabled:                                   len(registries) > 0,
+		configReloadsCounter:                      multi.NewCounter(configReloadsCounter...),
+		configReloadsFailureCounter:               multi.NewCounter(configReloadsFailureCounter...),
+		lastConfigReloadSuccessGauge:              multi.NewGauge(lastConfigReloadSuccessGauge...),
+		lastConfigReloadFailureGauge:              multi.NewGauge(lastConfigReloadFailureGauge...),
+		entrypointReqsCounter:                     multi.NewCounter(entrypointReqsCounter...),
+		entrypointReqDurationHistogram:            multi.NewHistogram(entrypointReqDurationHistogram...),
+		entrypointOpenConnsGauge:                  multi.NewGauge(entrypointOpenConnsGauge...),
+		backendReqsCounter:                        multi.NewCounter(backendReqsCounter...),
+		backendReqDurationHistogram:               multi.NewHistogram(backendReqDurationHistogram...),
+		backendOpenConnsGauge:                     multi.NewGauge(backendOpenConnsGauge...),
+		backendRetriesCounter:                     multi.NewCounter(backendRetriesCounter...),
+		backendServerUpGauge:                      multi.NewGauge(backendServerUpGauge...),
+		backendServerEjectedGauge:                 multi.NewGauge(backendServerEjectedGauge...),
+		backendServerHealthCheckFailuresCounter:   multi.NewCounter(backendServerHealthCheckFailuresCounter...),
+		backendServerHealthCheckDurationHistogram: multi.NewHistogram(backendServerHealthCheckDurationHistogram...),
+		backendHedgesCounter:                      multi.NewCounter(backendHedgesCounter...),
+		backendHedgesWonCounter:                   multi.NewCounter(backendHedgesWonCounter...),
+		backendServerOpenConnsGauge:               multi.NewGauge(backendServerOpenConnsGauge...),
+		tcpRouterOpenConnsGauge:                   multi.NewGauge(tcpRouterOpenConnsGauge...),
+		tcpRouterConnDurationHistogram:            multi.NewHistogram(tcpRouterConnDurationHistogram...),
+		tcpRouterBytesInCounter:                   multi.NewCounter(tcpRouterBytesInCounter...),
+		tcpRouterBytesOutCounter:                  multi.NewCounter(tcpRouterBytesOutCounter...),
+		tcpServiceOpenConnsGauge:                  multi.NewGauge(tcpServiceOpenConnsGauge...),
+		tcpServiceConnDurationHistogram:           multi.NewHistogram(tcpServiceConnDurationHistogram...),
+		tcpServiceBytesInCounter:                  multi.NewCounter(tcpServiceBytesInCounter...),
+		tcpServiceBytesOutCounter:                 multi.NewCounter(tcpServiceBytesOutCounter...),
 	}
 }
 
 type standardRegistry struct {
-	enabled                        bool
-	configReloadsCounter           metrics.Counter
-	configReloadsFailureCounter    metrics.Counter
-	lastConfigReloadSuccessGauge   metrics.Gauge
-	lastConfigReloadFailureGauge   metrics.Gauge
-	entrypointReqsCounter          metrics.Counter
-	entrypointReqDurationHistogram metrics.Histogram
-	entrypointOpenConnsGauge       metrics.Gauge
-	backendReqsCounter             metrics.Counter
-	backendReqDurationHistogram    metrics.Histogram
-	backendOpenConnsGauge          metrics.Gauge
-	backendRetriesCounter          metrics.Counter
-	backendServerUpGauge           metrics.Gauge
+	enabled                                   bool
+	configReloadsCounter                      metrics.Counter
+	configReloadsFailureCounter               metrics.Counter
+	lastConfigReloadSuccessGauge              metrics.Gauge
+	lastConfigReloadFailureGauge              metrics.Gauge
+	entrypointReqsCounter                     metrics.Counter
+	entrypointReqDurationHistogram            metrics.Histogram
+	entrypointOpenConnsGauge                  metrics.Gauge
+	backendReqsCounter                        metrics.Counter
+	backendReqDurationHistogram               metrics.Histogram
+	backendOpenConnsGauge                     metrics.Gauge
+	backendRetriesCounter                     metrics.Counter
+	backendServerUpGauge                      metrics.Gauge
+	backendServerEjectedGauge                 metrics.Gauge
+	backendServerHealthCheckFailuresCounter   metrics.Counter
+	backendServerHealthCheckDurationHistogram metrics.Histogram
+	backendHedgesCounter                      metrics.Counter
+	backendHedgesWonCounter                   metrics.Counter
+	backendServerOpenConnsGauge               metrics.Gauge
+	tcpRouterOpenConnsGauge                   metrics.Gauge
+	tcpRouterConnDurationHistogram            metrics.Histogram
+	tcpRouterBytesInCounter                   metrics.Counter
+	tcpRouterBytesOutCounter                  metrics.Counter
+	tcpServiceOpenConnsGauge                  metrics.Gauge
+	tcpServiceConnDurationHistogram           metrics.Histogram
+	tcpServiceBytesInCounter                  metrics.Counter
+	tcpServiceBytesOutCounter                 metrics.Counter
 }
 
 func (r *standardRegistry) IsEnabled() bool {
@@ -175,3 +277,59 @@ func (r *standardRegistry) BackendRetriesCounter() metrics.Counter {
 func (r *standardRegistry) BackendServerUpGauge() metrics.Gauge {
 	return r.backendServerUpGauge
 }
+
+func (r *standardRegistry) BackendServerEjectedGauge() metrics.Gauge {
+	return r.backendServerEjectedGauge
+}
+
+func (r *standardRegistry) BackendServerHealthCheckFailuresCounter() metrics.Counter {
+	return r.backendServerHealthCheckFailuresCounter
+}
+
+func (r *standardRegistry) BackendServerHealthCheckDurationHistogram() metrics.Histogram {
+	return r.backendServerHealthCheckDurationHistogram
+}
+
+func (r *standardRegistry) BackendHedgesCounter() metrics.Counter {
+	return r.backendHedgesCounter
+}
+
+func (r *standardRegistry) BackendHedgesWonCounter() metrics.Counter {
+	return r.backendHedgesWonCounter
+}
+
+func (r *standardRegistry) BackendServerOpenConnsGauge() metrics.Gauge {
+	return r.backendServerOpenConnsGauge
+}
+
+func (r *standardRegistry) TCPRouterOpenConnsGauge() metrics.Gauge {
+	return r.tcpRouterOpenConnsGauge
+}
+
+func (r *standardRegistry) TCPRouterConnDurationHistogram() metrics.Histogram {
+	return r.tcpRouterConnDurationHistogram
+}
+
+func (r *standardRegistry) TCPRouterBytesInCounter() metrics.Counter {
+	return r.tcpRouterBytesInCounter
+}
+
+func (r *standardRegistry) TCPRouterBytesOutCounter() metrics.Counter {
+	return r.tcpRouterBytesOutCounter
+}
+
+func (r *standardRegistry) TCPServiceOpenConnsGauge() metrics.Gauge {
+	return r.tcpServiceOpenConnsGauge
+}
+
+func (r *standardRegistry) TCPServiceConnDurationHistogram() metrics.Histogram {
+	return r.tcpServiceConnDurationHistogram
+}
+
+func (r *standardRegistry) TCPServiceBytesInCounter() metrics.Counter {
+	return r.tcpServiceBytesInCounter
+}
+
+func (r *standardRegistry) TCPServiceBytesOutCounter() metrics.Counter {
+	return r.tcpServiceBytesOutCounter
+}
@@ -37,12 +37,32 @@ const (
 	// backend level.
 
 	// MetricBackendPrefix prefix of all backend metric names
-	MetricBackendPrefix     = MetricNamePrefix + "backend_"
-	backendReqsTotalName    = MetricBackendPrefix + "requests_total"
-	backendReqDurationName  = MetricBackendPrefix + "request_duration_seconds"
-	backendOpenConnsName    = MetricBackendPrefix + "open_connections"
-	backendRetriesTotalName = MetricBackendPrefix + "retries_total"
-	backendServerUpName     = MetricBackendPrefix + "server_up"
+	MetricBackendPrefix                  = MetricNamePrefix + "backend_"
+	backendReqsTotalName                 = MetricBackendPrefix + "requests_total"
+	backendReqDurationName               = MetricBackendPrefix + "request_duration_seconds"
+	backendOpenConnsName                 = MetricBackendPrefix + "open_connections"
+	backendRetriesTotalName              = MetricBackendPrefix + "retries_total"
+	backendServerUpName                  = MetricBackendPrefix + "server_up"
+	backendServerEjectedName             = MetricBackendPrefix + "server_ejected"
+	backendServerHealthCheckFailuresName = MetricBackendPrefix + "server_healthcheck_failures_total"
+	backendServerHealthCheckDurationName = MetricBackendPrefix + "server_healthcheck_duration_seconds"
+	backendHedgesTotalName               = MetricBackendPrefix + "hedges_total"
+	backendHedgesWonTotalName            = MetricBackendPrefix + "hedges_won_total"
+	backendServerOpenConnsName           = MetricBackendPrefix + "server_open_connections"
+
+	// TCP router level.
+	metricTCPRouterPrefix      = MetricNamePrefix + "tcp_router_"
+	tcpRouterOpenConnsName     = metricTCPRouterPrefix + "open_connections"
+	tcpRouterConnDurationName  = metricTCPRouterPrefix + "connection_duration_seconds"
+	tcpRouterBytesInTotalName  = metricTCPRouterPrefix + "bytes_in_total"
+	tcpRouterBytesOutTotalName = metricTCPRouterPrefix + "bytes_out_total"
+
+	// TCP service level.
+	metricTCPServicePrefix      = MetricNamePrefix + "tcp_service_"
+	tcpServiceOpenConnsName     = metricTCPServicePrefix + "open_connections"
+	tcpServiceConnDurationName  = metricTCPServicePrefix + "connection_duration_seconds"
+	tcpServiceBytesInTotalName  = metricTCPServicePrefix + "bytes_in_total"
+	tcpServiceBytesOutTotalName = metricTCPServicePrefix + "bytes_out_total"
 )
 
 // promState holds all metric state internally and acts as the only Collector we register for Prometheus.
@@ -141,6 +161,67 @@ func initStandardRegistry(config *types.Prometheus) Registry {
 		Name: backendServerUpName,
 		Help: "Backend server is up, described by gauge value of 0 or 1.",
 	}, []string{"backend", "url"})
+	backendServerEjected := newGaugeFrom(promState.collectors, stdprometheus.GaugeOpts{
+		Name: backendServerEjectedName,
+		Help: "Backend server is ejected by the passive health check, described by gauge value of 0 or 1.",
+	}, []string{"backend", "url"})
+	backendServerHealthCheckFailures := newCounterFrom(promState.collectors, stdprometheus.CounterOpts{
+		Name: backendServerHealthCheckFailuresName,
+		Help: "How many health checks of a backend server failed.",
+	}, []string{"backend", "url"})
+	backendServerHealthCheckDurations := newHistogramFrom(promState.collectors, stdprometheus.HistogramOpts{
+		Name:    backendServerHealthCheckDurationName,
+		Help:    "How long it took to health check a backend server.",
+		Buckets: buckets,
+	}, []string{"backend", "url"})
+	backendHedges := newCounterFrom(promState.collectors, stdprometheus.CounterOpts{
+		Name: backendHedgesTotalName,
+		Help: "How many hedged requests were sent to a backend.",
+	}, []string{"backend"})
+	backendHedgesWon := newCounterFrom(promState.collectors, stdprometheus.CounterOpts{
+		Name: backendHedgesWonTotalName,
+		Help: "How many hedged requests of a backend responded before the request they duplicate.",
+	}, []string{"backend"})
+	backendServerOpenConns := newGaugeFrom(promState.collectors, stdprometheus.GaugeOpts{
+		Name: backendServerOpenConnsName,
+		Help: "How many open connections exist on a backend server.",
+	}, []string{"backend", "url"})
+
+	tcpRouterOpenConns := newGaugeFrom(promState.collectors, stdprometheus.GaugeOpts{
+		Name: tcpRouterOpenConnsName,
+		Help: "How many open connections exist on a TCP router.",
+	}, []string{"router"})
+	tcpRouterConnDurations := newHistogramFrom(promState.collectors, stdprometheus.HistogramOpts{
+		Name:    tcpRouterConnDurationName,
+		Help:    "How long the connections handled by a TCP router lasted.",
+		Buckets: buckets,
+	}, []string{"router"})
+	tcpRouterBytesIn := newCounterFrom(promState.collectors, stdprometheus.CounterOpts{
+		Name: tcpRouterBytesInTotalName,
+		Help: "How many bytes were received from the clients of a TCP router.",
+	}, []string{"router"})
+	tcpRouterBytesOut := newCounterFrom(promState.collectors, stdprometheus.CounterOpts{
+		Name: tcpRouterBytesOutTotalName,
+		Help: "How many bytes were sent to the clients of a TCP router.",
+	}, []string{"router"})
+
+	tcpServiceOpenConns := newGaugeFrom(promState.collectors, stdprometheus.GaugeOpts{
+		Name: tcpServiceOpenConnsName,
+		Help: "How many open connections exist on a TCP service.",
+	}, []string{"service"})
+	tcpServiceConnDurations := newHistogramFrom(promState.collectors, stdprometheus.HistogramOpts{
+		Name:    tcpServiceConnDurationName,
+		Help:    "How long the connections handled by a TCP service lasted.",
+		Buckets: buckets,
+	}, []string{"service"})
+	tcpServiceBytesIn := newCounterFrom(promState.collectors, stdprometheus.CounterOpts{
+		Name: tcpServiceBytesInTotalName,
+		Help: "How many bytes were received from the clients of a TCP service.",
+	}, []string{"service"})
+	tcpServiceBytesOut := newCounterFrom(promState.collectors, stdprometheus.CounterOpts{
+		Name: tcpServiceBytesOutTotalName,
+		Help: "How many bytes were sent to the clients of a TCP service.",
+	}, []string{"service"})
 
 	promState.describers = []func(chan<- *stdprometheus.Desc){
 		configReloads.cv.Describe,
@@ -155,22 +236,50 @@ func initStandardRegistry(config *types.Prometheus) Registry {
 		backendOpenConns.gv.Describe,
 		backendRetries.cv.Describe,
 		backendServerUp.gv.Describe,
+		backendServerEjected.gv.Describe,
+		backendServerHealthCheckFailures.cv.Describe,
+		backendServerHealthCheckDurations.hv.Describe,
+		backendHedges.cv.Describe,
+		backendHedgesWon.cv.Describe,
+		backendServerOpenConns.gv.Describe,
+		tcpRouterOpenConns.gv.Describe,
+		tcpRouterConnDurations.hv.Describe,
+		tcpRouterBytesIn.cv.Describe,
+		tcpRouterBytesOut.cv.Describe,
+		tcpServiceOpenConns.gv.Describe,
+		tcpServiceConnDurations.hv.Describe,
+		tcpServiceBytesIn.cv.Describe,
+		tcpServiceBytesOut.cv.Describe,
 	}
 
 	return &standardRegistry{
-		enabled:                        true,
-		configReloadsCounter:           configReloads,
-		configReloadsFailureCounter:    configReloadsFailures,
-		lastConfigReloadSuccessGauge:   lastConfigReloadSuccess,
-		lastConfigReloadFailureGauge:   lastConfigReloadFailure,
-		entrypointReqsCounter:          entrypointReqs,
-		entrypointReqDurationHistogram: entrypointReqDurations,
-		entrypointOpenConnsGauge:       entrypointOpenConns,
-		backendReqsCounter:             backendReqs,
-		backendReqDurationHistogram:    backendReqDurations,
-		backendOpenConnsGauge:          backendOpenConns,
-		backendRetriesCounter:          backendRetries,
-		backendServerUpGauge:           backendServerUp,
+		enabled:                                   true,
+		configReloadsCounter:                      configReloads,
+		configReloadsFailureCounter:               configReloadsFailures,
+		lastConfigReloadSuccessGauge:              lastConfigReloadSuccess,
+		lastConfigReloadFailureGauge:              lastConfigReloadFailure,
+		entrypointReqsCounter:                     entrypointReqs,
+		entrypointReqDurationHistogram:            entrypointReqDurations,
+		entrypointOpenConnsGauge:                  entrypointOpenConns,
+		backendReqsCounter:                        backendReqs,
+		backendReqDurationHistogram:               backendReqDurations,
+		backendOpenConnsGauge:                     backendOpenConns,
+		backendRetriesCounter:                     backendRetries,
+		backendServerUpGauge:                      backendServerUp,
+		backendServerEjectedGauge:                 backendServerEjected,
+		backendServerHealthCheckFailuresCounter:   backendServerHealthCheckFailures,
+		backendServerHealthCheckDurationHistogram: backendServerHealthCheckDurations,
+		backendHedgesCounter:                      backendHedges,
+		backendHedgesWonCounter:                   backendHedgesWon,
+		backendServerOpenConnsGauge:               backendServerOpenConns,
+		tcpRouterOpenConnsGauge:                   tcpRouterOpenConns,
+		tcpRouterConnDurationHistogram:            tcpRouterConnDurations,
+		tcpRouterBytesInCounter:                   tcpRouterBytesIn,
+		tcpRouterBytesOutCounter:                  tcpRouterBytesOut,
+		tcpServiceOpenConnsGauge:                  tcpServiceOpenConns,
+		tcpServiceConnDurationHistogram:           tcpServiceConnDurations,
+		tcpServiceBytesInCounter:                  tcpServiceBytesIn,
+		tcpServiceBytesOutCounter:                 tcpServiceBytesOut,
 	}
 }
 
@@ -147,6 +147,62 @@ func TestPrometheus(t *testing.T) {
 		BackendServerUpGauge().
 		With("backend", "backend1", "url", "http://127.0.0.10:80").
 		Set(1)
+	prometheusRegistry.
+		BackendServerEjectedGauge().
+		With("backend", "backend1", "url", "http://127.0.0.10:80").
+		Set(1)
+	prometheusRegistry.
+		BackendServerHealthCheckFailuresCounter().
+		With("backend", "backend1", "url", "http://127.0.0.10:80").
+		Add(1)
+	prometheusRegistry.
+		BackendServerHealthCheckDurationHistogram().
+		With("backend", "backend1", "url", "http://127.0.0.10:80").
+		Observe(1)
+	prometheusRegistry.
+		BackendHedgesCounter().
+		With("backend", "backend1").
+		Add(1)
+	prometheusRegistry.
+		BackendHedgesWonCounter().
+		With("backend", "backend1").
+		Add(1)
+	prometheusRegistry.
+		BackendServerOpenConnsGauge().
+		With("backend", "backend1", "url", "127.0.0.10:80").
+		Set(1)
+	prometheusRegistry.
+		TCPRouterOpenConnsGauge().
+		With("router", "router1").
+		Set(1)
+	prometheusRegistry.
+		TCPRouterConnDurationHistogram().
+		With("router", "router1").
+		Observe(1)
+	prometheusRegistry.
+		TCPRouterBytesInCounter().
+		With("router", "router1").
+		Add(1)
+	prometheusRegistry.
+		TCPRouterBytesOutCounter().
+		With("router", "router1").
+		Add(1)
+	prometheusRegistry.
+		TCPServiceOpenConnsGauge().
+		With("service", "service1").
+		Set(1)
+	prometheusRegistry.
+		TCPServiceConnDurationHistogram().
+		With("service", "service1").
+		Observe(1)
+	prometheusRegistry.
+		TCPServiceBytesInCounter().
+		With("service", "service1").
+		Add(1)
+	prometheusRegistry.
+		TCPServiceBytesOutCounter().
+		With("service", "service1").
+		Add(1)
 
 	delayForTrackingCompletion()
 
@@ -246,6 +302,108 @@ func TestPrometheus(t *testing.T) {
 			},
 			assert: buildGaugeAssert(t, backendServerUpName, 1),
 		},
+		{
+			name: backendServerEjectedName,
+			labels: map[string]string{
+				"backend": "backend1",
+				"url":     "http://127.0.0.10:80",
+			},
+			assert: buildGaugeAssert(t, backendServerEjectedName, 1),
+		},
+		{
+			name: backendServerHealthCheckFailuresName,
+			labels: map[string]string{
+				"backend": "backend1",
+				"url":     "http://127.0.0.10:80",
+			},
+			assert: buildCounterAssert(t, backendServerHealthCheckFailuresName, 1),
+		},
+		{
+			name: backendServerHealthCheckDurationName,
+			labels: map[string]string{
+				"backend": "backend1",
+				"url":     "http://127.0.0.10:80",
+			},
+			assert: buildHistogramAssert(t, backendServerHealthCheckDurationName, 1),
+		},
+		{
+			name: backendHedgesTotalName,
+			labels: map[string]string{
+				"backend": "backend1",
+			},
+			assert: buildCounterAssert(t, backendHedgesTotalName, 1),
+		},
+		{
+			name: backendHedgesWonTotalName,
+			labels: map[string]string{
+				"backend": "backend1",
+			},
+			assert: buildCounterAssert(t, backendHedgesWonTotalName, 1),
+		},
+		{
+			name: backendServerOpenConnsName,
+			labels: map[string]string{
+				"backend": "backend1",
+				"url":     "127.0.0.10:80",
+			},
+			assert: buildGaugeAssert(t, backendServerOpenConnsName, 1),
+		},
+		{
+			name: tcpRouterOpenConnsName,
+			labels: map[string]string{
+				"router": "router1",
+			},
+			assert: buildGaugeAssert(t, tcpRouterOpenConnsName, 1),
+		},
+		{
+			name: tcpRouterConnDurationName,
+			labels: map[string]string{
+				"router": "router1",
+			},
+			assert: buildHistogramAssert(t, tcpRouterConnDurationName, 1),
+		},
+		{
+			name: tcpRouterBytesInTotalName,
+			labels: map[string]string{
+				"router": "router1",
+			},
+			assert: buildCounterAssert(t, tcpRouterBytesInTotalName, 1),
+		},
+		{
+			name: tcpRouterBytesOutTotalName,
+			labels: map[string]string{
+				"router": "router1",
+			},
+			assert: buildCounterAssert(t, tcpRouterBytesOutTotalName, 1),
+		},
+		{
+			name: tcpServiceOpenConnsName,
+			labels: map[string]string{
+				"service": "service1",
+			},
+			assert: buildGaugeAssert(t, tcpServiceOpenConnsName, 1),
+		},
+		{
+			name: tcpServiceConnDurationName,
+			labels: map[string]string{
+				"service": "service1",
+			},
+			assert: buildHistogramAssert(t, tcpServiceConnDurationName, 1),
+		},
+		{
+			name: tcpServiceBytesInTotalName,
+			labels: map[string]string{
+				"service": "service1",
+			},
+			assert: buildCounterAssert(t, tcpServiceBytesInTotalName, 1),
+		},
+		{
+			name: tcpServiceBytesOutTotalName,
+			labels: map[string]string{
+				"service": "service1",
+			},
+			assert: buildCounterAssert(t, tcpServiceBytesOutTotalName, 1),
+		},
 	}
 
 	for _, test := range tests {
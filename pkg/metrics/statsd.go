@@ -19,18 +19,32 @@ var statsdClient = statsd.New("traefik.", kitlog.LoggerFunc(func(keyvals ...inte
 var statsdTicker *time.Ticker
 
 const (
-	statsdMetricsBackendReqsName      = "backend.request.total"
-	statsdMetricsBackendLatencyName   = "backend.request.duration"
-	statsdRetriesTotalName            = "backend.retries.total"
-	statsdConfigReloadsName           = "config.reload.total"
-	statsdConfigReloadsFailureName    = statsdConfigReloadsName + ".failure"
-	statsdLastConfigReloadSuccessName = "config.reload.lastSuccessTimestamp"
-	statsdLastConfigReloadFailureName = "config.reload.lastFailureTimestamp"
-	statsdEntrypointReqsName          = "entrypoint.request.total"
-	statsdEntrypointReqDurationName   = "entrypoint.request.duration"
-	statsdEntrypointOpenConnsName     = "entrypoint.connections.open"
-	statsdOpenConnsName               = "backend.connections.open"
-	statsdServerUpName                = "backend.server.up"
+	statsdMetricsBackendReqsName        = "backend.request.total"
+	statsdMetricsBackendLatencyName     = "backend.request.duration"
+	statsdRetriesTotalName              = "backend.retries.total"
+	statsdConfigReloadsName             = "config.reload.total"
+	statsdConfigReloadsFailureName      = statsdConfigReloadsName + ".failure"
+	statsdLastConfigReloadSuccessName   = "config.reload.lastSuccessTimestamp"
+	statsdLastConfigReloadFailureName   = "config.reload.lastFailureTimestamp"
+	statsdEntrypointReqsName            = "entrypoint.request.total"
+	statsdEntrypointReqDurationName     = "entrypoint.request.duration"
+	statsdEntrypointOpenConnsName       = "entrypoint.connections.open"
+	statsdOpenConnsName                 = "backend.connections.open"
+	statsdServerUpName                  = "backend.server.up"
+	statsdServerEjectedName             = "backend.server.ejected"
+	statsdServerHealthCheckFailuresName = "backend.server.healthcheck.failures.total"
+	statsdServerHealthCheckDurationName = "backend.server.healthcheck.duration"
+	statsdHedgesTotalName               = "backend.hedges.total"
+	statsdHedgesWonTotalName            = "backend.hedges.won.total"
+	statsdServerOpenConnsName           = "backend.server.open.connections"
+	statsdTCPRouterOpenConnsName        = "tcp.router.connections.open"
+	statsdTCPRouterConnDurationName     = "tcp.router.connection.duration"
+	statsdTCPRouterBytesInTotalName     = "tcp.router.bytes.in.total"
+	statsdTCPRouterBytesOutTotalName    = "tcp.router.bytes.out.total"
+	statsdTCPServiceOpenConnsName       = "tcp.service.connections.open"
+	statsdTCPServiceConnDurationName    = "tcp.service.connection.duration"
+	statsdTCPServiceBytesInTotalName    = "tcp.service.bytes.in.total"
+	statsdTCPServiceBytesOutTotalName   = "tcp.service.bytes.out.total"
 )
 
 // RegisterStatsd registers the metrics pusher if this didn't happen yet and creates a statsd Registry instance.
@@ -40,19 +54,33 @@ func RegisterStatsd(ctx context.Context, config *types.Statsd) Registry {
 	}
 
 	return &standardRegistry{
-		enabled:                        true,
-		configReloadsCounter:           statsdClient.NewCounter(statsdConfigReloadsName, 1.0),
-		configReloadsFailureCounter:    statsdClient.NewCounter(statsdConfigReloadsFailureName, 1.0),
-		lastConfigReloadSuccessGauge:   statsdClient.NewGauge(statsdLastConfigReloadSuccessName),
-		lastConfigReloadFailureGauge:   statsdClient.NewGauge(statsdLastConfigReloadFailureName),
-		entrypointReqsCounter:          statsdClient.NewCounter(statsdEntrypointReqsName, 1.0),
-		entrypointReqDurationHistogram: statsdClient.NewTiming(statsdEntrypointReqDurationName, 1.0),
-		entrypointOpenConnsGauge:       statsdClient.NewGauge(statsdEntrypointOpenConnsName),
-		backendReqsCounter:             statsdClient.NewCounter(statsdMetricsBackendReqsName, 1.0),
-		backendReqDurationHistogram:    statsdClient.NewTiming(statsdMetricsBackendLatencyName, 1.0),
-		backendRetriesCounter:          statsdClient.NewCounter(statsdRetriesTotalName, 1.0),
-		backendOpenConnsGauge:          statsdClient.NewGauge(statsdOpenConnsName),
-		backendServerUpGauge:           statsdClient.NewGauge(statsdServerUpName),
+		enabled:                                   true,
+		configReloadsCounter:                      statsdClient.NewCounter(statsdConfigReloadsName, 1.0),
+		configReloadsFailureCounter:               statsdClient.NewCounter(statsdConfigReloadsFailureName, 1.0),
+		lastConfigReloadSuccessGauge:              statsdClient.NewGauge(statsdLastConfigReloadSuccessName),
+		lastConfigReloadFailureGauge:              statsdClient.NewGauge(statsdLastConfigReloadFailureName),
+		entrypointReqsCounter:                     statsdClient.NewCounter(statsdEntrypointReqsName, 1.0),
+		entrypointReqDurationHistogram:            statsdClient.NewTiming(statsdEntrypointReqDurationName, 1.0),
+		entrypointOpenConnsGauge:                  statsdClient.NewGauge(statsdEntrypointOpenConnsName),
+		backendReqsCounter:                        statsdClient.NewCounter(statsdMetricsBackendReqsName, 1.0),
+		backendReqDurationHistogram:               statsdClient.NewTiming(statsdMetricsBackendLatencyName, 1.0),
+		backendRetriesCounter:                     statsdClient.NewCounter(statsdRetriesTotalName, 1.0),
+		backendOpenConnsGauge:                     statsdClient.NewGauge(statsdOpenConnsName),
+		backendServerUpGauge:                      statsdClient.NewGauge(statsdServerUpName),
+		backendServerEjectedGauge:                 statsdClient.NewGauge(statsdServerEjectedName),
+		backendServerHealthCheckFailuresCounter:   statsdClient.NewCounter(statsdServerHealthCheckFailuresName, 1.0),
+		backendServerHealthCheckDurationHistogram: statsdClient.NewTiming(statsdServerHealthCheckDurationName, 1.0),
+		backendHedgesCounter:                      statsdClient.NewCounter(statsdHedgesTotalName, 1.0),
+		backendHedgesWonCounter:                   statsdClient.NewCounter(statsdHedgesWonTotalName, 1.0),
+		backendServerOpenConnsGauge:               statsdClient.NewGauge(statsdServerOpenConnsName),
+		tcpRouterOpenConnsGauge:                   statsdClient.NewGauge(statsdTCPRouterOpenConnsName),
+		tcpRouterConnDurationHistogram:            statsdClient.NewTiming(statsdTCPRouterConnDurationName, 1.0),
+		tcpRouterBytesInCounter:                   statsdClient.NewCounter(statsdTCPRouterBytesInTotalName, 1.0),
+		tcpRouterBytesOutCounter:                  statsdClient.NewCounter(statsdTCPRouterBytesOutTotalName, 1.0),
+		tcpServiceOpenConnsGauge:                  statsdClient.NewGauge(statsdTCPServiceOpenConnsName),
+		tcpServiceConnDurationHistogram:           statsdClient.NewTiming(statsdTCPServiceConnDurationName, 1.0),
+		tcpServiceBytesInCounter:                  statsdClient.NewCounter(statsdTCPServiceBytesInTotalName, 1.0),
+		tcpServiceBytesOutCounter:                 statsdClient.NewCounter(statsdTCPServiceBytesOutTotalName, 1.0),
 	}
 }
 
@@ -33,6 +33,20 @@ func TestStatsD(t *testing.T) {
 		"traefik.entrypoint.request.duration:10000.000000|ms",
 		"traefik.entrypoint.connections.open:1.000000|g\n",
 		"traefik.backend.server.up:1.000000|g\n",
+		"traefik.backend.server.ejected:1.000000|g\n",
+		"traefik.backend.server.healthcheck.failures.total:1.000000|c\n",
+		"traefik.backend.server.healthcheck.duration:10000.000000|ms",
+		"traefik.backend.hedges.total:1.000000|c\n",
+		"traefik.backend.hedges.won.total:1.000000|c\n",
+		"traefik.backend.server.open.connections:1.000000|g\n",
+		"traefik.tcp.router.connections.open:1.000000|g\n",
+		"traefik.tcp.router.connection.duration:10000.000000|ms\n",
+		"traefik.tcp.router.bytes.in.total:1.000000|c\n",
+		"traefik.tcp.router.bytes.out.total:1.000000|c\n",
+		"traefik.tcp.service.connections.open:1.000000|g\n",
+		"traefik.tcp.service.connection.duration:10000.000000|ms\n",
+		"traefik.tcp.service.bytes.in.total:1.000000|c\n",
+		"traefik.tcp.service.bytes.out.total:1.000000|c\n",
 	}
 
 	udp.ShouldReceiveAll(t, expected, func() {
@@ -47,5 +61,19 @@ func TestStatsD(t *testing.T) {
 		statsdRegistry.EntrypointReqDurationHistogram().With("entrypoint", "test").Observe(10000)
 		statsdRegistry.EntrypointOpenConnsGauge().With("entrypoint", "test").Set(1)
 		statsdRegistry.BackendServerUpGauge().With("backend:test", "url", "http://127.0.0.1").Set(1)
+		statsdRegistry.BackendServerEjectedGauge().With("backend:test", "url", "http://127.0.0.1").Set(1)
+		statsdRegistry.BackendServerHealthCheckFailuresCounter().With("backend:test", "url", "http://127.0.0.1").Add(1)
+		statsdRegistry.BackendServerHealthCheckDurationHistogram().With("backend:test", "url", "http://127.0.0.1").Observe(10000)
+		statsdRegistry.BackendHedgesCounter().With("backend", "test").Add(1)
+		statsdRegistry.BackendHedgesWonCounter().With("backend", "test").Add(1)
+		statsdRegistry.BackendServerOpenConnsGauge().With("backend", "test", "url", "127.0.0.1:80").Set(1)
+		statsdRegistry.TCPRouterOpenConnsGauge().With("router", "test").Set(1)
+		statsdRegistry.TCPRouterConnDurationHistogram().With("router", "test").Observe(10000)
+		statsdRegistry.TCPRouterBytesInCounter().With("router", "test").Add(1)
+		statsdRegistry.TCPRouterBytesOutCounter().With("router", "test").Add(1)
+		statsdRegistry.TCPServiceOpenConnsGauge().With("service", "test").Set(1)
+		statsdRegistry.TCPServiceConnDurationHistogram().With("service", "test").Observe(10000)
+		statsdRegistry.TCPServiceBytesInCounter().With("service", "test").Add(1)
+		statsdRegistry.TCPServiceBytesOutCounter().With("service", "test").Add(1)
 	})
 }
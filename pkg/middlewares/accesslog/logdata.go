@@ -68,6 +68,15 @@ const (
 	Overhead = "Overhead"
 	// RetryAttempts is the map key used for the amount of attempts the request was retried.
 	RetryAttempts = "RetryAttempts"
+
+	// TLSServerName is the map key used for the SNI of a TCP connection, if it uses TLS.
+	TLSServerName = "TLSServerName"
+	// BytesIn is the map key used for the number of bytes received from the client on a TCP connection.
+	BytesIn = "BytesIn"
+	// BytesOut is the map key used for the number of bytes sent to the client on a TCP connection.
+	BytesOut = "BytesOut"
+	// CloseReason is the map key used for why a TCP connection was closed.
+	CloseReason = "CloseReason"
 )
 
 // These are written out in the default case when no config is provided to specify keys of interest.
@@ -108,6 +117,10 @@ func init() {
 	allCoreKeys[StartLocal] = struct{}{}
 	allCoreKeys[Overhead] = struct{}{}
 	allCoreKeys[RetryAttempts] = struct{}{}
+	allCoreKeys[TLSServerName] = struct{}{}
+	allCoreKeys[BytesIn] = struct{}{}
+	allCoreKeys[BytesOut] = struct{}{}
+	allCoreKeys[CloseReason] = struct{}{}
 }
 
 // CoreLogData holds the fields computed from the request/response.
@@ -41,6 +41,7 @@ type handlerParams struct {
 type Handler struct {
 	config         *types.AccessLog
 	logger         *logrus.Logger
+	tcpLogger      *logrus.Logger
 	file           *os.File
 	mu             sync.Mutex
 	httpCodeRanges types.HTTPCodeRanges
@@ -69,13 +70,15 @@ func NewHandler(config *types.AccessLog) (*Handler, error) {
 	}
 	logHandlerChan := make(chan handlerParams, config.BufferingSize)
 
-	var formatter logrus.Formatter
+	var formatter, tcpFormatter logrus.Formatter
 
 	switch config.Format {
 	case CommonFormat:
 		formatter = new(CommonLogFormatter)
+		tcpFormatter = new(CommonTCPLogFormatter)
 	case JSONFormat:
 		formatter = new(logrus.JSONFormatter)
+		tcpFormatter = formatter
 	default:
 		return nil, fmt.Errorf("unsupported access log format: %s", config.Format)
 	}
@@ -87,9 +90,18 @@ func NewHandler(config *types.AccessLog) (*Handler, error) {
 		Level:     logrus.InfoLevel,
 	}
 
+	// The TCP connections are written to the same file as the HTTP requests, with their own formatter.
+	tcpLogger := &logrus.Logger{
+		Out:       file,
+		Formatter: tcpFormatter,
+		Hooks:     make(logrus.LevelHooks),
+		Level:     logrus.InfoLevel,
+	}
+
 	logHandler := &Handler{
 		config:         config,
 		logger:         logger,
+		tcpLogger:      tcpLogger,
 		file:           file,
 		logHandlerChan: logHandlerChan,
 	}
@@ -227,6 +239,7 @@ func (h *Handler) Rotate() error {
 	h.mu.Lock()
 	defer h.mu.Unlock()
 	h.logger.Out = h.file
+	h.tcpLogger.Out = h.file
 	return nil
 }
 
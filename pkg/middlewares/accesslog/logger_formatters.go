@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/containous/traefik/pkg/log"
 	"github.com/sirupsen/logrus"
 )
 
@@ -50,6 +51,40 @@ func (f *CommonLogFormatter) Format(entry *logrus.Entry) ([]byte, error) {
 	return b.Bytes(), err
 }
 
+// CommonTCPLogFormatter provides formatting of the TCP connections in the Traefik common log format.
+type CommonTCPLogFormatter struct{}
+
+// Format formats the TCP connection entry in the Traefik common log format.
+func (f *CommonTCPLogFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	b := &bytes.Buffer{}
+
+	var timestamp = defaultValue
+	if v, ok := entry.Data[StartUTC]; ok {
+		timestamp = v.(time.Time).Format(commonLogTimeFormat)
+	}
+
+	var elapsedMillis int64
+	if v, ok := entry.Data[Duration]; ok {
+		elapsedMillis = v.(time.Duration).Nanoseconds() / 1000000
+	}
+
+	_, err := fmt.Fprintf(b, "%s - - [%s] \"%s %s %s\" %v %v %s %s %s %s %dms\n",
+		toLog(entry.Data, ClientHost, defaultValue, false),
+		timestamp,
+		toLog(entry.Data, RequestProtocol, defaultValue, false),
+		toLog(entry.Data, log.EntryPointName, defaultValue, false),
+		toLog(entry.Data, TLSServerName, defaultValue, false),
+		toLog(entry.Data, BytesIn, defaultValue, true),
+		toLog(entry.Data, BytesOut, defaultValue, true),
+		toLog(entry.Data, RouterName, defaultValue, true),
+		toLog(entry.Data, ServiceName, defaultValue, true),
+		toLog(entry.Data, ServiceAddr, defaultValue, true),
+		toLog(entry.Data, CloseReason, defaultValue, false),
+		elapsedMillis)
+
+	return b.Bytes(), err
+}
+
 func toLog(fields logrus.Fields, key string, defaultValue string, quoted bool) interface{} {
 	if v, ok := fields[key]; ok {
 		if v == nil {
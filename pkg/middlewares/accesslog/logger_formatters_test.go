@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/containous/traefik/pkg/log"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 )
@@ -77,6 +78,67 @@ func TestCommonLogFormatter_Format(t *testing.T) {
 
 }
 
+func TestCommonTCPLogFormatter_Format(t *testing.T) {
+	clf := CommonTCPLogFormatter{}
+
+	testCases := []struct {
+		name        string
+		data        map[string]interface{}
+		expectedLog string
+	}{
+		{
+			name: "no TLS and no server",
+			data: map[string]interface{}{
+				StartUTC:           time.Date(2009, time.November, 10, 23, 0, 0, 0, time.UTC),
+				Duration:           123 * time.Second,
+				ClientHost:         "10.0.0.1",
+				RequestProtocol:    TCPProtocol,
+				log.EntryPointName: "tcp",
+				BytesIn:            int64(0),
+				BytesOut:           int64(0),
+				RouterName:         "foo",
+				ServiceName:        "bar",
+				CloseReason:        "no_available_server",
+			},
+			expectedLog: `10.0.0.1 - - [10/Nov/2009:23:00:00 +0000] "TCP tcp -" 0 0 "foo" "bar" - no_available_server 123000ms
+`,
+		},
+		{
+			name: "all data",
+			data: map[string]interface{}{
+				StartUTC:           time.Date(2009, time.November, 10, 23, 0, 0, 0, time.UTC),
+				Duration:           123 * time.Second,
+				ClientHost:         "10.0.0.1",
+				RequestProtocol:    TCPProtocol,
+				log.EntryPointName: "websecure",
+				TLSServerName:      "db.example.com",
+				BytesIn:            int64(512),
+				BytesOut:           int64(2048),
+				RouterName:         "foo",
+				ServiceName:        "bar",
+				ServiceAddr:        "10.0.0.2:3306",
+				CloseReason:        "client_closed",
+			},
+			expectedLog: `10.0.0.1 - - [10/Nov/2009:23:00:00 +0000] "TCP websecure db.example.com" 512 2048 "foo" "bar" "10.0.0.2:3306" client_closed 123000ms
+`,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			entry := &logrus.Entry{Data: test.data}
+
+			raw, err := clf.Format(entry)
+			assert.NoError(t, err)
+
+			assert.Equal(t, test.expectedLog, string(raw))
+		})
+	}
+}
+
 func Test_toLog(t *testing.T) {
 
 	testCases := []struct {
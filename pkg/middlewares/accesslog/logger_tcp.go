@@ -0,0 +1,174 @@
+package accesslog
+
+import (
+	"crypto/tls"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/containous/traefik/pkg/log"
+	"github.com/containous/traefik/pkg/tcp"
+	"github.com/containous/traefik/pkg/types"
+	"github.com/sirupsen/logrus"
+)
+
+// TCPProtocol is the RequestProtocol of the TCP connections.
+const TCPProtocol = "TCP"
+
+// tcpHandler writes each connection of a TCP router to the access log, once closed.
+type tcpHandler struct {
+	handler        *Handler
+	next           tcp.Handler
+	entryPointName string
+	routerName     string
+	serviceName    string
+}
+
+// WrapTCPHandler wraps the handler of a TCP router, so that each of its connections is written to the access log once closed.
+func WrapTCPHandler(handler *Handler, next tcp.Handler, entryPointName, routerName, serviceName string) tcp.Handler {
+	return &tcpHandler{
+		handler:        handler,
+		next:           next,
+		entryPointName: entryPointName,
+		routerName:     routerName,
+		serviceName:    serviceName,
+	}
+}
+
+func (t *tcpHandler) ServeTCP(conn net.Conn) {
+	now := time.Now().UTC()
+
+	core := CoreLogData{
+		StartUTC:           now,
+		StartLocal:         now.Local(),
+		RequestProtocol:    TCPProtocol,
+		log.EntryPointName: t.entryPointName,
+		RouterName:         t.routerName,
+		ServiceName:        t.serviceName,
+	}
+
+	if conn.RemoteAddr() != nil {
+		core[ClientAddr] = conn.RemoteAddr().String()
+		core[ClientHost], core[ClientPort] = silentSplitHostPort(conn.RemoteAddr().String())
+	}
+
+	lConn := &tcpLogConn{Conn: conn}
+	t.next.ServeTCP(lConn)
+
+	// The TLS handshake with the client, when terminated by the router, is done by now.
+	if serverName := connServerName(conn); serverName != "" {
+		core[TLSServerName] = serverName
+	}
+
+	server, closeReason := lConn.records()
+	if server != "" {
+		core[ServiceAddr] = server
+	}
+	if closeReason != "" {
+		core[CloseReason] = closeReason
+	}
+
+	core[BytesIn] = atomic.LoadInt64(&lConn.bytesIn)
+	core[BytesOut] = atomic.LoadInt64(&lConn.bytesOut)
+
+	// n.b. take care to perform time arithmetic using UTC to avoid errors at DST boundaries.
+	core[Duration] = time.Now().UTC().Sub(now)
+
+	t.handler.logTheConnection(core)
+}
+
+// logTheConnection writes the TCP connection to the access log, unless it is filtered out.
+func (h *Handler) logTheConnection(core CoreLogData) {
+	if !h.keepTCPAccessLog(core[Duration].(time.Duration)) {
+		return
+	}
+
+	fields := logrus.Fields{}
+	for k, v := range core {
+		if h.config.Fields.Keep(k) {
+			fields[k] = v
+		}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.tcpLogger.WithFields(fields).Println()
+}
+
+// keepTCPAccessLog applies the minDuration filter to the TCP connections,
+// the other filters being about the HTTP requests.
+func (h *Handler) keepTCPAccessLog(duration time.Duration) bool {
+	if h.config.Filters == nil || h.config.Filters.MinDuration == 0 {
+		return true
+	}
+
+	return types.Duration(duration) > h.config.Filters.MinDuration
+}
+
+// connServerName returns the SNI of the connection, whether its TLS is terminated by the router or passed through.
+func connServerName(conn net.Conn) string {
+	switch c := conn.(type) {
+	case *tls.Conn:
+		return c.ConnectionState().ServerName
+	case *tcp.Conn:
+		return c.ServerName
+	default:
+		return ""
+	}
+}
+
+// tcpLogConn counts the bytes exchanged with the client,
+// and records the server the connection is forwarded to and why it is closed.
+type tcpLogConn struct {
+	net.Conn
+	bytesIn  int64
+	bytesOut int64
+
+	mu          sync.Mutex
+	server      string
+	closeReason string
+}
+
+func (c *tcpLogConn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	atomic.AddInt64(&c.bytesIn, int64(n))
+	return n, err
+}
+
+func (c *tcpLogConn) Write(p []byte) (int, error) {
+	n, err := c.Conn.Write(p)
+	atomic.AddInt64(&c.bytesOut, int64(n))
+	return n, err
+}
+
+// CloseWrite closes the writing half of the underlying connection,
+// or the whole connection if it cannot be half-closed.
+func (c *tcpLogConn) CloseWrite() error {
+	return tcp.CloseWrite(c.Conn)
+}
+
+// RecordServer records the address of the server the connection is forwarded to.
+func (c *tcpLogConn) RecordServer(address string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.server = address
+}
+
+// RecordCloseReason records why the connection is closed, keeping the first reason recorded.
+func (c *tcpLogConn) RecordCloseReason(reason string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closeReason == "" {
+		c.closeReason = reason
+	}
+}
+
+func (c *tcpLogConn) records() (server, closeReason string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.server, c.closeReason
+}
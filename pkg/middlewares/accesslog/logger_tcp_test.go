@@ -0,0 +1,187 @@
+package accesslog
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/pkg/log"
+	"github.com/containous/traefik/pkg/tcp"
+	"github.com/containous/traefik/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// tcpTestHandler reads the 4 bytes sent by the client, replies with 9 bytes,
+// and records the server and why the connection is closed.
+var tcpTestHandler = tcp.HandlerFunc(func(conn net.Conn) {
+	buf := make([]byte, 4)
+	if _, err := io.ReadFull(conn, buf); err != nil {
+		return
+	}
+
+	if _, err := conn.Write([]byte("pong pong")); err != nil {
+		return
+	}
+
+	conn.(tcp.ConnRecorder).RecordServer("10.0.0.2:3306")
+	tcp.RecordCloseReason(conn, tcp.CloseReasonClient)
+})
+
+// doTCPLogging serves a connection with the SNI db.example.com, from a client sending 4 bytes.
+func doTCPLogging(t *testing.T, logger *Handler, next tcp.Handler) {
+	t.Helper()
+
+	client, server := net.Pipe()
+	defer client.Close()
+
+	go func() {
+		_, _ = client.Write([]byte("ping"))
+		_, _ = io.Copy(ioutil.Discard, client)
+	}()
+
+	handler := WrapTCPHandler(logger, next, "websecure", "mysql@file", "mysql@file")
+	handler.ServeTCP(&tcp.Conn{Conn: server, ServerName: "db.example.com"})
+}
+
+func TestTCPLoggerCLF(t *testing.T) {
+	tmpDir := createTempDir(t, CommonFormat)
+	defer os.RemoveAll(tmpDir)
+
+	logFilePath := filepath.Join(tmpDir, logFileNameSuffix)
+	logger, err := NewHandler(&types.AccessLog{FilePath: logFilePath, Format: CommonFormat})
+	require.NoError(t, err)
+	defer logger.Close()
+
+	doTCPLogging(t, logger, tcpTestHandler)
+
+	logData, err := ioutil.ReadFile(logFilePath)
+	require.NoError(t, err)
+
+	assert.Regexp(t, `^pipe - - \[[^]]+\] "TCP websecure db.example.com" 4 9 "mysql@file" "mysql@file" "10.0.0.2:3306" client_closed [0-9]+ms\n$`, string(logData))
+}
+
+func TestTCPLoggerJSON(t *testing.T) {
+	tmpDir := createTempDir(t, JSONFormat)
+	defer os.RemoveAll(tmpDir)
+
+	logFilePath := filepath.Join(tmpDir, logFileNameSuffix)
+	logger, err := NewHandler(&types.AccessLog{FilePath: logFilePath, Format: JSONFormat})
+	require.NoError(t, err)
+	defer logger.Close()
+
+	doTCPLogging(t, logger, tcpTestHandler)
+
+	logData, err := ioutil.ReadFile(logFilePath)
+	require.NoError(t, err)
+
+	jsonData := make(map[string]interface{})
+	require.NoError(t, json.Unmarshal(logData, &jsonData))
+
+	expected := map[string]func(t *testing.T, value interface{}){
+		RequestProtocol:    assertString(TCPProtocol),
+		log.EntryPointName: assertString("websecure"),
+		TLSServerName:      assertString("db.example.com"),
+		RouterName:         assertString("mysql@file"),
+		ServiceName:        assertString("mysql@file"),
+		ServiceAddr:        assertString("10.0.0.2:3306"),
+		ClientAddr:         assertString("pipe"),
+		ClientHost:         assertString("pipe"),
+		ClientPort:         assertString("-"),
+		BytesIn:            assertFloat64(4),
+		BytesOut:           assertFloat64(9),
+		CloseReason:        assertString(tcp.CloseReasonClient),
+		Duration:           assertFloat64NotZero(),
+		StartUTC:           assertNotEmpty(),
+		StartLocal:         assertNotEmpty(),
+		"level":            assertString("info"),
+		"msg":              assertString(""),
+		"time":             assertNotEmpty(),
+	}
+
+	assert.Len(t, jsonData, len(expected))
+	for field, assertion := range expected {
+		assertion(t, jsonData[field])
+	}
+}
+
+func TestTCPLoggerFilters(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		filters       *types.AccessLogFilters
+		expectedLines int
+	}{
+		{
+			desc:          "no filters",
+			expectedLines: 1,
+		},
+		{
+			desc: "HTTP filters only",
+			filters: &types.AccessLogFilters{
+				StatusCodes:   []string{"500"},
+				RetryAttempts: true,
+			},
+			expectedLines: 1,
+		},
+		{
+			desc: "shorter than the min duration",
+			filters: &types.AccessLogFilters{
+				MinDuration: types.Duration(time.Hour),
+			},
+			expectedLines: 0,
+		},
+		{
+			desc: "longer than the min duration",
+			filters: &types.AccessLogFilters{
+				MinDuration: types.Duration(time.Nanosecond),
+			},
+			expectedLines: 1,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			tmpDir := createTempDir(t, CommonFormat)
+			defer os.RemoveAll(tmpDir)
+
+			logFilePath := filepath.Join(tmpDir, logFileNameSuffix)
+			logger, err := NewHandler(&types.AccessLog{FilePath: logFilePath, Format: CommonFormat, Filters: test.filters})
+			require.NoError(t, err)
+			defer logger.Close()
+
+			doTCPLogging(t, logger, tcpTestHandler)
+
+			assert.Equal(t, test.expectedLines, lineCount(t, logFilePath))
+		})
+	}
+}
+
+func TestTCPLogRotation(t *testing.T) {
+	tmpDir := createTempDir(t, CommonFormat)
+	defer os.RemoveAll(tmpDir)
+
+	fileName := filepath.Join(tmpDir, "traefik.log")
+	rotatedFileName := fileName + ".rotated"
+
+	logger, err := NewHandler(&types.AccessLog{FilePath: fileName, Format: CommonFormat})
+	require.NoError(t, err)
+	defer logger.Close()
+
+	doTCPLogging(t, logger, tcpTestHandler)
+
+	require.NoError(t, os.Rename(fileName, rotatedFileName))
+	require.NoError(t, logger.Rotate())
+
+	doTCPLogging(t, logger, tcpTestHandler)
+
+	assert.Equal(t, 1, lineCount(t, rotatedFileName))
+	assert.Equal(t, 1, lineCount(t, fileName))
+}
@@ -0,0 +1,35 @@
+package middlewares
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+)
+
+// DefaultMaxBodySize is the default maximum size (in bytes) of the request bodies buffered to be replayed.
+const DefaultMaxBodySize = 1 << 20
+
+// ErrBodyTooLarge is returned by ReadBody when the request body is larger than the allowed size.
+var ErrBodyTooLarge = errors.New("request body too large")
+
+// ReadBody reads the body of the request, up to maxBodySize bytes, so that it can be replayed.
+// If the body is larger, the request body is left readable from its start, and ErrBodyTooLarge is returned.
+func ReadBody(req *http.Request, maxBodySize int64) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(req.Body, maxBodySize+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if int64(len(body)) > maxBodySize {
+		req.Body = ioutil.NopCloser(io.MultiReader(bytes.NewReader(body), req.Body))
+		return nil, ErrBodyTooLarge
+	}
+
+	return body, nil
+}
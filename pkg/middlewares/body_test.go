@@ -0,0 +1,63 @@
+package middlewares
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadBody(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		body          string
+		expectedBody  []byte
+		expectedError error
+	}{
+		{
+			desc: "no body",
+		},
+		{
+			desc:         "body smaller than the limit",
+			body:         "foo",
+			expectedBody: []byte("foo"),
+		},
+		{
+			desc:         "body as large as the limit",
+			body:         "foobar",
+			expectedBody: []byte("foobar"),
+		},
+		{
+			desc:          "body larger than the limit",
+			body:          "foobarbaz",
+			expectedError: ErrBodyTooLarge,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(test.body))
+			if test.body == "" {
+				req = httptest.NewRequest(http.MethodGet, "/", nil)
+			}
+
+			body, err := ReadBody(req, 6)
+			assert.Equal(t, test.expectedError, err)
+			assert.Equal(t, test.expectedBody, body)
+
+			if err == ErrBodyTooLarge {
+				// The whole body can still be forwarded.
+				content, err := ioutil.ReadAll(req.Body)
+				require.NoError(t, err)
+				assert.Equal(t, test.body, string(content))
+			}
+		})
+	}
+}
@@ -2,17 +2,26 @@ package retry
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"fmt"
 	"io/ioutil"
+	"math"
+	"math/rand"
 	"net"
 	"net/http"
 	"net/http/httptrace"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/containous/traefik/pkg/config"
 	"github.com/containous/traefik/pkg/middlewares"
 	"github.com/containous/traefik/pkg/tracing"
+	"github.com/containous/traefik/pkg/types"
+	gokitmetrics "github.com/go-kit/kit/metrics"
 	"github.com/opentracing/opentracing-go/ext"
+	"github.com/vulcand/oxy/utils"
 )
 
 // Compile time validation that the response writer implements http interfaces correctly.
@@ -20,6 +29,9 @@ var _ middlewares.Stateful = &responseWriterWithCloseNotify{}
 
 const (
 	typeName = "Retry"
+
+	defaultBudgetPercent        = 20
+	defaultBudgetMinConcurrency = 3
 )
 
 // Listener is used to inform about retry attempts.
@@ -35,14 +47,24 @@ type Listeners []Listener
 
 // retry is a middleware that retries requests.
 type retry struct {
-	attempts int
-	next     http.Handler
-	listener Listener
-	name     string
+	attempts        int
+	initialInterval time.Duration
+	maxInterval     time.Duration
+	// statusCodes are the status codes of the responses for which the request is retried.
+	statusCodes        types.HTTPCodeRanges
+	retryNonIdempotent bool
+	// maxBodySize is the maximum size (in bytes) of the request bodies buffered to be replayed to the servers.
+	// The requests with a larger body are not retried because of the status code of the response.
+	maxBodySize int64
+	budget      *budget // can be nil
+	next        http.Handler
+	listener    Listener
+	name        string
 }
 
 // New returns a new retry middleware.
-func New(ctx context.Context, next http.Handler, config config.Retry, listener Listener, name string) (http.Handler, error) {
+// The retry budget, if any, counts the requests with the given counters, and with its own counters if nil.
+func New(ctx context.Context, next http.Handler, config config.Retry, counters *BudgetCounters, listener Listener, name string) (http.Handler, error) {
 	logger := middlewares.GetLogger(ctx, name, typeName)
 	logger.Debug("Creating middleware")
 
@@ -50,39 +72,118 @@ func New(ctx context.Context, next http.Handler, config config.Retry, listener L
 		return nil, fmt.Errorf("incorrect (or empty) value for attempt (%d)", config.Attempts)
 	}
 
+	initialInterval, err := parseInterval(config.InitialInterval)
+	if err != nil {
+		return nil, fmt.Errorf("incorrect value for initial interval: %v", err)
+	}
+
+	maxInterval, err := parseInterval(config.MaxInterval)
+	if err != nil {
+		return nil, fmt.Errorf("incorrect value for max interval: %v", err)
+	}
+
+	statusCodes, err := types.NewHTTPCodeRanges(config.StatusCodes)
+	if err != nil {
+		return nil, err
+	}
+
+	maxBodySize := config.MaxBodySize
+	if maxBodySize <= 0 {
+		maxBodySize = middlewares.DefaultMaxBodySize
+	}
+
 	return &retry{
-		attempts: config.Attempts,
-		next:     next,
-		listener: listener,
-		name:     name,
+		attempts:           config.Attempts,
+		initialInterval:    initialInterval,
+		maxInterval:        maxInterval,
+		statusCodes:        statusCodes,
+		retryNonIdempotent: config.RetryNonIdempotent,
+		maxBodySize:        maxBodySize,
+		budget:             newBudget(config.Budget, counters),
+		next:               next,
+		listener:           listener,
+		name:               name,
 	}, nil
 }
 
+func parseInterval(value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	interval, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, err
+	}
+
+	if interval < 0 {
+		return 0, fmt.Errorf("negative duration %s", value)
+	}
+
+	return interval, nil
+}
+
 func (r *retry) GetTracingInformation() (string, ext.SpanKindEnum) {
 	return r.name, tracing.SpanKindNoneEnum
 }
 
 func (r *retry) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if r.budget != nil {
+		atomic.AddInt64(&r.budget.counters.requests, 1)
+		defer atomic.AddInt64(&r.budget.counters.requests, -1)
+	}
+
+	logger := middlewares.GetLogger(req.Context(), r.name, typeName)
+
 	// if we might make multiple attempts, swap the body for an ioutil.NopCloser
 	// cf https://github.com/containous/traefik/issues/1008
-	if r.attempts > 1 {
+	if r.attempts > 1 && req.Body != nil {
 		body := req.Body
 		defer body.Close()
 		req.Body = ioutil.NopCloser(body)
 	}
 
+	// The requests already sent to a server are only retried because of the status code of the response
+	// if their method is idempotent, and if their body can be replayed.
+	retryOnStatus := r.attempts > 1 && len(r.statusCodes) > 0 && (r.retryNonIdempotent || isIdempotent(req.Method))
+
+	var body []byte
+	if retryOnStatus {
+		var err error
+		body, err = middlewares.ReadBody(req, r.maxBodySize)
+		if err == middlewares.ErrBodyTooLarge {
+			logger.Debug("No retry on the status code: the request body is larger than the allowed size")
+			retryOnStatus = false
+		} else if err != nil {
+			logger.Errorf("Error while reading the request body: %v", err)
+			http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+	}
+
 	attempts := 1
+	retrying := false
 	for {
-		shouldRetry := attempts < r.attempts
-		retryResponseWriter := newResponseWriter(rw, shouldRetry)
+		shouldRetry := attempts < r.attempts && (retrying || r.budget.allow())
+
+		var statusCodes types.HTTPCodeRanges
+		if retryOnStatus && shouldRetry {
+			statusCodes = r.statusCodes
+		}
+
+		if retryOnStatus && body != nil {
+			req.Body = ioutil.NopCloser(bytes.NewReader(body))
+		}
+
+		retryResponseWriter := newResponseWriter(rw, shouldRetry, statusCodes)
 
 		// Disable retries when the backend already received request data
 		trace := &httptrace.ClientTrace{
 			WroteHeaders: func() {
-				retryResponseWriter.DisableRetries()
+				retryResponseWriter.RequestSent()
 			},
 			WroteRequest: func(httptrace.WroteRequestInfo) {
-				retryResponseWriter.DisableRetries()
+				retryResponseWriter.RequestSent()
 			},
 		}
 		newCtx := httptrace.WithClientTrace(req.Context(), trace)
@@ -93,10 +194,167 @@ func (r *retry) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 			break
 		}
 
+		if !retrying && r.budget != nil {
+			atomic.AddInt64(&r.budget.counters.retries, 1)
+			defer atomic.AddInt64(&r.budget.counters.retries, -1)
+		}
+		retrying = true
+
 		attempts++
-		logger := middlewares.GetLogger(req.Context(), r.name, typeName)
 		logger.Debugf("New attempt %d for request: %v", attempts, req.URL)
 		r.listener.Retried(req, attempts)
+
+		if !r.wait(req.Context(), attempts-1) {
+			logger.Debugf("Request canceled while waiting for attempt %d: %v", attempts, req.URL)
+			rw.WriteHeader(utils.StatusClientClosedRequest)
+			return
+		}
+	}
+}
+
+// wait waits before the given retry, and returns false if the request was canceled in the meantime.
+func (r *retry) wait(ctx context.Context, retry int) bool {
+	backoff := r.backoff(retry)
+	if backoff <= 0 {
+		return true
+	}
+
+	timer := time.NewTimer(backoff)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// backoff returns the duration to wait before the given retry (starting at 1).
+// The interval doubles with each retry, up to the max interval,
+// and the actual duration is randomly picked between half the interval and the interval (jitter).
+func (r *retry) backoff(retry int) time.Duration {
+	if r.initialInterval <= 0 {
+		return 0
+	}
+
+	interval := r.initialInterval
+	for i := 1; i < retry && interval < math.MaxInt64/2; i++ {
+		if r.maxInterval > 0 && interval >= r.maxInterval {
+			break
+		}
+		interval *= 2
+	}
+
+	if r.maxInterval > 0 && interval > r.maxInterval {
+		interval = r.maxInterval
+	}
+
+	return interval/2 + time.Duration(rand.Int63n(int64(interval/2)+1))
+}
+
+// BudgetCounters counts the requests in flight, and the requests being retried, for the retry budgets.
+type BudgetCounters struct {
+	requests int64
+	retries  int64
+}
+
+// Budgets holds the counters of the retry budgets of each service,
+// so that they are shared by all the retry middlewares of the service, and kept across the configuration reloads.
+type Budgets struct {
+	mu       sync.Mutex
+	counters map[string]*BudgetCounters
+}
+
+// NewBudgets creates a new Budgets.
+func NewBudgets() *Budgets {
+	return &Budgets{counters: make(map[string]*BudgetCounters)}
+}
+
+// Get returns the counters of the given service, new counters if the service is unknown.
+func (b *Budgets) Get(serviceName string) *BudgetCounters {
+	if serviceName == "" {
+		return &BudgetCounters{}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	counters, ok := b.counters[serviceName]
+	if !ok {
+		counters = &BudgetCounters{}
+		b.counters[serviceName] = counters
+	}
+	return counters
+}
+
+// Prune forgets the counters of the services which are not part of the given configuration anymore.
+func (b *Budgets) Prune(services map[string]*config.ServiceInfo) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for serviceName := range b.counters {
+		if _, ok := services[serviceName]; !ok {
+			delete(b.counters, serviceName)
+		}
+	}
+}
+
+// budget limits the number of requests being retried at the same time,
+// to a percentage of the requests in flight.
+type budget struct {
+	percent        int64
+	minConcurrency int64
+	counters       *BudgetCounters
+}
+
+func newBudget(conf *config.RetryBudget, counters *BudgetCounters) *budget {
+	if conf == nil {
+		return nil
+	}
+
+	if counters == nil {
+		counters = &BudgetCounters{}
+	}
+
+	b := &budget{
+		percent:        defaultBudgetPercent,
+		minConcurrency: defaultBudgetMinConcurrency,
+		counters:       counters,
+	}
+
+	if conf.Percent > 0 {
+		b.percent = int64(conf.Percent)
+	}
+
+	if conf.MinConcurrency > 0 {
+		b.minConcurrency = int64(conf.MinConcurrency)
+	}
+
+	return b
+}
+
+// allow tells whether a request can be retried. A nil budget always allows the retries.
+func (b *budget) allow() bool {
+	if b == nil {
+		return true
+	}
+
+	retries := atomic.LoadInt64(&b.counters.retries)
+	if retries < b.minConcurrency {
+		return true
+	}
+
+	return retries*100 < atomic.LoadInt64(&b.counters.requests)*b.percent
+}
+
+// isIdempotent tells whether the method is idempotent, as defined by RFC 7231, section 4.2.2.
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
 	}
 }
 
@@ -107,18 +365,40 @@ func (l Listeners) Retried(req *http.Request, attempt int) {
 	}
 }
 
+// retryMetrics is the part of the metrics registry used to count the retries.
+type retryMetrics interface {
+	BackendRetriesCounter() gokitmetrics.Counter
+}
+
+// NewMetricsListener returns a Listener counting the retries of the requests sent to the given service.
+func NewMetricsListener(registry retryMetrics, serviceName string) Listener {
+	return &metricsListener{registry: registry, serviceName: serviceName}
+}
+
+type metricsListener struct {
+	registry    retryMetrics
+	serviceName string
+}
+
+// Retried implements the Listener interface, and increments the BackendRetriesCounter.
+func (m *metricsListener) Retried(req *http.Request, attempt int) {
+	m.registry.BackendRetriesCounter().With("backend", m.serviceName).Add(1)
+}
+
 type responseWriter interface {
 	http.ResponseWriter
 	http.Flusher
 	ShouldRetry() bool
 	DisableRetries()
+	RequestSent()
 }
 
-func newResponseWriter(rw http.ResponseWriter, shouldRetry bool) responseWriter {
+func newResponseWriter(rw http.ResponseWriter, shouldRetry bool, statusCodes types.HTTPCodeRanges) responseWriter {
 	responseWriter := &responseWriterWithoutCloseNotify{
 		responseWriter: rw,
 		headers:        make(http.Header),
 		shouldRetry:    shouldRetry,
+		statusCodes:    statusCodes,
 	}
 	if _, ok := rw.(http.CloseNotifier); ok {
 		return &responseWriterWithCloseNotify{
@@ -132,7 +412,11 @@ type responseWriterWithoutCloseNotify struct {
 	responseWriter http.ResponseWriter
 	headers        http.Header
 	shouldRetry    bool
-	written        bool
+	// statusCodes are the status codes of the responses of the server for which the request is retried.
+	statusCodes types.HTTPCodeRanges
+	// sent is true once the server received the request.
+	sent    bool
+	written bool
 }
 
 func (r *responseWriterWithoutCloseNotify) ShouldRetry() bool {
@@ -143,6 +427,13 @@ func (r *responseWriterWithoutCloseNotify) DisableRetries() {
 	r.shouldRetry = false
 }
 
+// RequestSent disables the retries, except because of the status code of the response,
+// as the server already received the request.
+func (r *responseWriterWithoutCloseNotify) RequestSent() {
+	r.sent = true
+	r.DisableRetries()
+}
+
 func (r *responseWriterWithoutCloseNotify) Header() http.Header {
 	if r.written {
 		return r.responseWriter.Header()
@@ -167,6 +458,11 @@ func (r *responseWriterWithoutCloseNotify) WriteHeader(code int) {
 		r.DisableRetries()
 	}
 
+	if r.sent && r.statusCodes.Contains(code) {
+		// The server responded with a status code for which the request is retried.
+		r.shouldRetry = true
+	}
+
 	if r.ShouldRetry() {
 		return
 	}
@@ -193,6 +489,10 @@ func (r *responseWriterWithoutCloseNotify) Hijack() (net.Conn, *bufio.ReadWriter
 }
 
 func (r *responseWriterWithoutCloseNotify) Flush() {
+	if r.ShouldRetry() {
+		return
+	}
+
 	if flusher, ok := r.responseWriter.(http.Flusher); ok {
 		flusher.Flush()
 	}
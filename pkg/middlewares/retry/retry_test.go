@@ -3,20 +3,26 @@ package retry
 import (
 	"context"
 	"fmt"
+	"io/ioutil"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/http/httptrace"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/containous/traefik/pkg/config"
 	"github.com/containous/traefik/pkg/middlewares/emptybackendhandler"
 	"github.com/containous/traefik/pkg/testhelpers"
+	gokitmetrics "github.com/go-kit/kit/metrics"
 	"github.com/gorilla/websocket"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/vulcand/oxy/forward"
 	"github.com/vulcand/oxy/roundrobin"
+	"github.com/vulcand/oxy/utils"
 )
 
 func TestRetry(t *testing.T) {
@@ -38,7 +44,7 @@ func TestRetry(t *testing.T) {
 			desc:                  "no retry when max request attempts is one",
 			config:                config.Retry{Attempts: 1},
 			wantRetryAttempts:     0,
-			wantResponseStatus:    http.StatusInternalServerError,
+			wantResponseStatus:    http.StatusBadGateway,
 			amountFaultyEndpoints: 1,
 		},
 		{
@@ -59,7 +65,7 @@ func TestRetry(t *testing.T) {
 			desc:                  "max attempts exhausted delivers the 5xx response",
 			config:                config.Retry{Attempts: 3},
 			wantRetryAttempts:     2,
-			wantResponseStatus:    http.StatusInternalServerError,
+			wantResponseStatus:    http.StatusBadGateway,
 			amountFaultyEndpoints: 3,
 		},
 	}
@@ -83,13 +89,9 @@ func TestRetry(t *testing.T) {
 			loadBalancer, err := roundrobin.New(forwarder)
 			require.NoError(t, err)
 
-			basePort := 33444
 			for i := 0; i < test.amountFaultyEndpoints; i++ {
-				// 192.0.2.0 is a non-routable IP for testing purposes.
-				// See: https://stackoverflow.com/questions/528538/non-routable-ip-address/18436928#18436928
-				// We only use the port specification here because the URL is used as identifier
-				// in the load balancer and using the exact same URL would not add a new server.
-				err = loadBalancer.UpsertServer(testhelpers.MustParseURL("http://192.0.2.0:" + string(basePort+i)))
+				// The address of a closed listener refuses the connections right away.
+				err = loadBalancer.UpsertServer(testhelpers.MustParseURL("http://" + closedListenerAddr(t)))
 				require.NoError(t, err)
 			}
 
@@ -98,7 +100,7 @@ func TestRetry(t *testing.T) {
 			require.NoError(t, err)
 
 			retryListener := &countingRetryListener{}
-			retry, err := New(context.Background(), loadBalancer, test.config, retryListener, "traefikTest")
+			retry, err := New(context.Background(), loadBalancer, test.config, nil, retryListener, "traefikTest")
 			require.NoError(t, err)
 
 			recorder := httptest.NewRecorder()
@@ -112,6 +114,19 @@ func TestRetry(t *testing.T) {
 	}
 }
 
+// closedListenerAddr returns the address of a local listener which is already closed.
+func closedListenerAddr(t *testing.T) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	addr := listener.Addr().String()
+	require.NoError(t, listener.Close())
+
+	return addr
+}
+
 func TestRetryEmptyServerList(t *testing.T) {
 	forwarder, err := forward.New()
 	require.NoError(t, err)
@@ -124,7 +139,7 @@ func TestRetryEmptyServerList(t *testing.T) {
 	next := emptybackendhandler.New(loadBalancer)
 
 	retryListener := &countingRetryListener{}
-	retry, err := New(context.Background(), next, config.Retry{Attempts: 3}, retryListener, "traefikTest")
+	retry, err := New(context.Background(), next, config.Retry{Attempts: 3}, nil, retryListener, "traefikTest")
 	require.NoError(t, err)
 
 	recorder := httptest.NewRecorder()
@@ -172,7 +187,7 @@ func TestMultipleRetriesShouldNotLooseHeaders(t *testing.T) {
 		rw.WriteHeader(http.StatusNoContent)
 	})
 
-	retry, err := New(context.Background(), next, config.Retry{Attempts: 3}, &countingRetryListener{}, "traefikTest")
+	retry, err := New(context.Background(), next, config.Retry{Attempts: 3}, nil, &countingRetryListener{}, "traefikTest")
 	require.NoError(t, err)
 
 	responseRecorder := httptest.NewRecorder()
@@ -218,7 +233,7 @@ func TestRetryWithFlush(t *testing.T) {
 		}
 	})
 
-	retry, err := New(context.Background(), next, config.Retry{Attempts: 1}, &countingRetryListener{}, "traefikTest")
+	retry, err := New(context.Background(), next, config.Retry{Attempts: 1}, nil, &countingRetryListener{}, "traefikTest")
 	require.NoError(t, err)
 
 	responseRecorder := httptest.NewRecorder()
@@ -277,13 +292,9 @@ func TestRetryWebsocket(t *testing.T) {
 				t.Fatalf("Error creating load balancer: %v", err)
 			}
 
-			basePort := 33444
 			for i := 0; i < test.amountFaultyEndpoints; i++ {
-				// 192.0.2.0 is a non-routable IP for testing purposes.
-				// See: https://stackoverflow.com/questions/528538/non-routable-ip-address/18436928#18436928
-				// We only use the port specification here because the URL is used as identifier
-				// in the load balancer and using the exact same URL would not add a new server.
-				_ = loadBalancer.UpsertServer(testhelpers.MustParseURL("http://192.0.2.0:" + string(basePort+i)))
+				// The address of a closed listener refuses the connections right away.
+				_ = loadBalancer.UpsertServer(testhelpers.MustParseURL("http://" + closedListenerAddr(t)))
 			}
 
 			// add the functioning server to the end of the load balancer list
@@ -293,7 +304,7 @@ func TestRetryWebsocket(t *testing.T) {
 			}
 
 			retryListener := &countingRetryListener{}
-			retryH, err := New(context.Background(), loadBalancer, config.Retry{Attempts: test.maxRequestAttempts}, retryListener, "traefikTest")
+			retryH, err := New(context.Background(), loadBalancer, config.Retry{Attempts: test.maxRequestAttempts}, nil, retryListener, "traefikTest")
 			require.NoError(t, err)
 
 			retryServer := httptest.NewServer(retryH)
@@ -310,3 +321,234 @@ func TestRetryWebsocket(t *testing.T) {
 		})
 	}
 }
+
+// statusHandler responds with the given status codes, one per attempt, and then with a 200.
+// Each attempt echoes the body of the request.
+func statusHandler(codes ...int) http.Handler {
+	attempt := 0
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		// The request has been written to the backend.
+		httptrace.ContextClientTrace(req.Context()).WroteHeaders()
+
+		body, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			http.Error(rw, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		code := http.StatusOK
+		if attempt < len(codes) {
+			code = codes[attempt]
+		}
+		attempt++
+
+		rw.WriteHeader(code)
+		_, _ = rw.Write(body)
+	})
+}
+
+func TestRetry_statusCodes(t *testing.T) {
+	testCases := []struct {
+		desc               string
+		config             config.Retry
+		method             string
+		codes              []int
+		wantRetryAttempts  int
+		wantResponseStatus int
+	}{
+		{
+			desc:               "no retry on the status code by default",
+			config:             config.Retry{Attempts: 3},
+			method:             http.MethodGet,
+			codes:              []int{http.StatusBadGateway},
+			wantRetryAttempts:  0,
+			wantResponseStatus: http.StatusBadGateway,
+		},
+		{
+			desc:               "retries on the status codes",
+			config:             config.Retry{Attempts: 3, StatusCodes: []string{"502-504"}},
+			method:             http.MethodPut,
+			codes:              []int{http.StatusBadGateway, http.StatusServiceUnavailable},
+			wantRetryAttempts:  2,
+			wantResponseStatus: http.StatusOK,
+		},
+		{
+			desc:               "no retry on other status codes",
+			config:             config.Retry{Attempts: 3, StatusCodes: []string{"502-504"}},
+			method:             http.MethodGet,
+			codes:              []int{http.StatusInternalServerError},
+			wantRetryAttempts:  0,
+			wantResponseStatus: http.StatusInternalServerError,
+		},
+		{
+			desc:               "attempts exhausted delivers the last response",
+			config:             config.Retry{Attempts: 2, StatusCodes: []string{"503"}},
+			method:             http.MethodGet,
+			codes:              []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable},
+			wantRetryAttempts:  1,
+			wantResponseStatus: http.StatusServiceUnavailable,
+		},
+		{
+			desc:               "no retry of non-idempotent methods",
+			config:             config.Retry{Attempts: 3, StatusCodes: []string{"503"}},
+			method:             http.MethodPost,
+			codes:              []int{http.StatusServiceUnavailable},
+			wantRetryAttempts:  0,
+			wantResponseStatus: http.StatusServiceUnavailable,
+		},
+		{
+			desc:               "retries of non-idempotent methods when allowed",
+			config:             config.Retry{Attempts: 3, StatusCodes: []string{"503"}, RetryNonIdempotent: true},
+			method:             http.MethodPost,
+			codes:              []int{http.StatusServiceUnavailable},
+			wantRetryAttempts:  1,
+			wantResponseStatus: http.StatusOK,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			retryListener := &countingRetryListener{}
+			retry, err := New(context.Background(), statusHandler(test.codes...), test.config, nil, retryListener, "traefikTest")
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			req := httptest.NewRequest(test.method, "http://localhost:3000/ok", strings.NewReader("payload"))
+
+			retry.ServeHTTP(recorder, req)
+
+			assert.Equal(t, test.wantResponseStatus, recorder.Code)
+			assert.Equal(t, "payload", recorder.Body.String())
+			assert.Equal(t, test.wantRetryAttempts, retryListener.timesCalled)
+		})
+	}
+}
+
+func TestRetry_backoff(t *testing.T) {
+	handler, err := New(context.Background(), nil, config.Retry{Attempts: 10, InitialInterval: "100ms", MaxInterval: "1s"}, nil, &countingRetryListener{}, "traefikTest")
+	require.NoError(t, err)
+
+	intervals := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second, time.Second}
+	for i, interval := range intervals {
+		for j := 0; j < 100; j++ {
+			backoff := handler.(*retry).backoff(i + 1)
+			assert.True(t, backoff >= interval/2 && backoff <= interval, "retry %d: %s not in [%s, %s]", i+1, backoff, interval/2, interval)
+		}
+	}
+}
+
+func TestRetry_canceledDuringBackoff(t *testing.T) {
+	retryListener := &countingRetryListener{}
+	retry, err := New(context.Background(), statusHandler(http.StatusServiceUnavailable), config.Retry{Attempts: 3, InitialInterval: "1h", StatusCodes: []string{"503"}}, nil, retryListener, "traefikTest")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	recorder := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "http://localhost:3000/ok", nil).WithContext(ctx)
+
+	retry.ServeHTTP(recorder, req)
+
+	assert.Equal(t, utils.StatusClientClosedRequest, recorder.Code)
+	assert.Equal(t, 1, retryListener.timesCalled)
+}
+
+func TestBudget_allow(t *testing.T) {
+	b := newBudget(&config.RetryBudget{Percent: 10, MinConcurrency: 2}, nil)
+
+	b.counters.requests = 10
+	b.counters.retries = 1
+	assert.True(t, b.allow())
+
+	// Only the minimum concurrency is allowed with few requests in flight.
+	b.counters.retries = 2
+	assert.False(t, b.allow())
+
+	b.counters.requests = 100
+	b.counters.retries = 9
+	assert.True(t, b.allow())
+
+	b.counters.retries = 10
+	assert.False(t, b.allow())
+
+	var unlimited *budget
+	assert.True(t, unlimited.allow())
+}
+
+func TestRetry_budgetExhausted(t *testing.T) {
+	retryListener := &countingRetryListener{}
+	handler, err := New(context.Background(), statusHandler(http.StatusServiceUnavailable), config.Retry{Attempts: 3, StatusCodes: []string{"503"}, Budget: &config.RetryBudget{MinConcurrency: 1}}, nil, retryListener, "traefikTest")
+	require.NoError(t, err)
+
+	// Another request is already being retried.
+	atomic.AddInt64(&handler.(*retry).budget.counters.retries, 1)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://localhost:3000/ok", nil))
+
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+	assert.Equal(t, 0, retryListener.timesCalled)
+}
+
+func TestRetry_sharedBudget(t *testing.T) {
+	budgets := NewBudgets()
+	conf := config.Retry{Attempts: 3, StatusCodes: []string{"503"}, Budget: &config.RetryBudget{MinConcurrency: 1}}
+
+	// The middlewares of the same service, even once rebuilt by a configuration reload, share the budget.
+	previous, err := New(context.Background(), statusHandler(http.StatusServiceUnavailable), conf, budgets.Get("foo@file"), &countingRetryListener{}, "traefikTest")
+	require.NoError(t, err)
+	atomic.AddInt64(&previous.(*retry).budget.counters.retries, 1)
+
+	retryListener := &countingRetryListener{}
+	handler, err := New(context.Background(), statusHandler(http.StatusServiceUnavailable), conf, budgets.Get("foo@file"), retryListener, "traefikTest")
+	require.NoError(t, err)
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://localhost:3000/ok", nil))
+	assert.Equal(t, 0, retryListener.timesCalled)
+
+	// Another service has its own budget.
+	retryListener = &countingRetryListener{}
+	handler, err = New(context.Background(), statusHandler(http.StatusServiceUnavailable), conf, budgets.Get("bar@file"), retryListener, "traefikTest")
+	require.NoError(t, err)
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://localhost:3000/ok", nil))
+	assert.Equal(t, 1, retryListener.timesCalled)
+}
+
+func TestBudgets_Prune(t *testing.T) {
+	budgets := NewBudgets()
+	foo := budgets.Get("foo@file")
+	budgets.Get("bar@file")
+
+	budgets.Prune(map[string]*config.ServiceInfo{"foo@file": {}})
+
+	assert.True(t, foo == budgets.Get("foo@file"))
+	assert.NotContains(t, budgets.counters, "bar@file")
+}
+
+type collectingRetryMetrics struct {
+	counter *testhelpers.CollectingCounter
+}
+
+func (m collectingRetryMetrics) BackendRetriesCounter() gokitmetrics.Counter {
+	return m.counter
+}
+
+func TestMetricsListener(t *testing.T) {
+	counter := &testhelpers.CollectingCounter{}
+	listener := NewMetricsListener(collectingRetryMetrics{counter: counter}, "foo@file")
+
+	retry, err := New(context.Background(), statusHandler(http.StatusBadGateway, http.StatusBadGateway), config.Retry{Attempts: 3, StatusCodes: []string{"502"}}, nil, listener, "traefikTest")
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	retry.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://localhost:3000/ok", nil))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, float64(2), counter.CounterValue)
+	assert.Equal(t, []string{"backend", "foo@file"}, counter.LastLabelValues)
+}
@@ -0,0 +1,141 @@
+package inflightconn
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+
+	"github.com/containous/traefik/pkg/config"
+	"github.com/containous/traefik/pkg/middlewares"
+	"github.com/containous/traefik/pkg/tcp"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	typeName = "InFlightConnTCP"
+)
+
+// Counters holds the connections in flight from each source IP,
+// shared by all the instances of a middleware.
+type Counters struct {
+	mu          sync.Mutex
+	connections map[string]int64 // keyed by source IP
+}
+
+// NewCounters creates a new Counters.
+func NewCounters() *Counters {
+	return &Counters{connections: make(map[string]int64)}
+}
+
+// SharedCounters holds the counters of each middleware,
+// so that they are shared by all the instances of the middleware, and kept across the configuration reloads.
+type SharedCounters struct {
+	mu       sync.Mutex
+	counters map[string]*Counters
+}
+
+// NewSharedCounters creates a new SharedCounters.
+func NewSharedCounters() *SharedCounters {
+	return &SharedCounters{counters: make(map[string]*Counters)}
+}
+
+// Get returns the counters of the given middleware, new counters if the middleware is unknown.
+func (s *SharedCounters) Get(middlewareName string) *Counters {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	counters, ok := s.counters[middlewareName]
+	if !ok {
+		counters = NewCounters()
+		s.counters[middlewareName] = counters
+	}
+	return counters
+}
+
+// Prune forgets the counters of the middlewares which are not part of the given configurations.
+// The connections still in flight through a removed middleware are released on counters which are not used anymore.
+func (s *SharedCounters) Prune(middlewares map[string]*config.TCPMiddlewareInfo) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for middlewareName := range s.counters {
+		if _, ok := middlewares[middlewareName]; !ok {
+			delete(s.counters, middlewareName)
+		}
+	}
+}
+
+// inFlightConn is a middleware limiting the number of simultaneous connections from each source IP.
+type inFlightConn struct {
+	next     tcp.Handler
+	max      int64
+	logger   logrus.FieldLogger
+	counters *Counters
+}
+
+// New creates a TCP in flight connection middleware.
+// The counters are shared with the other instances of the middleware, new counters are used if nil.
+func New(ctx context.Context, next tcp.Handler, config config.TCPInFlightConn, counters *Counters, name string) (tcp.Handler, error) {
+	logger := middlewares.GetLogger(ctx, name, typeName)
+	logger.Debug("Creating middleware")
+
+	if config.Amount <= 0 {
+		return nil, fmt.Errorf("invalid amount %d, it must be greater than 0", config.Amount)
+	}
+
+	if counters == nil {
+		counters = NewCounters()
+	}
+
+	return &inFlightConn{
+		next:     next,
+		max:      config.Amount,
+		logger:   logger,
+		counters: counters,
+	}, nil
+}
+
+func (i *inFlightConn) ServeTCP(conn net.Conn) {
+	ip, _, err := net.SplitHostPort(conn.RemoteAddr().String())
+	if err != nil {
+		i.logger.Errorf("Cannot parse IP from remote addr %s: %v", conn.RemoteAddr(), err)
+		conn.Close()
+		return
+	}
+
+	if !i.increment(ip) {
+		i.logger.Debugf("Rejecting connection from %s: more than %d connections", ip, i.max)
+		tcp.RecordCloseReason(conn, tcp.CloseReasonRejected)
+		conn.Close()
+		return
+	}
+	defer i.decrement(ip)
+
+	i.next.ServeTCP(conn)
+}
+
+// increment counts a new connection from the IP, unless the maximum is reached.
+func (i *inFlightConn) increment(ip string) bool {
+	c := i.counters
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.connections[ip] >= i.max {
+		return false
+	}
+
+	c.connections[ip]++
+	return true
+}
+
+func (i *inFlightConn) decrement(ip string) {
+	c := i.counters
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.connections[ip]--
+	if c.connections[ip] <= 0 {
+		delete(c.connections, ip)
+	}
+}
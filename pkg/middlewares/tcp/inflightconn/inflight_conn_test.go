@@ -0,0 +1,128 @@
+package inflightconn
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/containous/traefik/pkg/config"
+	"github.com/containous/traefik/pkg/tcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeConn struct {
+	net.Conn
+	remoteAddr string
+	closed     chan struct{}
+}
+
+func newFakeConn(remoteAddr string) *fakeConn {
+	return &fakeConn{remoteAddr: remoteAddr, closed: make(chan struct{})}
+}
+
+func (c *fakeConn) RemoteAddr() net.Addr {
+	addr, _ := net.ResolveTCPAddr("tcp", c.remoteAddr)
+	return addr
+}
+
+func (c *fakeConn) Close() error {
+	close(c.closed)
+	return nil
+}
+
+func TestNew_invalidAmount(t *testing.T) {
+	_, err := New(context.Background(), tcp.HandlerFunc(func(conn net.Conn) {}), config.TCPInFlightConn{}, nil, "traefikTest")
+	assert.Error(t, err)
+}
+
+func TestInFlightConn_ServeTCP(t *testing.T) {
+	served := make(chan string)
+	release := make(chan struct{})
+	next := tcp.HandlerFunc(func(conn net.Conn) {
+		served <- conn.RemoteAddr().String()
+		<-release
+	})
+
+	middleware, err := New(context.Background(), next, config.TCPInFlightConn{Amount: 2}, nil, "traefikTest")
+	require.NoError(t, err)
+
+	done := make(chan struct{})
+	for _, addr := range []string{"10.0.0.1:1000", "10.0.0.1:1001", "10.0.0.2:1000"} {
+		addr := addr
+		go func() {
+			middleware.ServeTCP(newFakeConn(addr))
+			done <- struct{}{}
+		}()
+		assert.Equal(t, addr, <-served)
+	}
+
+	// The third connection from the same IP is rejected.
+	rejected := newFakeConn("10.0.0.1:1002")
+	middleware.ServeTCP(rejected)
+	select {
+	case <-rejected.closed:
+	default:
+		t.Fatal("the connection has not been closed")
+	}
+
+	close(release)
+	for i := 0; i < 3; i++ {
+		<-done
+	}
+
+	// Once the connections are closed, a new connection is accepted.
+	release = make(chan struct{})
+	close(release)
+	go middleware.ServeTCP(newFakeConn("10.0.0.1:1003"))
+	assert.Equal(t, "10.0.0.1:1003", <-served)
+}
+
+func TestInFlightConn_sharedCounters(t *testing.T) {
+	served := make(chan struct{})
+	release := make(chan struct{})
+	next := tcp.HandlerFunc(func(conn net.Conn) {
+		served <- struct{}{}
+		<-release
+	})
+
+	counters := NewSharedCounters()
+
+	// The instance built with the former configuration keeps its connection open.
+	former, err := New(context.Background(), next, config.TCPInFlightConn{Amount: 1}, counters.Get("foo"), "foo")
+	require.NoError(t, err)
+
+	done := make(chan struct{})
+	go func() {
+		former.ServeTCP(newFakeConn("10.0.0.1:1000"))
+		close(done)
+	}()
+	<-served
+
+	// The instance built with the new configuration sees the connection.
+	current, err := New(context.Background(), next, config.TCPInFlightConn{Amount: 1}, counters.Get("foo"), "foo")
+	require.NoError(t, err)
+
+	rejected := newFakeConn("10.0.0.1:1001")
+	current.ServeTCP(rejected)
+	select {
+	case <-rejected.closed:
+	default:
+		t.Fatal("the connection has not been closed")
+	}
+
+	close(release)
+	<-done
+}
+
+func TestSharedCounters_Prune(t *testing.T) {
+	counters := NewSharedCounters()
+
+	foo := counters.Get("foo")
+	bar := counters.Get("bar")
+
+	counters.Prune(map[string]*config.TCPMiddlewareInfo{"foo": {}})
+
+	assert.True(t, foo == counters.Get("foo"))
+	assert.False(t, bar == counters.Get("bar"))
+}
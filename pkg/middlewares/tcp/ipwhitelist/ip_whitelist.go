@@ -0,0 +1,62 @@
+package ipwhitelist
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/containous/traefik/pkg/config"
+	"github.com/containous/traefik/pkg/ip"
+	"github.com/containous/traefik/pkg/middlewares"
+	"github.com/containous/traefik/pkg/tcp"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	typeName = "IPWhiteListerTCP"
+)
+
+// ipWhiteLister is a middleware that provides Checks of the connecting IP against a set of Whitelists
+type ipWhiteLister struct {
+	next        tcp.Handler
+	whiteLister *ip.Checker
+	logger      logrus.FieldLogger
+}
+
+// New builds a new TCP IPWhiteLister given a list of CIDR-Strings to whitelist
+func New(ctx context.Context, next tcp.Handler, config config.TCPIPWhiteList, name string) (tcp.Handler, error) {
+	logger := middlewares.GetLogger(ctx, name, typeName)
+	logger.Debug("Creating middleware")
+
+	if len(config.SourceRange) == 0 {
+		return nil, errors.New("sourceRange is empty, IPWhiteLister not created")
+	}
+
+	checker, err := ip.NewChecker(config.SourceRange)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse CIDR whitelist %s: %v", config.SourceRange, err)
+	}
+
+	logger.Debugf("Setting up IPWhiteLister with sourceRange: %s", config.SourceRange)
+	return &ipWhiteLister{
+		whiteLister: checker,
+		next:        next,
+		logger:      logger,
+	}, nil
+}
+
+func (wl *ipWhiteLister) ServeTCP(conn net.Conn) {
+	addr := conn.RemoteAddr().String()
+
+	err := wl.whiteLister.IsAuthorized(addr)
+	if err != nil {
+		wl.logger.Debugf("Rejecting connection from %s: %v", addr, err)
+		tcp.RecordCloseReason(conn, tcp.CloseReasonRejected)
+		conn.Close()
+		return
+	}
+	wl.logger.Debugf("Accept connection from %s", addr)
+
+	wl.next.ServeTCP(conn)
+}
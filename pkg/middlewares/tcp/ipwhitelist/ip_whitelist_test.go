@@ -0,0 +1,126 @@
+package ipwhitelist
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/containous/traefik/pkg/config"
+	"github.com/containous/traefik/pkg/tcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeConn struct {
+	net.Conn
+	remoteAddr string
+	closed     bool
+}
+
+func (c *fakeConn) RemoteAddr() net.Addr {
+	addr, _ := net.ResolveTCPAddr("tcp", c.remoteAddr)
+	return addr
+}
+
+func (c *fakeConn) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestNewIPWhiteLister(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		whiteList     config.TCPIPWhiteList
+		expectedError bool
+	}{
+		{
+			desc:          "empty source range",
+			whiteList:     config.TCPIPWhiteList{},
+			expectedError: true,
+		},
+		{
+			desc: "invalid IP",
+			whiteList: config.TCPIPWhiteList{
+				SourceRange: []string{"foo"},
+			},
+			expectedError: true,
+		},
+		{
+			desc: "valid IP",
+			whiteList: config.TCPIPWhiteList{
+				SourceRange: []string{"10.10.10.10"},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := tcp.HandlerFunc(func(conn net.Conn) {})
+			whiteLister, err := New(context.Background(), next, test.whiteList, "traefikTest")
+
+			if test.expectedError {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.NotNil(t, whiteLister)
+			}
+		})
+	}
+}
+
+func TestIPWhiteLister_ServeTCP(t *testing.T) {
+	testCases := []struct {
+		desc       string
+		whiteList  config.TCPIPWhiteList
+		remoteAddr string
+		expected   bool
+	}{
+		{
+			desc: "authorized with remote address",
+			whiteList: config.TCPIPWhiteList{
+				SourceRange: []string{"20.20.20.20"},
+			},
+			remoteAddr: "20.20.20.20:1234",
+			expected:   true,
+		},
+		{
+			desc: "non authorized with remote address",
+			whiteList: config.TCPIPWhiteList{
+				SourceRange: []string{"20.20.20.20"},
+			},
+			remoteAddr: "20.20.20.21:1234",
+		},
+		{
+			desc: "authorized with remote address in range",
+			whiteList: config.TCPIPWhiteList{
+				SourceRange: []string{"10.0.0.0/8", "2a03:4000:6:d080::/64"},
+			},
+			remoteAddr: "[2a03:4000:6:d080::42]:1234",
+			expected:   true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			served := false
+			next := tcp.HandlerFunc(func(conn net.Conn) {
+				served = true
+			})
+
+			whiteLister, err := New(context.Background(), next, test.whiteList, "traefikTest")
+			require.NoError(t, err)
+
+			conn := &fakeConn{remoteAddr: test.remoteAddr}
+			whiteLister.ServeTCP(conn)
+
+			assert.Equal(t, test.expected, served)
+			assert.Equal(t, !test.expected, conn.closed)
+		})
+	}
+}
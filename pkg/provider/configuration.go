@@ -25,8 +25,13 @@ func Merge(ctx context.Context, configurations map[string]*config.Configuration)
 			Services:    make(map[string]*config.Service),
 		},
 		TCP: &config.TCPConfiguration{
-			Routers:  make(map[string]*config.TCPRouter),
-			Services: make(map[string]*config.TCPService),
+			Routers:     make(map[string]*config.TCPRouter),
+			Middlewares: make(map[string]*config.TCPMiddleware),
+			Services:    make(map[string]*config.TCPService),
+		},
+		UDP: &config.UDPConfiguration{
+			Routers:  make(map[string]*config.UDPRouter),
+			Services: make(map[string]*config.UDPService),
 		},
 	}
 
@@ -42,9 +47,18 @@ func Merge(ctx context.Context, configurations map[string]*config.Configuration)
 	routersTCPToDelete := map[string]struct{}{}
 	routersTCP := map[string][]string{}
 
+	servicesUDPToDelete := map[string]struct{}{}
+	servicesUDP := map[string][]string{}
+
+	routersUDPToDelete := map[string]struct{}{}
+	routersUDP := map[string][]string{}
+
 	middlewaresToDelete := map[string]struct{}{}
 	middlewares := map[string][]string{}
 
+	middlewaresTCPToDelete := map[string]struct{}{}
+	middlewaresTCP := map[string][]string{}
+
 	var sortedKeys []string
 	for key := range configurations {
 		sortedKeys = append(sortedKeys, key)
@@ -81,12 +95,35 @@ func Merge(ctx context.Context, configurations map[string]*config.Configuration)
 			}
 		}
 
+		if conf.UDP != nil {
+			for serviceName, service := range conf.UDP.Services {
+				servicesUDP[serviceName] = append(servicesUDP[serviceName], root)
+				if !AddServiceUDP(configuration.UDP, serviceName, service) {
+					servicesUDPToDelete[serviceName] = struct{}{}
+				}
+			}
+
+			for routerName, router := range conf.UDP.Routers {
+				routersUDP[routerName] = append(routersUDP[routerName], root)
+				if !AddRouterUDP(configuration.UDP, routerName, router) {
+					routersUDPToDelete[routerName] = struct{}{}
+				}
+			}
+		}
+
 		for middlewareName, middleware := range conf.HTTP.Middlewares {
 			middlewares[middlewareName] = append(middlewares[middlewareName], root)
 			if !AddMiddleware(configuration.HTTP, middlewareName, middleware) {
 				middlewaresToDelete[middlewareName] = struct{}{}
 			}
 		}
+
+		for middlewareName, middleware := range conf.TCP.Middlewares {
+			middlewaresTCP[middlewareName] = append(middlewaresTCP[middlewareName], root)
+			if !AddMiddlewareTCP(configuration.TCP, middlewareName, middleware) {
+				middlewaresTCPToDelete[middlewareName] = struct{}{}
+			}
+		}
 	}
 
 	for serviceName := range servicesToDelete {
@@ -113,12 +150,30 @@ func Merge(ctx context.Context, configurations map[string]*config.Configuration)
 		delete(configuration.TCP.Routers, routerName)
 	}
 
+	for serviceName := range servicesUDPToDelete {
+		logger.WithField(log.ServiceName, serviceName).
+			Errorf("Service UDP defined multiple times with different configurations in %v", servicesUDP[serviceName])
+		delete(configuration.UDP.Services, serviceName)
+	}
+
+	for routerName := range routersUDPToDelete {
+		logger.WithField(log.RouterName, routerName).
+			Errorf("Router UDP defined multiple times with different configurations in %v", routersUDP[routerName])
+		delete(configuration.UDP.Routers, routerName)
+	}
+
 	for middlewareName := range middlewaresToDelete {
 		logger.WithField(log.MiddlewareName, middlewareName).
 			Errorf("Middleware defined multiple times with different configurations in %v", middlewares[middlewareName])
 		delete(configuration.HTTP.Middlewares, middlewareName)
 	}
 
+	for middlewareName := range middlewaresTCPToDelete {
+		logger.WithField(log.MiddlewareName, middlewareName).
+			Errorf("Middleware TCP defined multiple times with different configurations in %v", middlewaresTCP[middlewareName])
+		delete(configuration.TCP.Middlewares, middlewareName)
+	}
+
 	return configuration
 }
 
@@ -147,6 +202,41 @@ func AddRouterTCP(configuration *config.TCPConfiguration, routerName string, rou
 	return reflect.DeepEqual(configuration.Routers[routerName], router)
 }
 
+// AddMiddlewareTCP Adds a middleware to a configurations.
+func AddMiddlewareTCP(configuration *config.TCPConfiguration, middlewareName string, middleware *config.TCPMiddleware) bool {
+	if _, ok := configuration.Middlewares[middlewareName]; !ok {
+		configuration.Middlewares[middlewareName] = middleware
+		return true
+	}
+
+	return reflect.DeepEqual(configuration.Middlewares[middlewareName], middleware)
+}
+
+// AddServiceUDP Adds a service to a configurations.
+func AddServiceUDP(configuration *config.UDPConfiguration, serviceName string, service *config.UDPService) bool {
+	if _, ok := configuration.Services[serviceName]; !ok {
+		configuration.Services[serviceName] = service
+		return true
+	}
+
+	if !configuration.Services[serviceName].LoadBalancer.Mergeable(service.LoadBalancer) {
+		return false
+	}
+
+	configuration.Services[serviceName].LoadBalancer.Servers = append(configuration.Services[serviceName].LoadBalancer.Servers, service.LoadBalancer.Servers...)
+	return true
+}
+
+// AddRouterUDP Adds a router to a configurations.
+func AddRouterUDP(configuration *config.UDPConfiguration, routerName string, router *config.UDPRouter) bool {
+	if _, ok := configuration.Routers[routerName]; !ok {
+		configuration.Routers[routerName] = router
+		return true
+	}
+
+	return reflect.DeepEqual(configuration.Routers[routerName], router)
+}
+
 // AddService Adds a service to a configurations.
 func AddService(configuration *config.HTTPConfiguration, serviceName string, service *config.Service) bool {
 	if _, ok := configuration.Services[serviceName]; !ok {
@@ -154,6 +244,11 @@ func AddService(configuration *config.HTTPConfiguration, serviceName string, ser
 		return true
 	}
 
+	// Only load balancers can be merged, the other kinds of service must be strictly identical.
+	if configuration.Services[serviceName].LoadBalancer == nil || service.LoadBalancer == nil {
+		return reflect.DeepEqual(configuration.Services[serviceName], service)
+	}
+
 	if !configuration.Services[serviceName].LoadBalancer.Mergeable(service.LoadBalancer) {
 		return false
 	}
@@ -219,6 +314,27 @@ func BuildTCPRouterConfiguration(ctx context.Context, configuration *config.TCPC
 	}
 }
 
+// BuildUDPRouterConfiguration Builds a router configuration.
+func BuildUDPRouterConfiguration(ctx context.Context, configuration *config.UDPConfiguration) {
+	for routerName, router := range configuration.Routers {
+		loggerRouter := log.FromContext(ctx).WithField(log.RouterName, routerName)
+		if len(router.Service) > 0 {
+			continue
+		}
+
+		if len(configuration.Services) > 1 {
+			delete(configuration.Routers, routerName)
+			loggerRouter.
+				Error("Could not define the service name for the router: too many services")
+			continue
+		}
+
+		for serviceName := range configuration.Services {
+			router.Service = serviceName
+		}
+	}
+}
+
 // BuildRouterConfiguration Builds a router configuration.
 func BuildRouterConfiguration(ctx context.Context, configuration *config.HTTPConfiguration, defaultRouterName string, defaultRuleTpl *template.Template, model interface{}) {
 	if len(configuration.Routers) == 0 {
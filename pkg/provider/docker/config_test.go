@@ -43,8 +43,13 @@ func TestDefaultRule(t *testing.T) {
 			defaultRule: "Host(`foo.bar`)",
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers:  map[string]*config.TCPRouter{},
-					Services: map[string]*config.TCPService{},
+					Routers:     map[string]*config.TCPRouter{},
+					Middlewares: map[string]*config.TCPMiddleware{},
+					Services:    map[string]*config.TCPService{},
+				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
 				},
 				HTTP: &config.HTTPConfiguration{
 					Routers: map[string]*config.Router{
@@ -92,8 +97,13 @@ func TestDefaultRule(t *testing.T) {
 			defaultRule: "Host(`{{ .Name }}.foo.bar`)",
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers:  map[string]*config.TCPRouter{},
-					Services: map[string]*config.TCPService{},
+					Routers:     map[string]*config.TCPRouter{},
+					Middlewares: map[string]*config.TCPMiddleware{},
+					Services:    map[string]*config.TCPService{},
+				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
 				},
 				HTTP: &config.HTTPConfiguration{
 					Routers: map[string]*config.Router{
@@ -143,8 +153,13 @@ func TestDefaultRule(t *testing.T) {
 			defaultRule: `Host("{{ .Name }}.{{ index .Labels "traefik.domain" }}")`,
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers:  map[string]*config.TCPRouter{},
-					Services: map[string]*config.TCPService{},
+					Routers:     map[string]*config.TCPRouter{},
+					Middlewares: map[string]*config.TCPMiddleware{},
+					Services:    map[string]*config.TCPService{},
+				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
 				},
 				HTTP: &config.HTTPConfiguration{
 					Routers: map[string]*config.Router{
@@ -192,8 +207,13 @@ func TestDefaultRule(t *testing.T) {
 			defaultRule: `Host("{{ .Toto }}")`,
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers:  map[string]*config.TCPRouter{},
-					Services: map[string]*config.TCPService{},
+					Routers:     map[string]*config.TCPRouter{},
+					Middlewares: map[string]*config.TCPMiddleware{},
+					Services:    map[string]*config.TCPService{},
+				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
 				},
 				HTTP: &config.HTTPConfiguration{
 					Routers:     map[string]*config.Router{},
@@ -236,8 +256,13 @@ func TestDefaultRule(t *testing.T) {
 			defaultRule: ``,
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers:  map[string]*config.TCPRouter{},
-					Services: map[string]*config.TCPService{},
+					Routers:     map[string]*config.TCPRouter{},
+					Middlewares: map[string]*config.TCPMiddleware{},
+					Services:    map[string]*config.TCPService{},
+				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
 				},
 				HTTP: &config.HTTPConfiguration{
 					Routers:     map[string]*config.Router{},
@@ -280,8 +305,13 @@ func TestDefaultRule(t *testing.T) {
 			defaultRule: DefaultTemplateRule,
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers:  map[string]*config.TCPRouter{},
-					Services: map[string]*config.TCPService{},
+					Routers:     map[string]*config.TCPRouter{},
+					Middlewares: map[string]*config.TCPMiddleware{},
+					Services:    map[string]*config.TCPService{},
+				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
 				},
 				HTTP: &config.HTTPConfiguration{
 					Routers: map[string]*config.Router{
@@ -363,8 +393,13 @@ func Test_buildConfiguration(t *testing.T) {
 			},
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers:  map[string]*config.TCPRouter{},
-					Services: map[string]*config.TCPService{},
+					Routers:     map[string]*config.TCPRouter{},
+					Middlewares: map[string]*config.TCPMiddleware{},
+					Services:    map[string]*config.TCPService{},
+				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
 				},
 				HTTP: &config.HTTPConfiguration{
 					Routers: map[string]*config.Router{
@@ -427,8 +462,13 @@ func Test_buildConfiguration(t *testing.T) {
 			},
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers:  map[string]*config.TCPRouter{},
-					Services: map[string]*config.TCPService{},
+					Routers:     map[string]*config.TCPRouter{},
+					Middlewares: map[string]*config.TCPMiddleware{},
+					Services:    map[string]*config.TCPService{},
+				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
 				},
 				HTTP: &config.HTTPConfiguration{
 					Routers: map[string]*config.Router{
@@ -507,8 +547,13 @@ func Test_buildConfiguration(t *testing.T) {
 			},
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers:  map[string]*config.TCPRouter{},
-					Services: map[string]*config.TCPService{},
+					Routers:     map[string]*config.TCPRouter{},
+					Middlewares: map[string]*config.TCPMiddleware{},
+					Services:    map[string]*config.TCPService{},
+				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
 				},
 				HTTP: &config.HTTPConfiguration{
 					Routers: map[string]*config.Router{
@@ -560,8 +605,13 @@ func Test_buildConfiguration(t *testing.T) {
 			},
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers:  map[string]*config.TCPRouter{},
-					Services: map[string]*config.TCPService{},
+					Routers:     map[string]*config.TCPRouter{},
+					Middlewares: map[string]*config.TCPMiddleware{},
+					Services:    map[string]*config.TCPService{},
+				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
 				},
 				HTTP: &config.HTTPConfiguration{
 					Routers: map[string]*config.Router{
@@ -612,8 +662,13 @@ func Test_buildConfiguration(t *testing.T) {
 			},
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers:  map[string]*config.TCPRouter{},
-					Services: map[string]*config.TCPService{},
+					Routers:     map[string]*config.TCPRouter{},
+					Middlewares: map[string]*config.TCPMiddleware{},
+					Services:    map[string]*config.TCPService{},
+				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
 				},
 				HTTP: &config.HTTPConfiguration{
 					Routers: map[string]*config.Router{
@@ -662,8 +717,13 @@ func Test_buildConfiguration(t *testing.T) {
 			},
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers:  map[string]*config.TCPRouter{},
-					Services: map[string]*config.TCPService{},
+					Routers:     map[string]*config.TCPRouter{},
+					Middlewares: map[string]*config.TCPMiddleware{},
+					Services:    map[string]*config.TCPService{},
+				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
 				},
 				HTTP: &config.HTTPConfiguration{
 					Middlewares: map[string]*config.Middleware{},
@@ -713,8 +773,13 @@ func Test_buildConfiguration(t *testing.T) {
 			},
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers:  map[string]*config.TCPRouter{},
-					Services: map[string]*config.TCPService{},
+					Routers:     map[string]*config.TCPRouter{},
+					Middlewares: map[string]*config.TCPMiddleware{},
+					Services:    map[string]*config.TCPService{},
+				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
 				},
 				HTTP: &config.HTTPConfiguration{
 					Routers: map[string]*config.Router{
@@ -765,8 +830,13 @@ func Test_buildConfiguration(t *testing.T) {
 			},
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers:  map[string]*config.TCPRouter{},
-					Services: map[string]*config.TCPService{},
+					Routers:     map[string]*config.TCPRouter{},
+					Middlewares: map[string]*config.TCPMiddleware{},
+					Services:    map[string]*config.TCPService{},
+				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
 				},
 				HTTP: &config.HTTPConfiguration{
 					Routers:     map[string]*config.Router{},
@@ -840,8 +910,13 @@ func Test_buildConfiguration(t *testing.T) {
 			},
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers:  map[string]*config.TCPRouter{},
-					Services: map[string]*config.TCPService{},
+					Routers:     map[string]*config.TCPRouter{},
+					Middlewares: map[string]*config.TCPMiddleware{},
+					Services:    map[string]*config.TCPService{},
+				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
 				},
 				HTTP: &config.HTTPConfiguration{
 					Routers: map[string]*config.Router{
@@ -918,8 +993,13 @@ func Test_buildConfiguration(t *testing.T) {
 			},
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers:  map[string]*config.TCPRouter{},
-					Services: map[string]*config.TCPService{},
+					Routers:     map[string]*config.TCPRouter{},
+					Middlewares: map[string]*config.TCPMiddleware{},
+					Services:    map[string]*config.TCPService{},
+				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
 				},
 				HTTP: &config.HTTPConfiguration{
 					Routers: map[string]*config.Router{
@@ -977,8 +1057,13 @@ func Test_buildConfiguration(t *testing.T) {
 			},
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers:  map[string]*config.TCPRouter{},
-					Services: map[string]*config.TCPService{},
+					Routers:     map[string]*config.TCPRouter{},
+					Middlewares: map[string]*config.TCPMiddleware{},
+					Services:    map[string]*config.TCPService{},
+				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
 				},
 				HTTP: &config.HTTPConfiguration{
 					Routers: map[string]*config.Router{
@@ -1030,8 +1115,13 @@ func Test_buildConfiguration(t *testing.T) {
 			},
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers:  map[string]*config.TCPRouter{},
-					Services: map[string]*config.TCPService{},
+					Routers:     map[string]*config.TCPRouter{},
+					Middlewares: map[string]*config.TCPMiddleware{},
+					Services:    map[string]*config.TCPService{},
+				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
 				},
 				HTTP: &config.HTTPConfiguration{
 					Routers: map[string]*config.Router{
@@ -1107,8 +1197,13 @@ func Test_buildConfiguration(t *testing.T) {
 			},
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers:  map[string]*config.TCPRouter{},
-					Services: map[string]*config.TCPService{},
+					Routers:     map[string]*config.TCPRouter{},
+					Middlewares: map[string]*config.TCPMiddleware{},
+					Services:    map[string]*config.TCPService{},
+				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
 				},
 				HTTP: &config.HTTPConfiguration{
 					Routers: map[string]*config.Router{
@@ -1187,8 +1282,13 @@ func Test_buildConfiguration(t *testing.T) {
 			},
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers:  map[string]*config.TCPRouter{},
-					Services: map[string]*config.TCPService{},
+					Routers:     map[string]*config.TCPRouter{},
+					Middlewares: map[string]*config.TCPMiddleware{},
+					Services:    map[string]*config.TCPService{},
+				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
 				},
 				HTTP: &config.HTTPConfiguration{
 					Routers: map[string]*config.Router{
@@ -1279,8 +1379,13 @@ func Test_buildConfiguration(t *testing.T) {
 			},
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers:  map[string]*config.TCPRouter{},
-					Services: map[string]*config.TCPService{},
+					Routers:     map[string]*config.TCPRouter{},
+					Middlewares: map[string]*config.TCPMiddleware{},
+					Services:    map[string]*config.TCPService{},
+				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
 				},
 				HTTP: &config.HTTPConfiguration{
 					Routers: map[string]*config.Router{
@@ -1355,8 +1460,13 @@ func Test_buildConfiguration(t *testing.T) {
 			},
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers:  map[string]*config.TCPRouter{},
-					Services: map[string]*config.TCPService{},
+					Routers:     map[string]*config.TCPRouter{},
+					Middlewares: map[string]*config.TCPMiddleware{},
+					Services:    map[string]*config.TCPService{},
+				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
 				},
 				HTTP: &config.HTTPConfiguration{
 					Routers:     map[string]*config.Router{},
@@ -1442,8 +1552,13 @@ func Test_buildConfiguration(t *testing.T) {
 			},
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers:  map[string]*config.TCPRouter{},
-					Services: map[string]*config.TCPService{},
+					Routers:     map[string]*config.TCPRouter{},
+					Middlewares: map[string]*config.TCPMiddleware{},
+					Services:    map[string]*config.TCPService{},
+				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
 				},
 				HTTP: &config.HTTPConfiguration{
 					Routers:     map[string]*config.Router{},
@@ -1513,8 +1628,13 @@ func Test_buildConfiguration(t *testing.T) {
 			},
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers:  map[string]*config.TCPRouter{},
-					Services: map[string]*config.TCPService{},
+					Routers:     map[string]*config.TCPRouter{},
+					Middlewares: map[string]*config.TCPMiddleware{},
+					Services:    map[string]*config.TCPService{},
+				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
 				},
 				HTTP: &config.HTTPConfiguration{
 					Routers: map[string]*config.Router{
@@ -1584,8 +1704,13 @@ func Test_buildConfiguration(t *testing.T) {
 			},
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers:  map[string]*config.TCPRouter{},
-					Services: map[string]*config.TCPService{},
+					Routers:     map[string]*config.TCPRouter{},
+					Middlewares: map[string]*config.TCPMiddleware{},
+					Services:    map[string]*config.TCPService{},
+				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
 				},
 				HTTP: &config.HTTPConfiguration{
 					Routers:     map[string]*config.Router{},
@@ -1639,8 +1764,13 @@ func Test_buildConfiguration(t *testing.T) {
 			},
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers:  map[string]*config.TCPRouter{},
-					Services: map[string]*config.TCPService{},
+					Routers:     map[string]*config.TCPRouter{},
+					Middlewares: map[string]*config.TCPMiddleware{},
+					Services:    map[string]*config.TCPService{},
+				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
 				},
 				HTTP: &config.HTTPConfiguration{
 					Routers: map[string]*config.Router{
@@ -1690,8 +1820,13 @@ func Test_buildConfiguration(t *testing.T) {
 			},
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers:  map[string]*config.TCPRouter{},
-					Services: map[string]*config.TCPService{},
+					Routers:     map[string]*config.TCPRouter{},
+					Middlewares: map[string]*config.TCPMiddleware{},
+					Services:    map[string]*config.TCPService{},
+				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
 				},
 				HTTP: &config.HTTPConfiguration{
 					Routers: map[string]*config.Router{
@@ -1716,6 +1851,64 @@ func Test_buildConfiguration(t *testing.T) {
 				},
 			},
 		},
+		{
+			desc: "one container with label weight and labels",
+			containers: []dockerData{
+				{
+					ServiceName: "Test",
+					Name:        "Test",
+					Labels: map[string]string{
+						"traefik.http.services.Service1.LoadBalancer.server.weight":      "3",
+						"traefik.http.services.Service1.LoadBalancer.server.labels.zone": "eu",
+					},
+					NetworkSettings: networkSettings{
+						Ports: nat.PortMap{
+							nat.Port("80/tcp"): []nat.PortBinding{},
+						},
+						Networks: map[string]*networkData{
+							"bridge": {
+								Name: "bridge",
+								Addr: "127.0.0.1",
+							},
+						},
+					},
+				},
+			},
+			expected: &config.Configuration{
+				TCP: &config.TCPConfiguration{
+					Routers:     map[string]*config.TCPRouter{},
+					Middlewares: map[string]*config.TCPMiddleware{},
+					Services:    map[string]*config.TCPService{},
+				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
+				},
+				HTTP: &config.HTTPConfiguration{
+					Routers: map[string]*config.Router{
+						"Test": {
+							Service: "Service1",
+							Rule:    "Host(`Test.traefik.wtf`)",
+						},
+					},
+					Middlewares: map[string]*config.Middleware{},
+					Services: map[string]*config.Service{
+						"Service1": {
+							LoadBalancer: &config.LoadBalancerService{
+								Servers: []config.Server{
+									{
+										URL:    "http://127.0.0.1:80",
+										Weight: 3,
+										Labels: map[string]string{"zone": "eu"},
+									},
+								},
+								PassHostHeader: true,
+							},
+						},
+					},
+				},
+			},
+		},
 		{
 			desc: "one container with label port on two services",
 			containers: []dockerData{
@@ -1741,8 +1934,13 @@ func Test_buildConfiguration(t *testing.T) {
 			},
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers:  map[string]*config.TCPRouter{},
-					Services: map[string]*config.TCPService{},
+					Routers:     map[string]*config.TCPRouter{},
+					Middlewares: map[string]*config.TCPMiddleware{},
+					Services:    map[string]*config.TCPService{},
+				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
 				},
 				HTTP: &config.HTTPConfiguration{
 					Routers:     map[string]*config.Router{},
@@ -1792,8 +1990,13 @@ func Test_buildConfiguration(t *testing.T) {
 			},
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers:  map[string]*config.TCPRouter{},
-					Services: map[string]*config.TCPService{},
+					Routers:     map[string]*config.TCPRouter{},
+					Middlewares: map[string]*config.TCPMiddleware{},
+					Services:    map[string]*config.TCPService{},
+				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
 				},
 				HTTP: &config.HTTPConfiguration{
 					Routers:     map[string]*config.Router{},
@@ -1824,8 +2027,13 @@ func Test_buildConfiguration(t *testing.T) {
 			},
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers:  map[string]*config.TCPRouter{},
-					Services: map[string]*config.TCPService{},
+					Routers:     map[string]*config.TCPRouter{},
+					Middlewares: map[string]*config.TCPMiddleware{},
+					Services:    map[string]*config.TCPService{},
+				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
 				},
 				HTTP: &config.HTTPConfiguration{
 					Routers:     map[string]*config.Router{},
@@ -1858,8 +2066,13 @@ func Test_buildConfiguration(t *testing.T) {
 			},
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers:  map[string]*config.TCPRouter{},
-					Services: map[string]*config.TCPService{},
+					Routers:     map[string]*config.TCPRouter{},
+					Middlewares: map[string]*config.TCPMiddleware{},
+					Services:    map[string]*config.TCPService{},
+				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
 				},
 				HTTP: &config.HTTPConfiguration{
 					Routers:     map[string]*config.Router{},
@@ -1891,8 +2104,13 @@ func Test_buildConfiguration(t *testing.T) {
 			},
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers:  map[string]*config.TCPRouter{},
-					Services: map[string]*config.TCPService{},
+					Routers:     map[string]*config.TCPRouter{},
+					Middlewares: map[string]*config.TCPMiddleware{},
+					Services:    map[string]*config.TCPService{},
+				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
 				},
 				HTTP: &config.HTTPConfiguration{
 					Routers:     map[string]*config.Router{},
@@ -1926,8 +2144,13 @@ func Test_buildConfiguration(t *testing.T) {
 			constraints: `Label("traefik.tags", "bar")`,
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers:  map[string]*config.TCPRouter{},
-					Services: map[string]*config.TCPService{},
+					Routers:     map[string]*config.TCPRouter{},
+					Middlewares: map[string]*config.TCPMiddleware{},
+					Services:    map[string]*config.TCPService{},
+				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
 				},
 				HTTP: &config.HTTPConfiguration{
 					Routers:     map[string]*config.Router{},
@@ -1961,8 +2184,13 @@ func Test_buildConfiguration(t *testing.T) {
 			constraints: `Label("traefik.tags", "foo")`,
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers:  map[string]*config.TCPRouter{},
-					Services: map[string]*config.TCPService{},
+					Routers:     map[string]*config.TCPRouter{},
+					Middlewares: map[string]*config.TCPMiddleware{},
+					Services:    map[string]*config.TCPService{},
+				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
 				},
 				HTTP: &config.HTTPConfiguration{
 					Routers: map[string]*config.Router{
@@ -2012,8 +2240,13 @@ func Test_buildConfiguration(t *testing.T) {
 			},
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers:  map[string]*config.TCPRouter{},
-					Services: map[string]*config.TCPService{},
+					Routers:     map[string]*config.TCPRouter{},
+					Middlewares: map[string]*config.TCPMiddleware{},
+					Services:    map[string]*config.TCPService{},
+				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
 				},
 				HTTP: &config.HTTPConfiguration{
 					Routers: map[string]*config.Router{
@@ -2080,6 +2313,7 @@ func Test_buildConfiguration(t *testing.T) {
 							TLS:     &config.RouterTCPTLSConfig{},
 						},
 					},
+					Middlewares: map[string]*config.TCPMiddleware{},
 					Services: map[string]*config.TCPService{
 						"Test": {
 							LoadBalancer: &config.TCPLoadBalancerService{
@@ -2092,6 +2326,75 @@ func Test_buildConfiguration(t *testing.T) {
 						},
 					},
 				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
+				},
+				HTTP: &config.HTTPConfiguration{
+					Routers:     map[string]*config.Router{},
+					Middlewares: map[string]*config.Middleware{},
+					Services:    map[string]*config.Service{},
+				},
+			},
+		},
+		{
+			desc: "tcp with middleware",
+			containers: []dockerData{
+				{
+					ServiceName: "Test",
+					Name:        "Test",
+					Labels: map[string]string{
+						"traefik.tcp.routers.foo.rule":                              "HostSNI(`foo.bar`)",
+						"traefik.tcp.routers.foo.tls":                               "true",
+						"traefik.tcp.routers.foo.middlewares":                       "whitelist",
+						"traefik.tcp.middlewares.whitelist.ipwhitelist.sourcerange": "10.0.0.0/8",
+					},
+					NetworkSettings: networkSettings{
+						Ports: nat.PortMap{
+							nat.Port("80/tcp"): []nat.PortBinding{},
+						},
+						Networks: map[string]*networkData{
+							"bridge": {
+								Name: "bridge",
+								Addr: "127.0.0.1",
+							},
+						},
+					},
+				},
+			},
+			expected: &config.Configuration{
+				TCP: &config.TCPConfiguration{
+					Routers: map[string]*config.TCPRouter{
+						"foo": {
+							Service:     "Test",
+							Middlewares: []string{"whitelist"},
+							Rule:        "HostSNI(`foo.bar`)",
+							TLS:         &config.RouterTCPTLSConfig{},
+						},
+					},
+					Middlewares: map[string]*config.TCPMiddleware{
+						"whitelist": {
+							IPWhiteList: &config.TCPIPWhiteList{
+								SourceRange: []string{"10.0.0.0/8"},
+							},
+						},
+					},
+					Services: map[string]*config.TCPService{
+						"Test": {
+							LoadBalancer: &config.TCPLoadBalancerService{
+								Servers: []config.TCPServer{
+									{
+										Address: "127.0.0.1:80",
+									},
+								},
+							},
+						},
+					},
+				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
+				},
 				HTTP: &config.HTTPConfiguration{
 					Routers:     map[string]*config.Router{},
 					Middlewares: map[string]*config.Middleware{},
@@ -2123,7 +2426,8 @@ func Test_buildConfiguration(t *testing.T) {
 			},
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers: map[string]*config.TCPRouter{},
+					Routers:     map[string]*config.TCPRouter{},
+					Middlewares: map[string]*config.TCPMiddleware{},
 					Services: map[string]*config.TCPService{
 						"Test": {
 							LoadBalancer: &config.TCPLoadBalancerService{
@@ -2136,6 +2440,10 @@ func Test_buildConfiguration(t *testing.T) {
 						},
 					},
 				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
+				},
 				HTTP: &config.HTTPConfiguration{
 					Routers:     map[string]*config.Router{},
 					Middlewares: map[string]*config.Middleware{},
@@ -2178,6 +2486,7 @@ func Test_buildConfiguration(t *testing.T) {
 							},
 						},
 					},
+					Middlewares: map[string]*config.TCPMiddleware{},
 					Services: map[string]*config.TCPService{
 						"foo": {
 							LoadBalancer: &config.TCPLoadBalancerService{
@@ -2190,6 +2499,10 @@ func Test_buildConfiguration(t *testing.T) {
 						},
 					},
 				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
+				},
 				HTTP: &config.HTTPConfiguration{
 					Routers:     map[string]*config.Router{},
 					Middlewares: map[string]*config.Middleware{},
@@ -2253,6 +2566,7 @@ func Test_buildConfiguration(t *testing.T) {
 							TLS:     &config.RouterTCPTLSConfig{},
 						},
 					},
+					Middlewares: map[string]*config.TCPMiddleware{},
 					Services: map[string]*config.TCPService{
 						"foo": {
 							LoadBalancer: &config.TCPLoadBalancerService{
@@ -2268,6 +2582,10 @@ func Test_buildConfiguration(t *testing.T) {
 						},
 					},
 				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
+				},
 				HTTP: &config.HTTPConfiguration{
 					Routers: map[string]*config.Router{
 						"Test": {
@@ -2318,7 +2636,8 @@ func Test_buildConfiguration(t *testing.T) {
 			},
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers: map[string]*config.TCPRouter{},
+					Routers:     map[string]*config.TCPRouter{},
+					Middlewares: map[string]*config.TCPMiddleware{},
 					Services: map[string]*config.TCPService{
 						"foo": {
 							LoadBalancer: &config.TCPLoadBalancerService{
@@ -2331,6 +2650,141 @@ func Test_buildConfiguration(t *testing.T) {
 						},
 					},
 				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
+				},
+				HTTP: &config.HTTPConfiguration{
+					Routers:     map[string]*config.Router{},
+					Middlewares: map[string]*config.Middleware{},
+					Services:    map[string]*config.Service{},
+				},
+			},
+		},
+		{
+			desc: "udp with label",
+			containers: []dockerData{
+				{
+					ServiceName: "Test",
+					Name:        "Test",
+					Labels: map[string]string{
+						"traefik.udp.routers.foo.entrypoints": "dns",
+					},
+					NetworkSettings: networkSettings{
+						Ports: nat.PortMap{
+							nat.Port("53/udp"): []nat.PortBinding{},
+						},
+						Networks: map[string]*networkData{
+							"bridge": {
+								Name: "bridge",
+								Addr: "127.0.0.1",
+							},
+						},
+					},
+				},
+			},
+			expected: &config.Configuration{
+				TCP: &config.TCPConfiguration{
+					Routers:     map[string]*config.TCPRouter{},
+					Middlewares: map[string]*config.TCPMiddleware{},
+					Services:    map[string]*config.TCPService{},
+				},
+				UDP: &config.UDPConfiguration{
+					Routers: map[string]*config.UDPRouter{
+						"foo": {
+							EntryPoints: []string{"dns"},
+							Service:     "Test",
+						},
+					},
+					Services: map[string]*config.UDPService{
+						"Test": {
+							LoadBalancer: &config.UDPLoadBalancerService{
+								Servers: []config.UDPServer{
+									{
+										Address: "127.0.0.1:53",
+									},
+								},
+							},
+						},
+					},
+				},
+				HTTP: &config.HTTPConfiguration{
+					Routers:     map[string]*config.Router{},
+					Middlewares: map[string]*config.Middleware{},
+					Services:    map[string]*config.Service{},
+				},
+			},
+		},
+		{
+			desc: "udp with label and port on two containers",
+			containers: []dockerData{
+				{
+					ServiceName: "Test",
+					Name:        "Test",
+					Labels: map[string]string{
+						"traefik.udp.routers.foo.service":                   "foo",
+						"traefik.udp.services.foo.loadbalancer.server.port": "514",
+					},
+					NetworkSettings: networkSettings{
+						Ports: nat.PortMap{
+							nat.Port("80/tcp"): []nat.PortBinding{},
+						},
+						Networks: map[string]*networkData{
+							"bridge": {
+								Name: "bridge",
+								Addr: "127.0.0.1",
+							},
+						},
+					},
+				},
+				{
+					ID:          "2",
+					ServiceName: "Test",
+					Name:        "Test",
+					Labels: map[string]string{
+						"traefik.udp.routers.foo.service":                   "foo",
+						"traefik.udp.services.foo.loadbalancer.server.port": "514",
+					},
+					NetworkSettings: networkSettings{
+						Ports: nat.PortMap{
+							nat.Port("80/tcp"): []nat.PortBinding{},
+						},
+						Networks: map[string]*networkData{
+							"bridge": {
+								Name: "bridge",
+								Addr: "127.0.0.2",
+							},
+						},
+					},
+				},
+			},
+			expected: &config.Configuration{
+				TCP: &config.TCPConfiguration{
+					Routers:     map[string]*config.TCPRouter{},
+					Middlewares: map[string]*config.TCPMiddleware{},
+					Services:    map[string]*config.TCPService{},
+				},
+				UDP: &config.UDPConfiguration{
+					Routers: map[string]*config.UDPRouter{
+						"foo": {
+							Service: "foo",
+						},
+					},
+					Services: map[string]*config.UDPService{
+						"foo": {
+							LoadBalancer: &config.UDPLoadBalancerService{
+								Servers: []config.UDPServer{
+									{
+										Address: "127.0.0.1:514",
+									},
+									{
+										Address: "127.0.0.2:514",
+									},
+								},
+							},
+						},
+					},
+				},
 				HTTP: &config.HTTPConfiguration{
 					Routers:     map[string]*config.Router{},
 					Middlewares: map[string]*config.Middleware{},
@@ -231,13 +231,19 @@ func (p *Provider) loadFileConfigFromDirectory(ctx context.Context, directory st
 	if configuration == nil {
 		configuration = &config.Configuration{
 			HTTP: &config.HTTPConfiguration{
-				Routers:     make(map[string]*config.Router),
-				Middlewares: make(map[string]*config.Middleware),
-				Services:    make(map[string]*config.Service),
+				Routers:           make(map[string]*config.Router),
+				Middlewares:       make(map[string]*config.Middleware),
+				Services:          make(map[string]*config.Service),
+				ServersTransports: make(map[string]*config.ServersTransport),
 			},
 			TCP: &config.TCPConfiguration{
-				Routers:  make(map[string]*config.TCPRouter),
-				Services: make(map[string]*config.TCPService),
+				Routers:     make(map[string]*config.TCPRouter),
+				Middlewares: make(map[string]*config.TCPMiddleware),
+				Services:    make(map[string]*config.TCPService),
+			},
+			UDP: &config.UDPConfiguration{
+				Routers:  make(map[string]*config.UDPRouter),
+				Services: make(map[string]*config.UDPService),
 			},
 		}
 	}
@@ -286,6 +292,14 @@ func (p *Provider) loadFileConfigFromDirectory(ctx context.Context, directory st
 			}
 		}
 
+		for name, conf := range c.HTTP.ServersTransports {
+			if _, exists := configuration.HTTP.ServersTransports[name]; exists {
+				logger.Warnf("HTTP servers transport %s already configured, skipping", name)
+			} else {
+				configuration.HTTP.ServersTransports[name] = conf
+			}
+		}
+
 		for name, conf := range c.TCP.Routers {
 			if _, exists := configuration.TCP.Routers[name]; exists {
 				logger.WithField(log.RouterName, name).Warn("TCP router already configured, skipping")
@@ -294,6 +308,14 @@ func (p *Provider) loadFileConfigFromDirectory(ctx context.Context, directory st
 			}
 		}
 
+		for name, conf := range c.TCP.Middlewares {
+			if _, exists := configuration.TCP.Middlewares[name]; exists {
+				logger.WithField(log.MiddlewareName, name).Warn("TCP middleware already configured, skipping")
+			} else {
+				configuration.TCP.Middlewares[name] = conf
+			}
+		}
+
 		for name, conf := range c.TCP.Services {
 			if _, exists := configuration.TCP.Services[name]; exists {
 				logger.WithField(log.ServiceName, name).Warn("TCP service already configured, skipping")
@@ -302,6 +324,22 @@ func (p *Provider) loadFileConfigFromDirectory(ctx context.Context, directory st
 			}
 		}
 
+		for name, conf := range c.UDP.Routers {
+			if _, exists := configuration.UDP.Routers[name]; exists {
+				logger.WithField(log.RouterName, name).Warn("UDP router already configured, skipping")
+			} else {
+				configuration.UDP.Routers[name] = conf
+			}
+		}
+
+		for name, conf := range c.UDP.Services {
+			if _, exists := configuration.UDP.Services[name]; exists {
+				logger.WithField(log.ServiceName, name).Warn("UDP service already configured, skipping")
+			} else {
+				configuration.UDP.Services[name] = conf
+			}
+		}
+
 		for _, conf := range c.TLS {
 			if _, exists := configTLSMaps[conf]; exists {
 				logger.Warnf("TLS configuration %v already configured, skipping", conf)
@@ -356,8 +394,13 @@ func (p *Provider) DecodeConfiguration(content string) (*config.Configuration, e
 			Services:    make(map[string]*config.Service),
 		},
 		TCP: &config.TCPConfiguration{
-			Routers:  make(map[string]*config.TCPRouter),
-			Services: make(map[string]*config.TCPService),
+			Routers:     make(map[string]*config.TCPRouter),
+			Middlewares: make(map[string]*config.TCPMiddleware),
+			Services:    make(map[string]*config.TCPService),
+		},
+		UDP: &config.UDPConfiguration{
+			Routers:  make(map[string]*config.UDPRouter),
+			Services: make(map[string]*config.UDPService),
 		},
 		TLS:        make([]*tls.Configuration, 0),
 		TLSStores:  make(map[string]tls.Store),
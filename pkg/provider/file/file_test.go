@@ -23,6 +23,8 @@ type ProvideTestCase struct {
 	expectedNumRouter  int
 	expectedNumService int
 	expectedNumTLSConf int
+
+	expectedNumServersTransport int
 }
 
 func TestProvideWithoutWatch(t *testing.T) {
@@ -45,6 +47,7 @@ func TestProvideWithoutWatch(t *testing.T) {
 				assert.Len(t, conf.Configuration.HTTP.Services, test.expectedNumService)
 				assert.Len(t, conf.Configuration.HTTP.Routers, test.expectedNumRouter)
 				assert.Len(t, conf.Configuration.TLS, test.expectedNumTLSConf)
+				assert.Len(t, conf.Configuration.HTTP.ServersTransports, test.expectedNumServersTransport)
 			case <-timeout:
 				t.Errorf("timeout while waiting for config")
 			}
@@ -93,7 +96,7 @@ func TestProvideWithWatch(t *testing.T) {
 			}
 
 			timeout = time.After(time.Second * 1)
-			var numUpdates, numServices, numRouters, numTLSConfs int
+			var numUpdates, numServices, numRouters, numTLSConfs, numServersTransports int
 			for {
 				select {
 				case conf := <-configChan:
@@ -101,9 +104,10 @@ func TestProvideWithWatch(t *testing.T) {
 					numServices = len(conf.Configuration.HTTP.Services)
 					numRouters = len(conf.Configuration.HTTP.Routers)
 					numTLSConfs = len(conf.Configuration.TLS)
-					t.Logf("received update #%d: services %d/%d, routers %d/%d, TLS configs %d/%d", numUpdates, numServices, test.expectedNumService, numRouters, test.expectedNumRouter, numTLSConfs, test.expectedNumTLSConf)
+					numServersTransports = len(conf.Configuration.HTTP.ServersTransports)
+					t.Logf("received update #%d: services %d/%d, routers %d/%d, TLS configs %d/%d, servers transports %d/%d", numUpdates, numServices, test.expectedNumService, numRouters, test.expectedNumRouter, numTLSConfs, test.expectedNumTLSConf, numServersTransports, test.expectedNumServersTransport)
 
-					if numServices == test.expectedNumService && numRouters == test.expectedNumRouter && numTLSConfs == test.expectedNumTLSConf {
+					if numServices == test.expectedNumService && numRouters == test.expectedNumRouter && numTLSConfs == test.expectedNumTLSConf && numServersTransports == test.expectedNumServersTransport {
 						return
 					}
 				case <-timeout:
@@ -143,6 +147,13 @@ func getTestCases() []ProvideTestCase {
 			expectedNumService: 6,
 			expectedNumTLSConf: 5,
 		},
+		{
+			desc:                        "simple file with servers transports",
+			fileContent:                 createRoutersConfiguration(3) + createServicesConfiguration(6) + createServersTransportsConfiguration(2),
+			expectedNumRouter:           3,
+			expectedNumService:          6,
+			expectedNumServersTransport: 2,
+		},
 		{
 			desc:        "simple file and a traefik file",
 			fileContent: createRoutersConfiguration(4) + createServicesConfiguration(8) + createTLS(4),
@@ -175,6 +186,17 @@ func getTestCases() []ProvideTestCase {
 			expectedNumService: 3,
 			expectedNumTLSConf: 4,
 		},
+		{
+			desc: "directory with servers transports",
+			directoryContent: []string{
+				createRoutersConfiguration(2),
+				createServicesConfiguration(3),
+				createServersTransportsConfiguration(2),
+			},
+			expectedNumRouter:           2,
+			expectedNumService:          3,
+			expectedNumServersTransport: 2,
+		},
 		{
 			desc: "template in directory",
 			directoryContent: []string{
@@ -322,6 +344,18 @@ func createServicesConfiguration(n int) string {
 	return conf
 }
 
+// createServersTransportsConfiguration Helper
+func createServersTransportsConfiguration(n int) string {
+	conf := "[http.serversTransports]\n"
+	for i := 1; i <= n; i++ {
+		conf += fmt.Sprintf(`
+[http.serversTransports.transport-%[1]d]
+   serverName = "backend-%[1]d.example.com"
+`, i)
+	}
+	return conf
+}
+
 // createTLS Helper
 func createTLS(n int) string {
 	var conf string
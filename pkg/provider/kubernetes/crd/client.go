@@ -55,6 +55,7 @@ type Client interface {
 	GetService(namespace, name string) (*corev1.Service, bool, error)
 	GetSecret(namespace, name string) (*corev1.Secret, bool, error)
 	GetEndpoints(namespace, name string) (*corev1.Endpoints, bool, error)
+	GetPod(namespace, name string) (*corev1.Pod, bool, error)
 	UpdateIngressStatus(namespace, name, ip, hostname string) error
 }
 
@@ -195,6 +196,8 @@ func (c *clientWrapper) WatchAll(namespaces []string, stopCh <-chan struct{}) (<
 	// situation here in the future.
 	for _, ns := range namespaces {
 		c.factoriesKube[ns].Core().V1().Secrets().Informer().AddEventHandler(eventHandler)
+		// The Pods are only looked up for the labels of the servers, their changes do not trigger a new configuration.
+		c.factoriesKube[ns].Core().V1().Pods().Informer()
 		c.factoriesKube[ns].Start(stopCh)
 	}
 
@@ -322,6 +325,17 @@ func (c *clientWrapper) GetEndpoints(namespace, name string) (*corev1.Endpoints,
 	return endpoint, exist, err
 }
 
+// GetPod returns the named pod from the given namespace.
+func (c *clientWrapper) GetPod(namespace, name string) (*corev1.Pod, bool, error) {
+	if !c.isWatchedNamespace(namespace) {
+		return nil, false, fmt.Errorf("failed to get pod %s/%s: namespace is not within watched namespaces", namespace, name)
+	}
+
+	pod, err := c.factoriesKube[c.lookupNamespace(namespace)].Core().V1().Pods().Lister().Pods(namespace).Get(name)
+	exist, err := translateNotFoundError(err)
+	return pod, exist, err
+}
+
 // GetSecret returns the named secret from the given namespace.
 func (c *clientWrapper) GetSecret(namespace, name string) (*corev1.Secret, bool, error) {
 	if !c.isWatchedNamespace(namespace) {
@@ -18,6 +18,7 @@ import (
 	"github.com/containous/traefik/pkg/job"
 	"github.com/containous/traefik/pkg/log"
 	"github.com/containous/traefik/pkg/provider/kubernetes/crd/traefik/v1alpha1"
+	"github.com/containous/traefik/pkg/provider/kubernetes/k8s"
 	"github.com/containous/traefik/pkg/safe"
 	"github.com/containous/traefik/pkg/tls"
 	corev1 "k8s.io/api/core/v1"
@@ -215,15 +216,23 @@ func loadTCPServers(client Client, namespace string, svc v1alpha1.ServiceTCP) ([
 	return servers, nil
 }
 
-func loadServers(client Client, namespace string, svc v1alpha1.Service) ([]config.Server, error) {
-	strategy := svc.Strategy
-	if strategy == "" {
-		strategy = "RoundRobin"
+// getStrategy returns the load-balancing strategy of the given service, in the dynamic configuration format.
+// The default strategy (round robin) is returned empty.
+func getStrategy(svc v1alpha1.Service) (string, error) {
+	if svc.Strategy == "" || strings.EqualFold(svc.Strategy, config.StrategyRoundRobin) {
+		return "", nil
 	}
-	if strategy != "RoundRobin" {
-		return nil, fmt.Errorf("load balancing strategy %v is not supported", strategy)
+
+	for _, strategy := range []string{config.StrategyLeastConnections, config.StrategyEWMA} {
+		if strings.EqualFold(svc.Strategy, strategy) {
+			return strategy, nil
+		}
 	}
 
+	return "", fmt.Errorf("load balancing strategy %v is not supported", svc.Strategy)
+}
+
+func loadServers(client Client, namespace string, svc v1alpha1.Service) ([]config.Server, error) {
 	service, exists, err := client.GetService(namespace, svc.Name)
 	if err != nil {
 		return nil, err
@@ -283,8 +292,14 @@ func loadServers(client Client, namespace string, svc v1alpha1.Service) ([]confi
 			}
 
 			for _, addr := range subset.Addresses {
+				serverLabels, err := k8s.ServerLabels(client, addr)
+				if err != nil {
+					log.Errorf("Cannot get the labels of the server %s of the endpoints %s/%s: %v", addr.IP, endpoints.Namespace, endpoints.Name, err)
+				}
+
 				servers = append(servers, config.Server{
-					URL: fmt.Sprintf("%s://%s:%d", protocol, addr.IP, port),
+					URL:    fmt.Sprintf("%s://%s:%d", protocol, addr.IP, port),
+					Labels: serverLabels,
 				})
 			}
 		}
@@ -381,18 +396,63 @@ func (p *Provider) loadIngressRouteConfiguration(ctx context.Context, client Cli
 				continue
 			}
 
+			key, err := makeServiceKey(route.Match, ingressName)
+			if err != nil {
+				logger.Error(err)
+				continue
+			}
+
+			serviceName := makeID(ingressRoute.Namespace, key)
+			weighted := isWeighted(route.Services)
+
 			var allServers []config.Server
+			var allStrategy string
+			var hasStrategy bool
+			var weightedServices []config.WeightedServiceRef
 			for _, service := range route.Services {
+				serviceLogger := logger.
+					WithField("serviceName", service.Name).
+					WithField("servicePort", service.Port)
+
+				strategy, err := getStrategy(service)
+				if err != nil {
+					serviceLogger.Errorf("Cannot create service: %v", err)
+					continue
+				}
+
 				servers, err := loadServers(client, ingressRoute.Namespace, service)
 				if err != nil {
-					logger.
-						WithField("serviceName", service.Name).
-						WithField("servicePort", service.Port).
-						Errorf("Cannot create service: %v", err)
+					serviceLogger.Errorf("Cannot create service: %v", err)
 					continue
 				}
 
-				allServers = append(allServers, servers...)
+				if !weighted {
+					// The servers of all the services are merged under the same load-balancer.
+					if hasStrategy && strategy != allStrategy {
+						serviceLogger.Errorf("Cannot create service: load balancing strategy %q conflicts with the strategy %q of the other services of the route", strategy, allStrategy)
+						continue
+					}
+
+					allStrategy = strategy
+					hasStrategy = true
+					allServers = append(allServers, servers...)
+					continue
+				}
+
+				childName := makeID(ingressRoute.Namespace, fmt.Sprintf("%s-%s-%d", key, service.Name, service.Port))
+				conf.Services[childName] = &config.Service{
+					LoadBalancer: &config.LoadBalancerService{
+						Strategy:       strategy,
+						Servers:        servers,
+						PassHostHeader: true,
+					},
+				}
+
+				weight := 1
+				if service.Weight != nil {
+					weight = *service.Weight
+				}
+				weightedServices = append(weightedServices, config.WeightedServiceRef{Name: childName, Weight: weight})
 			}
 
 			// TODO: support middlewares from other providers.
@@ -409,14 +469,6 @@ func (p *Provider) loadIngressRouteConfiguration(ctx context.Context, client Cli
 				mds = append(mds, makeID(ns, mi.Name))
 			}
 
-			key, err := makeServiceKey(route.Match, ingressName)
-			if err != nil {
-				logger.Error(err)
-				continue
-			}
-
-			serviceName := makeID(ingressRoute.Namespace, key)
-
 			conf.Routers[serviceName] = &config.Router{
 				Middlewares: mds,
 				Priority:    route.Priority,
@@ -443,10 +495,19 @@ func (p *Provider) loadIngressRouteConfiguration(ctx context.Context, client Cli
 				conf.Routers[serviceName].TLS = tlsConf
 			}
 
+			if weighted {
+				conf.Services[serviceName] = &config.Service{
+					Weighted: &config.WeightedService{
+						Services: weightedServices,
+					},
+				}
+				continue
+			}
+
 			conf.Services[serviceName] = &config.Service{
 				LoadBalancer: &config.LoadBalancerService{
-					Servers: allServers,
-					// TODO: support other strategies.
+					Strategy:       allStrategy,
+					Servers:        allServers,
 					PassHostHeader: true,
 				},
 			}
@@ -456,6 +517,16 @@ func (p *Provider) loadIngressRouteConfiguration(ctx context.Context, client Cli
 	return conf
 }
 
+// isWeighted tells if the traffic of a route has to be balanced between its services, according to their weights.
+func isWeighted(services []v1alpha1.Service) bool {
+	for _, service := range services {
+		if service.Weight != nil {
+			return true
+		}
+	}
+	return false
+}
+
 func (p *Provider) loadIngressRouteTCPConfiguration(ctx context.Context, client Client, tlsConfigs map[string]*tls.Configuration) *config.TCPConfiguration {
 	conf := &config.TCPConfiguration{
 		Routers:  map[string]*config.TCPRouter{},
@@ -516,6 +587,7 @@ func (p *Provider) loadIngressRouteTCPConfiguration(ctx context.Context, client
 			conf.Routers[serviceName] = &config.TCPRouter{
 				EntryPoints: ingressRouteTCP.Spec.EntryPoints,
 				Rule:        route.Match,
+				Priority:    route.Priority,
 				Service:     serviceName,
 			}
 
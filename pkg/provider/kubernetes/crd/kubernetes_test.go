@@ -84,6 +84,7 @@ func TestLoadIngressRouteTCPs(t *testing.T) {
 							EntryPoints: []string{"foo"},
 							Service:     "default/test-crd-f44ce589164e656d231c",
 							Rule:        "HostSNI(`bar.com`)",
+							Priority:    12,
 						},
 					},
 					Services: map[string]*config.TCPService{
@@ -810,6 +811,149 @@ func TestLoadIngressRoutes(t *testing.T) {
 				},
 			},
 		},
+		{
+			desc:  "One ingress Route with two services with a load-balancing strategy",
+			paths: []string{"services.yml", "with_strategy.yml"},
+			expected: &config.Configuration{
+				TCP: &config.TCPConfiguration{
+					Routers:  map[string]*config.TCPRouter{},
+					Services: map[string]*config.TCPService{},
+				},
+				HTTP: &config.HTTPConfiguration{
+					Routers: map[string]*config.Router{
+						"default/test-crd-77c62dfe9517144aeeaa": {
+							EntryPoints: []string{"web"},
+							Service:     "default/test-crd-77c62dfe9517144aeeaa",
+							Rule:        "Host(`foo.com`) && PathPrefix(`/foo`)",
+							Priority:    12,
+						},
+					},
+					Middlewares: map[string]*config.Middleware{},
+					Services: map[string]*config.Service{
+						"default/test-crd-77c62dfe9517144aeeaa": {
+							LoadBalancer: &config.LoadBalancerService{
+								Strategy: "leastConnections",
+								Servers: []config.Server{
+									{
+										URL: "http://10.10.0.1:80",
+									},
+									{
+										URL: "http://10.10.0.2:80",
+									},
+									{
+										URL: "http://10.10.0.3:8080",
+									},
+									{
+										URL: "http://10.10.0.4:8080",
+									},
+								},
+								PassHostHeader: true,
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			desc:  "One ingress Route with two services with conflicting load-balancing strategies",
+			paths: []string{"services.yml", "with_conflicting_strategies.yml"},
+			expected: &config.Configuration{
+				TCP: &config.TCPConfiguration{
+					Routers:  map[string]*config.TCPRouter{},
+					Services: map[string]*config.TCPService{},
+				},
+				HTTP: &config.HTTPConfiguration{
+					Routers: map[string]*config.Router{
+						"default/test-crd-77c62dfe9517144aeeaa": {
+							EntryPoints: []string{"web"},
+							Service:     "default/test-crd-77c62dfe9517144aeeaa",
+							Rule:        "Host(`foo.com`) && PathPrefix(`/foo`)",
+							Priority:    12,
+						},
+					},
+					Middlewares: map[string]*config.Middleware{},
+					Services: map[string]*config.Service{
+						"default/test-crd-77c62dfe9517144aeeaa": {
+							LoadBalancer: &config.LoadBalancerService{
+								Strategy: "ewma",
+								Servers: []config.Server{
+									{
+										URL: "http://10.10.0.1:80",
+									},
+									{
+										URL: "http://10.10.0.2:80",
+									},
+								},
+								PassHostHeader: true,
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			desc:  "One ingress Route with two different weighted services",
+			paths: []string{"services.yml", "with_two_services_weighted.yml"},
+			expected: &config.Configuration{
+				TCP: &config.TCPConfiguration{
+					Routers:  map[string]*config.TCPRouter{},
+					Services: map[string]*config.TCPService{},
+				},
+				HTTP: &config.HTTPConfiguration{
+					Routers: map[string]*config.Router{
+						"default/test-crd-77c62dfe9517144aeeaa": {
+							EntryPoints: []string{"web"},
+							Service:     "default/test-crd-77c62dfe9517144aeeaa",
+							Rule:        "Host(`foo.com`) && PathPrefix(`/foo`)",
+							Priority:    12,
+						},
+					},
+					Middlewares: map[string]*config.Middleware{},
+					Services: map[string]*config.Service{
+						"default/test-crd-77c62dfe9517144aeeaa": {
+							Weighted: &config.WeightedService{
+								Services: []config.WeightedServiceRef{
+									{
+										Name:   "default/test-crd-77c62dfe9517144aeeaa-whoami-80",
+										Weight: 95,
+									},
+									{
+										Name:   "default/test-crd-77c62dfe9517144aeeaa-whoami2-8080",
+										Weight: 5,
+									},
+								},
+							},
+						},
+						"default/test-crd-77c62dfe9517144aeeaa-whoami-80": {
+							LoadBalancer: &config.LoadBalancerService{
+								Servers: []config.Server{
+									{
+										URL: "http://10.10.0.1:80",
+									},
+									{
+										URL: "http://10.10.0.2:80",
+									},
+								},
+								PassHostHeader: true,
+							},
+						},
+						"default/test-crd-77c62dfe9517144aeeaa-whoami2-8080": {
+							LoadBalancer: &config.LoadBalancerService{
+								Servers: []config.Server{
+									{
+										URL: "http://10.10.0.3:8080",
+									},
+									{
+										URL: "http://10.10.0.4:8080",
+									},
+								},
+								PassHostHeader: true,
+							},
+						},
+					},
+				},
+			},
+		},
 		{
 			desc:         "Ingress class",
 			paths:        []string{"services.yml", "simple.yml"},
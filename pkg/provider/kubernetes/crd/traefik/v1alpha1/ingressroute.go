@@ -47,6 +47,10 @@ type Service struct {
 	Port        int32        `json:"port"`
 	HealthCheck *HealthCheck `json:"healthCheck,omitempty"`
 	Strategy    string       `json:"strategy,omitempty"`
+	// Weight is the share of the route traffic sent to this service.
+	// When at least one service of the route has a weight, the traffic is balanced between the services
+	// (proportionally to their weights, defaulting to 1) instead of between all their servers.
+	Weight *int `json:"weight,omitempty"`
 }
 
 // MiddlewareRef is a ref to the Middleware resources.
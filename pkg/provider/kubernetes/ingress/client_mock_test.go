@@ -17,6 +17,7 @@ type clientMock struct {
 	services  []*corev1.Service
 	secrets   []*corev1.Secret
 	endpoints []*corev1.Endpoints
+	pods      []*corev1.Pod
 
 	apiServiceError       error
 	apiSecretError        error
@@ -44,6 +45,8 @@ func newClientMock(paths ...string) clientMock {
 				c.secrets = append(c.secrets, o)
 			case *corev1.Endpoints:
 				c.endpoints = append(c.endpoints, o)
+			case *corev1.Pod:
+				c.pods = append(c.pods, o)
 			case *v1beta12.Ingress:
 				c.ingresses = append(c.ingresses, o)
 			default:
@@ -86,6 +89,15 @@ func (c clientMock) GetEndpoints(namespace, name string) (*corev1.Endpoints, boo
 	return &corev1.Endpoints{}, false, nil
 }
 
+func (c clientMock) GetPod(namespace, name string) (*corev1.Pod, bool, error) {
+	for _, pod := range c.pods {
+		if pod.Namespace == namespace && pod.Name == name {
+			return pod, true, nil
+		}
+	}
+	return nil, false, nil
+}
+
 func (c clientMock) GetSecret(namespace, name string) (*corev1.Secret, bool, error) {
 	if c.apiSecretError != nil {
 		return nil, false, c.apiSecretError
@@ -17,6 +17,7 @@ import (
 	"github.com/containous/traefik/pkg/config"
 	"github.com/containous/traefik/pkg/job"
 	"github.com/containous/traefik/pkg/log"
+	"github.com/containous/traefik/pkg/provider/kubernetes/k8s"
 	"github.com/containous/traefik/pkg/safe"
 	"github.com/containous/traefik/pkg/tls"
 	corev1 "k8s.io/api/core/v1"
@@ -230,8 +231,14 @@ func loadService(client Client, namespace string, backend v1beta1.IngressBackend
 			}
 
 			for _, addr := range subset.Addresses {
+				serverLabels, err := k8s.ServerLabels(client, addr)
+				if err != nil {
+					log.Errorf("Cannot get the labels of the server %s of the endpoints %s/%s: %v", addr.IP, endpoints.Namespace, endpoints.Name, err)
+				}
+
 				servers = append(servers, config.Server{
-					URL: fmt.Sprintf("%s://%s:%d", protocol, addr.IP, port),
+					URL:    fmt.Sprintf("%s://%s:%d", protocol, addr.IP, port),
+					Labels: serverLabels,
 				})
 			}
 		}
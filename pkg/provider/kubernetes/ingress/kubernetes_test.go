@@ -66,6 +66,41 @@ func TestLoadConfigurationFromIngresses(t *testing.T) {
 				},
 			},
 		},
+		{
+			desc: "Ingress with labeled endpoints",
+			expected: &config.Configuration{
+				TCP: &config.TCPConfiguration{},
+				HTTP: &config.HTTPConfiguration{
+					Middlewares: map[string]*config.Middleware{},
+					Routers: map[string]*config.Router{
+						"/bar": {
+							Rule:    "PathPrefix(`/bar`)",
+							Service: "testing/service1/80",
+						},
+					},
+					Services: map[string]*config.Service{
+						"testing/service1/80": {
+							LoadBalancer: &config.LoadBalancerService{
+								PassHostHeader: true,
+								Servers: []config.Server{
+									{
+										URL:    "http://10.10.0.1:8080",
+										Labels: map[string]string{"version": "v1", "kubernetes.io/hostname": "node-1"},
+									},
+									{
+										URL:    "http://10.21.0.1:8080",
+										Labels: map[string]string{"version": "v2", "kubernetes.io/hostname": "node-2"},
+									},
+									{
+										URL: "http://10.32.0.1:8080",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
 		{
 			desc: "Ingress with two different rules with one path",
 			expected: &config.Configuration{
@@ -932,6 +967,10 @@ func TestLoadConfigurationFromIngresses(t *testing.T) {
 			if err == nil {
 				paths = append(paths, generateTestFilename("_secret", test.desc))
 			}
+			_, err = os.Stat(generateTestFilename("_pod", test.desc))
+			if err == nil {
+				paths = append(paths, generateTestFilename("_pod", test.desc))
+			}
 
 			clientMock := newClientMock(paths...)
 
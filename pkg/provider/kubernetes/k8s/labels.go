@@ -0,0 +1,42 @@
+package k8s
+
+import (
+	corev1 "k8s.io/api/core/v1"
+)
+
+// LabelNodeHostname is the label holding the name of the node of a server.
+// It is the well-known label of the nodes, used as topology key.
+const LabelNodeHostname = "kubernetes.io/hostname"
+
+// PodGetter looks up the pods.
+type PodGetter interface {
+	GetPod(namespace, name string) (*corev1.Pod, bool, error)
+}
+
+// ServerLabels returns the labels of the server behind the given endpoint address:
+// the labels of its pod, and the name of its node.
+func ServerLabels(client PodGetter, address corev1.EndpointAddress) (map[string]string, error) {
+	labels := make(map[string]string)
+
+	if address.TargetRef != nil && address.TargetRef.Kind == "Pod" {
+		pod, exists, err := client.GetPod(address.TargetRef.Namespace, address.TargetRef.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		if exists {
+			for key, value := range pod.Labels {
+				labels[key] = value
+			}
+		}
+	}
+
+	if address.NodeName != nil && *address.NodeName != "" {
+		labels[LabelNodeHostname] = *address.NodeName
+	}
+
+	if len(labels) == 0 {
+		return nil, nil
+	}
+	return labels, nil
+}
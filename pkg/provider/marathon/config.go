@@ -106,6 +106,11 @@ func (p *Provider) buildServiceConfiguration(ctx context.Context, app marathon.A
 	}
 
 	for serviceName, service := range conf.Services {
+		// Only load balancers hold the servers of the application.
+		if service.LoadBalancer == nil {
+			continue
+		}
+
 		var servers []config.Server
 
 		defaultServer := config.Server{}
@@ -240,7 +245,16 @@ func (p *Provider) getServer(app marathon.Application, task marathon.Task, extra
 	}
 
 	server := config.Server{
-		URL: fmt.Sprintf("%s://%s", defaultServer.Scheme, net.JoinHostPort(host, port)),
+		URL:    fmt.Sprintf("%s://%s", defaultServer.Scheme, net.JoinHostPort(host, port)),
+		Weight: defaultServer.Weight,
+	}
+
+	// Each server gets its own copy of the labels, as they are shared by all the tasks of the application.
+	if len(defaultServer.Labels) > 0 {
+		server.Labels = make(map[string]string, len(defaultServer.Labels))
+		for key, value := range defaultServer.Labels {
+			server.Labels[key] = value
+		}
 	}
 
 	return server, nil
@@ -44,8 +44,13 @@ func TestBuildConfiguration(t *testing.T) {
 				)),
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers:  map[string]*config.TCPRouter{},
-					Services: map[string]*config.TCPService{},
+					Routers:     map[string]*config.TCPRouter{},
+					Middlewares: map[string]*config.TCPMiddleware{},
+					Services:    map[string]*config.TCPService{},
+				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
 				},
 				HTTP: &config.HTTPConfiguration{
 					Routers: map[string]*config.Router{
@@ -78,8 +83,13 @@ func TestBuildConfiguration(t *testing.T) {
 				)),
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers:  map[string]*config.TCPRouter{},
-					Services: map[string]*config.TCPService{},
+					Routers:     map[string]*config.TCPRouter{},
+					Middlewares: map[string]*config.TCPMiddleware{},
+					Services:    map[string]*config.TCPService{},
+				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
 				},
 				HTTP: &config.HTTPConfiguration{
 					Routers:     map[string]*config.Router{},
@@ -98,8 +108,13 @@ func TestBuildConfiguration(t *testing.T) {
 				)),
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers:  map[string]*config.TCPRouter{},
-					Services: map[string]*config.TCPService{},
+					Routers:     map[string]*config.TCPRouter{},
+					Middlewares: map[string]*config.TCPMiddleware{},
+					Services:    map[string]*config.TCPService{},
+				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
 				},
 				HTTP: &config.HTTPConfiguration{
 					Routers: map[string]*config.Router{
@@ -134,8 +149,13 @@ func TestBuildConfiguration(t *testing.T) {
 				)),
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers:  map[string]*config.TCPRouter{},
-					Services: map[string]*config.TCPService{},
+					Routers:     map[string]*config.TCPRouter{},
+					Middlewares: map[string]*config.TCPMiddleware{},
+					Services:    map[string]*config.TCPService{},
+				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
 				},
 				HTTP: &config.HTTPConfiguration{
 					Routers: map[string]*config.Router{
@@ -188,8 +208,13 @@ func TestBuildConfiguration(t *testing.T) {
 			),
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers:  map[string]*config.TCPRouter{},
-					Services: map[string]*config.TCPService{},
+					Routers:     map[string]*config.TCPRouter{},
+					Middlewares: map[string]*config.TCPMiddleware{},
+					Services:    map[string]*config.TCPService{},
+				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
 				},
 				HTTP: &config.HTTPConfiguration{
 					Routers: map[string]*config.Router{
@@ -237,8 +262,13 @@ func TestBuildConfiguration(t *testing.T) {
 			),
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers:  map[string]*config.TCPRouter{},
-					Services: map[string]*config.TCPService{},
+					Routers:     map[string]*config.TCPRouter{},
+					Middlewares: map[string]*config.TCPMiddleware{},
+					Services:    map[string]*config.TCPService{},
+				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
 				},
 				HTTP: &config.HTTPConfiguration{
 					Routers: map[string]*config.Router{
@@ -284,8 +314,13 @@ func TestBuildConfiguration(t *testing.T) {
 			),
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers:  map[string]*config.TCPRouter{},
-					Services: map[string]*config.TCPService{},
+					Routers:     map[string]*config.TCPRouter{},
+					Middlewares: map[string]*config.TCPMiddleware{},
+					Services:    map[string]*config.TCPService{},
+				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
 				},
 				HTTP: &config.HTTPConfiguration{
 					Routers: map[string]*config.Router{
@@ -330,8 +365,13 @@ func TestBuildConfiguration(t *testing.T) {
 				)),
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers:  map[string]*config.TCPRouter{},
-					Services: map[string]*config.TCPService{},
+					Routers:     map[string]*config.TCPRouter{},
+					Middlewares: map[string]*config.TCPMiddleware{},
+					Services:    map[string]*config.TCPService{},
+				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
 				},
 				HTTP: &config.HTTPConfiguration{
 					Routers: map[string]*config.Router{
@@ -370,8 +410,13 @@ func TestBuildConfiguration(t *testing.T) {
 				)),
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers:  map[string]*config.TCPRouter{},
-					Services: map[string]*config.TCPService{},
+					Routers:     map[string]*config.TCPRouter{},
+					Middlewares: map[string]*config.TCPMiddleware{},
+					Services:    map[string]*config.TCPService{},
+				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
 				},
 				HTTP: &config.HTTPConfiguration{
 					Routers: map[string]*config.Router{
@@ -407,8 +452,13 @@ func TestBuildConfiguration(t *testing.T) {
 				)),
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers:  map[string]*config.TCPRouter{},
-					Services: map[string]*config.TCPService{},
+					Routers:     map[string]*config.TCPRouter{},
+					Middlewares: map[string]*config.TCPMiddleware{},
+					Services:    map[string]*config.TCPService{},
+				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
 				},
 				HTTP: &config.HTTPConfiguration{
 					Routers: map[string]*config.Router{
@@ -444,8 +494,13 @@ func TestBuildConfiguration(t *testing.T) {
 				)),
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers:  map[string]*config.TCPRouter{},
-					Services: map[string]*config.TCPService{},
+					Routers:     map[string]*config.TCPRouter{},
+					Middlewares: map[string]*config.TCPMiddleware{},
+					Services:    map[string]*config.TCPService{},
+				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
 				},
 				HTTP: &config.HTTPConfiguration{
 					Middlewares: map[string]*config.Middleware{},
@@ -482,8 +537,13 @@ func TestBuildConfiguration(t *testing.T) {
 				)),
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers:  map[string]*config.TCPRouter{},
-					Services: map[string]*config.TCPService{},
+					Routers:     map[string]*config.TCPRouter{},
+					Middlewares: map[string]*config.TCPMiddleware{},
+					Services:    map[string]*config.TCPService{},
+				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
 				},
 				HTTP: &config.HTTPConfiguration{
 					Routers: map[string]*config.Router{
@@ -521,8 +581,13 @@ func TestBuildConfiguration(t *testing.T) {
 				)),
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers:  map[string]*config.TCPRouter{},
-					Services: map[string]*config.TCPService{},
+					Routers:     map[string]*config.TCPRouter{},
+					Middlewares: map[string]*config.TCPMiddleware{},
+					Services:    map[string]*config.TCPService{},
+				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
 				},
 				HTTP: &config.HTTPConfiguration{
 					Routers:     map[string]*config.Router{},
@@ -569,8 +634,13 @@ func TestBuildConfiguration(t *testing.T) {
 				)),
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers:  map[string]*config.TCPRouter{},
-					Services: map[string]*config.TCPService{},
+					Routers:     map[string]*config.TCPRouter{},
+					Middlewares: map[string]*config.TCPMiddleware{},
+					Services:    map[string]*config.TCPService{},
+				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
 				},
 				HTTP: &config.HTTPConfiguration{
 					Routers: map[string]*config.Router{
@@ -605,8 +675,13 @@ func TestBuildConfiguration(t *testing.T) {
 				)),
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers:  map[string]*config.TCPRouter{},
-					Services: map[string]*config.TCPService{},
+					Routers:     map[string]*config.TCPRouter{},
+					Middlewares: map[string]*config.TCPMiddleware{},
+					Services:    map[string]*config.TCPService{},
+				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
 				},
 				HTTP: &config.HTTPConfiguration{
 					Routers: map[string]*config.Router{
@@ -669,8 +744,13 @@ func TestBuildConfiguration(t *testing.T) {
 				)),
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers:  map[string]*config.TCPRouter{},
-					Services: map[string]*config.TCPService{},
+					Routers:     map[string]*config.TCPRouter{},
+					Middlewares: map[string]*config.TCPMiddleware{},
+					Services:    map[string]*config.TCPService{},
+				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
 				},
 				HTTP: &config.HTTPConfiguration{
 					Routers: map[string]*config.Router{
@@ -726,8 +806,13 @@ func TestBuildConfiguration(t *testing.T) {
 				)),
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers:  map[string]*config.TCPRouter{},
-					Services: map[string]*config.TCPService{},
+					Routers:     map[string]*config.TCPRouter{},
+					Middlewares: map[string]*config.TCPMiddleware{},
+					Services:    map[string]*config.TCPService{},
+				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
 				},
 				HTTP: &config.HTTPConfiguration{
 					Routers:     map[string]*config.Router{},
@@ -776,8 +861,13 @@ func TestBuildConfiguration(t *testing.T) {
 				)),
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers:  map[string]*config.TCPRouter{},
-					Services: map[string]*config.TCPService{},
+					Routers:     map[string]*config.TCPRouter{},
+					Middlewares: map[string]*config.TCPMiddleware{},
+					Services:    map[string]*config.TCPService{},
+				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
 				},
 				HTTP: &config.HTTPConfiguration{
 					Routers: map[string]*config.Router{
@@ -822,8 +912,13 @@ func TestBuildConfiguration(t *testing.T) {
 				)),
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers:  map[string]*config.TCPRouter{},
-					Services: map[string]*config.TCPService{},
+					Routers:     map[string]*config.TCPRouter{},
+					Middlewares: map[string]*config.TCPMiddleware{},
+					Services:    map[string]*config.TCPService{},
+				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
 				},
 				HTTP: &config.HTTPConfiguration{
 					Routers:     map[string]*config.Router{},
@@ -864,8 +959,13 @@ func TestBuildConfiguration(t *testing.T) {
 				)),
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers:  map[string]*config.TCPRouter{},
-					Services: map[string]*config.TCPService{},
+					Routers:     map[string]*config.TCPRouter{},
+					Middlewares: map[string]*config.TCPMiddleware{},
+					Services:    map[string]*config.TCPService{},
+				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
 				},
 				HTTP: &config.HTTPConfiguration{
 					Routers: map[string]*config.Router{
@@ -902,8 +1002,13 @@ func TestBuildConfiguration(t *testing.T) {
 				)),
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers:  map[string]*config.TCPRouter{},
-					Services: map[string]*config.TCPService{},
+					Routers:     map[string]*config.TCPRouter{},
+					Middlewares: map[string]*config.TCPMiddleware{},
+					Services:    map[string]*config.TCPService{},
+				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
 				},
 				HTTP: &config.HTTPConfiguration{
 					Routers: map[string]*config.Router{
@@ -928,6 +1033,51 @@ func TestBuildConfiguration(t *testing.T) {
 				},
 			},
 		},
+		{
+			desc: "one app with label weight and labels",
+			applications: withApplications(
+				application(
+					appID("/app"),
+					appPorts(80, 81),
+					withTasks(localhostTask(taskPorts(80, 81))),
+					withLabel("traefik.http.services.Service1.LoadBalancer.server.weight", "3"),
+					withLabel("traefik.http.services.Service1.LoadBalancer.server.labels.zone", "eu"),
+				)),
+			expected: &config.Configuration{
+				TCP: &config.TCPConfiguration{
+					Routers:     map[string]*config.TCPRouter{},
+					Middlewares: map[string]*config.TCPMiddleware{},
+					Services:    map[string]*config.TCPService{},
+				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
+				},
+				HTTP: &config.HTTPConfiguration{
+					Routers: map[string]*config.Router{
+						"app": {
+							Service: "Service1",
+							Rule:    "Host(`app.marathon.localhost`)",
+						},
+					},
+					Middlewares: map[string]*config.Middleware{},
+					Services: map[string]*config.Service{
+						"Service1": {
+							LoadBalancer: &config.LoadBalancerService{
+								Servers: []config.Server{
+									{
+										URL:    "http://localhost:80",
+										Weight: 3,
+										Labels: map[string]string{"zone": "eu"},
+									},
+								},
+								PassHostHeader: true,
+							},
+						},
+					},
+				},
+			},
+		},
 		{
 			desc: "one app with label port on two services",
 			applications: withApplications(
@@ -940,8 +1090,13 @@ func TestBuildConfiguration(t *testing.T) {
 				)),
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers:  map[string]*config.TCPRouter{},
-					Services: map[string]*config.TCPService{},
+					Routers:     map[string]*config.TCPRouter{},
+					Middlewares: map[string]*config.TCPMiddleware{},
+					Services:    map[string]*config.TCPService{},
+				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
 				},
 				HTTP: &config.HTTPConfiguration{
 					Routers:     map[string]*config.Router{},
@@ -981,8 +1136,13 @@ func TestBuildConfiguration(t *testing.T) {
 				)),
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers:  map[string]*config.TCPRouter{},
-					Services: map[string]*config.TCPService{},
+					Routers:     map[string]*config.TCPRouter{},
+					Middlewares: map[string]*config.TCPMiddleware{},
+					Services:    map[string]*config.TCPService{},
+				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
 				},
 				HTTP: &config.HTTPConfiguration{
 					Routers:     map[string]*config.Router{},
@@ -1002,8 +1162,13 @@ func TestBuildConfiguration(t *testing.T) {
 				)),
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers:  map[string]*config.TCPRouter{},
-					Services: map[string]*config.TCPService{},
+					Routers:     map[string]*config.TCPRouter{},
+					Middlewares: map[string]*config.TCPMiddleware{},
+					Services:    map[string]*config.TCPService{},
+				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
 				},
 				HTTP: &config.HTTPConfiguration{
 					Routers:     map[string]*config.Router{},
@@ -1023,8 +1188,13 @@ func TestBuildConfiguration(t *testing.T) {
 				)),
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers:  map[string]*config.TCPRouter{},
-					Services: map[string]*config.TCPService{},
+					Routers:     map[string]*config.TCPRouter{},
+					Middlewares: map[string]*config.TCPMiddleware{},
+					Services:    map[string]*config.TCPService{},
+				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
 				},
 				HTTP: &config.HTTPConfiguration{
 					Routers:     map[string]*config.Router{},
@@ -1044,8 +1214,13 @@ func TestBuildConfiguration(t *testing.T) {
 				)),
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers:  map[string]*config.TCPRouter{},
-					Services: map[string]*config.TCPService{},
+					Routers:     map[string]*config.TCPRouter{},
+					Middlewares: map[string]*config.TCPMiddleware{},
+					Services:    map[string]*config.TCPService{},
+				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
 				},
 				HTTP: &config.HTTPConfiguration{
 					Routers:     map[string]*config.Router{},
@@ -1066,8 +1241,13 @@ func TestBuildConfiguration(t *testing.T) {
 			constraints: `Label("traefik.tags", "bar")`,
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers:  map[string]*config.TCPRouter{},
-					Services: map[string]*config.TCPService{},
+					Routers:     map[string]*config.TCPRouter{},
+					Middlewares: map[string]*config.TCPMiddleware{},
+					Services:    map[string]*config.TCPService{},
+				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
 				},
 				HTTP: &config.HTTPConfiguration{
 					Routers:     map[string]*config.Router{},
@@ -1088,8 +1268,13 @@ func TestBuildConfiguration(t *testing.T) {
 			constraints: `MarathonConstraint("rack_id:CLUSTER:rack-2")`,
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers:  map[string]*config.TCPRouter{},
-					Services: map[string]*config.TCPService{},
+					Routers:     map[string]*config.TCPRouter{},
+					Middlewares: map[string]*config.TCPMiddleware{},
+					Services:    map[string]*config.TCPService{},
+				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
 				},
 				HTTP: &config.HTTPConfiguration{
 					Routers:     map[string]*config.Router{},
@@ -1110,8 +1295,13 @@ func TestBuildConfiguration(t *testing.T) {
 			constraints: `MarathonConstraint("rack_id:CLUSTER:rack-1")`,
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers:  map[string]*config.TCPRouter{},
-					Services: map[string]*config.TCPService{},
+					Routers:     map[string]*config.TCPRouter{},
+					Middlewares: map[string]*config.TCPMiddleware{},
+					Services:    map[string]*config.TCPService{},
+				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
 				},
 				HTTP: &config.HTTPConfiguration{
 					Routers: map[string]*config.Router{
@@ -1148,8 +1338,13 @@ func TestBuildConfiguration(t *testing.T) {
 			constraints: `Label("traefik.tags", "bar")`,
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers:  map[string]*config.TCPRouter{},
-					Services: map[string]*config.TCPService{},
+					Routers:     map[string]*config.TCPRouter{},
+					Middlewares: map[string]*config.TCPMiddleware{},
+					Services:    map[string]*config.TCPService{},
+				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
 				},
 				HTTP: &config.HTTPConfiguration{
 					Routers: map[string]*config.Router{
@@ -1185,8 +1380,13 @@ func TestBuildConfiguration(t *testing.T) {
 				)),
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers:  map[string]*config.TCPRouter{},
-					Services: map[string]*config.TCPService{},
+					Routers:     map[string]*config.TCPRouter{},
+					Middlewares: map[string]*config.TCPMiddleware{},
+					Services:    map[string]*config.TCPService{},
+				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
 				},
 				HTTP: &config.HTTPConfiguration{
 					Routers: map[string]*config.Router{
@@ -1230,6 +1430,7 @@ func TestBuildConfiguration(t *testing.T) {
 							TLS:     &config.RouterTCPTLSConfig{},
 						},
 					},
+					Middlewares: map[string]*config.TCPMiddleware{},
 					Services: map[string]*config.TCPService{
 						"app": {
 							LoadBalancer: &config.TCPLoadBalancerService{
@@ -1242,6 +1443,10 @@ func TestBuildConfiguration(t *testing.T) {
 						},
 					},
 				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
+				},
 				HTTP: &config.HTTPConfiguration{
 					Routers:     map[string]*config.Router{},
 					Middlewares: map[string]*config.Middleware{},
@@ -1260,7 +1465,8 @@ func TestBuildConfiguration(t *testing.T) {
 				)),
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers: map[string]*config.TCPRouter{},
+					Routers:     map[string]*config.TCPRouter{},
+					Middlewares: map[string]*config.TCPMiddleware{},
 					Services: map[string]*config.TCPService{
 						"app": {
 							LoadBalancer: &config.TCPLoadBalancerService{
@@ -1273,6 +1479,10 @@ func TestBuildConfiguration(t *testing.T) {
 						},
 					},
 				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
+				},
 				HTTP: &config.HTTPConfiguration{
 					Routers:     map[string]*config.Router{},
 					Middlewares: map[string]*config.Middleware{},
@@ -1300,6 +1510,7 @@ func TestBuildConfiguration(t *testing.T) {
 							TLS:     &config.RouterTCPTLSConfig{},
 						},
 					},
+					Middlewares: map[string]*config.TCPMiddleware{},
 					Services: map[string]*config.TCPService{
 						"foo": {
 							LoadBalancer: &config.TCPLoadBalancerService{
@@ -1312,6 +1523,10 @@ func TestBuildConfiguration(t *testing.T) {
 						},
 					},
 				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
+				},
 				HTTP: &config.HTTPConfiguration{
 					Routers:     map[string]*config.Router{},
 					Middlewares: map[string]*config.Middleware{},
@@ -1340,6 +1555,7 @@ func TestBuildConfiguration(t *testing.T) {
 							TLS:     &config.RouterTCPTLSConfig{},
 						},
 					},
+					Middlewares: map[string]*config.TCPMiddleware{},
 					Services: map[string]*config.TCPService{
 						"foo": {
 							LoadBalancer: &config.TCPLoadBalancerService{
@@ -1352,6 +1568,10 @@ func TestBuildConfiguration(t *testing.T) {
 						},
 					},
 				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
+				},
 				HTTP: &config.HTTPConfiguration{
 					Routers: map[string]*config.Router{
 						"app": {
@@ -1404,6 +1624,31 @@ func TestBuildConfiguration(t *testing.T) {
 	}
 }
 
+func TestBuildConfiguration_serverLabelsCopy(t *testing.T) {
+	applications := withApplications(
+		application(
+			appID("/app"),
+			appPorts(80),
+			withTasks(
+				localhostTask(withTaskID("A"), taskPorts(80)),
+				localhostTask(withTaskID("B"), taskPorts(81)),
+			),
+			withLabel("traefik.http.services.Service1.LoadBalancer.server.labels.zone", "eu"),
+		))
+
+	p := &Provider{DefaultRule: "Host(`{{ normalize .Name }}.marathon.localhost`)", ExposedByDefault: true}
+	require.NoError(t, p.Init())
+
+	conf := p.buildConfiguration(context.Background(), applications)
+
+	servers := conf.HTTP.Services["Service1"].LoadBalancer.Servers
+	require.Len(t, servers, 2)
+
+	// Changing the labels of a server does not change the labels of the others.
+	servers[0].Labels["zone"] = "us"
+	assert.Equal(t, map[string]string{"zone": "eu"}, servers[1].Labels)
+}
+
 func TestApplicationFilterEnabled(t *testing.T) {
 	testCases := []struct {
 		desc             string
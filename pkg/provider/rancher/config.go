@@ -104,6 +104,11 @@ func (p *Provider) buildServiceConfiguration(ctx context.Context, service ranche
 	}
 
 	for _, confService := range configuration.Services {
+		// Only load balancers hold the servers of the service.
+		if confService.LoadBalancer == nil {
+			continue
+		}
+
 		err := p.addServers(ctx, service, confService.LoadBalancer)
 		if err != nil {
 			return err
@@ -30,8 +30,13 @@ func Test_buildConfiguration(t *testing.T) {
 			},
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers:  map[string]*config.TCPRouter{},
-					Services: map[string]*config.TCPService{},
+					Routers:     map[string]*config.TCPRouter{},
+					Middlewares: map[string]*config.TCPMiddleware{},
+					Services:    map[string]*config.TCPService{},
+				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
 				},
 				HTTP: &config.HTTPConfiguration{
 					Routers: map[string]*config.Router{
@@ -78,8 +83,13 @@ func Test_buildConfiguration(t *testing.T) {
 			},
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers:  map[string]*config.TCPRouter{},
-					Services: map[string]*config.TCPService{},
+					Routers:     map[string]*config.TCPRouter{},
+					Middlewares: map[string]*config.TCPMiddleware{},
+					Services:    map[string]*config.TCPService{},
+				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
 				},
 				HTTP: &config.HTTPConfiguration{
 					Routers: map[string]*config.Router{
@@ -140,8 +150,13 @@ func Test_buildConfiguration(t *testing.T) {
 			},
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers:  map[string]*config.TCPRouter{},
-					Services: map[string]*config.TCPService{},
+					Routers:     map[string]*config.TCPRouter{},
+					Middlewares: map[string]*config.TCPMiddleware{},
+					Services:    map[string]*config.TCPService{},
+				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
 				},
 				HTTP: &config.HTTPConfiguration{
 					Routers: map[string]*config.Router{
@@ -201,8 +216,13 @@ func Test_buildConfiguration(t *testing.T) {
 			},
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers:  map[string]*config.TCPRouter{},
-					Services: map[string]*config.TCPService{},
+					Routers:     map[string]*config.TCPRouter{},
+					Middlewares: map[string]*config.TCPMiddleware{},
+					Services:    map[string]*config.TCPService{},
+				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
 				},
 				HTTP: &config.HTTPConfiguration{
 					Routers: map[string]*config.Router{
@@ -240,8 +260,13 @@ func Test_buildConfiguration(t *testing.T) {
 			},
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers:  map[string]*config.TCPRouter{},
-					Services: map[string]*config.TCPService{},
+					Routers:     map[string]*config.TCPRouter{},
+					Middlewares: map[string]*config.TCPMiddleware{},
+					Services:    map[string]*config.TCPService{},
+				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
 				},
 				HTTP: &config.HTTPConfiguration{
 					Routers:     map[string]*config.Router{},
@@ -263,8 +288,13 @@ func Test_buildConfiguration(t *testing.T) {
 			},
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers:  map[string]*config.TCPRouter{},
-					Services: map[string]*config.TCPService{},
+					Routers:     map[string]*config.TCPRouter{},
+					Middlewares: map[string]*config.TCPMiddleware{},
+					Services:    map[string]*config.TCPService{},
+				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
 				},
 				HTTP: &config.HTTPConfiguration{
 					Routers:     map[string]*config.Router{},
@@ -289,8 +319,13 @@ func Test_buildConfiguration(t *testing.T) {
 			},
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers:  map[string]*config.TCPRouter{},
-					Services: map[string]*config.TCPService{},
+					Routers:     map[string]*config.TCPRouter{},
+					Middlewares: map[string]*config.TCPMiddleware{},
+					Services:    map[string]*config.TCPService{},
+				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
 				},
 				HTTP: &config.HTTPConfiguration{
 					Routers: map[string]*config.Router{
@@ -332,8 +367,13 @@ func Test_buildConfiguration(t *testing.T) {
 			constraints: `Label("traefik.tags", "bar")`,
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers:  map[string]*config.TCPRouter{},
-					Services: map[string]*config.TCPService{},
+					Routers:     map[string]*config.TCPRouter{},
+					Middlewares: map[string]*config.TCPMiddleware{},
+					Services:    map[string]*config.TCPService{},
+				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
 				},
 				HTTP: &config.HTTPConfiguration{
 					Routers:     map[string]*config.Router{},
@@ -359,8 +399,13 @@ func Test_buildConfiguration(t *testing.T) {
 			constraints: `Label("traefik.tags", "foo")`,
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers:  map[string]*config.TCPRouter{},
-					Services: map[string]*config.TCPService{},
+					Routers:     map[string]*config.TCPRouter{},
+					Middlewares: map[string]*config.TCPMiddleware{},
+					Services:    map[string]*config.TCPService{},
+				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
 				},
 				HTTP: &config.HTTPConfiguration{
 					Routers: map[string]*config.Router{
@@ -402,8 +447,13 @@ func Test_buildConfiguration(t *testing.T) {
 			},
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers:  map[string]*config.TCPRouter{},
-					Services: map[string]*config.TCPService{},
+					Routers:     map[string]*config.TCPRouter{},
+					Middlewares: map[string]*config.TCPMiddleware{},
+					Services:    map[string]*config.TCPService{},
+				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
 				},
 				HTTP: &config.HTTPConfiguration{
 					Routers: map[string]*config.Router{
@@ -454,8 +504,13 @@ func Test_buildConfiguration(t *testing.T) {
 			},
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers:  map[string]*config.TCPRouter{},
-					Services: map[string]*config.TCPService{},
+					Routers:     map[string]*config.TCPRouter{},
+					Middlewares: map[string]*config.TCPMiddleware{},
+					Services:    map[string]*config.TCPService{},
+				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
 				},
 				HTTP: &config.HTTPConfiguration{
 					Routers: map[string]*config.Router{
@@ -505,6 +560,7 @@ func Test_buildConfiguration(t *testing.T) {
 							TLS:     &config.RouterTCPTLSConfig{},
 						},
 					},
+					Middlewares: map[string]*config.TCPMiddleware{},
 					Services: map[string]*config.TCPService{
 						"Test": {
 							LoadBalancer: &config.TCPLoadBalancerService{
@@ -517,6 +573,10 @@ func Test_buildConfiguration(t *testing.T) {
 						},
 					},
 				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
+				},
 				HTTP: &config.HTTPConfiguration{
 					Routers:     map[string]*config.Router{},
 					Middlewares: map[string]*config.Middleware{},
@@ -540,7 +600,8 @@ func Test_buildConfiguration(t *testing.T) {
 			},
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers: map[string]*config.TCPRouter{},
+					Routers:     map[string]*config.TCPRouter{},
+					Middlewares: map[string]*config.TCPMiddleware{},
 					Services: map[string]*config.TCPService{
 						"Test": {
 							LoadBalancer: &config.TCPLoadBalancerService{
@@ -553,6 +614,10 @@ func Test_buildConfiguration(t *testing.T) {
 						},
 					},
 				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
+				},
 				HTTP: &config.HTTPConfiguration{
 					Routers:     map[string]*config.Router{},
 					Middlewares: map[string]*config.Middleware{},
@@ -583,6 +648,7 @@ func Test_buildConfiguration(t *testing.T) {
 							Rule:    "HostSNI(`foo.bar`)",
 						},
 					},
+					Middlewares: map[string]*config.TCPMiddleware{},
 					Services: map[string]*config.TCPService{
 						"foo": {
 							LoadBalancer: &config.TCPLoadBalancerService{
@@ -595,6 +661,10 @@ func Test_buildConfiguration(t *testing.T) {
 						},
 					},
 				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
+				},
 				HTTP: &config.HTTPConfiguration{
 					Routers:     map[string]*config.Router{},
 					Middlewares: map[string]*config.Middleware{},
@@ -628,6 +698,7 @@ func Test_buildConfiguration(t *testing.T) {
 							TLS:     &config.RouterTCPTLSConfig{},
 						},
 					},
+					Middlewares: map[string]*config.TCPMiddleware{},
 					Services: map[string]*config.TCPService{
 						"foo": {
 							LoadBalancer: &config.TCPLoadBalancerService{
@@ -643,6 +714,10 @@ func Test_buildConfiguration(t *testing.T) {
 						},
 					},
 				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
+				},
 				HTTP: &config.HTTPConfiguration{
 					Routers: map[string]*config.Router{
 						"Test": {
@@ -685,7 +760,8 @@ func Test_buildConfiguration(t *testing.T) {
 			},
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers: map[string]*config.TCPRouter{},
+					Routers:     map[string]*config.TCPRouter{},
+					Middlewares: map[string]*config.TCPMiddleware{},
 					Services: map[string]*config.TCPService{
 						"foo": {
 							LoadBalancer: &config.TCPLoadBalancerService{
@@ -698,6 +774,10 @@ func Test_buildConfiguration(t *testing.T) {
 						},
 					},
 				},
+				UDP: &config.UDPConfiguration{
+					Routers:  map[string]*config.UDPRouter{},
+					Services: map[string]*config.UDPService{},
+				},
 				HTTP: &config.HTTPConfiguration{
 					Routers:     map[string]*config.Router{},
 					Middlewares: map[string]*config.Middleware{},
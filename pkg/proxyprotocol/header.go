@@ -0,0 +1,113 @@
+package proxyprotocol
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+)
+
+// v2Signature starts every PROXY protocol v2 header.
+var v2Signature = []byte("\r\n\r\n\x00\r\nQUIT\n")
+
+const (
+	// v2VersionCommandProxy is the version 2 with the PROXY command.
+	v2VersionCommandProxy = 0x21
+
+	v2FamilyUnspec = 0x00
+	v2FamilyTCP4   = 0x11
+	v2FamilyTCP6   = 0x21
+)
+
+// WriteHeader writes the PROXY protocol header, in the given version (1 or 2),
+// telling that the connection is proxied from the source address to the destination address.
+// When the addresses are not both TCP addresses, the header does not carry them (UNKNOWN in v1, UNSPEC in v2).
+func WriteHeader(w io.Writer, version int, src, dst net.Addr) error {
+	var header []byte
+	switch version {
+	case 1:
+		header = headerV1(src, dst)
+	case 2:
+		header = headerV2(src, dst)
+	default:
+		return fmt.Errorf("unsupported PROXY protocol version %d", version)
+	}
+
+	_, err := w.Write(header)
+	return err
+}
+
+func headerV1(src, dst net.Addr) []byte {
+	srcIP, srcPort, dstIP, dstPort, ok := tcpAddresses(src, dst)
+	if !ok {
+		return []byte("PROXY UNKNOWN\r\n")
+	}
+
+	protocol := "TCP4"
+	if len(srcIP) == net.IPv6len {
+		protocol = "TCP6"
+	}
+
+	return []byte("PROXY " + protocol + " " + ipString(srcIP) + " " + ipString(dstIP) + " " +
+		strconv.Itoa(srcPort) + " " + strconv.Itoa(dstPort) + "\r\n")
+}
+
+// ipString returns the textual form of the IP, an IPv4-mapped IPv6 address being kept in the IPv6 form.
+func ipString(ip net.IP) string {
+	if len(ip) == net.IPv6len && ip.To4() != nil {
+		return "::ffff:" + ip.To4().String()
+	}
+	return ip.String()
+}
+
+func headerV2(src, dst net.Addr) []byte {
+	buf := bytes.NewBuffer(append([]byte{}, v2Signature...))
+	buf.WriteByte(v2VersionCommandProxy)
+
+	srcIP, srcPort, dstIP, dstPort, ok := tcpAddresses(src, dst)
+	if !ok {
+		buf.WriteByte(v2FamilyUnspec)
+		_ = binary.Write(buf, binary.BigEndian, uint16(0))
+		return buf.Bytes()
+	}
+
+	if len(srcIP) == net.IPv4len {
+		buf.WriteByte(v2FamilyTCP4)
+	} else {
+		buf.WriteByte(v2FamilyTCP6)
+	}
+	_ = binary.Write(buf, binary.BigEndian, uint16(2*len(srcIP)+4))
+	buf.Write(srcIP)
+	buf.Write(dstIP)
+
+	_ = binary.Write(buf, binary.BigEndian, uint16(srcPort))
+	_ = binary.Write(buf, binary.BigEndian, uint16(dstPort))
+
+	return buf.Bytes()
+}
+
+// tcpAddresses returns the IPs and ports of the TCP addresses, both IPs having the same length:
+// 4 bytes for IPv4 addresses, and 16 bytes when one of them is an IPv6 address,
+// the other one being then returned as an IPv4-mapped IPv6 address.
+func tcpAddresses(src, dst net.Addr) (net.IP, int, net.IP, int, bool) {
+	srcTCP, ok := src.(*net.TCPAddr)
+	if !ok || srcTCP == nil || srcTCP.IP == nil {
+		return nil, 0, nil, 0, false
+	}
+
+	dstTCP, ok := dst.(*net.TCPAddr)
+	if !ok || dstTCP == nil || dstTCP.IP == nil {
+		return nil, 0, nil, 0, false
+	}
+
+	srcIP, dstIP := srcTCP.IP, dstTCP.IP
+	if srcIP.To4() == nil || dstIP.To4() == nil {
+		srcIP, dstIP = srcIP.To16(), dstIP.To16()
+	} else {
+		srcIP, dstIP = srcIP.To4(), dstIP.To4()
+	}
+
+	return srcIP, srcTCP.Port, dstIP, dstTCP.Port, true
+}
@@ -0,0 +1,101 @@
+package proxyprotocol
+
+import (
+	"bytes"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteHeader(t *testing.T) {
+	v4Src := &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 4242}
+	v4Dst := &net.TCPAddr{IP: net.ParseIP("10.0.0.2"), Port: 443}
+	v6Src := &net.TCPAddr{IP: net.ParseIP("2001:db8::1"), Port: 4242}
+
+	testCases := []struct {
+		desc          string
+		version       int
+		src           net.Addr
+		dst           net.Addr
+		expected      []byte
+		expectedError bool
+	}{
+		{
+			desc:     "v1 TCP4",
+			version:  1,
+			src:      v4Src,
+			dst:      v4Dst,
+			expected: []byte("PROXY TCP4 10.0.0.1 10.0.0.2 4242 443\r\n"),
+		},
+		{
+			desc:     "v1 TCP6, with an IPv4 destination",
+			version:  1,
+			src:      v6Src,
+			dst:      v4Dst,
+			expected: []byte("PROXY TCP6 2001:db8::1 ::ffff:10.0.0.2 4242 443\r\n"),
+		},
+		{
+			desc:     "v1 unknown addresses",
+			version:  1,
+			src:      &net.UnixAddr{Name: "foo", Net: "unix"},
+			dst:      v4Dst,
+			expected: []byte("PROXY UNKNOWN\r\n"),
+		},
+		{
+			desc:    "v2 TCP4",
+			version: 2,
+			src:     v4Src,
+			dst:     v4Dst,
+			expected: append([]byte("\r\n\r\n\x00\r\nQUIT\n"),
+				0x21, 0x11, 0x00, 0x0c,
+				10, 0, 0, 1,
+				10, 0, 0, 2,
+				0x10, 0x92,
+				0x01, 0xbb),
+		},
+		{
+			desc:    "v2 TCP6, with an IPv4 destination",
+			version: 2,
+			src:     v6Src,
+			dst:     v4Dst,
+			expected: append([]byte("\r\n\r\n\x00\r\nQUIT\n"),
+				0x21, 0x21, 0x00, 0x24,
+				0x20, 0x01, 0x0d, 0xb8, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1,
+				0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0xff, 0xff, 10, 0, 0, 2,
+				0x10, 0x92,
+				0x01, 0xbb),
+		},
+		{
+			desc:     "v2 unknown addresses",
+			version:  2,
+			dst:      v4Dst,
+			expected: append([]byte("\r\n\r\n\x00\r\nQUIT\n"), 0x21, 0x00, 0x00, 0x00),
+		},
+		{
+			desc:          "unsupported version",
+			version:       3,
+			src:           v4Src,
+			dst:           v4Dst,
+			expectedError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			buf := &bytes.Buffer{}
+			err := WriteHeader(buf, test.version, test.src, test.dst)
+			if test.expectedError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected, buf.Bytes())
+		})
+	}
+}
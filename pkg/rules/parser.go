@@ -30,7 +30,6 @@ func ParseDomains(rule string) ([]string, error) {
 }
 
 // ParseHostSNI extracts the HostSNIs declared in a rule
-// This is a first naive implementation used in TCP routing
 func ParseHostSNI(rule string) ([]string, error) {
 	parser, err := newTCPParser()
 	if err != nil {
@@ -50,6 +49,26 @@ func ParseHostSNI(rule string) ([]string, error) {
 	return lower(parseDomain(buildTree())), nil
 }
 
+// ParseALPN extracts the protocols of the ALPN matchers declared in a TCP rule.
+func ParseALPN(rule string) ([]string, error) {
+	parser, err := newTCPParser()
+	if err != nil {
+		return nil, err
+	}
+
+	parse, err := parser.Parse(rule)
+	if err != nil {
+		return nil, err
+	}
+
+	buildTree, ok := parse.(treeBuilder)
+	if !ok {
+		return nil, errors.New("cannot parse")
+	}
+
+	return parseALPN(buildTree()), nil
+}
+
 func lower(slice []string) []string {
 	var lowerStrings []string
 	for _, value := range slice {
@@ -69,6 +88,17 @@ func parseDomain(tree *tree) []string {
 	}
 }
 
+func parseALPN(tree *tree) []string {
+	switch tree.matcher {
+	case "and", "or":
+		return append(parseALPN(tree.ruleLeft), parseALPN(tree.ruleRight)...)
+	case "ALPN":
+		return tree.value
+	default:
+		return nil
+	}
+}
+
 func andFunc(left, right treeBuilder) treeBuilder {
 	return func() *tree {
 		return &tree{
@@ -120,24 +150,26 @@ func newParser() (predicate.Parser, error) {
 func newTCPParser() (predicate.Parser, error) {
 	parserFuncs := make(map[string]interface{})
 
-	// FIXME quircky way of waiting for new rules
-	matcherName := "HostSNI"
-	fn := func(value ...string) treeBuilder {
-		return func() *tree {
-			return &tree{
-				matcher: matcherName,
-				value:   value,
+	for matcherName := range tcpFuncs {
+		matcherName := matcherName
+		fn := func(value ...string) treeBuilder {
+			return func() *tree {
+				return &tree{
+					matcher: matcherName,
+					value:   value,
+				}
 			}
 		}
+		parserFuncs[matcherName] = fn
+		parserFuncs[strings.ToLower(matcherName)] = fn
+		parserFuncs[strings.ToUpper(matcherName)] = fn
+		parserFuncs[strings.Title(strings.ToLower(matcherName))] = fn
 	}
-	parserFuncs[matcherName] = fn
-	parserFuncs[strings.ToLower(matcherName)] = fn
-	parserFuncs[strings.ToUpper(matcherName)] = fn
-	parserFuncs[strings.Title(strings.ToLower(matcherName))] = fn
 
 	return predicate.NewParser(predicate.Def{
 		Operators: predicate.Operators{
-			OR: orFunc,
+			AND: andFunc,
+			OR:  orFunc,
 		},
 		Functions: parserFuncs,
 	})
@@ -0,0 +1,162 @@
+package rules
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/containous/mux"
+	"github.com/containous/traefik/pkg/ip"
+	"github.com/containous/traefik/pkg/tcp"
+)
+
+var tcpFuncs = map[string]func(...string) (tcp.MatcherFunc, error){
+	"HostSNI":       hostSNI,
+	"HostSNIRegexp": hostSNIRegexp,
+	"ClientIP":      clientIP,
+	"ALPN":          alpn,
+}
+
+// ParseTCPRule parses a TCP router rule, and returns the matcher of the connections.
+func ParseTCPRule(rule string) (tcp.MatcherFunc, error) {
+	parser, err := newTCPParser()
+	if err != nil {
+		return nil, err
+	}
+
+	parse, err := parser.Parse(rule)
+	if err != nil {
+		return nil, fmt.Errorf("error while parsing rule %s: %v", rule, err)
+	}
+
+	buildTree, ok := parse.(treeBuilder)
+	if !ok {
+		return nil, fmt.Errorf("error while parsing rule %s", rule)
+	}
+
+	return buildTCPMatcher(buildTree())
+}
+
+func buildTCPMatcher(rule *tree) (tcp.MatcherFunc, error) {
+	switch rule.matcher {
+	case "and", "or":
+		left, err := buildTCPMatcher(rule.ruleLeft)
+		if err != nil {
+			return nil, err
+		}
+
+		right, err := buildTCPMatcher(rule.ruleRight)
+		if err != nil {
+			return nil, err
+		}
+
+		if rule.matcher == "and" {
+			return func(data tcp.ConnData) bool { return left(data) && right(data) }, nil
+		}
+		return func(data tcp.ConnData) bool { return left(data) || right(data) }, nil
+	default:
+		err := checkRule(rule)
+		if err != nil {
+			return nil, err
+		}
+
+		return tcpFuncs[rule.matcher](rule.value...)
+	}
+}
+
+// hostSNI matches the server name of the connection against the hosts.
+// The * host matches any connection, even without server name,
+// and a host starting with *. matches the server names with exactly one more label, e.g. *.example.com matches foo.example.com.
+func hostSNI(hosts ...string) (tcp.MatcherFunc, error) {
+	var suffixes []string
+	exact := make(map[string]struct{})
+	for _, host := range hosts {
+		host = strings.ToLower(host)
+
+		switch {
+		case host == "*":
+			return func(tcp.ConnData) bool { return true }, nil
+		case strings.HasPrefix(host, "*."):
+			if strings.Contains(host[2:], "*") {
+				return nil, fmt.Errorf("invalid HostSNI %q, only the leftmost label can be a wildcard", host)
+			}
+			suffixes = append(suffixes, host[1:])
+		case strings.Contains(host, "*"):
+			return nil, fmt.Errorf("invalid HostSNI %q, only the leftmost label can be a wildcard", host)
+		default:
+			exact[host] = struct{}{}
+		}
+	}
+
+	return func(data tcp.ConnData) bool {
+		if _, ok := exact[data.ServerName]; ok {
+			return true
+		}
+
+		for _, suffix := range suffixes {
+			if !strings.HasSuffix(data.ServerName, suffix) {
+				continue
+			}
+
+			label := data.ServerName[:len(data.ServerName)-len(suffix)]
+			if len(label) > 0 && !strings.Contains(label, ".") {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+// hostSNIRegexp matches the server name of the connection against the host templates,
+// with the same syntax as the HostRegexp matcher of the HTTP routers.
+func hostSNIRegexp(hosts ...string) (tcp.MatcherFunc, error) {
+	router := mux.NewRouter()
+	for _, host := range hosts {
+		route := router.NewRoute().Host(host)
+		if route.GetError() != nil {
+			return nil, route.GetError()
+		}
+	}
+
+	return func(data tcp.ConnData) bool {
+		if len(data.ServerName) == 0 {
+			return false
+		}
+
+		req := &http.Request{Host: data.ServerName, URL: &url.URL{}}
+		return router.Match(req, &mux.RouteMatch{})
+	}, nil
+}
+
+// clientIP matches the remote address of the connection against the IPs and CIDR ranges.
+func clientIP(ranges ...string) (tcp.MatcherFunc, error) {
+	checker, err := ip.NewChecker(ranges)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ClientIP: %v", err)
+	}
+
+	return func(data tcp.ConnData) bool {
+		if len(data.RemoteIP) == 0 {
+			return false
+		}
+
+		ok, err := checker.Contains(data.RemoteIP)
+		return err == nil && ok
+	}, nil
+}
+
+// alpn matches the connections offering at least one of the protocols in their TLS ClientHello.
+// The protocols are case-sensitive, as they are compared byte for byte during the negotiation.
+func alpn(protos ...string) (tcp.MatcherFunc, error) {
+	return func(data tcp.ConnData) bool {
+		for _, offered := range data.ALPNProtos {
+			for _, proto := range protos {
+				if offered == proto {
+					return true
+				}
+			}
+		}
+		return false
+	}, nil
+}
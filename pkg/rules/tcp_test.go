@@ -0,0 +1,194 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/containous/traefik/pkg/tcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// connMatch is a connection, along with whether it matches the rule.
+type connMatch struct {
+	connData tcp.ConnData
+	matches  bool
+}
+
+func TestParseTCPRule(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		rule          string
+		expected      []connMatch
+		expectedError bool
+	}{
+		{
+			desc:          "empty rule",
+			rule:          "",
+			expectedError: true,
+		},
+		{
+			desc:          "unknown matcher",
+			rule:          "Host(`foo.bar`)",
+			expectedError: true,
+		},
+		{
+			desc:          "no args",
+			rule:          "HostSNI()",
+			expectedError: true,
+		},
+		{
+			desc: "exact host",
+			rule: "HostSNI(`Foo.bar`, `bar.foo`)",
+			expected: []connMatch{
+				{tcp.ConnData{ServerName: "foo.bar"}, true},
+				{tcp.ConnData{ServerName: "bar.foo"}, true},
+				{tcp.ConnData{ServerName: "sub.foo.bar"}, false},
+				{tcp.ConnData{ServerName: ""}, false},
+			},
+		},
+		{
+			desc: "catch-all host",
+			rule: "HostSNI(`*`)",
+			expected: []connMatch{
+				{tcp.ConnData{ServerName: "foo.bar"}, true},
+				{tcp.ConnData{ServerName: ""}, true},
+			},
+		},
+		{
+			desc: "wildcard host",
+			rule: "HostSNI(`*.example.com`)",
+			expected: []connMatch{
+				{tcp.ConnData{ServerName: "tenant.example.com"}, true},
+				{tcp.ConnData{ServerName: "a.tenant.example.com"}, false},
+				{tcp.ConnData{ServerName: "example.com"}, false},
+				{tcp.ConnData{ServerName: ".example.com"}, false},
+				{tcp.ConnData{ServerName: "tenant.example.com.org"}, false},
+			},
+		},
+		{
+			desc:          "wildcard not in the leftmost label",
+			rule:          "HostSNI(`foo.*.com`)",
+			expectedError: true,
+		},
+		{
+			desc:          "wildcard in a label",
+			rule:          "HostSNI(`foo*.example.com`)",
+			expectedError: true,
+		},
+		{
+			desc: "host regexp",
+			rule: "HostSNIRegexp(`{tenant:[a-z]+}.example.com`)",
+			expected: []connMatch{
+				{tcp.ConnData{ServerName: "tenant.example.com"}, true},
+				{tcp.ConnData{ServerName: "tenant1.example.com"}, false},
+				{tcp.ConnData{ServerName: "example.com"}, false},
+				{tcp.ConnData{ServerName: ""}, false},
+			},
+		},
+		{
+			desc:          "invalid host regexp",
+			rule:          "HostSNIRegexp(`{tenant:[a-z+}.example.com`)",
+			expectedError: true,
+		},
+		{
+			desc: "client IP",
+			rule: "ClientIP(`10.0.0.0/8`, `192.168.1.1`)",
+			expected: []connMatch{
+				{tcp.ConnData{RemoteIP: "10.1.2.3"}, true},
+				{tcp.ConnData{RemoteIP: "192.168.1.1"}, true},
+				{tcp.ConnData{RemoteIP: "192.168.1.2"}, false},
+				{tcp.ConnData{RemoteIP: ""}, false},
+			},
+		},
+		{
+			desc:          "invalid client IP",
+			rule:          "ClientIP(`10.0.0.0/33`)",
+			expectedError: true,
+		},
+		{
+			desc: "ALPN",
+			rule: "ALPN(`h2`, `acme-tls/1`, `postgresql`)",
+			expected: []connMatch{
+				{tcp.ConnData{ALPNProtos: []string{"postgresql"}}, true},
+				{tcp.ConnData{ALPNProtos: []string{"http/1.1", "h2"}}, true},
+				{tcp.ConnData{ALPNProtos: []string{"H2"}}, false},
+				{tcp.ConnData{ALPNProtos: []string{"http/1.1"}}, false},
+				{tcp.ConnData{}, false},
+			},
+		},
+		{
+			desc:          "empty ALPN",
+			rule:          "ALPN(``)",
+			expectedError: true,
+		},
+		{
+			desc: "HostSNI and ALPN",
+			rule: "HostSNI(`foo.bar`) && ALPN(`h2`)",
+			expected: []connMatch{
+				{tcp.ConnData{ServerName: "foo.bar", ALPNProtos: []string{"h2"}}, true},
+				{tcp.ConnData{ServerName: "foo.bar", ALPNProtos: []string{"http/1.1"}}, false},
+				{tcp.ConnData{ServerName: "bar.foo", ALPNProtos: []string{"h2"}}, false},
+			},
+		},
+		{
+			desc: "and",
+			rule: "HostSNI(`*.example.com`) && ClientIP(`10.0.0.0/8`)",
+			expected: []connMatch{
+				{tcp.ConnData{ServerName: "tenant.example.com", RemoteIP: "10.1.2.3"}, true},
+				{tcp.ConnData{ServerName: "tenant.example.com", RemoteIP: "192.168.1.1"}, false},
+				{tcp.ConnData{ServerName: "foo.bar", RemoteIP: "10.1.2.3"}, false},
+			},
+		},
+		{
+			desc: "or",
+			rule: "HostSNI(`foo.bar`) || ClientIP(`10.0.0.0/8`)",
+			expected: []connMatch{
+				{tcp.ConnData{ServerName: "foo.bar", RemoteIP: "192.168.1.1"}, true},
+				{tcp.ConnData{ServerName: "bar.foo", RemoteIP: "10.1.2.3"}, true},
+				{tcp.ConnData{ServerName: "bar.foo", RemoteIP: "192.168.1.1"}, false},
+			},
+		},
+		{
+			desc: "and with or in parentheses",
+			rule: "ClientIP(`10.0.0.0/8`) && (HostSNI(`foo.bar`) || HostSNIRegexp(`{tenant:[a-z]+}.example.com`))",
+			expected: []connMatch{
+				{tcp.ConnData{ServerName: "foo.bar", RemoteIP: "10.1.2.3"}, true},
+				{tcp.ConnData{ServerName: "tenant.example.com", RemoteIP: "10.1.2.3"}, true},
+				{tcp.ConnData{ServerName: "foo.bar", RemoteIP: "192.168.1.1"}, false},
+				{tcp.ConnData{ServerName: "bar.foo", RemoteIP: "10.1.2.3"}, false},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			matcher, err := ParseTCPRule(test.rule)
+			if test.expectedError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			for _, expected := range test.expected {
+				assert.Equal(t, expected.matches, matcher(expected.connData), "%+v", expected.connData)
+			}
+		})
+	}
+}
+
+func TestParseHostSNI(t *testing.T) {
+	domains, err := ParseHostSNI("(HostSNI(`Foo.bar`) || HostSNI(`*.example.com`)) && ClientIP(`10.0.0.0/8`)")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"foo.bar", "*.example.com"}, domains)
+}
+
+func TestParseALPN(t *testing.T) {
+	protos, err := ParseALPN("HostSNI(`foo.bar`) && (ALPN(`h2`) || ALPN(`postgresql`, `acme-tls/1`))")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"h2", "postgresql", "acme-tls/1"}, protos)
+}
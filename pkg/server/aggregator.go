@@ -10,13 +10,19 @@ import (
 func mergeConfiguration(configurations config.Configurations) config.Configuration {
 	conf := config.Configuration{
 		HTTP: &config.HTTPConfiguration{
-			Routers:     make(map[string]*config.Router),
-			Middlewares: make(map[string]*config.Middleware),
-			Services:    make(map[string]*config.Service),
+			Routers:           make(map[string]*config.Router),
+			Middlewares:       make(map[string]*config.Middleware),
+			Services:          make(map[string]*config.Service),
+			ServersTransports: make(map[string]*config.ServersTransport),
 		},
 		TCP: &config.TCPConfiguration{
-			Routers:  make(map[string]*config.TCPRouter),
-			Services: make(map[string]*config.TCPService),
+			Routers:     make(map[string]*config.TCPRouter),
+			Middlewares: make(map[string]*config.TCPMiddleware),
+			Services:    make(map[string]*config.TCPService),
+		},
+		UDP: &config.UDPConfiguration{
+			Routers:  make(map[string]*config.UDPRouter),
+			Services: make(map[string]*config.UDPService),
 		},
 		TLSOptions: make(map[string]tls.TLS),
 		TLSStores:  make(map[string]tls.Store),
@@ -34,16 +40,31 @@ func mergeConfiguration(configurations config.Configurations) config.Configurati
 			for serviceName, service := range configuration.HTTP.Services {
 				conf.HTTP.Services[internal.MakeQualifiedName(provider, serviceName)] = service
 			}
+			for transportName, transport := range configuration.HTTP.ServersTransports {
+				conf.HTTP.ServersTransports[internal.MakeQualifiedName(provider, transportName)] = transport
+			}
 		}
 
 		if configuration.TCP != nil {
 			for routerName, router := range configuration.TCP.Routers {
 				conf.TCP.Routers[internal.MakeQualifiedName(provider, routerName)] = router
 			}
+			for middlewareName, middleware := range configuration.TCP.Middlewares {
+				conf.TCP.Middlewares[internal.MakeQualifiedName(provider, middlewareName)] = middleware
+			}
 			for serviceName, service := range configuration.TCP.Services {
 				conf.TCP.Services[internal.MakeQualifiedName(provider, serviceName)] = service
 			}
 		}
+
+		if configuration.UDP != nil {
+			for routerName, router := range configuration.UDP.Routers {
+				conf.UDP.Routers[internal.MakeQualifiedName(provider, routerName)] = router
+			}
+			for serviceName, service := range configuration.UDP.Services {
+				conf.UDP.Services[internal.MakeQualifiedName(provider, serviceName)] = service
+			}
+		}
 		conf.TLS = append(conf.TLS, configuration.TLS...)
 
 		for key, store := range configuration.TLSStores {
@@ -18,9 +18,10 @@ func TestAggregator(t *testing.T) {
 			desc:  "Nil returns an empty configuration",
 			given: nil,
 			expected: &config.HTTPConfiguration{
-				Routers:     make(map[string]*config.Router),
-				Middlewares: make(map[string]*config.Middleware),
-				Services:    make(map[string]*config.Service),
+				Routers:           make(map[string]*config.Router),
+				Middlewares:       make(map[string]*config.Middleware),
+				Services:          make(map[string]*config.Service),
+				ServersTransports: make(map[string]*config.ServersTransport),
 			},
 		},
 		{
@@ -37,6 +38,9 @@ func TestAggregator(t *testing.T) {
 						Services: map[string]*config.Service{
 							"service-1": {},
 						},
+						ServersTransports: map[string]*config.ServersTransport{
+							"transport-1": {},
+						},
 					},
 				},
 			},
@@ -50,6 +54,9 @@ func TestAggregator(t *testing.T) {
 				Services: map[string]*config.Service{
 					"service-1@provider-1": {},
 				},
+				ServersTransports: map[string]*config.ServersTransport{
+					"transport-1@provider-1": {},
+				},
 			},
 		},
 		{
@@ -66,6 +73,9 @@ func TestAggregator(t *testing.T) {
 						Services: map[string]*config.Service{
 							"service-1": {},
 						},
+						ServersTransports: map[string]*config.ServersTransport{
+							"transport-1": {},
+						},
 					},
 				},
 				"provider-2": &config.Configuration{
@@ -79,6 +89,9 @@ func TestAggregator(t *testing.T) {
 						Services: map[string]*config.Service{
 							"service-1": {},
 						},
+						ServersTransports: map[string]*config.ServersTransport{
+							"transport-1": {},
+						},
 					},
 				},
 			},
@@ -95,6 +108,10 @@ func TestAggregator(t *testing.T) {
 					"service-1@provider-1": {},
 					"service-1@provider-2": {},
 				},
+				ServersTransports: map[string]*config.ServersTransport{
+					"transport-1@provider-1": {},
+					"transport-1@provider-2": {},
+				},
 			},
 		},
 	}
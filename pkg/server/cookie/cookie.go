@@ -1,14 +1,22 @@
 package cookie
 
 import (
+	"crypto/hmac"
+	"crypto/rand"
 	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"strings"
 
 	"github.com/containous/traefik/pkg/log"
 )
 
-const cookieNameLength = 6
+const (
+	cookieNameLength = 6
+	// valueLength is the length of the hashed cookie values, in hexadecimal digits.
+	valueLength = 32
+)
 
 // GetName of a cookie
 func GetName(cookieName string, backendName string) string {
@@ -33,6 +41,33 @@ func GenerateName(backendName string) string {
 	return fmt.Sprintf("_%x", hash.Sum(nil))[:cookieNameLength]
 }
 
+// generatedSecret is the secret of the values hashed without a secret.
+// It is generated once per process, so that the hashed values do not change with the configuration reloads,
+// but cannot be computed from the hashed value alone.
+var generatedSecret = generateSecret()
+
+func generateSecret() string {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		// Impossible case
+		log.Errorf("Fail to generate the cookie secret: %v", err)
+	}
+	return hex.EncodeToString(secret)
+}
+
+// HashValue returns an opaque value identifying the given value (typically a server URL), which does not leak it.
+// The value is hashed with HMAC-SHA256, keyed with the given secret,
+// or with a secret generated when the process starts if the given one is empty.
+func HashValue(value string, secret string) string {
+	if secret == "" {
+		secret = generatedSecret
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))[:valueLength]
+}
+
 // sanitizeName According to [RFC 2616](https://www.ietf.org/rfc/rfc2616.txt) section 2.2
 func sanitizeName(backend string) string {
 	sanitizer := func(r rune) rune {
@@ -1,6 +1,8 @@
 package cookie
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -81,3 +83,20 @@ func TestGenerateName(t *testing.T) {
 	assert.Len(t, "_8a7bc", 6)
 	assert.Equal(t, "_8a7bc", cookieName)
 }
+
+func TestHashValue(t *testing.T) {
+	hashed := HashValue("http://10.0.0.1:80", "")
+	assert.Len(t, hashed, 32)
+	assert.NotContains(t, hashed, "10.0.0.1")
+	assert.Equal(t, hashed, HashValue("http://10.0.0.1:80", ""))
+	assert.NotEqual(t, hashed, HashValue("http://10.0.0.2:80", ""))
+
+	// Without a secret, the value is not a plain hash of the URL, which could be computed from the URLs of the servers.
+	sum := sha256.Sum256([]byte("http://10.0.0.1:80"))
+	assert.NotEqual(t, hex.EncodeToString(sum[:])[:32], hashed)
+
+	signed := HashValue("http://10.0.0.1:80", "secret")
+	assert.Len(t, signed, 32)
+	assert.NotEqual(t, hashed, signed)
+	assert.NotEqual(t, signed, HashValue("http://10.0.0.1:80", "other"))
+}
@@ -11,6 +11,7 @@ type contextKey int
 
 const (
 	providerKey contextKey = iota
+	serviceKey
 )
 
 // AddProviderInContext Adds the provider name in the context
@@ -43,3 +44,14 @@ func GetQualifiedName(ctx context.Context, elementName string) string {
 func MakeQualifiedName(providerName string, elementName string) string {
 	return elementName + "@" + providerName
 }
+
+// AddServiceInContext Adds the name of the service handling the requests in the context
+func AddServiceInContext(ctx context.Context, serviceName string) context.Context {
+	return context.WithValue(ctx, serviceKey, serviceName)
+}
+
+// GetServiceName Gets the name of the service handling the requests, or an empty string if it is unknown.
+func GetServiceName(ctx context.Context) string {
+	serviceName, _ := ctx.Value(serviceKey).(string)
+	return serviceName
+}
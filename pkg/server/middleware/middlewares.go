@@ -9,6 +9,8 @@ import (
 
 	"github.com/containous/alice"
 	"github.com/containous/traefik/pkg/config"
+	"github.com/containous/traefik/pkg/metrics"
+	"github.com/containous/traefik/pkg/middlewares/accesslog"
 	"github.com/containous/traefik/pkg/middlewares/addprefix"
 	"github.com/containous/traefik/pkg/middlewares/auth"
 	"github.com/containous/traefik/pkg/middlewares/buffering"
@@ -39,8 +41,10 @@ const (
 
 // Builder the middleware builder
 type Builder struct {
-	configs        map[string]*config.MiddlewareInfo
-	serviceBuilder serviceBuilder
+	configs         map[string]*config.MiddlewareInfo
+	serviceBuilder  serviceBuilder
+	stateManager    *StateManager
+	metricsRegistry metrics.Registry
 }
 
 type serviceBuilder interface {
@@ -48,8 +52,8 @@ type serviceBuilder interface {
 }
 
 // NewBuilder creates a new Builder
-func NewBuilder(configs map[string]*config.MiddlewareInfo, serviceBuilder serviceBuilder) *Builder {
-	return &Builder{configs: configs, serviceBuilder: serviceBuilder}
+func NewBuilder(configs map[string]*config.MiddlewareInfo, serviceBuilder serviceBuilder, stateManager *StateManager, metricsRegistry metrics.Registry) *Builder {
+	return &Builder{configs: configs, serviceBuilder: serviceBuilder, stateManager: stateManager, metricsRegistry: metricsRegistry}
 }
 
 // BuildChain creates a middleware chain
@@ -288,8 +292,13 @@ func (b *Builder) buildConstructor(ctx context.Context, middlewareName string) (
 			return nil, badConf
 		}
 		middleware = func(next http.Handler) (http.Handler, error) {
-			// FIXME missing metrics / accessLog
-			return retry.New(ctx, next, *config.Retry, retry.Listeners{}, middlewareName)
+			listeners := retry.Listeners{
+				&accesslog.SaveRetries{},
+				retry.NewMetricsListener(b.metricsRegistry, internal.GetServiceName(ctx)),
+			}
+			// The retry budget is shared by the retry middlewares of the service.
+			budgetCounters := b.stateManager.retryBudgets.Get(internal.GetServiceName(ctx))
+			return retry.New(ctx, next, *config.Retry, budgetCounters, listeners, middlewareName)
 		}
 	}
 
@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/containous/traefik/pkg/config"
+	"github.com/containous/traefik/pkg/metrics"
 	"github.com/containous/traefik/pkg/server/internal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -17,7 +18,7 @@ func TestBuilder_BuildChainNilConfig(t *testing.T) {
 	testConfig := map[string]*config.MiddlewareInfo{
 		"empty": {},
 	}
-	middlewaresBuilder := NewBuilder(testConfig, nil)
+	middlewaresBuilder := NewBuilder(testConfig, nil, NewStateManager(), metrics.NewVoidRegistry())
 
 	chain := middlewaresBuilder.BuildChain(context.Background(), []string{"empty"})
 	_, err := chain.Then(nil)
@@ -28,7 +29,7 @@ func TestBuilder_BuildChainNonExistentChain(t *testing.T) {
 	testConfig := map[string]*config.MiddlewareInfo{
 		"foobar": {},
 	}
-	middlewaresBuilder := NewBuilder(testConfig, nil)
+	middlewaresBuilder := NewBuilder(testConfig, nil, NewStateManager(), metrics.NewVoidRegistry())
 
 	chain := middlewaresBuilder.BuildChain(context.Background(), []string{"empty"})
 	_, err := chain.Then(nil)
@@ -269,7 +270,7 @@ func TestBuilder_BuildChainWithContext(t *testing.T) {
 					Middlewares: test.configuration,
 				},
 			})
-			builder := NewBuilder(rtConf.Middlewares, nil)
+			builder := NewBuilder(rtConf.Middlewares, nil, NewStateManager(), metrics.NewVoidRegistry())
 
 			result := builder.BuildChain(ctx, test.buildChain)
 
@@ -320,7 +321,7 @@ func TestBuilder_buildConstructor(t *testing.T) {
 			Middlewares: testConfig,
 		},
 	})
-	middlewaresBuilder := NewBuilder(rtConf.Middlewares, nil)
+	middlewaresBuilder := NewBuilder(rtConf.Middlewares, nil, NewStateManager(), metrics.NewVoidRegistry())
 
 	testCases := []struct {
 		desc          string
@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"github.com/containous/traefik/pkg/config"
+	"github.com/containous/traefik/pkg/middlewares/retry"
+)
+
+// StateManager keeps the state of the middlewares which outlives their handlers, rebuilt with each new configuration,
+// such as the retry budgets of the services.
+type StateManager struct {
+	retryBudgets *retry.Budgets
+}
+
+// NewStateManager creates a new StateManager.
+func NewStateManager() *StateManager {
+	return &StateManager{
+		retryBudgets: retry.NewBudgets(),
+	}
+}
+
+// Prune forgets the state of the services which are not part of the given configurations.
+func (s *StateManager) Prune(services map[string]*config.ServiceInfo) {
+	s.retryBudgets.Prune(services)
+}
@@ -0,0 +1,88 @@
+package tcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/containous/traefik/pkg/config"
+	"github.com/containous/traefik/pkg/middlewares/tcp/inflightconn"
+	"github.com/containous/traefik/pkg/middlewares/tcp/ipwhitelist"
+	"github.com/containous/traefik/pkg/server/internal"
+	"github.com/containous/traefik/pkg/tcp"
+)
+
+// Builder the TCP middleware builder
+type Builder struct {
+	configs      map[string]*config.TCPMiddlewareInfo
+	stateManager *StateManager
+}
+
+// NewBuilder creates a new Builder
+func NewBuilder(configs map[string]*config.TCPMiddlewareInfo, stateManager *StateManager) *Builder {
+	if stateManager == nil {
+		stateManager = NewStateManager()
+	}
+	return &Builder{configs: configs, stateManager: stateManager}
+}
+
+// BuildChain creates a middleware chain
+func (b *Builder) BuildChain(ctx context.Context, middlewares []string) *tcp.Chain {
+	chain := tcp.NewChain()
+	for _, name := range middlewares {
+		middlewareName := internal.GetQualifiedName(ctx, name)
+
+		chain = chain.Append(func(next tcp.Handler) (tcp.Handler, error) {
+			constructorContext := internal.AddProviderInContext(ctx, middlewareName)
+			if midInf, ok := b.configs[middlewareName]; !ok || midInf.TCPMiddleware == nil {
+				return nil, fmt.Errorf("middleware %q does not exist", middlewareName)
+			}
+
+			constructor, err := b.buildConstructor(constructorContext, middlewareName)
+			if err != nil {
+				b.configs[middlewareName].Err = err
+				return nil, err
+			}
+
+			handler, err := constructor(next)
+			if err != nil {
+				b.configs[middlewareName].Err = err
+				return nil, err
+			}
+
+			return handler, nil
+		})
+	}
+	return &chain
+}
+
+// it is the responsibility of the caller to make sure that b.configs[middlewareName].TCPMiddleware exists
+func (b *Builder) buildConstructor(ctx context.Context, middlewareName string) (tcp.Constructor, error) {
+	config := b.configs[middlewareName]
+	var middleware tcp.Constructor
+	badConf := errors.New("cannot create middleware: multi-types middleware not supported, consider declaring two different pieces of middleware instead")
+
+	// InFlightConn
+	if config.InFlightConn != nil {
+		counters := b.stateManager.inFlightConns.Get(middlewareName)
+		middleware = func(next tcp.Handler) (tcp.Handler, error) {
+			return inflightconn.New(ctx, next, *config.InFlightConn, counters, middlewareName)
+		}
+	}
+
+	// IPWhiteList
+	if config.IPWhiteList != nil {
+		if middleware != nil {
+			return nil, badConf
+		}
+		middleware = func(next tcp.Handler) (tcp.Handler, error) {
+			return ipwhitelist.New(ctx, next, *config.IPWhiteList, middlewareName)
+		}
+	}
+
+	if middleware == nil {
+		return nil, errors.New("middleware does not exist")
+	}
+
+	return middleware, nil
+}
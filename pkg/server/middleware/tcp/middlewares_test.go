@@ -0,0 +1,156 @@
+package tcp
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/containous/traefik/pkg/config"
+	"github.com/containous/traefik/pkg/server/internal"
+	"github.com/containous/traefik/pkg/tcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeConn struct {
+	net.Conn
+	remoteAddr string
+}
+
+func (c fakeConn) RemoteAddr() net.Addr {
+	addr, _ := net.ResolveTCPAddr("tcp", c.remoteAddr)
+	return addr
+}
+
+func (fakeConn) Close() error { return nil }
+
+func TestBuilder_BuildChainNilConfig(t *testing.T) {
+	testConfig := map[string]*config.TCPMiddlewareInfo{
+		"empty": {},
+	}
+	middlewaresBuilder := NewBuilder(testConfig, nil)
+
+	chain := middlewaresBuilder.BuildChain(context.Background(), []string{"empty"})
+	_, err := chain.Then(tcp.HandlerFunc(func(conn net.Conn) {}))
+	require.Error(t, err)
+}
+
+func TestBuilder_BuildChainWithContext(t *testing.T) {
+	testCases := []struct {
+		desc            string
+		buildChain      []string
+		configuration   map[string]*config.TCPMiddleware
+		contextProvider string
+		remoteAddr      string
+		expectedServed  bool
+		expectedError   string
+	}{
+		{
+			desc:       "authorized by the white list",
+			buildChain: []string{"whitelist"},
+			configuration: map[string]*config.TCPMiddleware{
+				"whitelist": {
+					IPWhiteList: &config.TCPIPWhiteList{SourceRange: []string{"10.0.0.0/8"}},
+				},
+			},
+			remoteAddr:     "10.0.0.1:1234",
+			expectedServed: true,
+		},
+		{
+			desc:       "rejected by the white list",
+			buildChain: []string{"whitelist"},
+			configuration: map[string]*config.TCPMiddleware{
+				"whitelist": {
+					IPWhiteList: &config.TCPIPWhiteList{SourceRange: []string{"10.0.0.0/8"}},
+				},
+			},
+			remoteAddr: "192.168.0.1:1234",
+		},
+		{
+			desc:       "should suffix the middleware name with the provider in the context",
+			buildChain: []string{"whitelist", "inflight@other"},
+			configuration: map[string]*config.TCPMiddleware{
+				"whitelist@provider-1": {
+					IPWhiteList: &config.TCPIPWhiteList{SourceRange: []string{"10.0.0.0/8"}},
+				},
+				"inflight@other": {
+					InFlightConn: &config.TCPInFlightConn{Amount: 1},
+				},
+			},
+			contextProvider: "provider-1",
+			remoteAddr:      "10.0.0.1:1234",
+			expectedServed:  true,
+		},
+		{
+			desc:       "middleware of another provider",
+			buildChain: []string{"whitelist"},
+			configuration: map[string]*config.TCPMiddleware{
+				"whitelist@other": {
+					IPWhiteList: &config.TCPIPWhiteList{SourceRange: []string{"10.0.0.0/8"}},
+				},
+			},
+			contextProvider: "provider-1",
+			expectedError:   `middleware "whitelist@provider-1" does not exist`,
+		},
+		{
+			desc:       "multi-types middleware",
+			buildChain: []string{"both"},
+			configuration: map[string]*config.TCPMiddleware{
+				"both": {
+					IPWhiteList:  &config.TCPIPWhiteList{SourceRange: []string{"10.0.0.0/8"}},
+					InFlightConn: &config.TCPInFlightConn{Amount: 1},
+				},
+			},
+			expectedError: "cannot create middleware: multi-types middleware not supported, consider declaring two different pieces of middleware instead",
+		},
+		{
+			desc:       "invalid middleware",
+			buildChain: []string{"inflight"},
+			configuration: map[string]*config.TCPMiddleware{
+				"inflight": {
+					InFlightConn: &config.TCPInFlightConn{},
+				},
+			},
+			expectedError: "invalid amount 0, it must be greater than 0",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			if len(test.contextProvider) > 0 {
+				ctx = internal.AddProviderInContext(ctx, "foobar@"+test.contextProvider)
+			}
+
+			rtConf := config.NewRuntimeConfig(config.Configuration{
+				TCP: &config.TCPConfiguration{
+					Middlewares: test.configuration,
+				},
+			})
+			builder := NewBuilder(rtConf.TCPMiddlewares, nil)
+
+			served := false
+			handler, err := builder.BuildChain(ctx, test.buildChain).Then(tcp.HandlerFunc(func(conn net.Conn) {
+				served = true
+			}))
+
+			if test.expectedError != "" {
+				require.EqualError(t, err, test.expectedError)
+
+				for name := range test.configuration {
+					if name == test.buildChain[0] {
+						assert.EqualError(t, rtConf.TCPMiddlewares[name].Err, test.expectedError)
+					}
+				}
+				return
+			}
+			require.NoError(t, err)
+
+			handler.ServeTCP(fakeConn{remoteAddr: test.remoteAddr})
+			assert.Equal(t, test.expectedServed, served)
+		})
+	}
+}
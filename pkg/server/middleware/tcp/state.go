@@ -0,0 +1,24 @@
+package tcp
+
+import (
+	"github.com/containous/traefik/pkg/config"
+	"github.com/containous/traefik/pkg/middlewares/tcp/inflightconn"
+)
+
+// StateManager keeps the state of the TCP middlewares which outlives their handlers, rebuilt with each new configuration,
+// such as the connections in flight counted by the InFlightConn middlewares.
+type StateManager struct {
+	inFlightConns *inflightconn.SharedCounters
+}
+
+// NewStateManager creates a new StateManager.
+func NewStateManager() *StateManager {
+	return &StateManager{
+		inFlightConns: inflightconn.NewSharedCounters(),
+	}
+}
+
+// Prune forgets the state of the middlewares which are not part of the given configurations.
+func (s *StateManager) Prune(middlewares map[string]*config.TCPMiddlewareInfo) {
+	s.inFlightConns.Prune(middlewares)
+}
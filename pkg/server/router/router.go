@@ -78,7 +78,7 @@ func (m *Manager) BuildHandlers(rootCtx context.Context, entryPoints []string, t
 		}
 	}
 
-	m.serviceManager.LaunchHealthCheck()
+	m.serviceManager.LaunchHealthCheck(rootCtx)
 
 	return entryPointHandlers
 }
@@ -153,7 +153,7 @@ func (m *Manager) buildHTTPHandler(ctx context.Context, router *config.RouterInf
 		return nil, err
 	}
 
-	mHandler := m.middlewaresBuilder.BuildChain(ctx, router.Middlewares)
+	mHandler := m.middlewaresBuilder.BuildChain(internal.AddServiceInContext(ctx, internal.GetQualifiedName(ctx, router.Service)), router.Middlewares)
 
 	tHandler := func(next http.Handler) (http.Handler, error) {
 		return tracing.NewForwarder(ctx, routerName, router.Service, next), nil
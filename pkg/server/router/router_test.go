@@ -9,6 +9,7 @@ import (
 	"testing"
 
 	"github.com/containous/traefik/pkg/config"
+	"github.com/containous/traefik/pkg/metrics"
 	"github.com/containous/traefik/pkg/middlewares/accesslog"
 	"github.com/containous/traefik/pkg/middlewares/requestdecorator"
 	"github.com/containous/traefik/pkg/responsemodifiers"
@@ -305,8 +306,8 @@ func TestRouterManager_Get(t *testing.T) {
 					Middlewares: test.middlewaresConfig,
 				},
 			})
-			serviceManager := service.NewManager(rtConf.Services, http.DefaultTransport)
-			middlewaresBuilder := middleware.NewBuilder(rtConf.Middlewares, serviceManager)
+			serviceManager := service.NewManager(rtConf.Services, service.NewRoundTripperManager(http.DefaultTransport), service.NewStateManager(), metrics.NewVoidRegistry())
+			middlewaresBuilder := middleware.NewBuilder(rtConf.Middlewares, serviceManager, middleware.NewStateManager(), metrics.NewVoidRegistry())
 			responseModifierFactory := responsemodifiers.NewBuilder(rtConf.Middlewares)
 			routerManager := NewManager(rtConf, serviceManager, middlewaresBuilder, responseModifierFactory)
 
@@ -406,8 +407,8 @@ func TestAccessLog(t *testing.T) {
 					Middlewares: test.middlewaresConfig,
 				},
 			})
-			serviceManager := service.NewManager(rtConf.Services, http.DefaultTransport)
-			middlewaresBuilder := middleware.NewBuilder(rtConf.Middlewares, serviceManager)
+			serviceManager := service.NewManager(rtConf.Services, service.NewRoundTripperManager(http.DefaultTransport), service.NewStateManager(), metrics.NewVoidRegistry())
+			middlewaresBuilder := middleware.NewBuilder(rtConf.Middlewares, serviceManager, middleware.NewStateManager(), metrics.NewVoidRegistry())
 			responseModifierFactory := responsemodifiers.NewBuilder(rtConf.Middlewares)
 			routerManager := NewManager(rtConf, serviceManager, middlewaresBuilder, responseModifierFactory)
 
@@ -692,8 +693,8 @@ func TestRuntimeConfiguration(t *testing.T) {
 					Middlewares: test.middlewareConfig,
 				},
 			})
-			serviceManager := service.NewManager(rtConf.Services, http.DefaultTransport)
-			middlewaresBuilder := middleware.NewBuilder(rtConf.Middlewares, serviceManager)
+			serviceManager := service.NewManager(rtConf.Services, service.NewRoundTripperManager(http.DefaultTransport), service.NewStateManager(), metrics.NewVoidRegistry())
+			middlewaresBuilder := middleware.NewBuilder(rtConf.Middlewares, serviceManager, middleware.NewStateManager(), metrics.NewVoidRegistry())
 			responseModifierFactory := responsemodifiers.NewBuilder(map[string]*config.MiddlewareInfo{})
 			routerManager := NewManager(rtConf, serviceManager, middlewaresBuilder, responseModifierFactory)
 
@@ -766,8 +767,8 @@ func BenchmarkRouterServe(b *testing.B) {
 			Middlewares: map[string]*config.Middleware{},
 		},
 	})
-	serviceManager := service.NewManager(rtConf.Services, &staticTransport{res})
-	middlewaresBuilder := middleware.NewBuilder(rtConf.Middlewares, serviceManager)
+	serviceManager := service.NewManager(rtConf.Services, service.NewRoundTripperManager(&staticTransport{res}), service.NewStateManager(), metrics.NewVoidRegistry())
+	middlewaresBuilder := middleware.NewBuilder(rtConf.Middlewares, serviceManager, middleware.NewStateManager(), metrics.NewVoidRegistry())
 	responseModifierFactory := responsemodifiers.NewBuilder(rtConf.Middlewares)
 	routerManager := NewManager(rtConf, serviceManager, middlewaresBuilder, responseModifierFactory)
 
@@ -807,7 +808,7 @@ func BenchmarkService(b *testing.B) {
 			Services: serviceConfig,
 		},
 	})
-	serviceManager := service.NewManager(rtConf.Services, &staticTransport{res})
+	serviceManager := service.NewManager(rtConf.Services, service.NewRoundTripperManager(&staticTransport{res}), service.NewStateManager(), metrics.NewVoidRegistry())
 	w := httptest.NewRecorder()
 	req := testhelpers.MustNewRequest(http.MethodGet, "http://foo.bar/", nil)
 
@@ -2,41 +2,55 @@ package tcp
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"net/http"
 
 	"github.com/containous/traefik/pkg/config"
 	"github.com/containous/traefik/pkg/log"
+	"github.com/containous/traefik/pkg/metrics"
+	"github.com/containous/traefik/pkg/middlewares/accesslog"
 	"github.com/containous/traefik/pkg/rules"
 	"github.com/containous/traefik/pkg/server/internal"
+	tcpmiddleware "github.com/containous/traefik/pkg/server/middleware/tcp"
 	tcpservice "github.com/containous/traefik/pkg/server/service/tcp"
 	"github.com/containous/traefik/pkg/tcp"
-	"github.com/containous/traefik/pkg/tls"
+	traefiktls "github.com/containous/traefik/pkg/tls"
 )
 
 // NewManager Creates a new Manager
 func NewManager(conf *config.RuntimeConfiguration,
 	serviceManager *tcpservice.Manager,
+	middlewaresBuilder *tcpmiddleware.Builder,
 	httpHandlers map[string]http.Handler,
 	httpsHandlers map[string]http.Handler,
-	tlsManager *tls.Manager,
+	tlsManager *traefiktls.Manager,
+	metricsRegistry metrics.Registry,
+	accessLogger *accesslog.Handler,
 ) *Manager {
 	return &Manager{
-		serviceManager: serviceManager,
-		httpHandlers:   httpHandlers,
-		httpsHandlers:  httpsHandlers,
-		tlsManager:     tlsManager,
-		conf:           conf,
+		serviceManager:     serviceManager,
+		middlewaresBuilder: middlewaresBuilder,
+		httpHandlers:       httpHandlers,
+		httpsHandlers:      httpsHandlers,
+		tlsManager:         tlsManager,
+		conf:               conf,
+		metricsRegistry:    metricsRegistry,
+		accessLogger:       accessLogger,
 	}
 }
 
 // Manager is a route/router manager
 type Manager struct {
-	serviceManager *tcpservice.Manager
-	httpHandlers   map[string]http.Handler
-	httpsHandlers  map[string]http.Handler
-	tlsManager     *tls.Manager
-	conf           *config.RuntimeConfiguration
+	serviceManager     *tcpservice.Manager
+	middlewaresBuilder *tcpmiddleware.Builder
+	httpHandlers       map[string]http.Handler
+	httpsHandlers      map[string]http.Handler
+	tlsManager         *traefiktls.Manager
+	conf               *config.RuntimeConfiguration
+	metricsRegistry    metrics.Registry
+	// accessLogger, if not nil, writes the connections of the routers to the access log.
+	accessLogger *accesslog.Handler
 }
 
 func (m *Manager) getTCPRouters(ctx context.Context, entryPoints []string) map[string]map[string]*config.TCPRouterInfo {
@@ -68,17 +82,20 @@ func (m *Manager) BuildHandlers(rootCtx context.Context, entryPoints []string) m
 
 		ctx := log.With(rootCtx, log.Str(log.EntryPointName, entryPointName))
 
-		handler, err := m.buildEntryPointHandler(ctx, routers, entryPointsRoutersHTTP[entryPointName], m.httpHandlers[entryPointName], m.httpsHandlers[entryPointName])
+		handler, err := m.buildEntryPointHandler(ctx, entryPointName, routers, entryPointsRoutersHTTP[entryPointName], m.httpHandlers[entryPointName], m.httpsHandlers[entryPointName])
 		if err != nil {
 			log.FromContext(ctx).Error(err)
 			continue
 		}
 		entryPointHandlers[entryPointName] = handler
 	}
+
+	m.serviceManager.LaunchHealthCheck(rootCtx)
+
 	return entryPointHandlers
 }
 
-func (m *Manager) buildEntryPointHandler(ctx context.Context, configs map[string]*config.TCPRouterInfo, configsHTTP map[string]*config.RouterInfo, handlerHTTP http.Handler, handlerHTTPS http.Handler) (*tcp.Router, error) {
+func (m *Manager) buildEntryPointHandler(ctx context.Context, entryPointName string, configs map[string]*config.TCPRouterInfo, configsHTTP map[string]*config.RouterInfo, handlerHTTP http.Handler, handlerHTTPS http.Handler) (*tcp.Router, error) {
 	router := &tcp.Router{}
 	router.HTTPHandler(handlerHTTP)
 	const defaultTLSConfigName = "default"
@@ -133,54 +150,140 @@ func (m *Manager) buildEntryPointHandler(ctx context.Context, configs map[string
 		ctxRouter := log.With(internal.AddProviderInContext(ctx, routerName), log.Str(log.RouterName, routerName))
 		logger := log.FromContext(ctxRouter)
 
-		handler, err := m.serviceManager.BuildTCP(ctxRouter, routerConfig.Service)
+		handler, err := m.buildTCPHandler(ctxRouter, entryPointName, routerName, routerConfig)
 		if err != nil {
 			routerConfig.Err = err.Error()
 			logger.Error(err)
 			continue
 		}
 
-		domains, err := rules.ParseHostSNI(routerConfig.Rule)
+		matcher, err := rules.ParseTCPRule(routerConfig.Rule)
 		if err != nil {
-			routerErr := fmt.Errorf("unknown rule %s", routerConfig.Rule)
+			routerErr := fmt.Errorf("invalid rule %s, error: %v", routerConfig.Rule, err)
 			routerConfig.Err = routerErr.Error()
 			logger.Debug(routerErr)
 			continue
 		}
 
-		for _, domain := range domains {
-			logger.Debugf("Adding route %s on TCP", domain)
-			switch {
-			case routerConfig.TLS != nil:
-				if routerConfig.TLS.Passthrough {
-					router.AddRoute(domain, handler)
-				} else {
-					tlsOptionsName := routerConfig.TLS.Options
-
-					if len(tlsOptionsName) == 0 {
-						tlsOptionsName = defaultTLSConfigName
-					}
-
-					if tlsOptionsName != defaultTLSConfigName {
-						tlsOptionsName = internal.GetQualifiedName(ctxRouter, tlsOptionsName)
-					}
-
-					tlsConf, err := m.tlsManager.Get("default", tlsOptionsName)
-					if err != nil {
-						routerConfig.Err = err.Error()
-						logger.Debug(err)
-						continue
-					}
-
-					router.AddRouteTLS(domain, handler, tlsConf)
-				}
-			case domain == "*":
-				router.AddCatchAllNoTLS(handler)
-			default:
+		priority := routerConfig.Priority
+		if priority == 0 {
+			priority = len(routerConfig.Rule)
+		}
+
+		alpnProtos, err := rules.ParseALPN(routerConfig.Rule)
+		if err != nil {
+			routerErr := fmt.Errorf("invalid rule %s, error: %v", routerConfig.Rule, err)
+			routerConfig.Err = routerErr.Error()
+			logger.Debug(routerErr)
+			continue
+		}
+
+		logger.Debugf("Adding route %s on TCP", routerConfig.Rule)
+		switch {
+		case routerConfig.TLS == nil:
+			if !hasOnlyCatchAllSNI(routerConfig.Rule) {
 				logger.Warn("TCP Router ignored, cannot specify a Host rule without TLS")
+				continue
+			}
+
+			if len(alpnProtos) > 0 {
+				logger.Warn("TCP Router ignored, cannot specify an ALPN rule without TLS")
+				continue
 			}
+
+			router.AddRouteNoTLS(routerName, priority, matcher, handler)
+		case routerConfig.TLS.Passthrough:
+			router.AddRoute(routerName, priority, matcher, handler)
+		default:
+			tlsOptionsName := routerConfig.TLS.Options
+
+			if len(tlsOptionsName) == 0 {
+				tlsOptionsName = defaultTLSConfigName
+			}
+
+			if tlsOptionsName != defaultTLSConfigName {
+				tlsOptionsName = internal.GetQualifiedName(ctxRouter, tlsOptionsName)
+			}
+
+			tlsConf, err := m.tlsManager.Get("default", tlsOptionsName)
+			if err != nil {
+				routerConfig.Err = err.Error()
+				logger.Debug(err)
+				continue
+			}
+
+			if len(alpnProtos) > 0 {
+				tlsConf = preferNextProtos(tlsConf, alpnProtos)
+			}
+
+			router.AddRouteTLS(routerName, priority, matcher, handler, tlsConf)
 		}
 	}
 
 	return router, nil
 }
+
+func (m *Manager) buildTCPHandler(ctx context.Context, entryPointName, routerName string, router *config.TCPRouterInfo) (tcp.Handler, error) {
+	handler, err := m.serviceManager.BuildTCP(ctx, router.Service)
+	if err != nil {
+		return nil, err
+	}
+
+	handler, err = m.middlewaresBuilder.BuildChain(ctx, router.Middlewares).Then(handler)
+	if err != nil {
+		return nil, err
+	}
+
+	handler = tcp.NewMetricsHandler(handler, tcp.ConnMetrics{
+		OpenConns: m.metricsRegistry.TCPRouterOpenConnsGauge().With("router", routerName),
+		Duration:  m.metricsRegistry.TCPRouterConnDurationHistogram().With("router", routerName),
+		BytesIn:   m.metricsRegistry.TCPRouterBytesInCounter().With("router", routerName),
+		BytesOut:  m.metricsRegistry.TCPRouterBytesOutCounter().With("router", routerName),
+	})
+
+	if m.accessLogger != nil {
+		handler = accesslog.WrapTCPHandler(m.accessLogger, handler, entryPointName, routerName, internal.GetQualifiedName(ctx, router.Service))
+	}
+
+	return handler, nil
+}
+
+// hasOnlyCatchAllSNI reports whether the HostSNI matchers of the rule are all the * catch-all,
+// as the non-TLS connections do not have any server name.
+func hasOnlyCatchAllSNI(rule string) bool {
+	domains, err := rules.ParseHostSNI(rule)
+	if err != nil {
+		return false
+	}
+
+	for _, domain := range domains {
+		if domain != "*" {
+			return false
+		}
+	}
+	return true
+}
+
+// preferNextProtos returns a copy of the TLS configuration offering first the protocols during the ALPN negotiation,
+// so that the connections matched on them negotiate them, the other protocols being kept after.
+func preferNextProtos(conf *tls.Config, protos []string) *tls.Config {
+	nextProtos := append([]string{}, protos...)
+	for _, proto := range conf.NextProtos {
+		if !contains(protos, proto) {
+			nextProtos = append(nextProtos, proto)
+		}
+	}
+
+	conf = conf.Clone()
+	conf.NextProtos = nextProtos
+	return conf
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
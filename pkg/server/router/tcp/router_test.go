@@ -5,17 +5,21 @@ import (
 	"testing"
 
 	"github.com/containous/traefik/pkg/config"
+	"github.com/containous/traefik/pkg/metrics"
+	tcpmiddleware "github.com/containous/traefik/pkg/server/middleware/tcp"
 	"github.com/containous/traefik/pkg/server/service/tcp"
 	"github.com/containous/traefik/pkg/tls"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestRuntimeConfiguration(t *testing.T) {
 	testCases := []struct {
-		desc          string
-		serviceConfig map[string]*config.TCPServiceInfo
-		routerConfig  map[string]*config.TCPRouterInfo
-		expectedError int
+		desc             string
+		serviceConfig    map[string]*config.TCPServiceInfo
+		middlewareConfig map[string]*config.TCPMiddlewareInfo
+		routerConfig     map[string]*config.TCPRouterInfo
+		expectedError    int
 	}{
 		{
 			desc: "No error",
@@ -185,6 +189,175 @@ func TestRuntimeConfiguration(t *testing.T) {
 			},
 			expectedError: 2,
 		},
+		{
+			desc: "Router with middleware",
+			serviceConfig: map[string]*config.TCPServiceInfo{
+				"foo-service": {
+					TCPService: &config.TCPService{
+						LoadBalancer: &config.TCPLoadBalancerService{
+							Servers: []config.TCPServer{
+								{
+									Address: "127.0.0.1:80",
+								},
+							},
+						},
+					},
+				},
+			},
+			middlewareConfig: map[string]*config.TCPMiddlewareInfo{
+				"whitelist": {
+					TCPMiddleware: &config.TCPMiddleware{
+						IPWhiteList: &config.TCPIPWhiteList{SourceRange: []string{"127.0.0.1/32"}},
+					},
+				},
+			},
+			routerConfig: map[string]*config.TCPRouterInfo{
+				"bar": {
+					TCPRouter: &config.TCPRouter{
+						EntryPoints: []string{"web"},
+						Middlewares: []string{"whitelist"},
+						Service:     "foo-service",
+						Rule:        "HostSNI(`foo.bar`)",
+						TLS:         &config.RouterTCPTLSConfig{Passthrough: true},
+					},
+				},
+			},
+			expectedError: 0,
+		},
+		{
+			desc: "Router with unknown middleware",
+			serviceConfig: map[string]*config.TCPServiceInfo{
+				"foo-service": {
+					TCPService: &config.TCPService{
+						LoadBalancer: &config.TCPLoadBalancerService{
+							Servers: []config.TCPServer{
+								{
+									Address: "127.0.0.1:80",
+								},
+							},
+						},
+					},
+				},
+			},
+			routerConfig: map[string]*config.TCPRouterInfo{
+				"bar": {
+					TCPRouter: &config.TCPRouter{
+						EntryPoints: []string{"web"},
+						Middlewares: []string{"unknown"},
+						Service:     "foo-service",
+						Rule:        "HostSNI(`foo.bar`)",
+					},
+				},
+			},
+			expectedError: 1,
+		},
+		{
+			desc: "Router with broken middleware",
+			serviceConfig: map[string]*config.TCPServiceInfo{
+				"foo-service": {
+					TCPService: &config.TCPService{
+						LoadBalancer: &config.TCPLoadBalancerService{
+							Servers: []config.TCPServer{
+								{
+									Address: "127.0.0.1:80",
+								},
+							},
+						},
+					},
+				},
+			},
+			middlewareConfig: map[string]*config.TCPMiddlewareInfo{
+				"whitelist": {
+					TCPMiddleware: &config.TCPMiddleware{
+						IPWhiteList: &config.TCPIPWhiteList{SourceRange: []string{"foo"}},
+					},
+				},
+			},
+			routerConfig: map[string]*config.TCPRouterInfo{
+				"bar": {
+					TCPRouter: &config.TCPRouter{
+						EntryPoints: []string{"web"},
+						Middlewares: []string{"whitelist"},
+						Service:     "foo-service",
+						Rule:        "HostSNI(`foo.bar`)",
+					},
+				},
+			},
+			expectedError: 2,
+		},
+		{
+			desc: "Routers with wildcard, regexp and client IP rules",
+			serviceConfig: map[string]*config.TCPServiceInfo{
+				"foo-service": {
+					TCPService: &config.TCPService{
+						LoadBalancer: &config.TCPLoadBalancerService{
+							Servers: []config.TCPServer{
+								{
+									Address: "127.0.0.1:80",
+								},
+							},
+						},
+					},
+				},
+			},
+			routerConfig: map[string]*config.TCPRouterInfo{
+				"foo": {
+					TCPRouter: &config.TCPRouter{
+						EntryPoints: []string{"web"},
+						Service:     "foo-service",
+						Rule:        "HostSNI(`*.foo.bar`) && ClientIP(`10.0.0.0/8`)",
+						TLS: &config.RouterTCPTLSConfig{
+							Passthrough: true,
+						},
+					},
+				},
+				"bar": {
+					TCPRouter: &config.TCPRouter{
+						EntryPoints: []string{"web"},
+						Service:     "foo-service",
+						Rule:        "HostSNIRegexp(`{tenant:[a-z]+}.foo.bar`)",
+						Priority:    42,
+						TLS: &config.RouterTCPTLSConfig{
+							Passthrough: true,
+						},
+					},
+				},
+				"baz": {
+					TCPRouter: &config.TCPRouter{
+						EntryPoints: []string{"web"},
+						Service:     "foo-service",
+						Rule:        "ClientIP(`10.0.0.0/8`)",
+					},
+				},
+			},
+			expectedError: 0,
+		},
+		{
+			desc: "Router with invalid client IP",
+			serviceConfig: map[string]*config.TCPServiceInfo{
+				"foo-service": {
+					TCPService: &config.TCPService{
+						LoadBalancer: &config.TCPLoadBalancerService{
+							Servers: []config.TCPServer{
+								{
+									Address: "127.0.0.1:80",
+								},
+							},
+						},
+					},
+				},
+			},
+			routerConfig: map[string]*config.TCPRouterInfo{
+				"foo": {
+					TCPRouter: &config.TCPRouter{
+						EntryPoints: []string{"web"},
+						Service:     "foo-service",
+						Rule:        "ClientIP(`10.0.0.0/33`)",
+					},
+				},
+			},
+			expectedError: 1,
+		},
 	}
 
 	for _, test := range testCases {
@@ -196,10 +369,12 @@ func TestRuntimeConfiguration(t *testing.T) {
 			entryPoints := []string{"web"}
 
 			conf := &config.RuntimeConfiguration{
-				TCPServices: test.serviceConfig,
-				TCPRouters:  test.routerConfig,
+				TCPServices:    test.serviceConfig,
+				TCPMiddlewares: test.middlewareConfig,
+				TCPRouters:     test.routerConfig,
 			}
-			serviceManager := tcp.NewManager(conf)
+			serviceManager := tcp.NewManager(conf, tcp.NewStateManager(), metrics.NewVoidRegistry())
+			middlewaresBuilder := tcpmiddleware.NewBuilder(conf.TCPMiddlewares, nil)
 			tlsManager := tls.NewManager()
 			tlsManager.UpdateConfigs(
 				map[string]tls.Store{},
@@ -216,8 +391,8 @@ func TestRuntimeConfiguration(t *testing.T) {
 				},
 				[]*tls.Configuration{})
 
-			routerManager := NewManager(conf, serviceManager,
-				nil, nil, tlsManager)
+			routerManager := NewManager(conf, serviceManager, middlewaresBuilder,
+				nil, nil, tlsManager, metrics.NewVoidRegistry(), nil)
 
 			_ = routerManager.BuildHandlers(context.Background(), entryPoints)
 
@@ -230,6 +405,11 @@ func TestRuntimeConfiguration(t *testing.T) {
 					allErrors++
 				}
 			}
+			for _, v := range conf.TCPMiddlewares {
+				if v.Err != nil {
+					allErrors++
+				}
+			}
 			for _, v := range conf.TCPRouters {
 				if v.Err != "" {
 					allErrors++
@@ -240,3 +420,17 @@ func TestRuntimeConfiguration(t *testing.T) {
 	}
 
 }
+
+func TestPreferNextProtos(t *testing.T) {
+	tlsManager := tls.NewManager()
+	tlsManager.UpdateConfigs(map[string]tls.Store{}, map[string]tls.TLS{"default": {}}, []*tls.Configuration{})
+
+	conf, err := tlsManager.Get("default", "default")
+	require.NoError(t, err)
+
+	preferred := preferNextProtos(conf, []string{"postgresql", "h2"})
+	assert.Equal(t, []string{"postgresql", "h2", "http/1.1", "acme-tls/1"}, preferred.NextProtos)
+
+	// The configuration shared with the other routers is left untouched.
+	assert.Equal(t, []string{"h2", "http/1.1", "acme-tls/1"}, conf.NextProtos)
+}
@@ -0,0 +1,83 @@
+package udp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/containous/traefik/pkg/config"
+	"github.com/containous/traefik/pkg/log"
+	"github.com/containous/traefik/pkg/server/internal"
+	udpservice "github.com/containous/traefik/pkg/server/service/udp"
+	"github.com/containous/traefik/pkg/udp"
+)
+
+// NewManager Creates a new Manager
+func NewManager(conf *config.RuntimeConfiguration, serviceManager *udpservice.Manager) *Manager {
+	return &Manager{
+		serviceManager: serviceManager,
+		conf:           conf,
+	}
+}
+
+// Manager is a route/router manager
+type Manager struct {
+	serviceManager *udpservice.Manager
+	conf           *config.RuntimeConfiguration
+}
+
+func (m *Manager) getUDPRouters(ctx context.Context, entryPoints []string) map[string]map[string]*config.UDPRouterInfo {
+	if m.conf != nil {
+		return m.conf.GetUDPRoutersByEntrypoints(ctx, entryPoints)
+	}
+
+	return make(map[string]map[string]*config.UDPRouterInfo)
+}
+
+// BuildHandlers builds the handlers for the given entrypoints.
+// As a UDP entry point cannot route the sessions, only its first router, by name, is used.
+func (m *Manager) BuildHandlers(rootCtx context.Context, entryPoints []string) map[string]udp.Handler {
+	entryPointsRouters := m.getUDPRouters(rootCtx, entryPoints)
+
+	entryPointHandlers := make(map[string]udp.Handler)
+	for _, entryPointName := range entryPoints {
+		routers := entryPointsRouters[entryPointName]
+
+		ctx := log.With(rootCtx, log.Str(log.EntryPointName, entryPointName))
+
+		// usedRouter is the name of the router whose handler is used by the entry point.
+		var usedRouter string
+
+		var routerNames []string
+		for routerName := range routers {
+			routerNames = append(routerNames, routerName)
+		}
+		sort.Strings(routerNames)
+
+		for _, routerName := range routerNames {
+			routerConfig := routers[routerName]
+
+			ctxRouter := log.With(internal.AddProviderInContext(ctx, routerName), log.Str(log.RouterName, routerName))
+			logger := log.FromContext(ctxRouter)
+
+			if usedRouter != "" {
+				routerErr := fmt.Errorf("the entry point %q already uses the UDP router %q", entryPointName, usedRouter)
+				routerConfig.Err = routerErr.Error()
+				logger.Error(routerErr)
+				continue
+			}
+
+			handler, err := m.serviceManager.BuildUDP(ctxRouter, routerConfig.Service)
+			if err != nil {
+				routerConfig.Err = err.Error()
+				logger.Error(err)
+				continue
+			}
+
+			entryPointHandlers[entryPointName] = handler
+			usedRouter = routerName
+		}
+	}
+
+	return entryPointHandlers
+}
@@ -0,0 +1,145 @@
+package udp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/containous/traefik/pkg/config"
+	"github.com/containous/traefik/pkg/server/service/udp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRuntimeConfiguration(t *testing.T) {
+	serviceConfig := map[string]*config.UDPServiceInfo{
+		"foo-service": {
+			UDPService: &config.UDPService{
+				LoadBalancer: &config.UDPLoadBalancerService{
+					Servers: []config.UDPServer{
+						{
+							Address: "127.0.0.1:53",
+						},
+					},
+				},
+			},
+		},
+		"broken-service": {
+			UDPService: &config.UDPService{},
+		},
+	}
+
+	testCases := []struct {
+		desc             string
+		routerConfig     map[string]*config.UDPRouterInfo
+		expectedHandlers []string
+		expectedErrors   map[string]string
+	}{
+		{
+			desc: "One router per entry point",
+			routerConfig: map[string]*config.UDPRouterInfo{
+				"foo": {
+					UDPRouter: &config.UDPRouter{
+						EntryPoints: []string{"dns"},
+						Service:     "foo-service",
+					},
+				},
+				"bar": {
+					UDPRouter: &config.UDPRouter{
+						EntryPoints: []string{"syslog"},
+						Service:     "foo-service",
+					},
+				},
+			},
+			expectedHandlers: []string{"dns", "syslog"},
+		},
+		{
+			desc: "Router with unknown service",
+			routerConfig: map[string]*config.UDPRouterInfo{
+				"foo": {
+					UDPRouter: &config.UDPRouter{
+						EntryPoints: []string{"dns"},
+						Service:     "wrong-service",
+					},
+				},
+			},
+			expectedErrors: map[string]string{
+				"foo": `the service "wrong-service" does not exist`,
+			},
+		},
+		{
+			desc: "Router with broken service",
+			routerConfig: map[string]*config.UDPRouterInfo{
+				"foo": {
+					UDPRouter: &config.UDPRouter{
+						EntryPoints: []string{"dns"},
+						Service:     "broken-service",
+					},
+				},
+			},
+			expectedErrors: map[string]string{
+				"foo": `the service "broken-service" doesn't have any UDP load balancer`,
+			},
+		},
+		{
+			desc: "Several routers on the same entry point",
+			routerConfig: map[string]*config.UDPRouterInfo{
+				"b": {
+					UDPRouter: &config.UDPRouter{
+						EntryPoints: []string{"dns"},
+						Service:     "foo-service",
+					},
+				},
+				"a": {
+					UDPRouter: &config.UDPRouter{
+						EntryPoints: []string{"dns"},
+						Service:     "broken-service",
+					},
+				},
+				"c": {
+					UDPRouter: &config.UDPRouter{
+						EntryPoints: []string{"dns"},
+						Service:     "foo-service",
+					},
+				},
+			},
+			expectedHandlers: []string{"dns"},
+			expectedErrors: map[string]string{
+				"a": `the service "broken-service" doesn't have any UDP load balancer`,
+				"c": `the entry point "dns" already uses the UDP router "b"`,
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			conf := &config.RuntimeConfiguration{
+				UDPServices: serviceConfig,
+				UDPRouters:  test.routerConfig,
+			}
+			routerManager := NewManager(conf, udp.NewManager(conf))
+
+			handlers := routerManager.BuildHandlers(context.Background(), []string{"dns", "syslog"})
+
+			var entryPoints []string
+			for entryPointName := range handlers {
+				entryPoints = append(entryPoints, entryPointName)
+			}
+			assert.ElementsMatch(t, test.expectedHandlers, entryPoints)
+
+			errors := make(map[string]string)
+			for routerName, routerInfo := range conf.UDPRouters {
+				if routerInfo.Err != "" {
+					errors[routerName] = routerInfo.Err
+				}
+			}
+			if len(test.expectedErrors) == 0 {
+				assert.Empty(t, errors)
+			} else {
+				assert.Equal(t, test.expectedErrors, errors)
+			}
+		})
+	}
+}
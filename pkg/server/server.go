@@ -18,6 +18,9 @@ import (
 	"github.com/containous/traefik/pkg/provider"
 	"github.com/containous/traefik/pkg/safe"
 	"github.com/containous/traefik/pkg/server/middleware"
+	tcpmiddleware "github.com/containous/traefik/pkg/server/middleware/tcp"
+	"github.com/containous/traefik/pkg/server/service"
+	tcpservice "github.com/containous/traefik/pkg/server/service/tcp"
 	"github.com/containous/traefik/pkg/tls"
 	"github.com/containous/traefik/pkg/tracing"
 	"github.com/containous/traefik/pkg/tracing/datadog"
@@ -30,6 +33,7 @@ import (
 // Server is the reverse-proxy/load-balancer engine
 type Server struct {
 	entryPointsTCP             TCPEntryPoints
+	entryPointsUDP             UDPEntryPoints
 	configurationChan          chan config.Message
 	configurationValidatedChan chan config.Message
 	signals                    chan os.Signal
@@ -39,7 +43,11 @@ type Server struct {
 	accessLoggerMiddleware     *accesslog.Handler
 	tracer                     *tracing.Tracing
 	routinesPool               *safe.Pool
-	defaultRoundTripper        http.RoundTripper
+	roundTripperManager        *service.RoundTripperManager
+	serviceStateManager        *service.StateManager
+	tcpServiceStateManager     *tcpservice.StateManager
+	middlewareStateManager     *middleware.StateManager
+	tcpMiddlewareStateManager  *tcpmiddleware.StateManager
 	metricsRegistry            metrics.Registry
 	provider                   provider.Provider
 	configurationListeners     []func(config.Configuration)
@@ -70,11 +78,12 @@ func setupTracing(conf *static.Tracing) tracing.TrackingBackend {
 }
 
 // NewServer returns an initialized Server.
-func NewServer(staticConfiguration static.Configuration, provider provider.Provider, entryPoints TCPEntryPoints, tlsManager *tls.Manager) *Server {
+func NewServer(staticConfiguration static.Configuration, provider provider.Provider, entryPoints TCPEntryPoints, entryPointsUDP UDPEntryPoints, tlsManager *tls.Manager) *Server {
 	server := &Server{}
 
 	server.provider = provider
 	server.entryPointsTCP = entryPoints
+	server.entryPointsUDP = entryPointsUDP
 	server.configurationChan = make(chan config.Message, 100)
 	server.configurationValidatedChan = make(chan config.Message, 100)
 	server.signals = make(chan os.Signal, 1)
@@ -89,13 +98,16 @@ func NewServer(staticConfiguration static.Configuration, provider provider.Provi
 		server.providersThrottleDuration = time.Duration(staticConfiguration.Providers.ProvidersThrottleDuration)
 	}
 
-	transport, err := createHTTPTransport(staticConfiguration.ServersTransport)
+	transport, err := service.CreateRoundTripper(newServersTransport(staticConfiguration.ServersTransport))
 	if err != nil {
 		log.WithoutContext().Errorf("Could not configure HTTP Transport, fallbacking on default transport: %v", err)
-		server.defaultRoundTripper = http.DefaultTransport
-	} else {
-		server.defaultRoundTripper = transport
+		transport = http.DefaultTransport
 	}
+	server.roundTripperManager = service.NewRoundTripperManager(transport)
+	server.serviceStateManager = service.NewStateManager()
+	server.tcpServiceStateManager = tcpservice.NewStateManager()
+	server.middlewareStateManager = middleware.NewStateManager()
+	server.tcpMiddlewareStateManager = tcpmiddleware.NewStateManager()
 
 	server.routinesPool = safe.NewPool(context.Background())
 
@@ -134,6 +146,7 @@ func (s *Server) Start(ctx context.Context) {
 	}()
 
 	s.startTCPServers()
+	s.startUDPServers()
 	s.routinesPool.Go(func(stop chan bool) {
 		s.listenProviders(stop)
 	})
@@ -167,6 +180,17 @@ func (s *Server) Stop() {
 			log.FromContext(ctx).Debugf("Entry point %s closed", entryPointName)
 		}(epn, ep)
 	}
+	for epn, ep := range s.entryPointsUDP {
+		wg.Add(1)
+		go func(entryPointName string, entryPoint *UDPEntryPoint) {
+			ctx := log.With(context.Background(), log.Str(log.EntryPointName, entryPointName))
+			defer wg.Done()
+
+			entryPoint.Shutdown(ctx)
+
+			log.FromContext(ctx).Debugf("Entry point %s closed", entryPointName)
+		}(epn, ep)
+	}
 	wg.Wait()
 	s.stopChan <- true
 }
@@ -217,6 +241,13 @@ func (s *Server) startTCPServers() {
 	}
 }
 
+func (s *Server) startUDPServers() {
+	for entryPointName, serverEntryPoint := range s.entryPointsUDP {
+		ctx := log.With(context.Background(), log.Str(log.EntryPointName, entryPointName))
+		go serverEntryPoint.startUDP(ctx)
+	}
+}
+
 func (s *Server) listenProviders(stop chan bool) {
 	for {
 		select {
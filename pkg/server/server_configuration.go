@@ -16,11 +16,15 @@ import (
 	"github.com/containous/traefik/pkg/middlewares/tracing"
 	"github.com/containous/traefik/pkg/responsemodifiers"
 	"github.com/containous/traefik/pkg/server/middleware"
+	tcpmiddleware "github.com/containous/traefik/pkg/server/middleware/tcp"
 	"github.com/containous/traefik/pkg/server/router"
 	routertcp "github.com/containous/traefik/pkg/server/router/tcp"
+	routerudp "github.com/containous/traefik/pkg/server/router/udp"
 	"github.com/containous/traefik/pkg/server/service"
 	"github.com/containous/traefik/pkg/server/service/tcp"
+	"github.com/containous/traefik/pkg/server/service/udp"
 	tcpCore "github.com/containous/traefik/pkg/tcp"
+	udpCore "github.com/containous/traefik/pkg/udp"
 	"github.com/eapache/channels"
 	"github.com/sirupsen/logrus"
 )
@@ -43,6 +47,11 @@ func (s *Server) loadConfiguration(configMsg config.Message) {
 		s.entryPointsTCP[entryPointName].switchRouter(router)
 	}
 
+	handlersUDP := s.loadConfigurationUDP(newConfigurations)
+	for entryPointName, entryPoint := range s.entryPointsUDP {
+		entryPoint.switchHandler(handlersUDP[entryPointName])
+	}
+
 	s.metricsRegistry.LastConfigReloadSuccessGauge().Set(float64(time.Now().Unix()))
 
 	s.currentConfigurations.Set(newConfigurations)
@@ -67,6 +76,7 @@ func (s *Server) loadConfigurationTCP(configurations config.Configurations) map[
 	conf := mergeConfiguration(configurations)
 
 	s.tlsManager.UpdateConfigs(conf.TLSStores, conf.TLSOptions, conf.TLS)
+	s.roundTripperManager.Update(conf.HTTP.ServersTransports)
 
 	rtConf := config.NewRuntimeConfig(conf)
 	handlersNonTLS, handlersTLS := s.createHTTPHandlers(ctx, rtConf, entryPoints)
@@ -76,29 +86,62 @@ func (s *Server) loadConfigurationTCP(configurations config.Configurations) map[
 	return routersTCP
 }
 
+// loadConfigurationUDP returns the handlers of the UDP entry points, from the dynamic provider configurations.
+func (s *Server) loadConfigurationUDP(configurations config.Configurations) map[string]udpCore.Handler {
+	ctx := context.TODO()
+
+	var entryPoints []string
+	for entryPointName := range s.entryPointsUDP {
+		entryPoints = append(entryPoints, entryPointName)
+	}
+
+	conf := mergeConfiguration(configurations)
+
+	return s.createUDPHandlers(ctx, config.NewRuntimeConfig(conf), entryPoints)
+}
+
+// the given configuration must not be nil. its fields will get mutated.
+func (s *Server) createUDPHandlers(ctx context.Context, configuration *config.RuntimeConfiguration, entryPoints []string) map[string]udpCore.Handler {
+	if configuration == nil {
+		return make(map[string]udpCore.Handler)
+	}
+
+	serviceManager := udp.NewManager(configuration)
+	routerManager := routerudp.NewManager(configuration, serviceManager)
+
+	return routerManager.BuildHandlers(ctx, entryPoints)
+}
+
 // the given configuration must not be nil. its fields will get mutated.
 func (s *Server) createTCPRouters(ctx context.Context, configuration *config.RuntimeConfiguration, entryPoints []string, handlers map[string]http.Handler, handlersTLS map[string]http.Handler) map[string]*tcpCore.Router {
 	if configuration == nil {
 		return make(map[string]*tcpCore.Router)
 	}
 
-	serviceManager := tcp.NewManager(configuration)
+	serviceManager := tcp.NewManager(configuration, s.tcpServiceStateManager, s.metricsRegistry)
+	middlewaresBuilder := tcpmiddleware.NewBuilder(configuration.TCPMiddlewares, s.tcpMiddlewareStateManager)
 
-	routerManager := routertcp.NewManager(configuration, serviceManager, handlers, handlersTLS, s.tlsManager)
+	routerManager := routertcp.NewManager(configuration, serviceManager, middlewaresBuilder, handlers, handlersTLS, s.tlsManager, s.metricsRegistry, s.accessLoggerMiddleware)
 
-	return routerManager.BuildHandlers(ctx, entryPoints)
+	routers := routerManager.BuildHandlers(ctx, entryPoints)
+
+	s.tcpMiddlewareStateManager.Prune(configuration.TCPMiddlewares)
+
+	return routers
 }
 
 // createHTTPHandlers returns, for the given configuration and entryPoints, the HTTP handlers for non-TLS connections, and for the TLS ones. the given configuration must not be nil. its fields will get mutated.
 func (s *Server) createHTTPHandlers(ctx context.Context, configuration *config.RuntimeConfiguration, entryPoints []string) (map[string]http.Handler, map[string]http.Handler) {
-	serviceManager := service.NewManager(configuration.Services, s.defaultRoundTripper)
-	middlewaresBuilder := middleware.NewBuilder(configuration.Middlewares, serviceManager)
+	serviceManager := service.NewManager(configuration.Services, s.roundTripperManager, s.serviceStateManager, s.metricsRegistry)
+	middlewaresBuilder := middleware.NewBuilder(configuration.Middlewares, serviceManager, s.middlewareStateManager, s.metricsRegistry)
 	responseModifierFactory := responsemodifiers.NewBuilder(configuration.Middlewares)
 	routerManager := router.NewManager(configuration, serviceManager, middlewaresBuilder, responseModifierFactory)
 
 	handlersNonTLS := routerManager.BuildHandlers(ctx, entryPoints, false)
 	handlersTLS := routerManager.BuildHandlers(ctx, entryPoints, true)
 
+	s.middlewareStateManager.Prune(configuration.Services)
+
 	routerHandlers := make(map[string]http.Handler)
 	for _, entryPointName := range entryPoints {
 		internalMuxRouter := mux.NewRouter().SkipClean(true)
@@ -163,13 +206,20 @@ func isEmptyConfiguration(conf *config.Configuration) bool {
 	if conf.HTTP == nil {
 		conf.HTTP = &config.HTTPConfiguration{}
 	}
+	if conf.UDP == nil {
+		conf.UDP = &config.UDPConfiguration{}
+	}
 
 	return conf.HTTP.Routers == nil &&
 		conf.HTTP.Services == nil &&
 		conf.HTTP.Middlewares == nil &&
+		conf.HTTP.ServersTransports == nil &&
 		conf.TLS == nil &&
 		conf.TCP.Routers == nil &&
-		conf.TCP.Services == nil
+		conf.TCP.Services == nil &&
+		conf.TCP.Middlewares == nil &&
+		conf.UDP.Routers == nil &&
+		conf.UDP.Services == nil
 }
 
 func (s *Server) preLoadConfiguration(configMsg config.Message) {
@@ -44,7 +44,7 @@ func TestReuseService(t *testing.T) {
 		),
 	)
 
-	srv := NewServer(staticConfig, nil, entryPoints, nil)
+	srv := NewServer(staticConfig, nil, entryPoints, nil, nil)
 
 	rtConf := config.NewRuntimeConfig(config.Configuration{HTTP: dynamicConfigs})
 	entrypointsHandlers, _ := srv.createHTTPHandlers(context.Background(), rtConf, []string{"http"})
@@ -75,7 +75,7 @@ func TestThrottleProviderConfigReload(t *testing.T) {
 	}()
 
 	staticConfiguration := static.Configuration{}
-	server := NewServer(staticConfiguration, nil, nil, nil)
+	server := NewServer(staticConfiguration, nil, nil, nil, nil)
 
 	go server.throttleProviderConfigReload(throttleDuration, publishConfig, providerConfig, stop)
 
@@ -119,3 +119,48 @@ func TestThrottleProviderConfigReload(t *testing.T) {
 		t.Error("Last config was not published in time")
 	}
 }
+
+func TestIsEmptyConfiguration(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		conf     *config.Configuration
+		expected bool
+	}{
+		{
+			desc:     "nil configuration",
+			expected: true,
+		},
+		{
+			desc:     "empty configuration",
+			conf:     &config.Configuration{},
+			expected: true,
+		},
+		{
+			desc: "HTTP services",
+			conf: &config.Configuration{
+				HTTP: &config.HTTPConfiguration{Services: map[string]*config.Service{"foo": {}}},
+			},
+		},
+		{
+			desc: "HTTP servers transports only",
+			conf: &config.Configuration{
+				HTTP: &config.HTTPConfiguration{ServersTransports: map[string]*config.ServersTransport{"foo": {}}},
+			},
+		},
+		{
+			desc: "TCP middlewares only",
+			conf: &config.Configuration{
+				TCP: &config.TCPConfiguration{Middlewares: map[string]*config.TCPMiddleware{"foo": {}}},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, isEmptyConfiguration(test.conf))
+		})
+	}
+}
@@ -239,14 +239,65 @@ func buildProxyProtocolListener(ctx context.Context, entryPoint *static.EntryPoi
 
 	log.FromContext(ctx).Infof("Enabling ProxyProtocol for trusted IPs %v", entryPoint.ProxyProtocol.TrustedIPs)
 
-	return &proxyproto.Listener{
+	return &proxyProtocolListener{
 		Listener:    listener,
-		SourceCheck: sourceCheck,
+		sourceCheck: sourceCheck,
 	}, nil
 }
 
+// proxyProtocolListener accepts the connections that may be speaking the PROXY protocol.
+// Unlike proxyproto.Listener, its connections can be half-closed.
+type proxyProtocolListener struct {
+	net.Listener
+	sourceCheck func(addr net.Addr) (bool, error)
+}
+
+// Accept waits for and returns the next connection to the listener.
+// The address claimed in the PROXY header is only used if the connection comes from a trusted source.
+func (l *proxyProtocolListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+
+	trusted, err := l.sourceCheck(conn.RemoteAddr())
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return &proxyProtocolConn{
+		Conn:    proxyproto.NewConn(conn, 0),
+		raw:     conn,
+		trusted: trusted,
+	}, nil
+}
+
+// proxyProtocolConn is a connection that may be speaking the PROXY protocol.
+type proxyProtocolConn struct {
+	*proxyproto.Conn
+	raw     net.Conn
+	trusted bool
+}
+
+// RemoteAddr returns the address claimed in the PROXY header if the source is trusted,
+// and otherwise the address of the socket peer. The PROXY header is read in both cases.
+func (c *proxyProtocolConn) RemoteAddr() net.Addr {
+	addr := c.Conn.RemoteAddr()
+	if !c.trusted {
+		return c.raw.RemoteAddr()
+	}
+	return addr
+}
+
+// CloseWrite closes the writing half of the underlying connection,
+// or the whole connection if it cannot be half-closed.
+func (c *proxyProtocolConn) CloseWrite() error {
+	return tcp.CloseWrite(c.raw)
+}
+
 func buildListener(ctx context.Context, entryPoint *static.EntryPoint) (net.Listener, error) {
-	listener, err := net.Listen("tcp", entryPoint.Address)
+	listener, err := net.Listen("tcp", entryPoint.GetAddress())
 
 	if err != nil {
 		return nil, fmt.Errorf("error opening listener: %v", err)
@@ -389,3 +440,9 @@ func (t *trackedConnection) Close() error {
 	t.tracker.RemoveConnection(t.Conn)
 	return t.Conn.Close()
 }
+
+// CloseWrite closes the writing half of the connection,
+// or the whole connection if it cannot be half-closed.
+func (t *trackedConnection) CloseWrite() error {
+	return tcp.CloseWrite(t.Conn)
+}
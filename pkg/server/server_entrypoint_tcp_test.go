@@ -3,6 +3,8 @@ package server
 import (
 	"bufio"
 	"context"
+	"io"
+	"io/ioutil"
 	"net"
 	"net/http"
 	"testing"
@@ -140,3 +142,72 @@ func TestShutdownTCPConn(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, resp.StatusCode, http.StatusOK)
 }
+
+func TestProxyProtocolCloseWrite(t *testing.T) {
+	entryPoint, err := NewTCPEntryPoint(context.Background(), &static.EntryPoint{
+		Address: "127.0.0.1:0",
+		Transport: &static.EntryPointsTransport{
+			LifeCycle: &static.LifeCycle{
+				GraceTimeOut: types.Duration(5 * time.Second),
+			},
+		},
+		ProxyProtocol:    &static.ProxyProtocol{Insecure: true},
+		ForwardedHeaders: &static.ForwardedHeaders{},
+	})
+	require.NoError(t, err)
+
+	go entryPoint.startTCP(context.Background())
+
+	type result struct {
+		remoteAddr string
+		data       []byte
+		err        error
+	}
+	results := make(chan result, 1)
+
+	router := &tcp.Router{}
+	router.AddCatchAllNoTLS(tcp.HandlerFunc(func(conn net.Conn) {
+		defer conn.Close()
+
+		buf := make([]byte, 4)
+		if _, err := io.ReadFull(conn, buf); err != nil {
+			results <- result{err: err}
+			return
+		}
+
+		if err := tcp.CloseWrite(conn); err != nil {
+			results <- result{err: err}
+			return
+		}
+
+		data, err := ioutil.ReadAll(conn)
+		results <- result{remoteAddr: conn.RemoteAddr().String(), data: data, err: err}
+	}))
+
+	entryPoint.switchRouter(router)
+
+	conn, err := net.Dial("tcp", entryPoint.listener.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Write([]byte("PROXY TCP4 1.2.3.4 5.6.7.8 1234 80\r\nping"))
+	require.NoError(t, err)
+
+	// The entry point half-closed the connection: the client reads EOF, but can still write.
+	data, err := ioutil.ReadAll(conn)
+	require.NoError(t, err)
+	assert.Empty(t, data)
+
+	_, err = conn.Write([]byte("pong"))
+	require.NoError(t, err)
+	require.NoError(t, conn.(*net.TCPConn).CloseWrite())
+
+	select {
+	case res := <-results:
+		require.NoError(t, res.err)
+		assert.Equal(t, "1.2.3.4:1234", res.remoteAddr)
+		assert.Equal(t, "pong", string(res.data))
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout while waiting for the entry point to read the connection")
+	}
+}
@@ -0,0 +1,85 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/containous/traefik/pkg/config/static"
+	"github.com/containous/traefik/pkg/log"
+	"github.com/containous/traefik/pkg/safe"
+	"github.com/containous/traefik/pkg/udp"
+)
+
+// UDPEntryPoints holds a map of UDPEntryPoint (the entrypoint names being the keys)
+type UDPEntryPoints map[string]*UDPEntryPoint
+
+// UDPEntryPoint is the UDP server
+type UDPEntryPoint struct {
+	listener               *udp.Listener
+	switcher               *udp.HandlerSwitcher
+	transportConfiguration *static.EntryPointsTransport
+}
+
+// NewUDPEntryPoint creates a new UDPEntryPoint
+func NewUDPEntryPoint(configuration *static.EntryPoint) (*UDPEntryPoint, error) {
+	addr, err := net.ResolveUDPAddr("udp", configuration.GetAddress())
+	if err != nil {
+		return nil, fmt.Errorf("error resolving address: %v", err)
+	}
+
+	timeout := static.DefaultUDPTimeout
+	if configuration.UDP != nil && configuration.UDP.Timeout > 0 {
+		timeout = time.Duration(configuration.UDP.Timeout)
+	}
+
+	listener, err := udp.Listen("udp", addr, timeout)
+	if err != nil {
+		return nil, fmt.Errorf("error opening listener: %v", err)
+	}
+
+	return &UDPEntryPoint{
+		listener:               listener,
+		switcher:               &udp.HandlerSwitcher{},
+		transportConfiguration: configuration.Transport,
+	}, nil
+}
+
+func (e *UDPEntryPoint) startUDP(ctx context.Context) {
+	log.FromContext(ctx).Debugf("Start UDP Server")
+
+	for {
+		conn, err := e.listener.Accept()
+		if err != nil {
+			log.FromContext(ctx).Debug(err)
+			return
+		}
+
+		safe.Go(func() {
+			e.switcher.ServeUDP(conn)
+		})
+	}
+}
+
+// Shutdown stops accepting new UDP sessions, and waits for the current ones to end, at most for the grace timeout.
+func (e *UDPEntryPoint) Shutdown(ctx context.Context) {
+	logger := log.FromContext(ctx)
+
+	graceTimeOut := time.Duration(static.DefaultGraceTimeout)
+	if e.transportConfiguration != nil && e.transportConfiguration.LifeCycle != nil {
+		graceTimeOut = time.Duration(e.transportConfiguration.LifeCycle.GraceTimeOut)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, graceTimeOut)
+	defer cancel()
+
+	logger.Debugf("Waiting %s seconds before killing sessions.", graceTimeOut)
+	if err := e.listener.Shutdown(ctx); err != nil && ctx.Err() != context.DeadlineExceeded {
+		logger.Error(err)
+	}
+}
+
+func (e *UDPEntryPoint) switchHandler(handler udp.Handler) {
+	e.switcher.Switch(handler)
+}
@@ -0,0 +1,61 @@
+package server
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/pkg/config/static"
+	"github.com/containous/traefik/pkg/types"
+	"github.com/containous/traefik/pkg/udp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShutdownUDPConn(t *testing.T) {
+	entryPoint, err := NewUDPEntryPoint(&static.EntryPoint{
+		Address: "127.0.0.1:0/udp",
+		Transport: &static.EntryPointsTransport{
+			LifeCycle: &static.LifeCycle{
+				GraceTimeOut: types.Duration(5 * time.Second),
+			},
+		},
+		UDP: &static.UDPConfig{Timeout: types.Duration(3 * time.Second)},
+	})
+	require.NoError(t, err)
+
+	go entryPoint.startUDP(context.Background())
+
+	received := make(chan struct{})
+	entryPoint.switchHandler(udp.HandlerFunc(func(conn *udp.Conn) {
+		defer conn.Close()
+
+		buf := make([]byte, 1024)
+		n, err := conn.Read(buf)
+		require.NoError(t, err)
+		close(received)
+
+		time.Sleep(1 * time.Second)
+
+		_, err = conn.Write(buf[:n])
+		require.NoError(t, err)
+	}))
+
+	conn, err := net.Dial("udp", entryPoint.listener.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Write([]byte("ping"))
+	require.NoError(t, err)
+	<-received
+
+	go entryPoint.Shutdown(context.Background())
+
+	// The current session still gets its reply.
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(3*time.Second)))
+	buf := make([]byte, 1024)
+	n, err := conn.Read(buf)
+	require.NoError(t, err)
+	assert.Equal(t, "ping", string(buf[:n]))
+}
@@ -136,7 +136,7 @@ func setupListenProvider(throttleDuration time.Duration) (server *Server, stop c
 		},
 	}
 
-	server = NewServer(staticConfiguration, nil, nil, nil)
+	server = NewServer(staticConfiguration, nil, nil, nil, nil)
 	go server.listenProviders(stop)
 
 	return server, stop, invokeStopChan
@@ -252,7 +252,7 @@ func TestServerResponseEmptyBackend(t *testing.T) {
 				"http": &TCPEntryPoint{},
 			}
 
-			srv := NewServer(globalConfig, nil, entryPointsConfig, nil)
+			srv := NewServer(globalConfig, nil, entryPointsConfig, nil, nil)
 			rtConf := config.NewRuntimeConfig(config.Configuration{HTTP: test.config(testServer.URL)})
 			entryPoints, _ := srv.createHTTPHandlers(context.Background(), rtConf, []string{"http"})
 
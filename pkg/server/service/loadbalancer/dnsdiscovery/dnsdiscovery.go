@@ -0,0 +1,519 @@
+// Package dnsdiscovery implements a load balancer wrapper,
+// which discovers the servers of a service from the DNS.
+package dnsdiscovery
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/containous/traefik/pkg/config"
+	"github.com/containous/traefik/pkg/healthcheck"
+	"github.com/containous/traefik/pkg/hostresolver"
+	"github.com/containous/traefik/pkg/log"
+	"github.com/containous/traefik/pkg/safe"
+	"github.com/vulcand/oxy/roundrobin"
+	"github.com/vulcand/oxy/utils"
+)
+
+// SchemeSRV is the scheme of the server URLs discovered from SRV records.
+const SchemeSRV = "srv"
+
+const (
+	defaultResolvConfig       = "/etc/resolv.conf"
+	defaultMinRefreshInterval = 5 * time.Second
+	defaultMaxRefreshInterval = 5 * time.Minute
+)
+
+type contextKey int
+
+const hostKey contextKey = iota
+
+// Resolver resolves the host names and the SRV records of the targets.
+type Resolver interface {
+	LookupHost(host string) ([]net.IP, time.Duration, error)
+	LookupSRV(name string) ([]hostresolver.SRV, time.Duration, error)
+}
+
+// target is a server of the service configuration, from which the members are discovered.
+type target struct {
+	url    *url.URL
+	weight int
+}
+
+// member is a server discovered from a target.
+type member struct {
+	url    *url.URL
+	weight int
+	// host is the host name of the target the member was discovered from.
+	host string
+}
+
+// resolution is the last successful resolution of a target.
+type resolution struct {
+	members []member
+	expires time.Time
+}
+
+// Balancer wraps a load balancer, whose servers are the addresses the targets of the service resolve to.
+// The targets are resolved again after the lowest TTL of their records, within the refresh interval bounds.
+// The members of a target which cannot be resolved are kept until the next successful resolution.
+type Balancer struct {
+	healthcheck.BalancerHandler
+	serviceName string
+	next        http.Handler
+	resolver    Resolver
+	serviceInfo *config.ServiceInfo // can be nil
+	store       *Store
+	minRefresh  time.Duration
+	maxRefresh  time.Duration
+	now         func() time.Time
+
+	targets []target
+
+	mu          sync.RWMutex
+	members     map[string]member
+	nextRefresh time.Duration
+}
+
+// New creates a new Balancer forwarding the requests to next, whose resolutions are kept in the store.
+// The configuration can be nil, in which case the defaults are used.
+// The wrapped load balancer, set with SetBalancer, must forward the requests to the Forwarder of the Balancer.
+func New(serviceName string, cfg *config.DNSDiscovery, serviceInfo *config.ServiceInfo, store *Store, next http.Handler) (*Balancer, error) {
+	if cfg == nil {
+		cfg = &config.DNSDiscovery{}
+	}
+
+	resolvConfig := cfg.ResolvConfig
+	if resolvConfig == "" {
+		resolvConfig = defaultResolvConfig
+	}
+
+	minRefresh, err := parseInterval(cfg.MinRefreshInterval, defaultMinRefreshInterval)
+	if err != nil {
+		return nil, fmt.Errorf("invalid min refresh interval: %v", err)
+	}
+
+	maxRefresh, err := parseInterval(cfg.MaxRefreshInterval, defaultMaxRefreshInterval)
+	if err != nil {
+		return nil, fmt.Errorf("invalid max refresh interval: %v", err)
+	}
+
+	if maxRefresh < minRefresh {
+		return nil, fmt.Errorf("max refresh interval %s lower than the min refresh interval %s", maxRefresh, minRefresh)
+	}
+
+	return &Balancer{
+		serviceName: serviceName,
+		next:        next,
+		resolver:    &hostresolver.Resolver{ResolvConfig: resolvConfig},
+		serviceInfo: serviceInfo,
+		store:       store,
+		minRefresh:  minRefresh,
+		maxRefresh:  maxRefresh,
+		now:         time.Now,
+		members:     make(map[string]member),
+	}, nil
+}
+
+// IsSRV tells whether the server URL is discovered from SRV records.
+func IsSRV(rawURL string) bool {
+	return strings.HasPrefix(strings.ToLower(rawURL), SchemeSRV+"://")
+}
+
+// SetBalancer sets the wrapped load balancer.
+func (b *Balancer) SetBalancer(lb healthcheck.BalancerHandler) {
+	b.BalancerHandler = lb
+}
+
+// Forwarder returns the handler the wrapped load balancer forwards the requests to,
+// which keeps track of the host name of the target the server was discovered from.
+func (b *Balancer) Forwarder() http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		b.mu.RLock()
+		m, ok := b.members[req.URL.String()]
+		b.mu.RUnlock()
+
+		if ok && m.host != "" {
+			req = req.WithContext(WithHost(req.Context(), m.host))
+		}
+		b.next.ServeHTTP(rw, req)
+	})
+}
+
+// WithHost returns a copy of the context holding the host name of the target the server of the request was discovered from.
+func WithHost(ctx context.Context, host string) context.Context {
+	return context.WithValue(ctx, hostKey, host)
+}
+
+// HostFromContext returns the host name of the target the server of the request was discovered from.
+func HostFromContext(ctx context.Context) (string, bool) {
+	host, ok := ctx.Value(hostKey).(string)
+	return host, ok
+}
+
+// AddTarget adds a server of the service configuration, to be resolved.
+func (b *Balancer) AddTarget(u *url.URL, weight int) error {
+	if u.Hostname() == "" {
+		return fmt.Errorf("missing host in server URL %s", u)
+	}
+
+	if strings.EqualFold(u.Scheme, SchemeSRV) && u.Port() != "" {
+		return fmt.Errorf("the port of the server URL %s is discovered from the SRV records", u)
+	}
+
+	b.targets = append(b.targets, target{url: utils.CopyURL(u), weight: weight})
+	return nil
+}
+
+// UpsertServer adds the given server to the wrapped load balancer, or updates it.
+// The servers which are not discovered anymore cannot be added back, e.g. by the health checks.
+func (b *Balancer) UpsertServer(u *url.URL, options ...roundrobin.ServerOption) error {
+	b.mu.RLock()
+	_, ok := b.members[u.String()]
+	b.mu.RUnlock()
+
+	if !ok {
+		return fmt.Errorf("server %s is not discovered anymore", u)
+	}
+	return b.BalancerHandler.UpsertServer(u, options...)
+}
+
+// Restore adds to the wrapped load balancer the servers previously discovered from the targets, without resolving them,
+// so that building a new configuration does not wait for the DNS.
+// The targets which were never resolved, or whose resolution expired, are resolved as soon as the Balancer runs.
+func (b *Balancer) Restore(ctx context.Context) {
+	b.store.prune(b.serviceName, b.targets)
+
+	now := b.now()
+	next := b.maxRefresh
+	members := make(map[string]member)
+
+	for _, t := range b.targets {
+		res, ok := b.store.cached(b.serviceName, t)
+		if !ok {
+			next = 0
+			continue
+		}
+
+		if interval := res.expires.Sub(now); interval < next {
+			next = interval
+		}
+		addMembers(members, res.members)
+	}
+
+	if next < 0 {
+		next = 0
+	}
+
+	b.apply(ctx, members, next)
+}
+
+// run resolves the targets when they expire, and updates the servers of the wrapped load balancer, until the context is done.
+func (b *Balancer) run(ctx context.Context) {
+	logger := log.FromContext(ctx)
+
+	b.mu.RLock()
+	timer := time.NewTimer(b.nextRefresh)
+	b.mu.RUnlock()
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Debugf("Stopping the DNS discovery of service %s", b.serviceName)
+			return
+		case <-timer.C:
+			b.update(ctx)
+
+			b.mu.RLock()
+			timer.Reset(b.nextRefresh)
+			b.mu.RUnlock()
+		}
+	}
+}
+
+// update resolves the expired targets, and updates the servers of the wrapped load balancer.
+func (b *Balancer) update(ctx context.Context) {
+	logger := log.FromContext(ctx)
+
+	now := b.now()
+	next := b.maxRefresh
+	members := make(map[string]member)
+
+	for _, t := range b.targets {
+		res, ok := b.store.cached(b.serviceName, t)
+		if !ok || !now.Before(res.expires) {
+			resolved, ttl, err := b.resolve(t)
+			if err != nil {
+				logger.Errorf("DNS discovery of server %s failed, keeping the servers previously discovered: %v", t.url, err)
+				res.expires = now.Add(b.minRefresh)
+			} else {
+				if len(resolved) == 0 {
+					logger.Warnf("No server discovered for %s", t.url)
+				}
+				res = resolution{members: resolved, expires: now.Add(b.clamp(ttl))}
+				b.store.store(b.serviceName, t, res)
+			}
+		}
+
+		if interval := res.expires.Sub(now); interval < next {
+			next = interval
+		}
+		addMembers(members, res.members)
+	}
+
+	b.apply(ctx, members, b.clamp(next))
+}
+
+// addMembers adds the discovered members to the given ones, summing the weights of the members discovered from several targets.
+func addMembers(members map[string]member, discovered []member) {
+	for _, m := range discovered {
+		key := m.url.String()
+		if existing, ok := members[key]; ok {
+			m.weight += existing.weight
+		}
+		members[key] = m
+	}
+}
+
+// apply replaces the members of the Balancer, and updates the servers of the wrapped load balancer accordingly.
+func (b *Balancer) apply(ctx context.Context, members map[string]member, nextRefresh time.Duration) {
+	logger := log.FromContext(ctx)
+
+	b.mu.Lock()
+	previous := b.members
+	b.members = members
+	b.nextRefresh = nextRefresh
+	b.mu.Unlock()
+
+	for key, m := range previous {
+		if _, ok := members[key]; ok {
+			continue
+		}
+
+		logger.Debugf("Removing server %s, which is not discovered anymore", key)
+
+		// The server may have already been removed by the health checks.
+		if _, ok := b.BalancerHandler.ServerWeight(m.url); ok {
+			if err := b.BalancerHandler.RemoveServer(m.url); err != nil {
+				logger.Errorf("Error removing server %s: %v", key, err)
+			}
+		}
+
+		if b.serviceInfo != nil {
+			b.serviceInfo.RemoveStatus(key)
+		}
+	}
+
+	keys := make([]string, 0, len(members))
+	for key := range members {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		m := members[key]
+
+		if prev, ok := previous[key]; ok {
+			if prev.weight == m.weight {
+				continue
+			}
+
+			// A server removed by the health checks is put back by them, with its former weight.
+			if _, ok := b.BalancerHandler.ServerWeight(m.url); !ok {
+				continue
+			}
+		} else {
+			logger.Debugf("Adding server %s discovered from %s", key, m.host)
+		}
+
+		if err := b.BalancerHandler.UpsertServer(m.url, roundrobin.Weight(m.weight)); err != nil {
+			logger.Errorf("Error adding server %s: %v", key, err)
+		}
+	}
+}
+
+// resolve returns the members discovered from the target, and the lowest TTL of the records.
+func (b *Balancer) resolve(t target) ([]member, time.Duration, error) {
+	name := t.url.Hostname()
+
+	if !strings.EqualFold(t.url.Scheme, SchemeSRV) {
+		ips, ttl, err := b.resolver.LookupHost(name)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		var members []member
+		for _, ip := range ips {
+			members = append(members, newMember(t, t.url.Scheme, ip, t.url.Port(), t.weight, t.url.Host))
+		}
+		return members, ttl, nil
+	}
+
+	records, ttl, err := b.resolver.LookupSRV(name)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	scheme := "http"
+	if strings.HasPrefix(strings.ToLower(name), "_https.") {
+		scheme = "https"
+	}
+
+	var members []member
+	for _, record := range records {
+		weight := t.weight
+		if record.Weight > 1 {
+			weight *= int(record.Weight)
+		}
+
+		port := strconv.Itoa(int(record.Port))
+		host := net.JoinHostPort(strings.TrimSuffix(record.Target, "."), port)
+		for _, ip := range record.IPs {
+			members = append(members, newMember(t, scheme, ip, port, weight, host))
+		}
+	}
+	return members, ttl, nil
+}
+
+func newMember(t target, scheme string, ip net.IP, port string, weight int, host string) member {
+	u := utils.CopyURL(t.url)
+	u.Scheme = scheme
+
+	switch {
+	case port != "":
+		u.Host = net.JoinHostPort(ip.String(), port)
+	case ip.To4() == nil:
+		u.Host = "[" + ip.String() + "]"
+	default:
+		u.Host = ip.String()
+	}
+
+	return member{url: u, weight: weight, host: host}
+}
+
+func (b *Balancer) clamp(interval time.Duration) time.Duration {
+	if interval < b.minRefresh {
+		return b.minRefresh
+	}
+	if interval > b.maxRefresh {
+		return b.maxRefresh
+	}
+	return interval
+}
+
+// Store keeps, for each service, the last successful resolutions of its targets.
+// It outlives the balancers, which are rebuilt with each new configuration,
+// so that a new configuration starts with the known members of the targets,
+// and keeps them for the targets which cannot be resolved.
+// It also runs the periodic resolutions of the balancers of the current configuration.
+type Store struct {
+	mu          sync.Mutex
+	resolutions map[string]map[string]resolution
+
+	launchMu sync.Mutex
+	cancel   context.CancelFunc
+}
+
+// NewStore creates a new Store.
+func NewStore() *Store {
+	return &Store{resolutions: make(map[string]map[string]resolution)}
+}
+
+// Launch starts the periodic resolutions of the targets of the given balancers,
+// and stops the ones started by the previous call, whose balancers belong to a former configuration.
+func (s *Store) Launch(parentCtx context.Context, balancers []*Balancer) {
+	s.launchMu.Lock()
+	defer s.launchMu.Unlock()
+
+	if s.cancel != nil {
+		s.cancel()
+	}
+
+	ctx, cancel := context.WithCancel(parentCtx)
+	s.cancel = cancel
+
+	for _, balancer := range balancers {
+		balancer := balancer
+		balancerCtx := log.With(ctx, log.Str(log.ServiceName, balancer.serviceName))
+		safe.Go(func() {
+			balancer.run(balancerCtx)
+		})
+	}
+}
+
+// Prune forgets the resolutions of the services which are not part of the given configurations.
+func (s *Store) Prune(configs map[string]*config.ServiceInfo) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for serviceName := range s.resolutions {
+		if _, ok := configs[serviceName]; !ok {
+			delete(s.resolutions, serviceName)
+		}
+	}
+}
+
+func (s *Store) cached(serviceName string, t target) (resolution, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	res, ok := s.resolutions[serviceName][t.url.String()]
+	return res, ok
+}
+
+func (s *Store) store(serviceName string, t target, res resolution) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	targets, ok := s.resolutions[serviceName]
+	if !ok {
+		targets = make(map[string]resolution)
+		s.resolutions[serviceName] = targets
+	}
+	targets[t.url.String()] = res
+}
+
+// prune forgets the resolutions of the targets of the service which are not part of the given ones.
+func (s *Store) prune(serviceName string, targets []target) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key := range s.resolutions[serviceName] {
+		found := false
+		for _, t := range targets {
+			if t.url.String() == key {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			delete(s.resolutions[serviceName], key)
+		}
+	}
+}
+
+func parseInterval(value string, defaultValue time.Duration) (time.Duration, error) {
+	if value == "" {
+		return defaultValue, nil
+	}
+
+	interval, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, err
+	}
+
+	if interval <= 0 {
+		return 0, fmt.Errorf("%s is not a positive duration", value)
+	}
+	return interval, nil
+}
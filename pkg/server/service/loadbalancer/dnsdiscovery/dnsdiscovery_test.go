@@ -0,0 +1,360 @@
+package dnsdiscovery
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/pkg/config"
+	"github.com/containous/traefik/pkg/healthcheck"
+	"github.com/containous/traefik/pkg/hostresolver"
+	"github.com/containous/traefik/pkg/testhelpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vulcand/oxy/roundrobin"
+)
+
+type fakeResolver struct {
+	mu    sync.Mutex
+	hosts map[string][]net.IP
+	srv   map[string][]hostresolver.SRV
+	ttl   time.Duration
+	err   error
+}
+
+func (r *fakeResolver) LookupHost(host string) ([]net.IP, time.Duration, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.hosts[host], r.ttl, r.err
+}
+
+func (r *fakeResolver) LookupSRV(name string) ([]hostresolver.SRV, time.Duration, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.srv[name], r.ttl, r.err
+}
+
+func (r *fakeResolver) set(host string, ips ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.hosts[host] = nil
+	for _, ip := range ips {
+		r.hosts[host] = append(r.hosts[host], net.ParseIP(ip))
+	}
+}
+
+// hostHandler answers with the server the request is forwarded to, and the host name it was discovered from.
+var hostHandler = http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+	host, _ := HostFromContext(req.Context())
+	rw.Header().Set("server", req.URL.Host)
+	rw.Header().Set("host", host)
+})
+
+func servers(balancer *Balancer) map[string]int {
+	weights := make(map[string]int)
+	for _, u := range balancer.Servers() {
+		weights[u.String()], _ = balancer.ServerWeight(u)
+	}
+	return weights
+}
+
+func TestBalancer_update(t *testing.T) {
+	testCases := []struct {
+		desc                string
+		resolver            *fakeResolver
+		target              string
+		expectedServers     map[string]int
+		expectedHosts       map[string]string
+		expectedNextRefresh time.Duration
+	}{
+		{
+			desc: "host",
+			resolver: &fakeResolver{
+				hosts: map[string][]net.IP{"api.internal": {net.ParseIP("10.0.0.1"), net.ParseIP("::1")}},
+				ttl:   30 * time.Second,
+			},
+			target:              "http://api.internal:8080",
+			expectedServers:     map[string]int{"http://10.0.0.1:8080": 1, "http://[::1]:8080": 1},
+			expectedHosts:       map[string]string{"http://10.0.0.1:8080": "api.internal:8080", "http://[::1]:8080": "api.internal:8080"},
+			expectedNextRefresh: 30 * time.Second,
+		},
+		{
+			desc: "SRV records",
+			resolver: &fakeResolver{
+				srv: map[string][]hostresolver.SRV{
+					"_https._tcp.api.internal": {
+						{Target: "a.api.internal.", Port: 8443, Weight: 3, IPs: []net.IP{net.ParseIP("10.0.0.1")}},
+						{Target: "b.api.internal.", Port: 9443, Weight: 0, IPs: []net.IP{net.ParseIP("10.0.0.2")}},
+					},
+				},
+				ttl: time.Second,
+			},
+			target:          "srv://_https._tcp.api.internal",
+			expectedServers: map[string]int{"https://10.0.0.1:8443": 3, "https://10.0.0.2:9443": 1},
+			expectedHosts:   map[string]string{"https://10.0.0.1:8443": "a.api.internal:8443", "https://10.0.0.2:9443": "b.api.internal:9443"},
+			// The TTL is lower than the min refresh interval.
+			expectedNextRefresh: defaultMinRefreshInterval,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			balancer, err := New("test", nil, nil, NewStore(), hostHandler)
+			require.NoError(t, err)
+			balancer.resolver = test.resolver
+
+			lb, err := roundrobin.New(balancer.Forwarder())
+			require.NoError(t, err)
+			balancer.SetBalancer(lb)
+
+			require.NoError(t, balancer.AddTarget(testhelpers.MustParseURL(test.target), 1))
+
+			// The target was never resolved, it is resolved as soon as the balancer runs.
+			balancer.Restore(context.Background())
+			assert.Empty(t, servers(balancer))
+			assert.Equal(t, time.Duration(0), balancer.nextRefresh)
+
+			balancer.update(context.Background())
+			assert.Equal(t, test.expectedServers, servers(balancer))
+			assert.Equal(t, test.expectedNextRefresh, balancer.nextRefresh)
+
+			for server, host := range test.expectedHosts {
+				req := httptest.NewRequest(http.MethodGet, server, nil)
+
+				recorder := httptest.NewRecorder()
+				balancer.Forwarder().ServeHTTP(recorder, req)
+				assert.Equal(t, host, recorder.Header().Get("host"))
+			}
+		})
+	}
+}
+
+func TestBalancer_refresh(t *testing.T) {
+	resolver := &fakeResolver{hosts: make(map[string][]net.IP), ttl: time.Minute}
+	resolver.set("api.internal", "10.0.0.1", "10.0.0.2")
+
+	clock := time.Now()
+
+	serviceInfo := &config.ServiceInfo{}
+
+	balancer, err := New("test", nil, serviceInfo, NewStore(), hostHandler)
+	require.NoError(t, err)
+	balancer.resolver = resolver
+	balancer.now = func() time.Time { return clock }
+
+	lb, err := roundrobin.New(balancer.Forwarder())
+	require.NoError(t, err)
+	balancer.SetBalancer(lb)
+
+	require.NoError(t, balancer.AddTarget(testhelpers.MustParseURL("http://api.internal"), 1))
+	balancer.SetBalancer(healthcheck.NewLBStatusUpdater(lb, serviceInfo))
+	balancer.update(context.Background())
+
+	assert.Equal(t, map[string]int{"http://10.0.0.1": 1, "http://10.0.0.2": 1}, servers(balancer))
+
+	resolver.set("api.internal", "10.0.0.2", "10.0.0.3")
+
+	// The resolution did not expire.
+	balancer.update(context.Background())
+	assert.Equal(t, map[string]int{"http://10.0.0.1": 1, "http://10.0.0.2": 1}, servers(balancer))
+
+	clock = clock.Add(time.Minute)
+	balancer.update(context.Background())
+	assert.Equal(t, map[string]int{"http://10.0.0.2": 1, "http://10.0.0.3": 1}, servers(balancer))
+	assert.Equal(t, map[string]string{"http://10.0.0.2": "UP", "http://10.0.0.3": "UP"}, serviceInfo.GetAllStatus())
+
+	// A server which is not discovered anymore cannot be put back.
+	assert.Error(t, balancer.UpsertServer(testhelpers.MustParseURL("http://10.0.0.1")))
+
+	// The servers are kept when the resolution fails.
+	resolver.err = errors.New("timeout")
+	clock = clock.Add(time.Minute)
+	balancer.update(context.Background())
+	assert.Equal(t, map[string]int{"http://10.0.0.2": 1, "http://10.0.0.3": 1}, servers(balancer))
+	assert.Equal(t, defaultMinRefreshInterval, balancer.nextRefresh)
+}
+
+func TestBalancer_healthCheckRemoved(t *testing.T) {
+	resolver := &fakeResolver{hosts: make(map[string][]net.IP), ttl: time.Minute}
+	resolver.set("api.internal", "10.0.0.1", "10.0.0.2")
+
+	clock := time.Now()
+
+	balancer, err := New("test", nil, nil, NewStore(), hostHandler)
+	require.NoError(t, err)
+	balancer.resolver = resolver
+	balancer.now = func() time.Time { return clock }
+
+	lb, err := roundrobin.New(balancer.Forwarder())
+	require.NoError(t, err)
+	balancer.SetBalancer(lb)
+
+	require.NoError(t, balancer.AddTarget(testhelpers.MustParseURL("http://api.internal"), 1))
+	balancer.update(context.Background())
+
+	// The health checks remove a server, which is still discovered.
+	require.NoError(t, balancer.RemoveServer(testhelpers.MustParseURL("http://10.0.0.1")))
+
+	clock = clock.Add(time.Minute)
+	balancer.update(context.Background())
+	assert.Equal(t, map[string]int{"http://10.0.0.2": 1}, servers(balancer))
+
+	require.NoError(t, balancer.UpsertServer(testhelpers.MustParseURL("http://10.0.0.1")))
+	assert.Equal(t, map[string]int{"http://10.0.0.1": 1, "http://10.0.0.2": 1}, servers(balancer))
+}
+
+func TestBalancer_cache(t *testing.T) {
+	resolver := &fakeResolver{hosts: make(map[string][]net.IP), ttl: time.Minute}
+	resolver.set("api.internal", "10.0.0.1")
+
+	store := NewStore()
+	start := time.Now()
+
+	// Each configuration builds a new balancer, sharing the resolutions of the store.
+	configurations := []struct {
+		desc                string
+		elapsed             time.Duration
+		target              string
+		resolved            []string
+		err                 error
+		expectedRestored    map[string]int
+		expectedNextRefresh time.Duration
+		expectedServers     map[string]int
+	}{
+		{
+			desc:                "first resolution",
+			target:              "http://api.internal",
+			resolved:            []string{"10.0.0.1"},
+			expectedRestored:    map[string]int{},
+			expectedNextRefresh: 0,
+			expectedServers:     map[string]int{"http://10.0.0.1": 1},
+		},
+		{
+			desc:                "resolution which did not expire",
+			elapsed:             20 * time.Second,
+			target:              "http://api.internal",
+			resolved:            []string{"10.0.0.2"},
+			expectedRestored:    map[string]int{"http://10.0.0.1": 1},
+			expectedNextRefresh: 40 * time.Second,
+			expectedServers:     map[string]int{"http://10.0.0.1": 1},
+		},
+		{
+			desc:                "expired resolution, which fails",
+			elapsed:             80 * time.Second,
+			target:              "http://api.internal",
+			err:                 errors.New("timeout"),
+			expectedRestored:    map[string]int{"http://10.0.0.1": 1},
+			expectedNextRefresh: 0,
+			expectedServers:     map[string]int{"http://10.0.0.1": 1},
+		},
+		{
+			desc:                "target removed from the service",
+			elapsed:             80 * time.Second,
+			target:              "http://other.internal",
+			err:                 errors.New("timeout"),
+			expectedRestored:    map[string]int{},
+			expectedNextRefresh: 0,
+			expectedServers:     map[string]int{},
+		},
+	}
+
+	for _, configuration := range configurations {
+		now := start.Add(configuration.elapsed)
+		resolver.set("api.internal", configuration.resolved...)
+		resolver.err = configuration.err
+
+		balancer, err := New("test", nil, nil, store, hostHandler)
+		require.NoError(t, err)
+		balancer.resolver = resolver
+		balancer.now = func() time.Time { return now }
+
+		lb, err := roundrobin.New(balancer.Forwarder())
+		require.NoError(t, err)
+		balancer.SetBalancer(lb)
+
+		require.NoError(t, balancer.AddTarget(testhelpers.MustParseURL(configuration.target), 1))
+
+		// The servers previously discovered are restored without resolving the target.
+		balancer.Restore(context.Background())
+		assert.Equal(t, configuration.expectedRestored, servers(balancer), configuration.desc)
+		assert.Equal(t, configuration.expectedNextRefresh, balancer.nextRefresh, configuration.desc)
+
+		balancer.update(context.Background())
+		assert.Equal(t, configuration.expectedServers, servers(balancer), configuration.desc)
+	}
+
+	// The resolutions of the targets removed from the service are forgotten.
+	assert.NotContains(t, store.resolutions["test"], "http://api.internal")
+}
+
+func TestStore_Prune(t *testing.T) {
+	resolver := &fakeResolver{hosts: make(map[string][]net.IP), ttl: time.Minute}
+	resolver.set("api.internal", "10.0.0.1")
+
+	store := NewStore()
+
+	for _, serviceName := range []string{"foo", "bar"} {
+		balancer, err := New(serviceName, nil, nil, store, hostHandler)
+		require.NoError(t, err)
+		balancer.resolver = resolver
+
+		lb, err := roundrobin.New(balancer.Forwarder())
+		require.NoError(t, err)
+		balancer.SetBalancer(lb)
+
+		require.NoError(t, balancer.AddTarget(testhelpers.MustParseURL("http://api.internal"), 1))
+		balancer.update(context.Background())
+	}
+
+	store.Prune(map[string]*config.ServiceInfo{"foo": {}})
+
+	assert.Contains(t, store.resolutions, "foo")
+	assert.NotContains(t, store.resolutions, "bar")
+}
+
+func TestNew_invalid(t *testing.T) {
+	testCases := []struct {
+		desc string
+		cfg  *config.DNSDiscovery
+	}{
+		{
+			desc: "invalid min refresh interval",
+			cfg:  &config.DNSDiscovery{MinRefreshInterval: "foo"},
+		},
+		{
+			desc: "negative max refresh interval",
+			cfg:  &config.DNSDiscovery{MaxRefreshInterval: "-1s"},
+		},
+		{
+			desc: "max refresh interval lower than min",
+			cfg:  &config.DNSDiscovery{MinRefreshInterval: "1m", MaxRefreshInterval: "10s"},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := New("test", test.cfg, nil, NewStore(), http.NotFoundHandler())
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestBalancer_AddTarget_invalid(t *testing.T) {
+	balancer, err := New("test", nil, nil, NewStore(), http.NotFoundHandler())
+	require.NoError(t, err)
+
+	assert.Error(t, balancer.AddTarget(testhelpers.MustParseURL("srv://_http._tcp.api.internal:8080"), 1))
+	assert.Error(t, balancer.AddTarget(testhelpers.MustParseURL("/foo"), 1))
+}
@@ -0,0 +1,199 @@
+// Package failover implements a handler forwarding the requests to a main handler,
+// and to a fallback handler when the main one is down or fails to handle them.
+package failover
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"sync"
+
+	"github.com/containous/traefik/pkg/log"
+	"github.com/containous/traefik/pkg/middlewares"
+	"github.com/containous/traefik/pkg/types"
+)
+
+// Failover is an http.Handler forwarding the requests to a main handler,
+// and to a fallback handler when the main one is down,
+// or when the status code of its response is one of the configured status codes.
+// The requests are forwarded to the main handler again as soon as it is up.
+type Failover struct {
+	name        string
+	handler     http.Handler
+	fallback    http.Handler
+	isUp        func() bool
+	statusCodes types.HTTPCodeRanges
+	// maxBodySize is the maximum size (in bytes) of the request bodies buffered to be replayed to the fallback handler.
+	// The requests with a larger body are never sent to the fallback handler because of the status code of the main one.
+	maxBodySize int64
+
+	lock       sync.Mutex
+	inFallback bool
+}
+
+// New creates a new Failover.
+// isUp tells whether the main handler is up, and can be nil if the main handler is always considered up.
+func New(name string, handler, fallback http.Handler, isUp func() bool) *Failover {
+	return &Failover{
+		name:        name,
+		handler:     handler,
+		fallback:    fallback,
+		isUp:        isUp,
+		maxBodySize: middlewares.DefaultMaxBodySize,
+	}
+}
+
+// SetStatusCodes sets the status codes of the responses of the main handler
+// for which the request is sent to the fallback handler.
+func (f *Failover) SetStatusCodes(statusCodes types.HTTPCodeRanges) {
+	f.statusCodes = statusCodes
+}
+
+// SetMaxBodySize sets the maximum size (in bytes) of the request bodies buffered to be replayed to the fallback handler.
+func (f *Failover) SetMaxBodySize(maxBodySize int64) {
+	f.maxBodySize = maxBodySize
+}
+
+func (f *Failover) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if f.isUp != nil && !f.isUp() {
+		f.setFallback(req, true)
+		f.fallback.ServeHTTP(rw, req)
+		return
+	}
+
+	f.setFallback(req, false)
+
+	if len(f.statusCodes) == 0 {
+		f.handler.ServeHTTP(rw, req)
+		return
+	}
+
+	logger := log.FromContext(req.Context())
+
+	body, err := middlewares.ReadBody(req, f.maxBodySize)
+	if err == middlewares.ErrBodyTooLarge {
+		logger.Debug("No failover on the status code: the request body is larger than the allowed size")
+		f.handler.ServeHTTP(rw, req)
+		return
+	}
+	if err != nil {
+		logger.Errorf("Error while reading the request body: %v", err)
+		http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	recorder := newResponseInterceptor(rw, f.statusCodes)
+	f.handler.ServeHTTP(recorder, withBody(req, body))
+
+	if recorder.intercepted {
+		logger.Debugf("Sending the request to the fallback service of %s: the main service responded with %d", f.name, recorder.code)
+		f.fallback.ServeHTTP(rw, withBody(req, body))
+	}
+}
+
+// setFallback records whether the requests are sent to the fallback handler, and logs the transitions.
+func (f *Failover) setFallback(req *http.Request, inFallback bool) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if f.inFallback == inFallback {
+		return
+	}
+	f.inFallback = inFallback
+
+	if inFallback {
+		log.FromContext(req.Context()).Warnf("The main service of %s is down, sending the requests to the fallback service", f.name)
+	} else {
+		log.FromContext(req.Context()).Infof("The main service of %s is up again, sending the requests to the main service", f.name)
+	}
+}
+
+func withBody(req *http.Request, body []byte) *http.Request {
+	clone := req.WithContext(req.Context())
+
+	if body == nil {
+		clone.Body = http.NoBody
+		return clone
+	}
+
+	clone.Body = ioutil.NopCloser(bytes.NewReader(body))
+	clone.ContentLength = int64(len(body))
+	return clone
+}
+
+// responseInterceptor forwards the response of the main handler to the client,
+// unless its status code is one of the given status codes.
+// In this case, the response is discarded so that the fallback handler can respond instead.
+type responseInterceptor struct {
+	rw          http.ResponseWriter
+	header      http.Header
+	statusCodes types.HTTPCodeRanges
+
+	code        int
+	wroteHeader bool
+	intercepted bool
+}
+
+func newResponseInterceptor(rw http.ResponseWriter, statusCodes types.HTTPCodeRanges) *responseInterceptor {
+	return &responseInterceptor{
+		rw:          rw,
+		header:      make(http.Header),
+		statusCodes: statusCodes,
+	}
+}
+
+func (r *responseInterceptor) Header() http.Header {
+	return r.header
+}
+
+func (r *responseInterceptor) WriteHeader(code int) {
+	if r.wroteHeader {
+		return
+	}
+	r.wroteHeader = true
+	r.code = code
+
+	if r.statusCodes.Contains(code) {
+		r.intercepted = true
+		return
+	}
+
+	for k, v := range r.header {
+		r.rw.Header()[k] = v
+	}
+	r.rw.WriteHeader(code)
+}
+
+func (r *responseInterceptor) Write(p []byte) (int, error) {
+	if !r.wroteHeader {
+		r.WriteHeader(http.StatusOK)
+	}
+
+	if r.intercepted {
+		return len(p), nil
+	}
+	return r.rw.Write(p)
+}
+
+// Flush sends any buffered data to the client, unless the response is intercepted.
+func (r *responseInterceptor) Flush() {
+	if !r.wroteHeader || r.intercepted {
+		return
+	}
+
+	if flusher, ok := r.rw.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack hijacks the connection.
+func (r *responseInterceptor) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := r.rw.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("the response writer does not implement http.Hijacker")
+	}
+	return hijacker.Hijack()
+}
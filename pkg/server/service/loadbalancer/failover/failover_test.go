@@ -0,0 +1,136 @@
+package failover
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/containous/traefik/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newHandler(t *testing.T, name string, code int) http.Handler {
+	t.Helper()
+
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		body, err := ioutil.ReadAll(req.Body)
+		require.NoError(t, err)
+
+		rw.Header().Set("X-From", name)
+		rw.WriteHeader(code)
+		_, _ = rw.Write([]byte(name + ":" + string(body)))
+	})
+}
+
+func TestFailover(t *testing.T) {
+	testCases := []struct {
+		desc         string
+		mainCode     int
+		mainDown     bool
+		statusCodes  []string
+		body         string
+		expectedCode int
+		expectedFrom string
+		expectedBody string
+	}{
+		{
+			desc:         "main service up",
+			mainCode:     http.StatusOK,
+			expectedCode: http.StatusOK,
+			expectedFrom: "main",
+			expectedBody: "main:",
+		},
+		{
+			desc:         "main service down",
+			mainCode:     http.StatusOK,
+			mainDown:     true,
+			expectedCode: http.StatusOK,
+			expectedFrom: "fallback",
+			expectedBody: "fallback:",
+		},
+		{
+			desc:         "main service error without status codes",
+			mainCode:     http.StatusServiceUnavailable,
+			expectedCode: http.StatusServiceUnavailable,
+			expectedFrom: "main",
+			expectedBody: "main:",
+		},
+		{
+			desc:         "main service error in the status codes",
+			mainCode:     http.StatusServiceUnavailable,
+			statusCodes:  []string{"404", "500-599"},
+			body:         "foobar",
+			expectedCode: http.StatusOK,
+			expectedFrom: "fallback",
+			expectedBody: "fallback:foobar",
+		},
+		{
+			desc:         "main service error not in the status codes",
+			mainCode:     http.StatusForbidden,
+			statusCodes:  []string{"500-599"},
+			body:         "foobar",
+			expectedCode: http.StatusForbidden,
+			expectedFrom: "main",
+			expectedBody: "main:foobar",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			failover := New("test", newHandler(t, "main", test.mainCode), newHandler(t, "fallback", http.StatusOK), func() bool {
+				return !test.mainDown
+			})
+
+			statusCodes, err := types.NewHTTPCodeRanges(test.statusCodes)
+			require.NoError(t, err)
+			failover.SetStatusCodes(statusCodes)
+
+			recorder := httptest.NewRecorder()
+			failover.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(test.body)))
+
+			assert.Equal(t, test.expectedCode, recorder.Code)
+			assert.Equal(t, []string{test.expectedFrom}, recorder.Header()["X-From"])
+			assert.Equal(t, test.expectedBody, recorder.Body.String())
+		})
+	}
+}
+
+func TestFailover_switchBack(t *testing.T) {
+	up := true
+	failover := New("test", newHandler(t, "main", http.StatusOK), newHandler(t, "fallback", http.StatusOK), func() bool {
+		return up
+	})
+
+	var froms []string
+	for _, status := range []bool{true, false, false, true} {
+		up = status
+
+		recorder := httptest.NewRecorder()
+		failover.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+		froms = append(froms, recorder.Header().Get("X-From"))
+	}
+
+	assert.Equal(t, []string{"main", "fallback", "fallback", "main"}, froms)
+}
+
+func TestFailover_bodyTooLarge(t *testing.T) {
+	failover := New("test", newHandler(t, "main", http.StatusBadGateway), newHandler(t, "fallback", http.StatusOK), nil)
+	failover.SetStatusCodes(types.HTTPCodeRanges{{500, 599}})
+
+	failover.SetMaxBodySize(16)
+
+	body := strings.Repeat("a", 17)
+
+	recorder := httptest.NewRecorder()
+	failover.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)))
+
+	// The body cannot be replayed, so the response of the main handler is kept.
+	assert.Equal(t, http.StatusBadGateway, recorder.Code)
+	assert.Equal(t, "main:"+body, recorder.Body.String())
+}
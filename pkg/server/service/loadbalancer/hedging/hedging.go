@@ -0,0 +1,394 @@
+// Package hedging implements a handler which sends a request again to a service,
+// when the first attempt did not get its response headers in time, and forwards the first response.
+package hedging
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/containous/traefik/pkg/config"
+	"github.com/containous/traefik/pkg/middlewares/accesslog"
+	"github.com/go-kit/kit/metrics"
+)
+
+const (
+	defaultMaxHedges = 1
+
+	// latencyPercentile is the percentile of the latencies of the service used as delay, when none is configured.
+	latencyPercentile = 95
+	// latencyWindow is the number of latencies the percentile is computed on.
+	latencyWindow = 1000
+	// latencyRefresh is the number of latencies observed between two computations of the percentile,
+	// and the number of latencies needed before hedging on the percentile.
+	latencyRefresh = 100
+)
+
+var defaultMethods = []string{http.MethodGet, http.MethodHead}
+
+// Handler forwards the requests to the next handler, and sends them again,
+// up to maxHedges times, if no response headers were received after the delay.
+// Each hedged request is balanced like the first one, thus usually handled by another server of the service.
+// The first response wins, and the other requests are canceled.
+type Handler struct {
+	next      http.Handler
+	delay     time.Duration
+	maxHedges int
+	methods   map[string]struct{}
+	latencies *latencies
+
+	hedgesCounter metrics.Counter
+	wonCounter    metrics.Counter
+}
+
+// New creates a new Handler.
+// The hedgesCounter counts the hedged requests, and the wonCounter the hedged requests whose response was forwarded.
+func New(next http.Handler, cfg *config.Hedging, hedgesCounter, wonCounter metrics.Counter) (*Handler, error) {
+	h := &Handler{
+		next:          next,
+		maxHedges:     defaultMaxHedges,
+		methods:       make(map[string]struct{}),
+		latencies:     &latencies{},
+		hedgesCounter: hedgesCounter,
+		wonCounter:    wonCounter,
+	}
+
+	if cfg.Delay != "" {
+		delay, err := time.ParseDuration(cfg.Delay)
+		if err != nil {
+			return nil, fmt.Errorf("invalid delay: %v", err)
+		}
+		if delay <= 0 {
+			return nil, fmt.Errorf("delay must be greater than zero: %s", cfg.Delay)
+		}
+		h.delay = delay
+	}
+
+	if cfg.MaxHedges < 0 {
+		return nil, fmt.Errorf("max hedges must not be smaller than zero: %d", cfg.MaxHedges)
+	}
+	if cfg.MaxHedges > 0 {
+		h.maxHedges = cfg.MaxHedges
+	}
+
+	methods := cfg.Methods
+	if len(methods) == 0 {
+		methods = defaultMethods
+	}
+	for _, method := range methods {
+		h.methods[strings.ToUpper(method)] = struct{}{}
+	}
+
+	return h, nil
+}
+
+func (h *Handler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if !h.hedgeable(req) {
+		h.next.ServeHTTP(rw, req)
+		return
+	}
+
+	start := time.Now()
+
+	delay := h.hedgeDelay()
+	if delay == 0 {
+		h.next.ServeHTTP(&latencyRecorder{ResponseWriter: rw, start: start, latencies: h.latencies}, req)
+		return
+	}
+
+	r := &race{rw: rw, elected: make(chan struct{})}
+	results := make(chan *attempt, h.maxHedges+1)
+
+	attempts := []*attempt{h.launch(req, r, 0, results)}
+	pending := 1
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	elected := r.elected
+	hedge := timer.C
+	for pending > 0 {
+		select {
+		case <-elected:
+			elected = nil
+			hedge = nil
+			h.latencies.observe(time.Since(start))
+
+			winner := r.getWinner()
+			for _, a := range attempts {
+				if a != winner {
+					a.cancel()
+				}
+			}
+		case <-results:
+			pending--
+		case <-hedge:
+			attempts = append(attempts, h.launch(req, r, len(attempts), results))
+			pending++
+			h.hedgesCounter.Add(1)
+
+			if len(attempts) <= h.maxHedges {
+				timer.Reset(delay)
+			} else {
+				hedge = nil
+			}
+		}
+	}
+
+	for _, a := range attempts {
+		a.cancel()
+	}
+
+	winner := r.getWinner()
+	if winner == nil {
+		// All the attempts panicked before writing the response headers.
+		panic(attempts[0].panicked)
+	}
+
+	if data := accesslog.GetLogData(req); data != nil && winner.logData != nil {
+		for k, v := range winner.logData.Core {
+			data.Core[k] = v
+		}
+	}
+
+	if winner.index > 0 {
+		h.wonCounter.Add(1)
+	}
+
+	if winner.panicked != nil {
+		panic(winner.panicked)
+	}
+}
+
+// hedgeDelay returns the delay after which a request is hedged, or zero if the requests must not be hedged yet.
+func (h *Handler) hedgeDelay() time.Duration {
+	if h.delay > 0 {
+		return h.delay
+	}
+	return h.latencies.percentile()
+}
+
+// hedgeable returns whether the request can be sent several times,
+// which requires an allowed method and no body.
+func (h *Handler) hedgeable(req *http.Request) bool {
+	if _, ok := h.methods[req.Method]; !ok {
+		return false
+	}
+
+	if req.ContentLength != 0 {
+		return false
+	}
+
+	// The connection of an upgraded request is hijacked, thus cannot be raced.
+	return req.Header.Get("Upgrade") == ""
+}
+
+// launch sends the request to the next handler, in its own goroutine.
+// Each attempt has its own context, headers, and access log data, so that it can be canceled and not interfere with the others.
+func (h *Handler) launch(req *http.Request, r *race, index int, results chan<- *attempt) *attempt {
+	ctx, cancel := context.WithCancel(req.Context())
+
+	a := &attempt{
+		race:   r,
+		index:  index,
+		cancel: cancel,
+		header: make(http.Header),
+	}
+
+	if data := accesslog.GetLogData(req); data != nil {
+		a.logData = &accesslog.LogData{
+			Core:               make(accesslog.CoreLogData, len(data.Core)),
+			Request:            data.Request,
+			OriginResponse:     make(http.Header),
+			DownstreamResponse: data.DownstreamResponse,
+		}
+		for k, v := range data.Core {
+			a.logData.Core[k] = v
+		}
+		ctx = context.WithValue(ctx, accesslog.DataTableKey, a.logData)
+	}
+
+	attemptReq := req.WithContext(ctx)
+	attemptReq.Header = cloneHeader(req.Header)
+
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				a.panicked = p
+			}
+			results <- a
+		}()
+
+		h.next.ServeHTTP(a, attemptReq)
+		a.WriteHeader(http.StatusOK)
+	}()
+
+	return a
+}
+
+// race elects the first attempt writing its response headers.
+type race struct {
+	rw      http.ResponseWriter
+	elected chan struct{}
+
+	mu     sync.Mutex
+	winner *attempt
+}
+
+func (r *race) elect(a *attempt) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.winner != nil {
+		return false
+	}
+
+	r.winner = a
+	close(r.elected)
+	return true
+}
+
+func (r *race) getWinner() *attempt {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.winner
+}
+
+// attempt is the response writer of one of the requests sent for a hedged request.
+// The response of the winner is written to the response writer of the race,
+// while the responses of the other attempts are discarded.
+type attempt struct {
+	race     *race
+	index    int
+	cancel   context.CancelFunc
+	header   http.Header
+	logData  *accesslog.LogData
+	panicked interface{}
+
+	wroteHeader bool
+	won         bool
+}
+
+func (a *attempt) Header() http.Header {
+	return a.header
+}
+
+func (a *attempt) WriteHeader(code int) {
+	if a.wroteHeader {
+		return
+	}
+	a.wroteHeader = true
+
+	if !a.race.elect(a) {
+		return
+	}
+	a.won = true
+
+	header := a.race.rw.Header()
+	for k, v := range a.header {
+		header[k] = v
+	}
+	a.race.rw.WriteHeader(code)
+}
+
+func (a *attempt) Write(b []byte) (int, error) {
+	a.WriteHeader(http.StatusOK)
+
+	if !a.won {
+		return len(b), nil
+	}
+	return a.race.rw.Write(b)
+}
+
+func (a *attempt) Flush() {
+	if !a.won {
+		return
+	}
+
+	if flusher, ok := a.race.rw.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// latencyRecorder records the latency of the response headers of the requests sent before the percentile is known.
+type latencyRecorder struct {
+	http.ResponseWriter
+	start       time.Time
+	latencies   *latencies
+	wroteHeader bool
+}
+
+func (l *latencyRecorder) WriteHeader(code int) {
+	if !l.wroteHeader {
+		l.wroteHeader = true
+		l.latencies.observe(time.Since(l.start))
+	}
+	l.ResponseWriter.WriteHeader(code)
+}
+
+func (l *latencyRecorder) Write(b []byte) (int, error) {
+	if !l.wroteHeader {
+		l.WriteHeader(http.StatusOK)
+	}
+	return l.ResponseWriter.Write(b)
+}
+
+func (l *latencyRecorder) Flush() {
+	if flusher, ok := l.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// latencies tracks the latencies of the response headers of a service, over a sliding window.
+type latencies struct {
+	mu       sync.Mutex
+	samples  []time.Duration
+	next     int
+	observed int
+	value    time.Duration
+}
+
+func (l *latencies) observe(latency time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if len(l.samples) < latencyWindow {
+		l.samples = append(l.samples, latency)
+	} else {
+		l.samples[l.next] = latency
+	}
+	l.next = (l.next + 1) % latencyWindow
+
+	l.observed++
+	if l.observed%latencyRefresh != 0 {
+		return
+	}
+
+	sorted := make([]time.Duration, len(l.samples))
+	copy(sorted, l.samples)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	index := (len(sorted)*latencyPercentile+99)/100 - 1
+	l.value = sorted[index]
+}
+
+// percentile returns the percentile of the latencies, or zero if not enough latencies were observed.
+func (l *latencies) percentile() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.value
+}
+
+func cloneHeader(header http.Header) http.Header {
+	clone := make(http.Header, len(header))
+	for k, v := range header {
+		clone[k] = append([]string(nil), v...)
+	}
+	return clone
+}
@@ -0,0 +1,252 @@
+package hedging
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/pkg/config"
+	"github.com/containous/traefik/pkg/middlewares/accesslog"
+	"github.com/go-kit/kit/metrics/generic"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// slowFirstHandler responds after the request is canceled to the first request,
+// and immediately to the following ones.
+type slowFirstHandler struct {
+	calls    int32
+	canceled chan struct{}
+}
+
+func (s *slowFirstHandler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	call := atomic.AddInt32(&s.calls, 1)
+	if call == 1 {
+		select {
+		case <-req.Context().Done():
+			close(s.canceled)
+			return
+		case <-time.After(5 * time.Second):
+		}
+	}
+
+	rw.Header().Set("X-Attempt", strconv.Itoa(int(call)))
+	rw.WriteHeader(http.StatusOK)
+	_, _ = rw.Write([]byte("attempt " + strconv.Itoa(int(call))))
+}
+
+func newHandler(t *testing.T, next http.Handler, cfg *config.Hedging) (*Handler, *generic.Counter, *generic.Counter) {
+	t.Helper()
+
+	hedges := generic.NewCounter("hedges")
+	won := generic.NewCounter("won")
+
+	handler, err := New(next, cfg, hedges, won)
+	require.NoError(t, err)
+
+	return handler, hedges, won
+}
+
+func TestHandler_hedgeWins(t *testing.T) {
+	next := &slowFirstHandler{canceled: make(chan struct{})}
+	handler, hedges, won := newHandler(t, next, &config.Hedging{Delay: "10ms"})
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://foo", nil))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "2", recorder.Header().Get("X-Attempt"))
+	assert.Equal(t, "attempt 2", recorder.Body.String())
+
+	select {
+	case <-next.canceled:
+	default:
+		t.Error("the first request has not been canceled")
+	}
+
+	assert.Equal(t, float64(1), hedges.Value())
+	assert.Equal(t, float64(1), won.Value())
+}
+
+func TestHandler_noHedge(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		method  string
+		body    string
+		header  http.Header
+		latency time.Duration
+		cfg     *config.Hedging
+	}{
+		{
+			desc:   "fast response",
+			method: http.MethodGet,
+			cfg:    &config.Hedging{Delay: "1s"},
+		},
+		{
+			desc:    "method not allowed by default",
+			latency: 20 * time.Millisecond,
+			method:  http.MethodPost,
+			cfg:     &config.Hedging{Delay: "1ms"},
+		},
+		{
+			desc:    "method not allowed",
+			latency: 20 * time.Millisecond,
+			method:  http.MethodGet,
+			cfg:     &config.Hedging{Delay: "1ms", Methods: []string{"options"}},
+		},
+		{
+			desc:    "request with a body",
+			latency: 20 * time.Millisecond,
+			method:  http.MethodGet,
+			body:    "foo",
+			cfg:     &config.Hedging{Delay: "1ms"},
+		},
+		{
+			desc:    "upgrade request",
+			latency: 20 * time.Millisecond,
+			method:  http.MethodGet,
+			header:  http.Header{"Upgrade": []string{"websocket"}},
+			cfg:     &config.Hedging{Delay: "1ms"},
+		},
+		{
+			desc:    "percentile not known yet",
+			latency: 20 * time.Millisecond,
+			method:  http.MethodGet,
+			cfg:     &config.Hedging{},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var calls int32
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				atomic.AddInt32(&calls, 1)
+				time.Sleep(test.latency)
+				rw.WriteHeader(http.StatusNoContent)
+			})
+
+			handler, hedges, _ := newHandler(t, next, test.cfg)
+
+			req := httptest.NewRequest(test.method, "http://foo", strings.NewReader(test.body))
+			for k, v := range test.header {
+				req.Header[k] = v
+			}
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			assert.Equal(t, http.StatusNoContent, recorder.Code)
+			assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+			assert.Equal(t, float64(0), hedges.Value())
+		})
+	}
+}
+
+func TestHandler_maxHedges(t *testing.T) {
+	var calls int32
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		<-req.Context().Done()
+		rw.WriteHeader(http.StatusGatewayTimeout)
+	})
+
+	handler, hedges, _ := newHandler(t, next, &config.Hedging{Delay: "10ms", MaxHedges: 2})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://foo", nil).WithContext(ctx))
+
+	// None of the requests respond until the request is canceled, and only two hedged requests are sent.
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+	assert.Equal(t, float64(2), hedges.Value())
+}
+
+func TestHandler_accessLog(t *testing.T) {
+	slow := &slowFirstHandler{canceled: make(chan struct{})}
+	fields := accesslog.NewFieldHandler(slow, accesslog.ServiceURL, "", func(rw http.ResponseWriter, req *http.Request, next http.Handler, data *accesslog.LogData) {
+		data.Core[accesslog.ServiceURL] = "server " + strconv.Itoa(int(atomic.LoadInt32(&slow.calls)+1))
+		next.ServeHTTP(rw, req)
+	})
+
+	handler, _, _ := newHandler(t, fields, &config.Hedging{Delay: "10ms"})
+
+	data := &accesslog.LogData{Core: accesslog.CoreLogData{accesslog.RouterName: "router"}}
+	req := httptest.NewRequest(http.MethodGet, "http://foo", nil)
+	req = req.WithContext(context.WithValue(req.Context(), accesslog.DataTableKey, data))
+
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Equal(t, "router", data.Core[accesslog.RouterName])
+	assert.Equal(t, "server 2", data.Core[accesslog.ServiceURL])
+}
+
+func TestHandler_panic(t *testing.T) {
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+		panic(http.ErrAbortHandler)
+	})
+
+	handler, _, _ := newHandler(t, next, &config.Hedging{Delay: "1s"})
+
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://foo", nil))
+	})
+}
+
+func TestLatencies_percentile(t *testing.T) {
+	l := &latencies{}
+
+	for i := 1; i < latencyRefresh; i++ {
+		l.observe(time.Duration(i) * time.Millisecond)
+	}
+	assert.Equal(t, time.Duration(0), l.percentile())
+
+	l.observe(latencyRefresh * time.Millisecond)
+	assert.Equal(t, 95*time.Millisecond, l.percentile())
+
+	// The oldest latencies are replaced once the window is full.
+	for i := 0; i < latencyWindow; i++ {
+		l.observe(time.Second)
+	}
+	assert.Equal(t, time.Second, l.percentile())
+}
+
+func TestNew_invalid(t *testing.T) {
+	testCases := []struct {
+		desc string
+		cfg  *config.Hedging
+	}{
+		{
+			desc: "invalid delay",
+			cfg:  &config.Hedging{Delay: "foo"},
+		},
+		{
+			desc: "negative delay",
+			cfg:  &config.Hedging{Delay: "-1s"},
+		},
+		{
+			desc: "negative max hedges",
+			cfg:  &config.Hedging{MaxHedges: -1},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := New(http.NotFoundHandler(), test.cfg, generic.NewCounter("hedges"), generic.NewCounter("won"))
+			assert.Error(t, err)
+		})
+	}
+}
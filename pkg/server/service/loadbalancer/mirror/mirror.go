@@ -0,0 +1,205 @@
+// Package mirror implements a handler forwarding the requests to a main handler,
+// and sending a copy of a percentage of them to mirror handlers.
+package mirror
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/containous/traefik/pkg/log"
+	"github.com/containous/traefik/pkg/middlewares"
+	"github.com/containous/traefik/pkg/middlewares/accesslog"
+	"github.com/containous/traefik/pkg/safe"
+)
+
+type mirrorHandler struct {
+	http.Handler
+	name    string
+	percent int
+
+	lock  sync.Mutex
+	count uint64
+}
+
+// Mirroring is an http.Handler that forwards the requests to a main handler,
+// and sends a copy of a percentage of them to mirror handlers.
+// The responses of the mirrors are discarded, and the mirrored requests are sent
+// once the main handler has served the request, so they never delay the response.
+type Mirroring struct {
+	handler        http.Handler
+	mirrorHandlers []*mirrorHandler
+	maxBodySize    int64
+	timeout        time.Duration
+
+	// inFlight bounds the number of mirrored requests being served.
+	inFlight chan struct{}
+
+	lock  sync.RWMutex
+	total uint64
+}
+
+// New creates a new Mirroring.
+// The bodies of the requests larger than maxBodySize are not buffered, and these requests are not mirrored.
+// At most maxInFlight mirrored requests are served at once, the others are dropped,
+// and each mirrored request is canceled after timeout.
+func New(handler http.Handler, maxBodySize int64, maxInFlight int, timeout time.Duration) *Mirroring {
+	return &Mirroring{
+		handler:     handler,
+		maxBodySize: maxBodySize,
+		timeout:     timeout,
+		inFlight:    make(chan struct{}, maxInFlight),
+	}
+}
+
+// AddMirror adds a mirror receiving the given percentage of the requests.
+func (m *Mirroring) AddMirror(name string, handler http.Handler, percent int) error {
+	if percent < 0 || percent > 100 {
+		return errors.New("percentage must be between 0 and 100")
+	}
+
+	m.mirrorHandlers = append(m.mirrorHandlers, &mirrorHandler{Handler: handler, name: name, percent: percent})
+	return nil
+}
+
+func (m *Mirroring) getActiveMirrors() []*mirrorHandler {
+	m.lock.Lock()
+	m.total++
+	total := m.total
+	m.lock.Unlock()
+
+	var mirrors []*mirrorHandler
+	for _, handler := range m.mirrorHandlers {
+		handler.lock.Lock()
+		if handler.count*100 < total*uint64(handler.percent) {
+			handler.count++
+			mirrors = append(mirrors, handler)
+		}
+		handler.lock.Unlock()
+	}
+	return mirrors
+}
+
+// uncount forgets the requests counted by the given mirrors, which were not sent to them after all,
+// so that they get another request instead.
+func uncount(mirrors []*mirrorHandler) {
+	for _, handler := range mirrors {
+		handler.lock.Lock()
+		handler.count--
+		handler.lock.Unlock()
+	}
+}
+
+func (m *Mirroring) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	mirrors := m.getActiveMirrors()
+	if len(mirrors) == 0 {
+		m.handler.ServeHTTP(rw, req)
+		return
+	}
+
+	logger := log.FromContext(req.Context())
+
+	body, err := middlewares.ReadBody(req, m.maxBodySize)
+	if err == middlewares.ErrBodyTooLarge {
+		logger.Debug("No mirroring: the request body is larger than the allowed size")
+		uncount(mirrors)
+		m.handler.ServeHTTP(rw, req)
+		return
+	}
+	if err != nil {
+		logger.Errorf("Error while reading the request body: %v", err)
+		http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	m.handler.ServeHTTP(rw, cloneRequest(req.Context(), req, body))
+
+	select {
+	case <-req.Context().Done():
+		// No mirroring if the request has been canceled while being served by the main handler.
+		return
+	default:
+	}
+
+	// The mirrored requests must not be canceled when the main one is done,
+	// and must not contribute to the access log of the main request.
+	ctx := context.WithValue(contextStopPropagation{req.Context()}, accesslog.DataTableKey, nil)
+
+	for _, handler := range mirrors {
+		select {
+		case m.inFlight <- struct{}{}:
+		default:
+			logger.Debug("Mirrored request dropped: too many mirrored requests in flight")
+			continue
+		}
+
+		handler := handler
+		safe.Go(func() {
+			defer func() { <-m.inFlight }()
+
+			mirrorCtx, cancel := context.WithTimeout(ctx, m.timeout)
+			defer cancel()
+
+			handler.ServeHTTP(blackHoleResponseWriter{}, cloneRequest(mirrorCtx, req, body))
+		})
+	}
+}
+
+func cloneRequest(ctx context.Context, req *http.Request, body []byte) *http.Request {
+	clone := req.WithContext(ctx)
+	clone.Header = cloneHeader(req.Header)
+
+	if body == nil {
+		clone.Body = http.NoBody
+		return clone
+	}
+
+	clone.Body = ioutil.NopCloser(bytes.NewReader(body))
+	clone.ContentLength = int64(len(body))
+	return clone
+}
+
+func cloneHeader(header http.Header) http.Header {
+	clone := make(http.Header, len(header))
+	for k, v := range header {
+		clone[k] = append([]string(nil), v...)
+	}
+	return clone
+}
+
+// blackHoleResponseWriter discards the responses of the mirrors.
+type blackHoleResponseWriter struct{}
+
+func (b blackHoleResponseWriter) Flush() {}
+
+func (b blackHoleResponseWriter) Header() http.Header {
+	return http.Header{}
+}
+
+func (b blackHoleResponseWriter) Write(p []byte) (int, error) {
+	return len(p), nil
+}
+
+func (b blackHoleResponseWriter) WriteHeader(statusCode int) {}
+
+// contextStopPropagation keeps the values of the parent context,
+// but is never canceled nor has a deadline.
+type contextStopPropagation struct {
+	context.Context
+}
+
+func (c contextStopPropagation) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (c contextStopPropagation) Done() <-chan struct{} {
+	return nil
+}
+
+func (c contextStopPropagation) Err() error {
+	return nil
+}
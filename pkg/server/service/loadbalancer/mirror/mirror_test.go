@@ -0,0 +1,218 @@
+package mirror
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMirroringOn100(t *testing.T) {
+	var countMirror1, countMirror2 int32
+	var wg sync.WaitGroup
+
+	handler := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	})
+
+	mirror := New(handler, 1024, 100, time.Second)
+	err := mirror.AddMirror("mirror1", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&countMirror1, 1)
+		wg.Done()
+	}), 10)
+	require.NoError(t, err)
+
+	err = mirror.AddMirror("mirror2", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&countMirror2, 1)
+		wg.Done()
+	}), 50)
+	require.NoError(t, err)
+
+	wg.Add(60)
+	for i := 0; i < 100; i++ {
+		mirror.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(10), atomic.LoadInt32(&countMirror1))
+	assert.Equal(t, int32(50), atomic.LoadInt32(&countMirror2))
+}
+
+func TestMirroringBody(t *testing.T) {
+	bodies := make(chan string, 1)
+
+	handler := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		body, err := ioutil.ReadAll(req.Body)
+		require.NoError(t, err)
+		assert.Equal(t, "foobar", string(body))
+		rw.WriteHeader(http.StatusOK)
+	})
+
+	mirror := New(handler, 1024, 10, time.Second)
+	err := mirror.AddMirror("mirror", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		body, err := ioutil.ReadAll(req.Body)
+		require.NoError(t, err)
+		bodies <- string(body)
+	}), 100)
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	mirror.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString("foobar")))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	select {
+	case body := <-bodies:
+		assert.Equal(t, "foobar", body)
+	case <-time.After(time.Second):
+		t.Fatal("the request has not been mirrored")
+	}
+}
+
+func TestMirroringBodyTooLarge(t *testing.T) {
+	var countMirror int32
+
+	handler := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		body, err := ioutil.ReadAll(req.Body)
+		require.NoError(t, err)
+		assert.Equal(t, "foobar", string(body))
+		rw.WriteHeader(http.StatusOK)
+	})
+
+	mirror := New(handler, 3, 10, time.Second)
+	err := mirror.AddMirror("mirror", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&countMirror, 1)
+	}), 100)
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	mirror.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString("foobar")))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&countMirror))
+}
+
+func TestMirroringBodyTooLarge_notCounted(t *testing.T) {
+	mirrored := make(chan string, 2)
+
+	handler := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	})
+
+	mirror := New(handler, 3, 10, time.Second)
+	err := mirror.AddMirror("mirror", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		body, err := ioutil.ReadAll(req.Body)
+		require.NoError(t, err)
+		mirrored <- string(body)
+	}), 50)
+	require.NoError(t, err)
+
+	// The request with a too large body is not mirrored, so the next one is.
+	mirror.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString("foobar")))
+	mirror.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString("foo")))
+
+	select {
+	case body := <-mirrored:
+		assert.Equal(t, "foo", body)
+	case <-time.After(time.Second):
+		t.Fatal("the request has not been mirrored")
+	}
+}
+
+func TestMirroringSlowMirror(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	handler := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	})
+
+	mirror := New(handler, 1024, 10, time.Second)
+	err := mirror.AddMirror("mirror", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		<-release
+	}), 100)
+	require.NoError(t, err)
+
+	done := make(chan struct{})
+	go func() {
+		mirror.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("the main response has been delayed by the mirror")
+	}
+}
+
+func TestMirroringMaxInFlight(t *testing.T) {
+	release := make(chan struct{})
+	var countMirror int32
+
+	handler := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	})
+
+	mirror := New(handler, 1024, 2, time.Second)
+	err := mirror.AddMirror("mirror", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&countMirror, 1)
+		<-release
+	}), 100)
+	require.NoError(t, err)
+
+	for i := 0; i < 5; i++ {
+		mirror.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&countMirror))
+
+	// The mirrored requests are served again once the in flight ones are done.
+	close(release)
+	time.Sleep(50 * time.Millisecond)
+
+	mirror.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&countMirror))
+}
+
+func TestMirroringTimeout(t *testing.T) {
+	handler := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	})
+
+	canceled := make(chan error, 1)
+
+	mirror := New(handler, 1024, 10, 10*time.Millisecond)
+	err := mirror.AddMirror("mirror", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		<-req.Context().Done()
+		canceled <- req.Context().Err()
+	}), 100)
+	require.NoError(t, err)
+
+	mirror.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	select {
+	case err := <-canceled:
+		assert.Equal(t, context.DeadlineExceeded, err)
+	case <-time.After(time.Second):
+		t.Fatal("the mirrored request has not been canceled")
+	}
+}
+
+func TestMirroringInvalidPercent(t *testing.T) {
+	mirror := New(http.NotFoundHandler(), 1024, 10, time.Second)
+
+	assert.Error(t, mirror.AddMirror("mirror", http.NotFoundHandler(), 101))
+	assert.Error(t, mirror.AddMirror("mirror", http.NotFoundHandler(), -1))
+}
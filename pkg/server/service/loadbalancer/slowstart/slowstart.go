@@ -0,0 +1,239 @@
+// Package slowstart implements a load balancer wrapper,
+// which ramps up the share of the requests sent to the servers added to a load balancer.
+package slowstart
+
+import (
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/containous/traefik/pkg/config"
+	"github.com/containous/traefik/pkg/healthcheck"
+	"github.com/vulcand/oxy/roundrobin"
+	"github.com/vulcand/oxy/utils"
+)
+
+// weightScale is the factor applied to the weights of the servers in the wrapped load balancer,
+// so that the weight of a ramping server can be a fraction of its configured weight.
+const weightScale = 10
+
+// StartTimes keeps, for each service, the times at which its servers started to ramp up.
+// It outlives the balancers, which are rebuilt with each new configuration,
+// so that only the servers which were not already part of the service ramp up.
+type StartTimes struct {
+	mu       sync.Mutex
+	services map[string]map[string]time.Time
+}
+
+// NewStartTimes creates a new StartTimes.
+func NewStartTimes() *StartTimes {
+	return &StartTimes{services: make(map[string]map[string]time.Time)}
+}
+
+// Prune forgets the start times of the servers of the services which are not part of the given configurations.
+func (s *StartTimes) Prune(configs map[string]*config.ServiceInfo) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for serviceName := range s.services {
+		if _, ok := configs[serviceName]; !ok {
+			delete(s.services, serviceName)
+		}
+	}
+}
+
+type server struct {
+	url    *url.URL
+	weight int
+	start  time.Time
+	// applied is the weight of the server in the wrapped load balancer.
+	applied int
+}
+
+// Balancer wraps a load balancer, so that the effective weight of a new server,
+// or of a server put back by the health checks, ramps up linearly from a fraction of its weight to its full weight,
+// over the slow start duration.
+type Balancer struct {
+	healthcheck.BalancerHandler
+	serviceName string
+	startTimes  *StartTimes
+	duration    time.Duration
+	now         func() time.Time
+
+	mu      sync.Mutex
+	servers map[string]*server
+	ramping int
+}
+
+// New creates a new Balancer, keeping the start times of the servers in startTimes.
+func New(serviceName string, lb healthcheck.BalancerHandler, duration time.Duration, startTimes *StartTimes) *Balancer {
+	return &Balancer{
+		BalancerHandler: lb,
+		serviceName:     serviceName,
+		startTimes:      startTimes,
+		duration:        duration,
+		now:             time.Now,
+		servers:         make(map[string]*server),
+	}
+}
+
+func (b *Balancer) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	b.refresh()
+	b.BalancerHandler.ServeHTTP(rw, req)
+}
+
+// UpsertServer adds the given server to the wrapped load balancer, or updates it.
+// A server added to the Balancer starts ramping up, unless it was already part of the service in the previous configuration.
+func (b *Balancer) UpsertServer(u *url.URL, options ...roundrobin.ServerOption) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	key := u.String()
+
+	weight := 1
+	srv, ok := b.servers[key]
+	if ok {
+		weight = srv.weight
+	}
+
+	weight, err := healthcheck.ApplyServerOptions(u, weight, options)
+	if err != nil {
+		return err
+	}
+
+	now := b.now()
+	if !ok {
+		srv = &server{url: utils.CopyURL(u), start: b.startTime(key, now)}
+		if b.isRamping(srv, now) {
+			b.ramping++
+		}
+		b.servers[key] = srv
+	}
+	srv.weight = weight
+
+	return b.apply(srv, now)
+}
+
+// RemoveServer removes the given server from the wrapped load balancer.
+// The server ramps up again once put back.
+func (b *Balancer) RemoveServer(u *url.URL) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err := b.BalancerHandler.RemoveServer(u); err != nil {
+		return err
+	}
+
+	key := u.String()
+	if srv, ok := b.servers[key]; ok {
+		if b.isRamping(srv, b.now()) {
+			b.ramping--
+		}
+		delete(b.servers, key)
+	}
+
+	b.startTimes.mu.Lock()
+	delete(b.startTimes.services[b.serviceName], key)
+	b.startTimes.mu.Unlock()
+
+	return nil
+}
+
+// ServerWeight returns the configured weight of the given server, and whether the server is part of the balancer.
+func (b *Balancer) ServerWeight(u *url.URL) (int, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	srv, ok := b.servers[u.String()]
+	if !ok {
+		return 0, false
+	}
+	return srv.weight, true
+}
+
+// Prune forgets the start times of the servers of the service which are not part of the Balancer,
+// so that they ramp up if they are added back in a later configuration.
+func (b *Balancer) Prune() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.startTimes.mu.Lock()
+	defer b.startTimes.mu.Unlock()
+
+	for key := range b.startTimes.services[b.serviceName] {
+		if _, ok := b.servers[key]; !ok {
+			delete(b.startTimes.services[b.serviceName], key)
+		}
+	}
+}
+
+// refresh updates the weights of the ramping servers in the wrapped load balancer.
+func (b *Balancer) refresh() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.ramping == 0 {
+		return
+	}
+
+	now := b.now()
+	b.ramping = 0
+	for _, srv := range b.servers {
+		_ = b.apply(srv, now)
+		if b.isRamping(srv, now) {
+			b.ramping++
+		}
+	}
+}
+
+// apply sets the weight of the server in the wrapped load balancer, if it changed.
+func (b *Balancer) apply(srv *server, now time.Time) error {
+	weight := b.effectiveWeight(srv, now)
+	if weight == srv.applied {
+		return nil
+	}
+
+	if err := b.BalancerHandler.UpsertServer(srv.url, roundrobin.Weight(weight)); err != nil {
+		return err
+	}
+	srv.applied = weight
+	return nil
+}
+
+func (b *Balancer) effectiveWeight(srv *server, now time.Time) int {
+	full := srv.weight * weightScale
+	if !b.isRamping(srv, now) || full == 0 {
+		return full
+	}
+
+	weight := int(int64(full) * int64(now.Sub(srv.start)) / int64(b.duration))
+	if weight < 1 {
+		return 1
+	}
+	return weight
+}
+
+func (b *Balancer) isRamping(srv *server, now time.Time) bool {
+	return now.Sub(srv.start) < b.duration
+}
+
+// startTime returns the time at which the server of the service started to ramp up,
+// which is now if the server is new to the service.
+func (b *Balancer) startTime(key string, now time.Time) time.Time {
+	b.startTimes.mu.Lock()
+	defer b.startTimes.mu.Unlock()
+
+	servers, ok := b.startTimes.services[b.serviceName]
+	if !ok {
+		servers = make(map[string]time.Time)
+		b.startTimes.services[b.serviceName] = servers
+	}
+
+	start, ok := servers[key]
+	if !ok {
+		start = now
+		servers[key] = start
+	}
+	return start
+}
@@ -0,0 +1,178 @@
+package slowstart
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/pkg/config"
+	"github.com/containous/traefik/pkg/testhelpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vulcand/oxy/roundrobin"
+)
+
+func serve(balancer *Balancer) {
+	balancer.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://foo", nil))
+}
+
+func weight(t *testing.T, next *roundrobin.RoundRobin, u *url.URL) int {
+	t.Helper()
+
+	w, ok := next.ServerWeight(u)
+	require.True(t, ok)
+	return w
+}
+
+func TestBalancer_ramp(t *testing.T) {
+	testCases := []struct {
+		desc            string
+		weight          int
+		elapsed         []time.Duration
+		expectedWeights []int
+	}{
+		{
+			desc:            "weight of one",
+			weight:          1,
+			elapsed:         []time.Duration{0, 5 * time.Second, 10 * time.Second},
+			expectedWeights: []int{1, 5, 10},
+		},
+		{
+			desc:            "weight of two",
+			weight:          2,
+			elapsed:         []time.Duration{0, 2500 * time.Millisecond, 7500 * time.Millisecond, 12500 * time.Millisecond},
+			expectedWeights: []int{1, 5, 15, 20},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			start := time.Now()
+			now := start
+
+			next, err := roundrobin.New(http.NotFoundHandler())
+			require.NoError(t, err)
+
+			balancer := New("test", next, 10*time.Second, NewStartTimes())
+			balancer.now = func() time.Time { return now }
+
+			u := testhelpers.MustParseURL("http://a")
+			require.NoError(t, balancer.UpsertServer(u, roundrobin.Weight(test.weight)))
+
+			for i, elapsed := range test.elapsed {
+				now = start.Add(elapsed)
+				serve(balancer)
+				assert.Equal(t, test.expectedWeights[i], weight(t, next, u), "after %s", elapsed)
+			}
+
+			// The configured weight is reported to the health checks.
+			w, ok := balancer.ServerWeight(u)
+			assert.True(t, ok)
+			assert.Equal(t, test.weight, w)
+		})
+	}
+}
+
+func TestBalancer_removeAndRestore(t *testing.T) {
+	now := time.Now()
+
+	next, err := roundrobin.New(http.NotFoundHandler())
+	require.NoError(t, err)
+
+	balancer := New("test", next, 10*time.Second, NewStartTimes())
+	balancer.now = func() time.Time { return now }
+
+	a := testhelpers.MustParseURL("http://a")
+	b := testhelpers.MustParseURL("http://b")
+	require.NoError(t, balancer.UpsertServer(a, roundrobin.Weight(1)))
+	require.NoError(t, balancer.UpsertServer(b, roundrobin.Weight(1)))
+
+	now = now.Add(time.Minute)
+	serve(balancer)
+	assert.Equal(t, 10, weight(t, next, a))
+	assert.Equal(t, 10, weight(t, next, b))
+
+	require.NoError(t, balancer.RemoveServer(b))
+	assert.Len(t, next.Servers(), 1)
+
+	// The server put back by the health checks ramps up again.
+	require.NoError(t, balancer.UpsertServer(b, roundrobin.Weight(1)))
+	assert.Equal(t, 10, weight(t, next, a))
+	assert.Equal(t, 1, weight(t, next, b))
+
+	now = now.Add(5 * time.Second)
+	serve(balancer)
+	assert.Equal(t, 5, weight(t, next, b))
+}
+
+func TestBalancer_newConfiguration(t *testing.T) {
+	start := time.Now()
+	startTimes := NewStartTimes()
+
+	a := testhelpers.MustParseURL("http://a")
+	b := testhelpers.MustParseURL("http://b")
+	d := testhelpers.MustParseURL("http://d")
+
+	// Each configuration builds a new balancer, sharing the start times of the servers.
+	configurations := []struct {
+		elapsed         time.Duration
+		servers         []*url.URL
+		expectedWeights []int
+	}{
+		{
+			servers:         []*url.URL{a, b},
+			expectedWeights: []int{1, 1},
+		},
+		{
+			// Only the servers which were not part of the previous configuration ramp up.
+			elapsed:         time.Minute,
+			servers:         []*url.URL{a, d},
+			expectedWeights: []int{10, 1},
+		},
+		{
+			// The servers removed from the configuration ramp up once added back.
+			elapsed:         time.Minute,
+			servers:         []*url.URL{b},
+			expectedWeights: []int{1},
+		},
+	}
+
+	for _, configuration := range configurations {
+		next, err := roundrobin.New(http.NotFoundHandler())
+		require.NoError(t, err)
+
+		balancer := New("test", next, 10*time.Second, startTimes)
+		balancer.now = func() time.Time { return start.Add(configuration.elapsed) }
+
+		for _, u := range configuration.servers {
+			require.NoError(t, balancer.UpsertServer(u, roundrobin.Weight(1)))
+		}
+		balancer.Prune()
+
+		for i, u := range configuration.servers {
+			assert.Equal(t, configuration.expectedWeights[i], weight(t, next, u), u.String())
+		}
+	}
+}
+
+func TestStartTimes_Prune(t *testing.T) {
+	startTimes := NewStartTimes()
+
+	for _, serviceName := range []string{"foo", "bar"} {
+		next, err := roundrobin.New(http.NotFoundHandler())
+		require.NoError(t, err)
+
+		balancer := New(serviceName, next, 10*time.Second, startTimes)
+		require.NoError(t, balancer.UpsertServer(testhelpers.MustParseURL("http://a"), roundrobin.Weight(1)))
+	}
+
+	startTimes.Prune(map[string]*config.ServiceInfo{"foo": {}})
+
+	assert.Contains(t, startTimes.services, "foo")
+	assert.NotContains(t, startTimes.services, "bar")
+}
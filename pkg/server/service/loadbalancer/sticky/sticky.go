@@ -0,0 +1,291 @@
+// Package sticky implements a load balancer wrapper,
+// which pins the requests of a client, or of a session, to a server.
+package sticky
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"net/http"
+	"net/url"
+	"sync"
+
+	"github.com/containous/traefik/pkg/config"
+	"github.com/containous/traefik/pkg/healthcheck"
+	"github.com/containous/traefik/pkg/server/cookie"
+	"github.com/vulcand/oxy/roundrobin"
+	"github.com/vulcand/oxy/utils"
+)
+
+type server struct {
+	url    *url.URL
+	weight int
+	// value identifies the server in the sticky cookie.
+	value string
+}
+
+// Balancer wraps a load balancer, so that the requests are pinned to a server.
+//
+// By default, the server picked by the wrapped load balancer for the first request of a client
+// is stored in a cookie, as an opaque hash of its URL.
+// With a session key, the requests sharing the same key (from a header or from an existing cookie of the application)
+// are pinned to the same server, picked by rendezvous hashing among the servers of the service, according to their weight.
+//
+// When the pinned server is unavailable, the request is either rebalanced to another server, or rejected.
+type Balancer struct {
+	healthcheck.BalancerHandler
+	next http.Handler
+
+	cookieName     string
+	secureCookie   bool
+	httpOnlyCookie bool
+	secret         string
+	sessionKey     func(req *http.Request) string
+	reject         bool
+
+	mu sync.RWMutex
+	// servers are all the servers ever added to the Balancer, including those currently removed by the health checks.
+	servers map[string]*server
+	values  map[string]*server
+}
+
+// New creates a new Balancer forwarding the pinned requests to next.
+// The wrapped load balancer, set with SetBalancer, must forward the requests to the Forwarder of the Balancer.
+func New(serviceName string, cfg *config.Stickiness, next http.Handler) (*Balancer, error) {
+	b := &Balancer{
+		next:           next,
+		cookieName:     cookie.GetName(cfg.CookieName, serviceName),
+		secureCookie:   cfg.SecureCookie,
+		httpOnlyCookie: cfg.HTTPOnlyCookie,
+		secret:         cfg.Secret,
+		servers:        make(map[string]*server),
+		values:         make(map[string]*server),
+	}
+
+	switch {
+	case cfg.SessionHeader != "" && cfg.SessionCookie != "":
+		return nil, errors.New("only one of sessionHeader and sessionCookie can be set")
+	case cfg.SessionHeader != "":
+		b.sessionKey = headerKey(cfg.SessionHeader)
+	case cfg.SessionCookie != "":
+		b.sessionKey = cookieKey(cfg.SessionCookie)
+	}
+
+	switch cfg.Fallback {
+	case "", config.StickinessFallbackRebalance:
+	case config.StickinessFallbackReject:
+		b.reject = true
+	default:
+		return nil, fmt.Errorf("unknown stickiness fallback %q", cfg.Fallback)
+	}
+
+	return b, nil
+}
+
+// CookieName returns the name of the sticky cookie.
+func (b *Balancer) CookieName() string {
+	return b.cookieName
+}
+
+// SetBalancer sets the wrapped load balancer.
+func (b *Balancer) SetBalancer(lb healthcheck.BalancerHandler) {
+	b.BalancerHandler = lb
+}
+
+// Forwarder returns the handler the wrapped load balancer forwards the requests to,
+// which pins the client to the server picked by the load balancer.
+func (b *Balancer) Forwarder() http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if b.sessionKey == nil {
+			b.stick(rw, req.URL)
+		}
+		b.next.ServeHTTP(rw, req)
+	})
+}
+
+func (b *Balancer) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	srv, key := b.pinnedServer(req)
+	if srv == nil {
+		b.BalancerHandler.ServeHTTP(rw, req)
+		return
+	}
+
+	available := b.BalancerHandler.Servers()
+	if isAvailable(srv, available) {
+		b.serve(rw, req, srv)
+		return
+	}
+
+	if b.reject {
+		http.Error(rw, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+		return
+	}
+
+	if key != "" {
+		if srv := b.rebalance(key, available); srv != nil {
+			b.serve(rw, req, srv)
+			return
+		}
+	}
+
+	b.BalancerHandler.ServeHTTP(rw, req)
+}
+
+// UpsertServer adds the given server to the wrapped load balancer, or updates it.
+func (b *Balancer) UpsertServer(u *url.URL, options ...roundrobin.ServerOption) error {
+	if err := b.BalancerHandler.UpsertServer(u, options...); err != nil {
+		return err
+	}
+
+	weight, _ := b.BalancerHandler.ServerWeight(u)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	key := u.String()
+	if srv, ok := b.servers[key]; ok {
+		srv.weight = weight
+		return nil
+	}
+
+	srv := &server{url: utils.CopyURL(u), weight: weight, value: cookie.HashValue(key, b.secret)}
+	b.servers[key] = srv
+	b.values[srv.value] = srv
+	return nil
+}
+
+// pinnedServer returns the server the request is pinned to, if any,
+// and the session key of the request when a session key is used.
+func (b *Balancer) pinnedServer(req *http.Request) (*server, string) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if b.sessionKey != nil {
+		key := b.sessionKey(req)
+		if key == "" {
+			return nil, ""
+		}
+
+		servers := make([]*server, 0, len(b.servers))
+		for _, srv := range b.servers {
+			servers = append(servers, srv)
+		}
+		return rendezvous(key, servers), key
+	}
+
+	c, err := req.Cookie(b.cookieName)
+	if err != nil {
+		return nil, ""
+	}
+
+	if srv, ok := b.values[c.Value]; ok {
+		return srv, ""
+	}
+
+	// The cookies set by former versions hold the URL of the server.
+	return b.servers[c.Value], ""
+}
+
+// serve forwards the request to the given server.
+func (b *Balancer) serve(rw http.ResponseWriter, req *http.Request, srv *server) {
+	if b.sessionKey == nil {
+		if c, err := req.Cookie(b.cookieName); err == nil && c.Value != srv.value {
+			b.stick(rw, srv.url)
+		}
+	}
+
+	// make a shallow copy of the request before changing its URL, to avoid side effects.
+	newReq := *req
+	newReq.URL = utils.CopyURL(srv.url)
+
+	b.next.ServeHTTP(rw, &newReq)
+}
+
+// stick sets the sticky cookie, pinning the client to the given server.
+func (b *Balancer) stick(rw http.ResponseWriter, u *url.URL) {
+	http.SetCookie(rw, &http.Cookie{
+		Name:     b.cookieName,
+		Value:    cookie.HashValue(u.String(), b.secret),
+		Path:     "/",
+		HttpOnly: b.httpOnlyCookie,
+		Secure:   b.secureCookie,
+	})
+}
+
+// rebalance returns the server the session key is pinned to, among the given available servers.
+func (b *Balancer) rebalance(key string, urls []*url.URL) *server {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	var servers []*server
+	for _, u := range urls {
+		if srv, ok := b.servers[u.String()]; ok {
+			servers = append(servers, srv)
+		}
+	}
+	return rendezvous(key, servers)
+}
+
+// rendezvous returns the server with the highest weighted score for the key among the given servers,
+// so that only the keys of a removed server move to other servers.
+func rendezvous(key string, servers []*server) *server {
+	var selected *server
+	var selectedScore float64
+	for _, srv := range servers {
+		if srv.weight <= 0 {
+			continue
+		}
+
+		score := float64(srv.weight) / -math.Log(hashUnit(key, srv.url.String()))
+		if selected == nil || score > selectedScore {
+			selected = srv
+			selectedScore = score
+		}
+	}
+
+	return selected
+}
+
+// hashUnit hashes the key and the server to a number in the open interval (0, 1).
+func hashUnit(key, server string) float64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(key))
+	_, _ = h.Write([]byte{0})
+	_, _ = h.Write([]byte(server))
+
+	// Finalizer of SplitMix64, as the high bits of FNV are poorly mixed for similar inputs.
+	x := h.Sum64()
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+
+	return (float64(x>>11) + 0.5) / (1 << 53)
+}
+
+func isAvailable(srv *server, servers []*url.URL) bool {
+	for _, u := range servers {
+		if u.String() == srv.url.String() {
+			return true
+		}
+	}
+	return false
+}
+
+func headerKey(name string) func(req *http.Request) string {
+	return func(req *http.Request) string {
+		return req.Header.Get(name)
+	}
+}
+
+func cookieKey(name string) func(req *http.Request) string {
+	return func(req *http.Request) string {
+		c, err := req.Cookie(name)
+		if err != nil {
+			return ""
+		}
+		return c.Value
+	}
+}
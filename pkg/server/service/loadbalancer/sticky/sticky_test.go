@@ -0,0 +1,316 @@
+package sticky
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/containous/traefik/pkg/config"
+	"github.com/containous/traefik/pkg/server/cookie"
+	"github.com/containous/traefik/pkg/testhelpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vulcand/oxy/roundrobin"
+)
+
+// serverHandler answers with the host of the server the request is forwarded to.
+var serverHandler = http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+	rw.Header().Set("server", req.URL.Host)
+})
+
+func serve(balancer *Balancer, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+
+	recorder := httptest.NewRecorder()
+	balancer.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func stickyCookie(t *testing.T, recorder *httptest.ResponseRecorder) *http.Cookie {
+	t.Helper()
+
+	cookies := recorder.Result().Cookies()
+	require.Len(t, cookies, 1)
+	return cookies[0]
+}
+
+func TestBalancer_cookie(t *testing.T) {
+	testCases := []struct {
+		desc           string
+		cfg            *config.Stickiness
+		cookie         string
+		expectedServer string
+	}{
+		{
+			desc: "new client",
+			cfg:  &config.Stickiness{CookieName: "sticky", HTTPOnlyCookie: true},
+		},
+		{
+			desc:           "cookie holding the URL, set by a former version",
+			cfg:            &config.Stickiness{CookieName: "sticky"},
+			cookie:         "http://10.0.0.2",
+			expectedServer: "10.0.0.2",
+		},
+		{
+			desc:   "cookie hashed with another secret",
+			cfg:    &config.Stickiness{CookieName: "sticky", Secret: "secret"},
+			cookie: cookie.HashValue("http://10.0.0.2", "other"),
+			// The cookie is not honored, and the request is balanced.
+			expectedServer: "10.0.0.1",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			balancer, err := New("test", test.cfg, serverHandler)
+			require.NoError(t, err)
+
+			lb, err := roundrobin.New(balancer.Forwarder())
+			require.NoError(t, err)
+			balancer.SetBalancer(lb)
+
+			for _, server := range []string{"10.0.0.1", "10.0.0.2"} {
+				require.NoError(t, balancer.UpsertServer(testhelpers.MustParseURL("http://"+server)))
+			}
+
+			var header http.Header
+			if test.cookie != "" {
+				header = http.Header{"Cookie": {"sticky=" + test.cookie}}
+			}
+
+			recorder := serve(balancer, header)
+			server := recorder.Header().Get("server")
+			if test.expectedServer != "" {
+				assert.Equal(t, test.expectedServer, server)
+			}
+
+			// The cookie identifies the server with an opaque value.
+			c := stickyCookie(t, recorder)
+			assert.Equal(t, "sticky", c.Name)
+			assert.Equal(t, test.cfg.HTTPOnlyCookie, c.HttpOnly)
+			assert.False(t, c.Secure)
+			assert.Equal(t, cookie.HashValue("http://"+server, test.cfg.Secret), c.Value)
+
+			for i := 0; i < 3; i++ {
+				recorder = serve(balancer, http.Header{"Cookie": {c.String()}})
+				assert.Equal(t, server, recorder.Header().Get("server"))
+				assert.Empty(t, recorder.Result().Cookies())
+			}
+		})
+	}
+}
+
+func TestBalancer_unavailable(t *testing.T) {
+	testCases := []struct {
+		desc           string
+		cfg            *config.Stickiness
+		expectedStatus int
+		expectedServer string
+	}{
+		{
+			desc:           "rebalance by default",
+			cfg:            &config.Stickiness{CookieName: "sticky"},
+			expectedStatus: http.StatusOK,
+			expectedServer: "10.0.0.2",
+		},
+		{
+			desc:           "rebalance",
+			cfg:            &config.Stickiness{CookieName: "sticky", Fallback: config.StickinessFallbackRebalance},
+			expectedStatus: http.StatusOK,
+			expectedServer: "10.0.0.2",
+		},
+		{
+			desc:           "reject",
+			cfg:            &config.Stickiness{CookieName: "sticky", Fallback: config.StickinessFallbackReject},
+			expectedStatus: http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			balancer, err := New("test", test.cfg, serverHandler)
+			require.NoError(t, err)
+
+			lb, err := roundrobin.New(balancer.Forwarder())
+			require.NoError(t, err)
+			balancer.SetBalancer(lb)
+
+			for _, server := range []string{"10.0.0.1", "10.0.0.2"} {
+				require.NoError(t, balancer.UpsertServer(testhelpers.MustParseURL("http://"+server)))
+			}
+
+			recorder := serve(balancer, nil)
+			require.Equal(t, "10.0.0.1", recorder.Header().Get("server"))
+			c := stickyCookie(t, recorder)
+
+			// The pinned server is removed by the health checks.
+			require.NoError(t, balancer.RemoveServer(testhelpers.MustParseURL("http://10.0.0.1")))
+
+			recorder = serve(balancer, http.Header{"Cookie": {c.String()}})
+			assert.Equal(t, test.expectedStatus, recorder.Code)
+			assert.Equal(t, test.expectedServer, recorder.Header().Get("server"))
+
+			// The server is pinned again once put back.
+			require.NoError(t, balancer.UpsertServer(testhelpers.MustParseURL("http://10.0.0.1")))
+
+			recorder = serve(balancer, http.Header{"Cookie": {c.String()}})
+			assert.Equal(t, http.StatusOK, recorder.Code)
+			assert.Equal(t, "10.0.0.1", recorder.Header().Get("server"))
+		})
+	}
+}
+
+func TestBalancer_session(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		cfg    *config.Stickiness
+		header func(session string) http.Header
+	}{
+		{
+			desc: "session header",
+			cfg:  &config.Stickiness{SessionHeader: "X-Session"},
+			header: func(session string) http.Header {
+				return http.Header{"X-Session": {session}}
+			},
+		},
+		{
+			desc: "session cookie",
+			cfg:  &config.Stickiness{SessionCookie: "JSESSIONID"},
+			header: func(session string) http.Header {
+				return http.Header{"Cookie": {"JSESSIONID=" + session}}
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			balancer, err := New("test", test.cfg, serverHandler)
+			require.NoError(t, err)
+
+			lb, err := roundrobin.New(balancer.Forwarder())
+			require.NoError(t, err)
+			balancer.SetBalancer(lb)
+
+			for _, server := range []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"} {
+				require.NoError(t, balancer.UpsertServer(testhelpers.MustParseURL("http://"+server)))
+			}
+
+			pinned := make(map[string]string)
+			counts := make(map[string]int)
+			for i := 0; i < 300; i++ {
+				session := strconv.Itoa(i)
+				recorder := serve(balancer, test.header(session))
+				assert.Empty(t, recorder.Result().Cookies())
+
+				pinned[session] = recorder.Header().Get("server")
+				counts[pinned[session]]++
+			}
+
+			// The sessions are spread between the servers.
+			assert.Len(t, counts, 3)
+			for _, count := range counts {
+				assert.InDelta(t, 100, count, 40)
+			}
+
+			for session, server := range pinned {
+				assert.Equal(t, server, serve(balancer, test.header(session)).Header().Get("server"))
+			}
+
+			// Only the sessions pinned to a removed server move to other servers.
+			require.NoError(t, balancer.RemoveServer(testhelpers.MustParseURL("http://10.0.0.3")))
+
+			for session, server := range pinned {
+				moved := serve(balancer, test.header(session)).Header().Get("server")
+				if server == "10.0.0.3" {
+					assert.NotEqual(t, server, moved)
+				} else {
+					assert.Equal(t, server, moved)
+				}
+			}
+		})
+	}
+}
+
+func TestBalancer_sessionWeight(t *testing.T) {
+	balancer, err := New("test", &config.Stickiness{SessionHeader: "X-Session"}, serverHandler)
+	require.NoError(t, err)
+
+	lb, err := roundrobin.New(balancer.Forwarder())
+	require.NoError(t, err)
+	balancer.SetBalancer(lb)
+
+	require.NoError(t, balancer.UpsertServer(testhelpers.MustParseURL("http://10.0.0.1")))
+	require.NoError(t, balancer.UpsertServer(testhelpers.MustParseURL("http://10.0.0.2"), roundrobin.Weight(3)))
+
+	counts := make(map[string]int)
+	for i := 0; i < 400; i++ {
+		recorder := serve(balancer, http.Header{"X-Session": {strconv.Itoa(i)}})
+		counts[recorder.Header().Get("server")]++
+	}
+
+	assert.InDelta(t, 100, counts["10.0.0.1"], 40)
+	assert.InDelta(t, 300, counts["10.0.0.2"], 40)
+}
+
+func TestBalancer_sessionReject(t *testing.T) {
+	balancer, err := New("test", &config.Stickiness{SessionHeader: "X-Session", Fallback: config.StickinessFallbackReject}, serverHandler)
+	require.NoError(t, err)
+
+	lb, err := roundrobin.New(balancer.Forwarder())
+	require.NoError(t, err)
+	balancer.SetBalancer(lb)
+
+	for _, server := range []string{"10.0.0.1", "10.0.0.2"} {
+		require.NoError(t, balancer.UpsertServer(testhelpers.MustParseURL("http://"+server)))
+	}
+
+	server := serve(balancer, http.Header{"X-Session": {"foo"}}).Header().Get("server")
+	require.NoError(t, balancer.RemoveServer(testhelpers.MustParseURL("http://"+server)))
+
+	recorder := serve(balancer, http.Header{"X-Session": {"foo"}})
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+
+	// The requests without a session are balanced between the available servers.
+	recorder = serve(balancer, nil)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.NotEqual(t, server, recorder.Header().Get("server"))
+}
+
+func TestNew_invalid(t *testing.T) {
+	testCases := []struct {
+		desc string
+		cfg  *config.Stickiness
+	}{
+		{
+			desc: "session header and cookie",
+			cfg:  &config.Stickiness{SessionHeader: "X-Session", SessionCookie: "JSESSIONID"},
+		},
+		{
+			desc: "unknown fallback",
+			cfg:  &config.Stickiness{Fallback: "foo"},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := New("test", test.cfg, http.NotFoundHandler())
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package strategy
+
+import (
+	"errors"
+	"hash/fnv"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/containous/traefik/pkg/ip"
+)
+
+// virtualNodes is the number of points a server with a weight of 1 has on the hash ring.
+// The higher it is, the more evenly the keys are spread between the servers.
+const virtualNodes = 160
+
+// HashKeyFunc returns the key of a request, used to pick a server with consistent hashing.
+type HashKeyFunc func(req *http.Request) string
+
+// HeaderKey returns a HashKeyFunc hashing the value of the given request header.
+func HeaderKey(name string) HashKeyFunc {
+	return func(req *http.Request) string {
+		return req.Header.Get(name)
+	}
+}
+
+// CookieKey returns a HashKeyFunc hashing the value of the given cookie.
+func CookieKey(name string) HashKeyFunc {
+	return func(req *http.Request) string {
+		cookie, err := req.Cookie(name)
+		if err != nil {
+			return ""
+		}
+		return cookie.Value
+	}
+}
+
+// PathKey is a HashKeyFunc hashing the request path.
+func PathKey(req *http.Request) string {
+	return req.URL.Path
+}
+
+// IPKey returns a HashKeyFunc hashing the client IP, as returned by the given strategy.
+func IPKey(strategy ip.Strategy) HashKeyFunc {
+	return func(req *http.Request) string {
+		clientIP := strategy.GetIP(req)
+
+		// The remote address strategy returns the port as well, which changes with each connection.
+		if host, _, err := net.SplitHostPort(clientIP); err == nil {
+			return host
+		}
+		return clientIP
+	}
+}
+
+type ringPoint struct {
+	hash   uint64
+	server *server
+}
+
+// NewConsistentHash creates a Balancer forwarding each request to the server picked by hashing its key on a hash ring.
+// Each server has a number of virtual nodes on the ring proportional to its weight,
+// so adding or removing a server only moves around 1/N of the keys.
+// The requests without a key are forwarded to the server with the fewest in-flight requests.
+func NewConsistentHash(next http.Handler, key HashKeyFunc) *Balancer {
+	b := newBalancer(next, leastConnectionsCost)
+	b.hashKey = key
+	return b
+}
+
+func (b *Balancer) hashServer(key string) (*server, error) {
+	if b.ring == nil {
+		b.ring = buildRing(b.servers)
+	}
+
+	if len(b.ring) == 0 {
+		if len(b.servers) == 0 {
+			return nil, errors.New("no servers in the pool")
+		}
+		return nil, errors.New("all servers have 0 weight")
+	}
+
+	h := hash(key)
+	i := sort.Search(len(b.ring), func(i int) bool {
+		return b.ring[i].hash >= h
+	})
+	if i == len(b.ring) {
+		i = 0
+	}
+
+	return b.ring[i].server, nil
+}
+
+func buildRing(servers []*server) []ringPoint {
+	ring := []ringPoint{}
+	for _, srv := range servers {
+		name := srv.url.String()
+		for i := 0; i < virtualNodes*srv.weight; i++ {
+			ring = append(ring, ringPoint{hash: hash(name + "-" + strconv.Itoa(i)), server: srv})
+		}
+	}
+
+	sort.Slice(ring, func(i, j int) bool {
+		return ring[i].hash < ring[j].hash
+	})
+
+	return ring
+}
+
+func hash(key string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(key))
+
+	// FNV spreads similar keys (like the virtual node names) poorly over the ring,
+	// so its result goes through the finalizer of MurmurHash3.
+	x := h.Sum64()
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	return x
+}
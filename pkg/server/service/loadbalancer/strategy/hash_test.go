@@ -0,0 +1,124 @@
+package strategy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/containous/traefik/pkg/ip"
+	"github.com/containous/traefik/pkg/testhelpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHashKeys(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "http://foo.com/bar?baz=1", nil)
+	req.RemoteAddr = "10.0.0.1:1234"
+	req.Header.Set("X-Key", "header-value")
+	req.AddCookie(&http.Cookie{Name: "session", Value: "cookie-value"})
+
+	assert.Equal(t, "header-value", HeaderKey("X-Key")(req))
+	assert.Equal(t, "", HeaderKey("X-Missing")(req))
+	assert.Equal(t, "cookie-value", CookieKey("session")(req))
+	assert.Equal(t, "", CookieKey("missing")(req))
+	assert.Equal(t, "/bar", PathKey(req))
+	assert.Equal(t, "10.0.0.1", IPKey(&ip.RemoteAddrStrategy{})(req))
+}
+
+func TestConsistentHash(t *testing.T) {
+	balancer := NewConsistentHash(hostHandler(), HeaderKey("X-Key"))
+	upsertServers(t, balancer, nil, "a", "b", "c")
+
+	serve := func(key string) string {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("X-Key", key)
+
+		recorder := httptest.NewRecorder()
+		balancer.ServeHTTP(recorder, req)
+		return recorder.Header().Get("server")
+	}
+
+	const keys = 3000
+
+	before := make(map[string]string)
+	counts := make(map[string]int)
+	for i := 0; i < keys; i++ {
+		key := strconv.Itoa(i)
+		before[key] = serve(key)
+		counts[before[key]]++
+
+		// The same key is always forwarded to the same server.
+		assert.Equal(t, before[key], serve(key))
+	}
+
+	// The keys are evenly spread between the servers.
+	for _, name := range []string{"a", "b", "c"} {
+		assert.InDelta(t, keys/3, counts[name], keys/10, name)
+	}
+
+	require.NoError(t, balancer.RemoveServer(testhelpers.MustParseURL("http://b")))
+
+	var moved int
+	for key, host := range before {
+		after := serve(key)
+		if host != "b" {
+			// Only the keys of the removed server are moved.
+			assert.Equal(t, host, after, key)
+		} else {
+			assert.NotEqual(t, "b", after, key)
+			moved++
+		}
+	}
+	assert.Equal(t, counts["b"], moved)
+
+	// Adding the server back restores the previous mapping.
+	upsertServers(t, balancer, nil, "b")
+	for key, host := range before {
+		assert.Equal(t, host, serve(key), key)
+	}
+}
+
+func TestConsistentHash_weights(t *testing.T) {
+	balancer := NewConsistentHash(hostHandler(), PathKey)
+	upsertServers(t, balancer, map[string]int{"a": 3, "b": 1, "c": 0}, "a", "b", "c")
+
+	const keys = 4000
+
+	counts := make(map[string]int)
+	for i := 0; i < keys; i++ {
+		srv, err := balancer.acquire(httptest.NewRequest(http.MethodGet, "/"+strconv.Itoa(i), nil))
+		require.NoError(t, err)
+
+		counts[srv.url.Host]++
+	}
+
+	assert.InDelta(t, keys*3/4, counts["a"], keys/10)
+	assert.InDelta(t, keys/4, counts["b"], keys/10)
+	assert.Zero(t, counts["c"])
+}
+
+func TestConsistentHash_noKey(t *testing.T) {
+	balancer := NewConsistentHash(hostHandler(), HeaderKey("X-Key"))
+	upsertServers(t, balancer, nil, "a", "b")
+
+	// Without a key, the requests are forwarded to the least loaded server.
+	var hosts []string
+	for i := 0; i < 4; i++ {
+		srv, err := balancer.acquire(httptest.NewRequest(http.MethodGet, "/", nil))
+		require.NoError(t, err)
+
+		hosts = append(hosts, srv.url.Host)
+	}
+
+	assert.Equal(t, []string{"a", "b", "a", "b"}, hosts)
+}
+
+func TestConsistentHash_noServer(t *testing.T) {
+	balancer := NewConsistentHash(hostHandler(), PathKey)
+
+	recorder := httptest.NewRecorder()
+	balancer.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/foo", nil))
+
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+}
@@ -0,0 +1,282 @@
+// Package strategy implements load balancers between the servers of a service,
+// which forward each request to a server picked according to a load-balancing strategy.
+package strategy
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/containous/traefik/pkg/healthcheck"
+	"github.com/vulcand/oxy/roundrobin"
+	"github.com/vulcand/oxy/utils"
+)
+
+// defaultDecay is the time it takes for the latency measured on a server to lose most of its influence.
+const defaultDecay = 10 * time.Second
+
+type server struct {
+	url    *url.URL
+	weight int
+
+	inFlight int
+	// latency is the exponentially weighted moving average of the response times of the server, in nanoseconds.
+	latency    float64
+	lastUpdate time.Time
+}
+
+// costFunc returns the cost of forwarding a request to a server, the server with the lowest cost being selected.
+type costFunc func(srv *server, now time.Time) float64
+
+// Balancer forwards each request to the server with the lowest cost.
+// Ties are broken in a round robin fashion, and the servers with a weight of zero never receive any request.
+// It implements the healthcheck.BalancerHandler interface, so that the health checks can add and remove its servers.
+type Balancer struct {
+	next  http.Handler
+	cost  costFunc
+	decay time.Duration
+	now   func() time.Time
+
+	// hashKey is only set for the consistent hashing balancers.
+	hashKey HashKeyFunc
+
+	mu      sync.Mutex
+	servers []*server
+	// index is the index of the last selected server.
+	index int
+	// ring is the hash ring of the consistent hashing balancers, rebuilt when the servers change.
+	ring []ringPoint
+}
+
+// NewLeastConnections creates a Balancer forwarding each request to the server with the fewest in-flight requests,
+// relatively to its weight.
+func NewLeastConnections(next http.Handler) *Balancer {
+	return newBalancer(next, leastConnectionsCost)
+}
+
+// NewEWMA creates a Balancer forwarding each request to the server with the lowest peak EWMA
+// (exponentially weighted moving average) of its response times, multiplied by its number of in-flight requests.
+// The measured latency of a server decays over time, so that an idle slow server is eventually retried.
+func NewEWMA(next http.Handler) *Balancer {
+	b := newBalancer(next, nil)
+	b.cost = b.ewmaCost
+	return b
+}
+
+func newBalancer(next http.Handler, cost costFunc) *Balancer {
+	return &Balancer{
+		next:  next,
+		cost:  cost,
+		decay: defaultDecay,
+		now:   time.Now,
+		index: -1,
+	}
+}
+
+func leastConnectionsCost(srv *server, _ time.Time) float64 {
+	return float64(srv.inFlight) / float64(srv.weight)
+}
+
+func (b *Balancer) ewmaCost(srv *server, now time.Time) float64 {
+	// A server without any response yet has a latency of zero,
+	// so it is selected until its latency can be measured.
+	return (b.decayedLatency(srv, now) + 1) * float64(srv.inFlight+1) / float64(srv.weight)
+}
+
+func (b *Balancer) decayedLatency(srv *server, now time.Time) float64 {
+	if srv.lastUpdate.IsZero() {
+		return 0
+	}
+
+	elapsed := now.Sub(srv.lastUpdate)
+	if elapsed <= 0 {
+		return srv.latency
+	}
+
+	return srv.latency * math.Exp(-float64(elapsed)/float64(b.decay))
+}
+
+// release counts the request as done on the server, and records its response time.
+// A response time higher than the current average replaces it, so that the balancer reacts quickly to a slow server.
+func (b *Balancer) release(srv *server, rtt time.Duration, now time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	srv.inFlight--
+
+	if srv.lastUpdate.IsZero() || float64(rtt) > srv.latency {
+		srv.latency = float64(rtt)
+	} else {
+		w := math.Exp(-float64(now.Sub(srv.lastUpdate)) / float64(b.decay))
+		srv.latency = srv.latency*w + float64(rtt)*(1-w)
+	}
+	srv.lastUpdate = now
+}
+
+func (b *Balancer) nextServer(now time.Time) (*server, error) {
+	if len(b.servers) == 0 {
+		return nil, errors.New("no servers in the pool")
+	}
+
+	var selected *server
+	var selectedCost float64
+	selectedIndex := -1
+	for i := 1; i <= len(b.servers); i++ {
+		index := (b.index + i) % len(b.servers)
+		srv := b.servers[index]
+		if srv.weight <= 0 {
+			continue
+		}
+
+		cost := b.cost(srv, now)
+		if selected == nil || cost < selectedCost {
+			selected = srv
+			selectedCost = cost
+			selectedIndex = index
+		}
+	}
+
+	if selected == nil {
+		return nil, errors.New("all servers have 0 weight")
+	}
+
+	b.index = selectedIndex
+	return selected, nil
+}
+
+func (b *Balancer) selectServer(req *http.Request) (*server, error) {
+	if b.hashKey != nil {
+		if key := b.hashKey(req); key != "" {
+			return b.hashServer(key)
+		}
+	}
+
+	return b.nextServer(b.now())
+}
+
+// acquire selects the server to forward the request to, and counts the request as in-flight on it.
+func (b *Balancer) acquire(req *http.Request) (*server, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	srv, err := b.selectServer(req)
+	if err != nil {
+		return nil, err
+	}
+
+	srv.inFlight++
+	return srv, nil
+}
+
+func (b *Balancer) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	srv, err := b.acquire(req)
+	if err != nil {
+		utils.DefaultHandler.ServeHTTP(rw, req, err)
+		return
+	}
+
+	start := b.now()
+	defer func() {
+		end := b.now()
+		b.release(srv, end.Sub(start), end)
+	}()
+
+	// make a shallow copy of the request before changing its URL, to avoid side effects.
+	newReq := *req
+	newReq.URL = utils.CopyURL(srv.url)
+
+	b.next.ServeHTTP(rw, &newReq)
+}
+
+// Servers returns the URLs of the servers of the balancer.
+func (b *Balancer) Servers() []*url.URL {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.urls()
+}
+
+func (b *Balancer) urls() []*url.URL {
+	urls := make([]*url.URL, len(b.servers))
+	for i, srv := range b.servers {
+		urls[i] = srv.url
+	}
+	return urls
+}
+
+// ServerWeight returns the weight of the given server, and whether the server is part of the balancer.
+func (b *Balancer) ServerWeight(u *url.URL) (int, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if srv, _ := b.findServer(u); srv != nil {
+		return srv.weight, true
+	}
+	return -1, false
+}
+
+// RemoveServer removes the given server from the balancer.
+func (b *Balancer) RemoveServer(u *url.URL) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	srv, index := b.findServer(u)
+	if srv == nil {
+		return errors.New("server not found")
+	}
+
+	b.servers = append(b.servers[:index], b.servers[index+1:]...)
+	b.index = -1
+	b.ring = nil
+	return nil
+}
+
+// UpsertServer adds the given server to the balancer, or updates its options if it is already part of it.
+// It accepts the same options as the oxy round robin load balancer.
+func (b *Balancer) UpsertServer(u *url.URL, options ...roundrobin.ServerOption) error {
+	if u == nil {
+		return errors.New("server URL can't be nil")
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	srv, _ := b.findServer(u)
+
+	weight := 1
+	if srv != nil {
+		weight = srv.weight
+	}
+
+	weight, err := healthcheck.ApplyServerOptions(u, weight, options)
+	if err != nil {
+		return err
+	}
+
+	b.ring = nil
+
+	if srv != nil {
+		srv.weight = weight
+		return nil
+	}
+
+	b.servers = append(b.servers, &server{url: utils.CopyURL(u), weight: weight})
+	b.index = -1
+	return nil
+}
+
+func (b *Balancer) findServer(u *url.URL) (*server, int) {
+	for i, srv := range b.servers {
+		if sameURL(u, srv.url) {
+			return srv, i
+		}
+	}
+	return nil, -1
+}
+
+func sameURL(a, b *url.URL) bool {
+	return a.Path == b.Path && a.Host == b.Host && a.Scheme == b.Scheme
+}
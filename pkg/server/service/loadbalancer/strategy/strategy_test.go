@@ -0,0 +1,202 @@
+package strategy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/pkg/testhelpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vulcand/oxy/roundrobin"
+)
+
+func upsertServers(t *testing.T, balancer *Balancer, weights map[string]int, names ...string) {
+	t.Helper()
+
+	for _, name := range names {
+		u := testhelpers.MustParseURL("http://" + name)
+		require.NoError(t, balancer.UpsertServer(u))
+
+		// Like with the oxy round robin load balancer, a weight of zero only applies to an existing server.
+		if weight, ok := weights[name]; ok {
+			require.NoError(t, balancer.UpsertServer(u, roundrobin.Weight(weight)))
+		}
+	}
+}
+
+func hostHandler() http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("server", req.URL.Host)
+		rw.WriteHeader(http.StatusOK)
+	})
+}
+
+func TestLeastConnections(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		weights  map[string]int
+		servers  []string
+		released []int
+		expected []string
+	}{
+		{
+			desc:     "round robin between idle servers",
+			servers:  []string{"a", "b", "c"},
+			released: []int{0, 1, 2, 3, 4, 5},
+			expected: []string{"a", "b", "c", "a", "b", "c"},
+		},
+		{
+			desc:     "least loaded server",
+			servers:  []string{"a", "b", "c"},
+			released: []int{1},
+			expected: []string{"a", "b", "c", "b"},
+		},
+		{
+			desc:     "weighted servers",
+			weights:  map[string]int{"a": 2, "b": 1},
+			servers:  []string{"a", "b"},
+			expected: []string{"a", "b", "a", "b", "a", "a"},
+		},
+		{
+			desc:     "zero weight",
+			weights:  map[string]int{"a": 0},
+			servers:  []string{"a", "b"},
+			expected: []string{"b", "b", "b"},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			balancer := NewLeastConnections(hostHandler())
+			upsertServers(t, balancer, test.weights, test.servers...)
+
+			released := make(map[int]bool)
+			for _, index := range test.released {
+				released[index] = true
+			}
+
+			var hosts []string
+			for i := range test.expected {
+				srv, err := balancer.acquire(httptest.NewRequest(http.MethodGet, "/", nil))
+				require.NoError(t, err)
+
+				hosts = append(hosts, srv.url.Host)
+				if released[i] {
+					balancer.release(srv, 0, time.Now())
+				}
+			}
+
+			assert.Equal(t, test.expected, hosts)
+		})
+	}
+}
+
+func TestLeastConnections_noServerWithPositiveWeight(t *testing.T) {
+	balancer := NewLeastConnections(hostHandler())
+	upsertServers(t, balancer, map[string]int{"a": 0}, "a")
+
+	recorder := httptest.NewRecorder()
+	balancer.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+}
+
+func TestEWMA(t *testing.T) {
+	now := time.Now()
+	latencies := map[string]time.Duration{
+		"slow": 100 * time.Millisecond,
+		"fast": 10 * time.Millisecond,
+	}
+
+	balancer := NewEWMA(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		now = now.Add(latencies[req.URL.Host])
+		rw.Header().Set("server", req.URL.Host)
+	}))
+	balancer.now = func() time.Time { return now }
+
+	upsertServers(t, balancer, nil, "slow", "fast")
+
+	var hosts []string
+	for i := 0; i < 6; i++ {
+		recorder := httptest.NewRecorder()
+		balancer.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+		hosts = append(hosts, recorder.Header().Get("server"))
+	}
+
+	// Each server is tried once, then the fastest one is preferred.
+	assert.Equal(t, []string{"slow", "fast", "fast", "fast", "fast", "fast"}, hosts)
+
+	// The slow server is selected again when the fast one has too many in-flight requests.
+	hosts = nil
+	for i := 0; i < 10; i++ {
+		srv, err := balancer.acquire(httptest.NewRequest(http.MethodGet, "/", nil))
+		require.NoError(t, err)
+
+		hosts = append(hosts, srv.url.Host)
+	}
+
+	assert.Contains(t, hosts, "slow")
+	assert.Equal(t, "fast", hosts[0])
+}
+
+func TestEWMA_peak(t *testing.T) {
+	now := time.Now()
+
+	balancer := NewEWMA(hostHandler())
+	balancer.now = func() time.Time { return now }
+	upsertServers(t, balancer, nil, "a")
+
+	srv := balancer.servers[0]
+	for _, rtt := range []time.Duration{10 * time.Millisecond, 10 * time.Millisecond, time.Second} {
+		srv.inFlight++
+		now = now.Add(rtt)
+		balancer.release(srv, rtt, now)
+	}
+
+	// A latency peak immediately replaces the average.
+	assert.Equal(t, float64(time.Second), srv.latency)
+
+	srv.inFlight++
+	now = now.Add(defaultDecay)
+	balancer.release(srv, 10*time.Millisecond, now)
+
+	// Then it decays towards the latest measured latencies.
+	assert.InDelta(t, float64(time.Second)/2.718+float64(10*time.Millisecond)*(1-1/2.718), srv.latency, float64(time.Millisecond))
+	assert.Equal(t, 0, srv.inFlight)
+}
+
+func TestBalancer_UpsertServer(t *testing.T) {
+	balancer := NewLeastConnections(hostHandler())
+	u := testhelpers.MustParseURL("http://a")
+
+	require.Error(t, balancer.UpsertServer(nil))
+	require.Error(t, balancer.RemoveServer(u))
+
+	require.NoError(t, balancer.UpsertServer(u))
+	weight, ok := balancer.ServerWeight(u)
+	require.True(t, ok)
+	assert.Equal(t, 1, weight)
+
+	require.NoError(t, balancer.UpsertServer(u, roundrobin.Weight(3)))
+	weight, _ = balancer.ServerWeight(u)
+	assert.Equal(t, 3, weight)
+
+	// Upserting an existing server without any option keeps its weight.
+	require.NoError(t, balancer.UpsertServer(u))
+	weight, _ = balancer.ServerWeight(u)
+	assert.Equal(t, 3, weight)
+
+	require.Error(t, balancer.UpsertServer(u, roundrobin.Weight(-1)))
+	assert.Len(t, balancer.Servers(), 1)
+
+	require.NoError(t, balancer.RemoveServer(u))
+	assert.Empty(t, balancer.Servers())
+
+	_, ok = balancer.ServerWeight(u)
+	assert.False(t, ok)
+}
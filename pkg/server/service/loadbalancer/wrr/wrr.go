@@ -0,0 +1,78 @@
+// Package wrr implements a weighted round robin load balancer between http.Handlers.
+package wrr
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/containous/traefik/pkg/log"
+)
+
+type namedHandler struct {
+	http.Handler
+	name          string
+	weight        int
+	currentWeight int
+}
+
+// Balancer is a smooth weighted round robin load balancer between http.Handlers,
+// based on the algorithm used by nginx:
+// each time a handler is picked, its current weight is increased by its weight,
+// the handler with the highest current weight is selected,
+// and its current weight is decreased by the total weight of all the handlers.
+type Balancer struct {
+	mu       sync.Mutex
+	handlers []*namedHandler
+}
+
+// New creates a new Balancer.
+func New() *Balancer {
+	return &Balancer{}
+}
+
+// AddService adds a handler to the balancer.
+// A handler with a weight of zero (or lower) does not receive any request.
+func (b *Balancer) AddService(name string, handler http.Handler, weight int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.handlers = append(b.handlers, &namedHandler{Handler: handler, name: name, weight: weight})
+}
+
+func (b *Balancer) nextHandler() *namedHandler {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var total int
+	var selected *namedHandler
+	for _, handler := range b.handlers {
+		if handler.weight <= 0 {
+			continue
+		}
+
+		handler.currentWeight += handler.weight
+		total += handler.weight
+
+		if selected == nil || handler.currentWeight > selected.currentWeight {
+			selected = handler
+		}
+	}
+
+	if selected != nil {
+		selected.currentWeight -= total
+	}
+
+	return selected
+}
+
+// ServeHTTP forwards the request to the next service, according to their weights.
+func (b *Balancer) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	handler := b.nextHandler()
+	if handler == nil {
+		log.FromContext(req.Context()).Error("no available service")
+		http.Error(rw, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+		return
+	}
+
+	handler.ServeHTTP(rw, req)
+}
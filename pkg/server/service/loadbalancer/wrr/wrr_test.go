@@ -0,0 +1,91 @@
+package wrr
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func serviceHandler(name string) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("server", name)
+		rw.WriteHeader(http.StatusOK)
+	})
+}
+
+func TestBalancer(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		weights  map[string]int
+		requests int
+		expected map[string]int
+	}{
+		{
+			desc:     "same weights",
+			weights:  map[string]int{"first": 1, "second": 1},
+			requests: 4,
+			expected: map[string]int{"first": 2, "second": 2},
+		},
+		{
+			desc:     "different weights",
+			weights:  map[string]int{"first": 95, "second": 5},
+			requests: 100,
+			expected: map[string]int{"first": 95, "second": 5},
+		},
+		{
+			desc:     "zero weight",
+			weights:  map[string]int{"first": 0, "second": 3},
+			requests: 6,
+			expected: map[string]int{"second": 6},
+		},
+		{
+			desc:     "no service with a positive weight",
+			weights:  map[string]int{"first": 0},
+			requests: 2,
+			expected: map[string]int{},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			balancer := New()
+			for name, weight := range test.weights {
+				balancer.AddService(name, serviceHandler(name), weight)
+			}
+
+			recorded := make(map[string]int)
+			for i := 0; i < test.requests; i++ {
+				recorder := httptest.NewRecorder()
+				balancer.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+
+				if recorder.Code != http.StatusOK {
+					assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+					continue
+				}
+				recorded[recorder.Header().Get("server")]++
+			}
+
+			assert.Equal(t, test.expected, recorded)
+		})
+	}
+}
+
+func TestBalancerSmoothness(t *testing.T) {
+	balancer := New()
+	balancer.AddService("first", serviceHandler("first"), 2)
+	balancer.AddService("second", serviceHandler("second"), 1)
+
+	var servers []string
+	for i := 0; i < 6; i++ {
+		recorder := httptest.NewRecorder()
+		balancer.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+		servers = append(servers, recorder.Header().Get("server"))
+	}
+
+	assert.Equal(t, []string{"first", "second", "first", "first", "second", "first"}, servers)
+}
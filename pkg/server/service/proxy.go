@@ -12,6 +12,7 @@ import (
 
 	"github.com/containous/traefik/pkg/config"
 	"github.com/containous/traefik/pkg/log"
+	"github.com/containous/traefik/pkg/server/service/loadbalancer/dnsdiscovery"
 	"github.com/containous/traefik/pkg/types"
 )
 
@@ -55,6 +56,11 @@ func buildProxy(passHostHeader bool, responseForwarding *config.ResponseForwardi
 			// Do not pass client Host header unless optsetter PassHostHeader is set.
 			if !passHostHeader {
 				outReq.Host = outReq.URL.Host
+
+				// The servers discovered from the DNS are IP addresses, the Host header is the host name they were resolved from.
+				if host, ok := dnsdiscovery.HostFromContext(outReq.Context()); ok {
+					outReq.Host = host
+				}
 			}
 
 		},
@@ -0,0 +1,378 @@
+package service
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/containous/traefik/pkg/config"
+	"github.com/containous/traefik/pkg/log"
+	"github.com/containous/traefik/pkg/proxyprotocol"
+	"github.com/containous/traefik/pkg/server/service/loadbalancer/dnsdiscovery"
+	traefiktls "github.com/containous/traefik/pkg/tls"
+	"golang.org/x/net/http2"
+)
+
+const (
+	defaultDialTimeout     = 30 * time.Second
+	defaultIdleConnTimeout = 90 * time.Second
+)
+
+type h2cTransportWrapper struct {
+	*http2.Transport
+}
+
+func (t *h2cTransportWrapper) RoundTrip(req *http.Request) (*http.Response, error) {
+	req.URL.Scheme = "http"
+	return t.Transport.RoundTrip(req)
+}
+
+type clientAddrKey struct{}
+
+// proxyProtocolRoundTripper passes on the address of the client of each request to the dialer,
+// so that it is sent in the PROXY protocol header of the connection to the server.
+type proxyProtocolRoundTripper struct {
+	*http.Transport
+}
+
+func (t *proxyProtocolRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	host, port, err := net.SplitHostPort(req.RemoteAddr)
+	if err == nil {
+		clientAddr := &net.TCPAddr{IP: net.ParseIP(host)}
+		clientAddr.Port, _ = strconv.Atoi(port)
+		req = req.WithContext(context.WithValue(req.Context(), clientAddrKey{}, clientAddr))
+	}
+
+	return t.Transport.RoundTrip(req)
+}
+
+// proxyProtocolDialContext returns a dial function writing the PROXY protocol header, in the given version,
+// on each connection, with the address of the client of the request and the local address it connected to.
+func proxyProtocolDialContext(dialer *net.Dialer, version int) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := dialer.DialContext(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+
+		// Without the addresses, the header tells that they are unknown.
+		clientAddr, _ := ctx.Value(clientAddrKey{}).(net.Addr)
+		localAddr, _ := ctx.Value(http.LocalAddrContextKey).(net.Addr)
+
+		if err := proxyprotocol.WriteHeader(conn, version, clientAddr, localAddr); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+
+		return conn, nil
+	}
+}
+
+// dialTLSContext returns a dial function opening the TLS connections of the transport,
+// which authenticates the servers discovered from the DNS with the host name they were resolved from, as their URL holds an IP address.
+// The other servers are authenticated as by default, with the host of their URL, unless the TLS configuration sets a server name.
+func dialTLSContext(transport *http.Transport) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := transport.DialContext(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+
+		// The TLS configuration is read once the transport is configured for HTTP/2, which adds the protocol to the negotiated ones.
+		tlsConfig := &tls.Config{}
+		if transport.TLSClientConfig != nil {
+			tlsConfig = transport.TLSClientConfig.Clone()
+		}
+
+		if tlsConfig.ServerName == "" {
+			host := addr
+			if discovered, ok := dnsdiscovery.HostFromContext(ctx); ok {
+				host = discovered
+			}
+			tlsConfig.ServerName = hostname(host)
+		}
+
+		if transport.TLSHandshakeTimeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, transport.TLSHandshakeTimeout)
+			defer cancel()
+		}
+
+		tlsConn := tls.Client(conn, tlsConfig)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+
+		return tlsConn, nil
+	}
+}
+
+// hostname returns the host, without its port if any.
+func hostname(host string) string {
+	if name, _, err := net.SplitHostPort(host); err == nil {
+		return name
+	}
+	return host
+}
+
+type roundTripper struct {
+	config *config.ServersTransport
+	// files are the contents of the certificates files of the configuration, when the round tripper was created.
+	files        [][]byte
+	roundTripper http.RoundTripper
+	// err is the error which prevented the creation of the round tripper.
+	err error
+}
+
+// RoundTripperManager creates the round trippers of the ServersTransports of the dynamic configuration,
+// and keeps them across the configuration reloads as long as their configuration is unchanged,
+// so that their pools of idle connections are kept as well.
+type RoundTripperManager struct {
+	defaultRoundTripper http.RoundTripper
+
+	lock          sync.RWMutex
+	roundTrippers map[string]*roundTripper
+}
+
+// NewRoundTripperManager creates a new RoundTripperManager.
+// The default round tripper is used by the services which do not reference any ServersTransport.
+func NewRoundTripperManager(defaultRoundTripper http.RoundTripper) *RoundTripperManager {
+	return &RoundTripperManager{
+		defaultRoundTripper: defaultRoundTripper,
+		roundTrippers:       make(map[string]*roundTripper),
+	}
+}
+
+// Update updates the round trippers with the given ServersTransports configurations, keyed by their qualified name.
+// The round trippers whose configuration, and certificates files, are unchanged are reused.
+func (r *RoundTripperManager) Update(newConfigs map[string]*config.ServersTransport) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	newFiles := make(map[string][][]byte, len(newConfigs))
+	for name, newConfig := range newConfigs {
+		newFiles[name] = readCertificatesFiles(newConfig)
+	}
+
+	for name, current := range r.roundTrippers {
+		newConfig, ok := newConfigs[name]
+		if ok && current.err == nil && reflect.DeepEqual(newConfig, current.config) && reflect.DeepEqual(newFiles[name], current.files) {
+			continue
+		}
+
+		if current.roundTripper != nil {
+			closeIdleConnections(current.roundTripper)
+		}
+		delete(r.roundTrippers, name)
+	}
+
+	for name, newConfig := range newConfigs {
+		if _, ok := r.roundTrippers[name]; ok {
+			continue
+		}
+
+		transport, err := CreateRoundTripper(newConfig)
+		if err != nil {
+			log.WithoutContext().Errorf("Could not configure the servers transport %s: %v", name, err)
+		}
+
+		r.roundTrippers[name] = &roundTripper{config: newConfig, files: newFiles[name], roundTripper: transport, err: err}
+	}
+}
+
+// Get returns the round tripper of the ServersTransport with the given qualified name,
+// or the default round tripper if the name is empty.
+func (r *RoundTripperManager) Get(name string) (http.RoundTripper, error) {
+	if name == "" {
+		return r.defaultRoundTripper, nil
+	}
+
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	if rt, ok := r.roundTrippers[name]; ok {
+		if rt.err != nil {
+			return nil, fmt.Errorf("servers transport %q: %v", name, rt.err)
+		}
+		return rt.roundTripper, nil
+	}
+
+	return nil, fmt.Errorf("servers transport %q not found", name)
+}
+
+// CreateRoundTripper creates an http.RoundTripper configured with the ServersTransport configuration settings.
+// For the settings that can't be configured in Traefik it uses the default http.Transport settings.
+// An exception to this is the MaxIdleConns setting as we only provide the option MaxIdleConnsPerHost
+// in Traefik at this point in time. Setting this value to the default of 100 could lead to confusing
+// behavior and backwards compatibility issues.
+func CreateRoundTripper(cfg *config.ServersTransport) (http.RoundTripper, error) {
+	if cfg == nil {
+		return nil, errors.New("no transport configuration given")
+	}
+
+	dialer := &net.Dialer{
+		Timeout:   defaultDialTimeout,
+		KeepAlive: 30 * time.Second,
+		DualStack: true,
+	}
+
+	transport := &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           dialer.DialContext,
+		MaxIdleConnsPerHost:   cfg.MaxIdleConnsPerHost,
+		IdleConnTimeout:       defaultIdleConnTimeout,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+
+	if cfg.ForwardingTimeouts != nil {
+		if cfg.ForwardingTimeouts.DialTimeout > 0 {
+			dialer.Timeout = time.Duration(cfg.ForwardingTimeouts.DialTimeout)
+		}
+		if cfg.ForwardingTimeouts.IdleConnTimeout > 0 {
+			transport.IdleConnTimeout = time.Duration(cfg.ForwardingTimeouts.IdleConnTimeout)
+		}
+		transport.ResponseHeaderTimeout = time.Duration(cfg.ForwardingTimeouts.ResponseHeaderTimeout)
+	}
+
+	transport.RegisterProtocol("h2c", &h2cTransportWrapper{
+		Transport: &http2.Transport{
+			// The connections are dialed as the HTTP/1 ones, with the dial timeout and the PROXY protocol header if any.
+			// As they are shared by the requests of all the clients, the header tells that the addresses are unknown.
+			DialTLS: func(netw, addr string, cfg *tls.Config) (net.Conn, error) {
+				return transport.DialContext(context.Background(), netw, addr)
+			},
+			AllowHTTP: true,
+		},
+	})
+
+	if cfg.InsecureSkipVerify || len(cfg.RootCAs) > 0 || len(cfg.Certificates) > 0 || cfg.ServerName != "" {
+		tlsConfig, err := createTLSConfig(cfg)
+		if err != nil {
+			return nil, err
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	if cfg.ProxyProtocol != nil {
+		version := cfg.ProxyProtocol.GetVersion()
+		if version != 1 && version != 2 {
+			return nil, fmt.Errorf("unsupported PROXY protocol version %d", cfg.ProxyProtocol.Version)
+		}
+
+		transport.DialContext = proxyProtocolDialContext(dialer, version)
+		// A connection carries the address of a single client, so it can't be reused for the requests of other clients.
+		transport.DisableKeepAlives = true
+	}
+
+	transport.DialTLSContext = dialTLSContext(transport)
+
+	if !cfg.DisableHTTP2 {
+		err := http2.ConfigureTransport(transport)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.ProxyProtocol != nil {
+		return &proxyProtocolRoundTripper{Transport: transport}, nil
+	}
+
+	return transport, nil
+}
+
+func createTLSConfig(cfg *config.ServersTransport) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		ServerName:         cfg.ServerName,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+	}
+
+	if len(cfg.RootCAs) > 0 {
+		tlsConfig.RootCAs = createRootCACertPool(cfg.RootCAs)
+	}
+
+	for _, certificate := range cfg.Certificates {
+		certContent, err := certificate.CertFile.Read()
+		if err != nil {
+			return nil, fmt.Errorf("unable to read the client certificate: %v", err)
+		}
+
+		keyContent, err := certificate.KeyFile.Read()
+		if err != nil {
+			return nil, fmt.Errorf("unable to read the client certificate key: %v", err)
+		}
+
+		cert, err := tls.X509KeyPair(certContent, keyContent)
+		if err != nil {
+			return nil, fmt.Errorf("invalid client certificate: %v", err)
+		}
+
+		tlsConfig.Certificates = append(tlsConfig.Certificates, cert)
+	}
+
+	return tlsConfig, nil
+}
+
+// readCertificatesFiles returns the contents of the root CAs and of the client certificates of the configuration,
+// so that a change of the files, at the same paths, can be detected.
+func readCertificatesFiles(cfg *config.ServersTransport) [][]byte {
+	if cfg == nil {
+		return nil
+	}
+
+	var files []traefiktls.FileOrContent
+	files = append(files, cfg.RootCAs...)
+	for _, certificate := range cfg.Certificates {
+		files = append(files, certificate.CertFile, certificate.KeyFile)
+	}
+
+	var contents [][]byte
+	for _, file := range files {
+		// An unreadable file has no content, so that the round tripper is created again once the file is readable.
+		content, _ := file.Read()
+		contents = append(contents, content)
+	}
+	return contents
+}
+
+func createRootCACertPool(rootCAs []traefiktls.FileOrContent) *x509.CertPool {
+	roots := x509.NewCertPool()
+
+	for _, cert := range rootCAs {
+		certContent, err := cert.Read()
+		if err != nil {
+			log.WithoutContext().Error("Error while read RootCAs", err)
+			continue
+		}
+		roots.AppendCertsFromPEM(certContent)
+	}
+
+	return roots
+}
+
+// tlsClientConfig returns the TLS configuration of the given round tripper, or nil if it has none.
+func tlsClientConfig(rt http.RoundTripper) *tls.Config {
+	switch transport := rt.(type) {
+	case *http.Transport:
+		return transport.TLSClientConfig
+	case *proxyProtocolRoundTripper:
+		return transport.TLSClientConfig
+	default:
+		return nil
+	}
+}
+
+func closeIdleConnections(rt http.RoundTripper) {
+	if transport, ok := rt.(interface{ CloseIdleConnections() }); ok {
+		transport.CloseIdleConnections()
+	}
+}
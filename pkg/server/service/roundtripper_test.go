@@ -0,0 +1,489 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/armon/go-proxyproto"
+	"github.com/containous/traefik/pkg/config"
+	"github.com/containous/traefik/pkg/server/service/loadbalancer/dnsdiscovery"
+	traefiktls "github.com/containous/traefik/pkg/tls"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/http2"
+)
+
+type testCert struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPEM []byte
+	keyPEM  []byte
+}
+
+func (c testCert) tlsCertificate(t *testing.T) tls.Certificate {
+	t.Helper()
+
+	cert, err := tls.X509KeyPair(c.certPEM, c.keyPEM)
+	require.NoError(t, err)
+	return cert
+}
+
+// generateCert generates a certificate for the given DNS name, signed by the given parent (self-signed if nil).
+func generateCert(t *testing.T, name string, parent *testCert, usage x509.ExtKeyUsage) testCert {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		DNSNames:     []string{name},
+	}
+
+	signer, signerKey := template, key
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage |= x509.KeyUsageCertSign
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	return testCert{
+		cert:    cert,
+		key:     key,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		keyPEM:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}
+}
+
+func TestCreateRoundTripper_mTLS(t *testing.T) {
+	ca := generateCert(t, "ca", nil, x509.ExtKeyUsageAny)
+	serverCert := generateCert(t, "backend.example.com", &ca, x509.ExtKeyUsageServerAuth)
+	clientCert := generateCert(t, "traefik", &ca, x509.ExtKeyUsageClientAuth)
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(ca.cert)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("X-Client", req.TLS.PeerCertificates[0].Subject.CommonName)
+		rw.Header().Set("X-Server-Name", req.TLS.ServerName)
+	}))
+	server.TLS = &tls.Config{
+		Certificates: []tls.Certificate{serverCert.tlsCertificate(t)},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    clientCAs,
+	}
+	server.StartTLS()
+	defer server.Close()
+
+	testCases := []struct {
+		desc          string
+		transport     *config.ServersTransport
+		expectedError bool
+	}{
+		{
+			desc: "trusted server and client certificate",
+			transport: &config.ServersTransport{
+				ServerName: "backend.example.com",
+				RootCAs:    []traefiktls.FileOrContent{traefiktls.FileOrContent(ca.certPEM)},
+				Certificates: traefiktls.Certificates{
+					{CertFile: traefiktls.FileOrContent(clientCert.certPEM), KeyFile: traefiktls.FileOrContent(clientCert.keyPEM)},
+				},
+			},
+		},
+		{
+			desc: "without client certificate",
+			transport: &config.ServersTransport{
+				ServerName: "backend.example.com",
+				RootCAs:    []traefiktls.FileOrContent{traefiktls.FileOrContent(ca.certPEM)},
+			},
+			expectedError: true,
+		},
+		{
+			desc: "without server name",
+			transport: &config.ServersTransport{
+				RootCAs: []traefiktls.FileOrContent{traefiktls.FileOrContent(ca.certPEM)},
+				Certificates: traefiktls.Certificates{
+					{CertFile: traefiktls.FileOrContent(clientCert.certPEM), KeyFile: traefiktls.FileOrContent(clientCert.keyPEM)},
+				},
+			},
+			expectedError: true,
+		},
+		{
+			desc: "without root CAs",
+			transport: &config.ServersTransport{
+				ServerName: "backend.example.com",
+				Certificates: traefiktls.Certificates{
+					{CertFile: traefiktls.FileOrContent(clientCert.certPEM), KeyFile: traefiktls.FileOrContent(clientCert.keyPEM)},
+				},
+			},
+			expectedError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			rt, err := CreateRoundTripper(test.transport)
+			require.NoError(t, err)
+
+			req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+			require.NoError(t, err)
+
+			resp, err := rt.RoundTrip(req)
+			if test.expectedError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+			assert.Equal(t, "traefik", resp.Header.Get("X-Client"))
+			assert.Equal(t, "backend.example.com", resp.Header.Get("X-Server-Name"))
+		})
+	}
+}
+
+func TestCreateRoundTripper_discoveredHost(t *testing.T) {
+	ca := generateCert(t, "ca", nil, x509.ExtKeyUsageAny)
+	serverCert := generateCert(t, "backend.example.com", &ca, x509.ExtKeyUsageServerAuth)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("X-Server-Name", req.TLS.ServerName)
+		rw.Header().Set("X-Proto", req.Proto)
+	}))
+	server.TLS = &tls.Config{Certificates: []tls.Certificate{serverCert.tlsCertificate(t)}}
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	defer server.Close()
+
+	_, port, err := net.SplitHostPort(server.Listener.Addr().String())
+	require.NoError(t, err)
+
+	testCases := []struct {
+		desc           string
+		transport      *config.ServersTransport
+		discoveredHost string
+		expectedName   string
+		expectedProto  string
+		expectedError  bool
+	}{
+		{
+			desc:           "discovered host",
+			transport:      &config.ServersTransport{RootCAs: []traefiktls.FileOrContent{traefiktls.FileOrContent(ca.certPEM)}},
+			discoveredHost: net.JoinHostPort("backend.example.com", port),
+			expectedName:   "backend.example.com",
+			expectedProto:  "HTTP/2.0",
+		},
+		{
+			desc: "discovered host without HTTP/2",
+			transport: &config.ServersTransport{
+				RootCAs:      []traefiktls.FileOrContent{traefiktls.FileOrContent(ca.certPEM)},
+				DisableHTTP2: true,
+			},
+			discoveredHost: "backend.example.com",
+			expectedName:   "backend.example.com",
+			expectedProto:  "HTTP/1.1",
+		},
+		{
+			desc: "server name of the servers transport",
+			transport: &config.ServersTransport{
+				RootCAs:    []traefiktls.FileOrContent{traefiktls.FileOrContent(ca.certPEM)},
+				ServerName: "backend.example.com",
+			},
+			discoveredHost: "other.example.com",
+			expectedName:   "backend.example.com",
+			expectedProto:  "HTTP/2.0",
+		},
+		{
+			desc:          "server not discovered",
+			transport:     &config.ServersTransport{RootCAs: []traefiktls.FileOrContent{traefiktls.FileOrContent(ca.certPEM)}},
+			expectedError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			rt, err := CreateRoundTripper(test.transport)
+			require.NoError(t, err)
+
+			req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+			require.NoError(t, err)
+			if test.discoveredHost != "" {
+				req = req.WithContext(dnsdiscovery.WithHost(req.Context(), test.discoveredHost))
+			}
+
+			resp, err := rt.RoundTrip(req)
+			if test.expectedError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, test.expectedName, resp.Header.Get("X-Server-Name"))
+			assert.Equal(t, test.expectedProto, resp.Header.Get("X-Proto"))
+		})
+	}
+}
+
+func TestCreateRoundTripper(t *testing.T) {
+	rt, err := CreateRoundTripper(&config.ServersTransport{
+		MaxIdleConnsPerHost: 42,
+		ForwardingTimeouts: &config.ForwardingTimeouts{
+			ResponseHeaderTimeout: 1,
+			IdleConnTimeout:       2,
+		},
+		DisableHTTP2: true,
+	})
+	require.NoError(t, err)
+
+	transport, ok := rt.(*http.Transport)
+	require.True(t, ok)
+
+	assert.Equal(t, 42, transport.MaxIdleConnsPerHost)
+	assert.Equal(t, time.Duration(1), transport.ResponseHeaderTimeout)
+	assert.Equal(t, time.Duration(2), transport.IdleConnTimeout)
+	assert.Nil(t, transport.TLSClientConfig)
+	assert.NotContains(t, transport.TLSNextProto, "h2")
+
+	_, err = CreateRoundTripper(nil)
+	assert.Error(t, err)
+
+	_, err = CreateRoundTripper(&config.ServersTransport{
+		Certificates: traefiktls.Certificates{{CertFile: "foo", KeyFile: "bar"}},
+	})
+	assert.Error(t, err)
+}
+
+func TestTLSClientConfig(t *testing.T) {
+	rt, err := CreateRoundTripper(&config.ServersTransport{
+		ServerName:   "foo.bar",
+		DisableHTTP2: true,
+	})
+	require.NoError(t, err)
+
+	tlsConfig := tlsClientConfig(rt)
+	require.NotNil(t, tlsConfig)
+	assert.Equal(t, "foo.bar", tlsConfig.ServerName)
+
+	rt, err = CreateRoundTripper(&config.ServersTransport{
+		ServerName:    "foo.bar",
+		ProxyProtocol: &config.ProxyProtocol{},
+	})
+	require.NoError(t, err)
+
+	tlsConfig = tlsClientConfig(rt)
+	require.NotNil(t, tlsConfig)
+	assert.Equal(t, "foo.bar", tlsConfig.ServerName)
+
+	assert.Nil(t, tlsClientConfig(http.RoundTripper(nil)))
+}
+
+func TestCreateRoundTripper_proxyProtocol(t *testing.T) {
+	backend := httptest.NewUnstartedServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("X-Remote-Addr", req.RemoteAddr)
+	}))
+	backend.Listener = &proxyproto.Listener{Listener: backend.Listener}
+	backend.Start()
+	defer backend.Close()
+
+	rt, err := CreateRoundTripper(&config.ServersTransport{
+		ProxyProtocol: &config.ProxyProtocol{Version: 1},
+	})
+	require.NoError(t, err)
+
+	localAddr := &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 80}
+
+	// Each request is sent on a new connection, with the address of its own client.
+	for _, clientAddr := range []string{"10.1.2.3:4242", "10.1.2.4:4343"} {
+		req, err := http.NewRequest(http.MethodGet, backend.URL, nil)
+		require.NoError(t, err)
+		req = req.WithContext(context.WithValue(req.Context(), http.LocalAddrContextKey, localAddr))
+		req.RemoteAddr = clientAddr
+
+		resp, err := rt.RoundTrip(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		assert.Equal(t, clientAddr, resp.Header.Get("X-Remote-Addr"))
+	}
+
+	_, err = CreateRoundTripper(&config.ServersTransport{
+		ProxyProtocol: &config.ProxyProtocol{Version: 3},
+	})
+	assert.Error(t, err)
+}
+
+func TestCreateRoundTripper_h2cProxyProtocol(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	headers := make(chan string, 1)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			reader := bufio.NewReader(conn)
+			header, err := reader.ReadString('\n')
+			if err != nil {
+				conn.Close()
+				continue
+			}
+			headers <- header
+
+			handler := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+			go (&http2.Server{}).ServeConn(&bufferedConn{Conn: conn, reader: reader}, &http2.ServeConnOpts{Handler: handler})
+		}
+	}()
+
+	rt, err := CreateRoundTripper(&config.ServersTransport{
+		ProxyProtocol: &config.ProxyProtocol{Version: 1},
+	})
+	require.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodGet, "h2c://"+listener.Addr().String(), nil)
+	require.NoError(t, err)
+
+	resp, err := rt.RoundTrip(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	// The h2c connections are dialed with the PROXY protocol dialer.
+	assert.Equal(t, "PROXY UNKNOWN\r\n", <-headers)
+}
+
+type bufferedConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+func (c *bufferedConn) Read(p []byte) (int, error) {
+	return c.reader.Read(p)
+}
+
+func TestRoundTripperManager(t *testing.T) {
+	manager := NewRoundTripperManager(http.DefaultTransport)
+
+	rt, err := manager.Get("")
+	require.NoError(t, err)
+	assert.Equal(t, http.DefaultTransport, rt)
+
+	_, err = manager.Get("foo@provider")
+	assert.Error(t, err)
+
+	manager.Update(map[string]*config.ServersTransport{
+		"foo@provider": {MaxIdleConnsPerHost: 1},
+		"bar@provider": {MaxIdleConnsPerHost: 2},
+	})
+
+	foo, err := manager.Get("foo@provider")
+	require.NoError(t, err)
+	bar, err := manager.Get("bar@provider")
+	require.NoError(t, err)
+	assert.NotEqual(t, foo, bar)
+
+	manager.Update(map[string]*config.ServersTransport{
+		"foo@provider": {MaxIdleConnsPerHost: 1},
+		"bar@provider": {MaxIdleConnsPerHost: 3},
+	})
+
+	// The round tripper of an unchanged servers transport is reused.
+	newFoo, err := manager.Get("foo@provider")
+	require.NoError(t, err)
+	assert.True(t, foo == newFoo)
+
+	newBar, err := manager.Get("bar@provider")
+	require.NoError(t, err)
+	assert.False(t, bar == newBar)
+	assert.Equal(t, 3, newBar.(*http.Transport).MaxIdleConnsPerHost)
+
+	manager.Update(nil)
+
+	_, err = manager.Get("foo@provider")
+	assert.Error(t, err)
+}
+
+func TestRoundTripperManager_certificatesFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "traefik-servers-transport")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	caFile := filepath.Join(dir, "ca.pem")
+	ca := generateCert(t, "ca", nil, x509.ExtKeyUsageServerAuth)
+	require.NoError(t, ioutil.WriteFile(caFile, ca.certPEM, 0600))
+
+	manager := NewRoundTripperManager(http.DefaultTransport)
+
+	configs := map[string]*config.ServersTransport{
+		"foo@provider": {RootCAs: []traefiktls.FileOrContent{traefiktls.FileOrContent(caFile)}},
+	}
+
+	manager.Update(configs)
+	foo, err := manager.Get("foo@provider")
+	require.NoError(t, err)
+
+	// The round tripper is reused as long as the file is unchanged.
+	manager.Update(configs)
+	newFoo, err := manager.Get("foo@provider")
+	require.NoError(t, err)
+	assert.True(t, foo == newFoo)
+
+	// A change of the file, at the same path, creates a new round tripper.
+	other := generateCert(t, "other", nil, x509.ExtKeyUsageServerAuth)
+	require.NoError(t, ioutil.WriteFile(caFile, other.certPEM, 0600))
+
+	manager.Update(configs)
+	newFoo, err = manager.Get("foo@provider")
+	require.NoError(t, err)
+	assert.False(t, foo == newFoo)
+}
+
+func TestRoundTripperManager_invalidServersTransport(t *testing.T) {
+	manager := NewRoundTripperManager(http.DefaultTransport)
+
+	manager.Update(map[string]*config.ServersTransport{
+		"foo@provider": {Certificates: traefiktls.Certificates{{CertFile: "foo", KeyFile: "bar"}}},
+	})
+
+	// The invalid servers transport does not fall back on the default round tripper.
+	_, err := manager.Get("foo@provider")
+	assert.Error(t, err)
+}
@@ -2,34 +2,64 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httputil"
 	"net/url"
+	"regexp"
 	"time"
 
 	"github.com/containous/alice"
 	"github.com/containous/traefik/pkg/config"
 	"github.com/containous/traefik/pkg/healthcheck"
 	"github.com/containous/traefik/pkg/log"
+	"github.com/containous/traefik/pkg/metrics"
+	"github.com/containous/traefik/pkg/middlewares"
 	"github.com/containous/traefik/pkg/middlewares/accesslog"
 	"github.com/containous/traefik/pkg/middlewares/emptybackendhandler"
 	"github.com/containous/traefik/pkg/middlewares/pipelining"
-	"github.com/containous/traefik/pkg/server/cookie"
 	"github.com/containous/traefik/pkg/server/internal"
+	"github.com/containous/traefik/pkg/server/service/loadbalancer/dnsdiscovery"
+	"github.com/containous/traefik/pkg/server/service/loadbalancer/failover"
+	"github.com/containous/traefik/pkg/server/service/loadbalancer/hedging"
+	"github.com/containous/traefik/pkg/server/service/loadbalancer/mirror"
+	"github.com/containous/traefik/pkg/server/service/loadbalancer/slowstart"
+	"github.com/containous/traefik/pkg/server/service/loadbalancer/sticky"
+	"github.com/containous/traefik/pkg/server/service/loadbalancer/strategy"
+	"github.com/containous/traefik/pkg/server/service/loadbalancer/wrr"
+	"github.com/containous/traefik/pkg/types"
 	"github.com/vulcand/oxy/roundrobin"
 )
 
+type contextKey int
+
+const (
+	// parentServicesKey holds the names of the services referencing the service being built, to detect recursions.
+	parentServicesKey contextKey = iota
+)
+
 const (
 	defaultHealthCheckInterval = 30 * time.Second
 	defaultHealthCheckTimeout  = 5 * time.Second
+
+	defaultPassiveHealthCheckMaxConsecutiveErrors = 5
+	defaultPassiveHealthCheckWindow               = 10 * time.Second
+	defaultPassiveHealthCheckBaseEjectionTime     = 30 * time.Second
+	defaultPassiveHealthCheckMaxEjectionTime      = 5 * time.Minute
+	defaultPassiveHealthCheckMaxEjectionPercent   = 50
+
+	defaultMirroringMaxInFlight = 100
+	defaultMirroringTimeout     = 30 * time.Second
 )
 
 // NewManager creates a new Manager
-func NewManager(configs map[string]*config.ServiceInfo, defaultRoundTripper http.RoundTripper) *Manager {
+func NewManager(configs map[string]*config.ServiceInfo, roundTripperManager *RoundTripperManager, stateManager *StateManager, metricsRegistry metrics.Registry) *Manager {
 	return &Manager{
 		bufferPool:          newBufferPool(),
-		defaultRoundTripper: defaultRoundTripper,
+		roundTripperManager: roundTripperManager,
+		stateManager:        stateManager,
+		metricsRegistry:     metricsRegistry,
 		balancers:           make(map[string][]healthcheck.BalancerHandler),
 		configs:             configs,
 	}
@@ -38,8 +68,12 @@ func NewManager(configs map[string]*config.ServiceInfo, defaultRoundTripper http
 // Manager The service manager
 type Manager struct {
 	bufferPool          httputil.BufferPool
-	defaultRoundTripper http.RoundTripper
+	roundTripperManager *RoundTripperManager
+	stateManager        *StateManager
+	metricsRegistry     metrics.Registry
 	balancers           map[string][]healthcheck.BalancerHandler
+	discoveries         []*dnsdiscovery.Balancer
+	passiveHealthChecks []*healthcheck.PassiveHealthCheck
 	configs             map[string]*config.ServiceInfo
 }
 
@@ -55,20 +89,196 @@ func (m *Manager) BuildHTTP(rootCtx context.Context, serviceName string, respons
 		return nil, fmt.Errorf("the service %q does not exist", serviceName)
 	}
 
-	// TODO Should handle multiple service types
-	// FIXME Check if the service is declared multiple times with different types
-	if conf.LoadBalancer == nil {
-		conf.Err = fmt.Errorf("the service %q doesn't have any load balancer", serviceName)
+	if countServiceTypes(conf.Service) > 1 {
+		conf.Err = fmt.Errorf("the service %q can only be of one type", serviceName)
 		return nil, conf.Err
 	}
 
-	lb, err := m.getLoadBalancerServiceHandler(ctx, serviceName, conf.LoadBalancer, responseModifier)
+	var handler http.Handler
+	var err error
+	switch {
+	case conf.LoadBalancer != nil:
+		handler, err = m.getLoadBalancerServiceHandler(ctx, serviceName, conf.LoadBalancer, responseModifier)
+	case conf.Weighted != nil:
+		handler, err = m.getWeightedServiceHandler(ctx, serviceName, conf.Weighted, responseModifier)
+	case conf.Mirroring != nil:
+		handler, err = m.getMirroringServiceHandler(ctx, serviceName, conf.Mirroring, responseModifier)
+	case conf.Failover != nil:
+		handler, err = m.getFailoverServiceHandler(ctx, serviceName, conf.Failover, responseModifier)
+	default:
+		err = fmt.Errorf("the service %q doesn't have any load balancer", serviceName)
+	}
+
 	if err != nil {
 		conf.Err = err
 		return nil, err
 	}
 
-	return lb, nil
+	return handler, nil
+}
+
+func (m *Manager) getWeightedServiceHandler(
+	ctx context.Context,
+	serviceName string,
+	service *config.WeightedService,
+	responseModifier func(*http.Response) error,
+) (http.Handler, error) {
+	childCtx, err := withParentService(ctx, serviceName)
+	if err != nil {
+		return nil, err
+	}
+
+	balancer := wrr.New()
+	for _, child := range service.Services {
+		if child.Weight < 0 {
+			return nil, fmt.Errorf("invalid weight %d for service %q: must be positive", child.Weight, child.Name)
+		}
+
+		handler, err := m.BuildHTTP(childCtx, child.Name, responseModifier)
+		if err != nil {
+			return nil, fmt.Errorf("error building service %q: %v", child.Name, err)
+		}
+
+		balancer.AddService(child.Name, handler, child.Weight)
+		log.FromContext(ctx).Debugf("Adding service %s with weight %d", child.Name, child.Weight)
+	}
+
+	return balancer, nil
+}
+
+func (m *Manager) getMirroringServiceHandler(
+	ctx context.Context,
+	serviceName string,
+	service *config.MirroringService,
+	responseModifier func(*http.Response) error,
+) (http.Handler, error) {
+	childCtx, err := withParentService(ctx, serviceName)
+	if err != nil {
+		return nil, err
+	}
+
+	if service.Service == "" {
+		return nil, fmt.Errorf("the mirroring service %q doesn't have any main service", serviceName)
+	}
+
+	handler, err := m.BuildHTTP(childCtx, service.Service, responseModifier)
+	if err != nil {
+		return nil, fmt.Errorf("error building service %q: %v", service.Service, err)
+	}
+
+	maxBodySize := service.MaxBodySize
+	if maxBodySize <= 0 {
+		maxBodySize = middlewares.DefaultMaxBodySize
+	}
+
+	maxInFlight := service.MaxInFlight
+	if maxInFlight <= 0 {
+		maxInFlight = defaultMirroringMaxInFlight
+	}
+
+	timeout := defaultMirroringTimeout
+	if service.Timeout != "" {
+		timeout, err = time.ParseDuration(service.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid mirroring timeout: %v", err)
+		}
+		if timeout <= 0 {
+			return nil, fmt.Errorf("mirroring timeout must be greater than zero: %s", service.Timeout)
+		}
+	}
+
+	mirroring := mirror.New(handler, maxBodySize, maxInFlight, timeout)
+	for _, mirrorService := range service.Mirrors {
+		mirrorHandler, err := m.BuildHTTP(childCtx, mirrorService.Name, responseModifier)
+		if err != nil {
+			return nil, fmt.Errorf("error building mirror service %q: %v", mirrorService.Name, err)
+		}
+
+		if err := mirroring.AddMirror(mirrorService.Name, mirrorHandler, mirrorService.Percent); err != nil {
+			return nil, fmt.Errorf("invalid mirror service %q: %v", mirrorService.Name, err)
+		}
+		log.FromContext(ctx).Debugf("Adding mirror service %s with %d%% of the requests", mirrorService.Name, mirrorService.Percent)
+	}
+
+	return mirroring, nil
+}
+
+func (m *Manager) getFailoverServiceHandler(
+	ctx context.Context,
+	serviceName string,
+	service *config.FailoverService,
+	responseModifier func(*http.Response) error,
+) (http.Handler, error) {
+	childCtx, err := withParentService(ctx, serviceName)
+	if err != nil {
+		return nil, err
+	}
+
+	if service.Service == "" {
+		return nil, fmt.Errorf("the failover service %q doesn't have any main service", serviceName)
+	}
+
+	if service.Fallback == "" {
+		return nil, fmt.Errorf("the failover service %q doesn't have any fallback service", serviceName)
+	}
+
+	statusCodes, err := types.NewHTTPCodeRanges(service.StatusCodes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid status codes for the failover service %q: %v", serviceName, err)
+	}
+
+	handler, err := m.BuildHTTP(childCtx, service.Service, responseModifier)
+	if err != nil {
+		return nil, fmt.Errorf("error building service %q: %v", service.Service, err)
+	}
+
+	fallback, err := m.BuildHTTP(childCtx, service.Fallback, responseModifier)
+	if err != nil {
+		return nil, fmt.Errorf("error building fallback service %q: %v", service.Fallback, err)
+	}
+
+	// The status of the main service is kept up to date in the runtime configuration by the health checks.
+	runtimeConfig := &config.RuntimeConfiguration{Services: m.configs}
+	mainServiceName := internal.GetQualifiedName(childCtx, service.Service)
+	isUp := runtimeConfig.ServiceUpFunc(mainServiceName)
+
+	failoverHandler := failover.New(serviceName, handler, fallback, isUp)
+	failoverHandler.SetStatusCodes(statusCodes)
+	if service.MaxBodySize > 0 {
+		failoverHandler.SetMaxBodySize(service.MaxBodySize)
+	}
+
+	return failoverHandler, nil
+}
+
+// withParentService returns a context keeping track of the services referencing other services,
+// so the recursions between them can be detected.
+func withParentService(ctx context.Context, serviceName string) (context.Context, error) {
+	parents, _ := ctx.Value(parentServicesKey).([]string)
+	for _, parent := range parents {
+		if parent == serviceName {
+			return nil, fmt.Errorf("recursion detected in service %q", serviceName)
+		}
+	}
+
+	return context.WithValue(ctx, parentServicesKey, append(parents[:len(parents):len(parents)], serviceName)), nil
+}
+
+func countServiceTypes(service *config.Service) int {
+	var count int
+	if service.LoadBalancer != nil {
+		count++
+	}
+	if service.Weighted != nil {
+		count++
+	}
+	if service.Mirroring != nil {
+		count++
+	}
+	if service.Failover != nil {
+		count++
+	}
+	return count
 }
 
 func (m *Manager) getLoadBalancerServiceHandler(
@@ -77,7 +287,22 @@ func (m *Manager) getLoadBalancerServiceHandler(
 	service *config.LoadBalancerService,
 	responseModifier func(*http.Response) error,
 ) (http.Handler, error) {
-	fwd, err := buildProxy(service.PassHostHeader, service.ResponseForwarding, m.defaultRoundTripper, m.bufferPool, responseModifier)
+	// The hedged requests are balanced like the first one, thus would be sent to the same server with a server affinity.
+	if service.Hedging != nil && (service.Stickiness != nil || service.Strategy == config.StrategyConsistentHash) {
+		return nil, fmt.Errorf("error configuring hedging for service %s: hedging cannot be combined with stickiness or the %s strategy", serviceName, config.StrategyConsistentHash)
+	}
+
+	// The hash ring would be rebuilt at each step of the ramp up, with ten times more nodes per server.
+	if service.SlowStart != "" && service.Strategy == config.StrategyConsistentHash {
+		return nil, fmt.Errorf("error configuring slow start for service %s: slow start cannot be combined with the %s strategy", serviceName, config.StrategyConsistentHash)
+	}
+
+	roundTripper, err := m.getRoundTripper(ctx, service.ServersTransport)
+	if err != nil {
+		return nil, err
+	}
+
+	fwd, err := buildProxy(service.PassHostHeader, service.ResponseForwarding, roundTripper, m.bufferPool, responseModifier)
 	if err != nil {
 		return nil, err
 	}
@@ -91,24 +316,50 @@ func (m *Manager) getLoadBalancerServiceHandler(
 		return nil, err
 	}
 
+	var passiveHealthCheck *healthcheck.PassiveHealthCheck
+	if service.PassiveHealthCheck != nil {
+		opts := buildPassiveHealthCheckOptions(ctx, serviceName, service.PassiveHealthCheck)
+		opts.EjectedGauge = m.metricsRegistry.BackendServerEjectedGauge()
+
+		passiveHealthCheck = healthcheck.NewPassiveHealthCheck(ctx, handler, opts, serviceName, m.configs[serviceName])
+		handler = passiveHealthCheck
+		m.passiveHealthChecks = append(m.passiveHealthChecks, passiveHealthCheck)
+	}
+
 	balancer, err := m.getLoadBalancer(ctx, serviceName, service, handler)
 	if err != nil {
 		return nil, err
 	}
 
+	if passiveHealthCheck != nil {
+		passiveHealthCheck.SetBalancer(balancer)
+	}
+
 	// TODO rename and checks
 	m.balancers[serviceName] = append(m.balancers[serviceName], balancer)
 
 	// Empty (backend with no servers)
-	return emptybackendhandler.New(balancer), nil
+	handler = emptybackendhandler.New(balancer)
+
+	if service.Hedging != nil {
+		hedgesCounter := m.metricsRegistry.BackendHedgesCounter().With("backend", serviceName)
+		wonCounter := m.metricsRegistry.BackendHedgesWonCounter().With("backend", serviceName)
+
+		handler, err = hedging.New(handler, service.Hedging, hedgesCounter, wonCounter)
+		if err != nil {
+			return nil, fmt.Errorf("error configuring hedging for service %s: %v", serviceName, err)
+		}
+	}
+
+	return handler, nil
 }
 
 // LaunchHealthCheck Launches the health checks.
-func (m *Manager) LaunchHealthCheck() {
+func (m *Manager) LaunchHealthCheck(rootCtx context.Context) {
 	backendConfigs := make(map[string]*healthcheck.BackendConfig)
 
 	for serviceName, balancers := range m.balancers {
-		ctx := log.With(context.Background(), log.Str(log.ServiceName, serviceName))
+		ctx := log.With(rootCtx, log.Str(log.ServiceName, serviceName))
 
 		// TODO aggregate
 		balancer := balancers[0]
@@ -117,11 +368,21 @@ func (m *Manager) LaunchHealthCheck() {
 		service := m.configs[serviceName].LoadBalancer
 
 		// Health Check
+		// The status of the servers is kept up to date in the service info, as the failover services rely on it.
+		lbsu := healthcheck.NewLBStatusUpdater(balancer, m.configs[serviceName])
+
 		var backendHealthCheck *healthcheck.BackendConfig
-		if hcOpts := buildHealthCheckOptions(ctx, balancer, serviceName, service.HealthCheck); hcOpts != nil {
+		if hcOpts := buildHealthCheckOptions(ctx, lbsu, serviceName, service.HealthCheck); hcOpts != nil {
 			log.FromContext(ctx).Debugf("Setting up healthcheck for service %s with %s", serviceName, *hcOpts)
 
-			hcOpts.Transport = m.defaultRoundTripper
+			transport, err := m.getRoundTripper(internal.AddProviderInContext(ctx, serviceName), service.ServersTransport)
+			if err != nil {
+				log.FromContext(ctx).Errorf("Could not set up the healthcheck for service %s: %v", serviceName, err)
+				continue
+			}
+
+			hcOpts.Transport = transport
+			hcOpts.TLSConfig = tlsClientConfig(transport)
 			backendHealthCheck = healthcheck.NewBackendConfig(*hcOpts, serviceName)
 		}
 
@@ -130,17 +391,51 @@ func (m *Manager) LaunchHealthCheck() {
 		}
 	}
 
-	// FIXME metrics and context
-	healthcheck.GetHealthCheck().SetBackendsConfiguration(context.TODO(), backendConfigs)
+	healthcheck.GetHealthCheck(m.metricsRegistry).SetBackendsConfiguration(rootCtx, backendConfigs)
+
+	m.stateManager.prune(m.configs)
+	m.stateManager.discoveries.Launch(rootCtx, m.discoveries)
+	m.stateManager.setPassiveHealthChecks(m.passiveHealthChecks)
+}
+
+// getRoundTripper returns the round tripper of the servers transport with the given name,
+// qualified with the provider of the context, or the default round tripper if the name is empty.
+func (m *Manager) getRoundTripper(ctx context.Context, serversTransport string) (http.RoundTripper, error) {
+	if serversTransport == "" {
+		return m.roundTripperManager.Get("")
+	}
+	return m.roundTripperManager.Get(internal.GetQualifiedName(ctx, serversTransport))
 }
 
 func buildHealthCheckOptions(ctx context.Context, lb healthcheck.BalancerHandler, backend string, hc *config.HealthCheck) *healthcheck.Options {
-	if hc == nil || hc.Path == "" {
+	if hc == nil || (hc.Path == "" && hc.Mode != healthcheck.ModeGRPC) {
 		return nil
 	}
 
 	logger := log.FromContext(ctx)
 
+	switch hc.Mode {
+	case "", healthcheck.ModeHTTP, healthcheck.ModeGRPC:
+	default:
+		logger.Errorf("Unknown health check mode for backend '%s': %s", backend, hc.Mode)
+		return nil
+	}
+
+	statusCodes, err := types.NewHTTPCodeRanges(hc.StatusCodes)
+	if err != nil {
+		logger.Errorf("Illegal health check status codes for backend '%s': %s", backend, err)
+		return nil
+	}
+
+	var bodyRegexp *regexp.Regexp
+	if hc.BodyRegexp != "" {
+		bodyRegexp, err = regexp.Compile(hc.BodyRegexp)
+		if err != nil {
+			logger.Errorf("Illegal health check body regexp for backend '%s': %s", backend, err)
+			return nil
+		}
+	}
+
 	interval := defaultHealthCheckInterval
 	if hc.Interval != "" {
 		intervalOverride, err := time.ParseDuration(hc.Interval)
@@ -180,36 +475,227 @@ func buildHealthCheckOptions(ctx context.Context, lb healthcheck.BalancerHandler
 		LB:       lb,
 		Hostname: hc.Hostname,
 		Headers:  hc.Headers,
+
+		Mode:             hc.Mode,
+		Method:           hc.Method,
+		StatusCodes:      statusCodes,
+		BodyContains:     hc.BodyContains,
+		BodyRegexp:       bodyRegexp,
+		GRPCServiceName:  hc.GRPCServiceName,
+		SuccessThreshold: hc.SuccessThreshold,
+		FailureThreshold: hc.FailureThreshold,
+	}
+}
+
+func buildPassiveHealthCheckOptions(ctx context.Context, backend string, hc *config.PassiveHealthCheck) healthcheck.PassiveOptions {
+	opts := healthcheck.PassiveOptions{
+		MaxConsecutiveErrors: defaultPassiveHealthCheckMaxConsecutiveErrors,
+		Window:               parsePassiveHealthCheckDuration(ctx, backend, "window", hc.Window, defaultPassiveHealthCheckWindow),
+		BaseEjectionTime:     parsePassiveHealthCheckDuration(ctx, backend, "base ejection time", hc.BaseEjectionTime, defaultPassiveHealthCheckBaseEjectionTime),
+		MaxEjectionTime:      parsePassiveHealthCheckDuration(ctx, backend, "max ejection time", hc.MaxEjectionTime, defaultPassiveHealthCheckMaxEjectionTime),
+		MaxEjectionPercent:   defaultPassiveHealthCheckMaxEjectionPercent,
+	}
+
+	if hc.MaxConsecutiveErrors > 0 {
+		opts.MaxConsecutiveErrors = hc.MaxConsecutiveErrors
+	}
+
+	if hc.MaxEjectionPercent > 0 {
+		opts.MaxEjectionPercent = hc.MaxEjectionPercent
+	}
+
+	if opts.MaxEjectionTime < opts.BaseEjectionTime {
+		log.FromContext(ctx).Warnf("Passive health check max ejection time for backend '%s' is lower than the base ejection time. Max ejection time set to the base ejection time (%s).", backend, opts.BaseEjectionTime)
+		opts.MaxEjectionTime = opts.BaseEjectionTime
+	}
+
+	return opts
+}
+
+func parsePassiveHealthCheckDuration(ctx context.Context, backend, name, value string, defaultValue time.Duration) time.Duration {
+	if value == "" {
+		return defaultValue
+	}
+
+	duration, err := time.ParseDuration(value)
+	switch {
+	case err != nil:
+		log.FromContext(ctx).Errorf("Illegal passive health check %s for backend '%s': %s", name, backend, err)
+	case duration <= 0:
+		log.FromContext(ctx).Errorf("Passive health check %s smaller than zero for backend '%s'", name, backend)
+	default:
+		return duration
 	}
+
+	return defaultValue
 }
 
 func (m *Manager) getLoadBalancer(ctx context.Context, serviceName string, service *config.LoadBalancerService, fwd http.Handler) (healthcheck.BalancerHandler, error) {
 	logger := log.FromContext(ctx)
 	logger.Debug("Creating load-balancer")
 
-	var options []roundrobin.LBOption
+	var discovery *dnsdiscovery.Balancer
+	if service.DNSDiscovery != nil || hasSRVServer(service.Servers) {
+		var err error
+		discovery, err = dnsdiscovery.New(serviceName, service.DNSDiscovery, m.configs[serviceName], m.stateManager.discoveries, fwd)
+		if err != nil {
+			return nil, fmt.Errorf("error configuring DNS discovery for service %s: %v", serviceName, err)
+		}
+		fwd = discovery.Forwarder()
+	}
 
-	var cookieName string
-	if stickiness := service.Stickiness; stickiness != nil {
-		cookieName = cookie.GetName(stickiness.CookieName, serviceName)
-		opts := roundrobin.CookieOptions{HTTPOnly: stickiness.HTTPOnlyCookie, Secure: stickiness.SecureCookie}
-		options = append(options, roundrobin.EnableStickySession(roundrobin.NewStickySessionWithOptions(cookieName, opts)))
-		logger.Debugf("Sticky session cookie name: %v", cookieName)
+	var stickiness *sticky.Balancer
+	if service.Stickiness != nil {
+		var err error
+		stickiness, err = sticky.New(serviceName, service.Stickiness, fwd)
+		if err != nil {
+			return nil, fmt.Errorf("error configuring stickiness for service %s: %v", serviceName, err)
+		}
+		fwd = stickiness.Forwarder()
+		logger.Debugf("Sticky session cookie name: %v", stickiness.CookieName())
 	}
 
-	lb, err := roundrobin.New(fwd, options...)
+	lb, err := newBalancer(service, fwd)
 	if err != nil {
 		return nil, err
 	}
 
+	var slowStart *slowstart.Balancer
+	if duration := parseSlowStart(ctx, serviceName, service.SlowStart); duration > 0 {
+		slowStart = slowstart.New(serviceName, lb, duration, m.stateManager.startTimes)
+		lb = slowStart
+	}
+
+	if stickiness != nil {
+		stickiness.SetBalancer(lb)
+		lb = stickiness
+	}
+
 	lbsu := healthcheck.NewLBStatusUpdater(lb, m.configs[serviceName])
-	if err := m.upsertServers(ctx, lbsu, service.Servers); err != nil {
+
+	if discovery != nil {
+		discovery.SetBalancer(lbsu)
+		if err := addTargets(ctx, discovery, service.Servers); err != nil {
+			return nil, fmt.Errorf("error configuring DNS discovery for service %s: %v", serviceName, err)
+		}
+
+		// The servers previously discovered are restored before the slow start forgets the ones which are not part of the service anymore.
+		// The targets are resolved in the background, once the discovery is launched with the health checks.
+		discovery.Restore(ctx)
+		m.discoveries = append(m.discoveries, discovery)
+		lb = discovery
+	} else if err := m.upsertServers(ctx, lbsu, service.Servers); err != nil {
 		return nil, fmt.Errorf("error configuring load balancer for service %s: %v", serviceName, err)
 	}
 
+	if slowStart != nil {
+		slowStart.Prune()
+	}
+
 	return lb, nil
 }
 
+// parseSlowStart returns the slow start duration of the service, or zero if the slow start is disabled.
+func parseSlowStart(ctx context.Context, backend, value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	duration, err := time.ParseDuration(value)
+	switch {
+	case err != nil:
+		log.FromContext(ctx).Errorf("Illegal slow start duration for backend '%s': %s", backend, err)
+	case duration < 0:
+		log.FromContext(ctx).Errorf("Slow start duration smaller than zero for backend '%s'", backend)
+	default:
+		return duration
+	}
+
+	return 0
+}
+
+func newBalancer(service *config.LoadBalancerService, fwd http.Handler) (healthcheck.BalancerHandler, error) {
+	switch service.Strategy {
+	case "", config.StrategyRoundRobin:
+		return roundrobin.New(fwd)
+	case config.StrategyLeastConnections:
+		return strategy.NewLeastConnections(fwd), nil
+	case config.StrategyEWMA:
+		return strategy.NewEWMA(fwd), nil
+	case config.StrategyConsistentHash:
+		key, err := buildHashKey(service.ConsistentHash)
+		if err != nil {
+			return nil, err
+		}
+		return strategy.NewConsistentHash(fwd, key), nil
+	default:
+		return nil, fmt.Errorf("unknown load-balancing strategy %q", service.Strategy)
+	}
+}
+
+func buildHashKey(hash *config.ConsistentHash) (strategy.HashKeyFunc, error) {
+	if hash == nil {
+		return nil, errors.New("the consistentHash strategy requires a consistentHash configuration")
+	}
+
+	var keys []strategy.HashKeyFunc
+	if hash.Header != "" {
+		keys = append(keys, strategy.HeaderKey(hash.Header))
+	}
+	if hash.Cookie != "" {
+		keys = append(keys, strategy.CookieKey(hash.Cookie))
+	}
+	if hash.Path {
+		keys = append(keys, strategy.PathKey)
+	}
+	if hash.IPStrategy != nil {
+		ipStrategy, err := hash.IPStrategy.Get()
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, strategy.IPKey(ipStrategy))
+	}
+
+	if len(keys) != 1 {
+		return nil, errors.New("the consistentHash configuration must define exactly one of header, cookie, path or ipStrategy")
+	}
+
+	return keys[0], nil
+}
+
+func hasSRVServer(servers []config.Server) bool {
+	for _, srv := range servers {
+		if dnsdiscovery.IsSRV(srv.URL) {
+			return true
+		}
+	}
+	return false
+}
+
+// addTargets adds the servers of the service to the DNS discovery, which resolves them into the servers of the load balancer.
+func addTargets(ctx context.Context, discovery *dnsdiscovery.Balancer, servers []config.Server) error {
+	logger := log.FromContext(ctx)
+
+	for name, srv := range servers {
+		u, err := url.Parse(srv.URL)
+		if err != nil {
+			return fmt.Errorf("error parsing server URL %s: %v", srv.URL, err)
+		}
+
+		logger.WithField(log.ServerName, name).Debugf("Discovering servers %d from %s", name, u)
+
+		weight := srv.Weight
+		if weight <= 0 {
+			weight = 1
+		}
+
+		if err := discovery.AddTarget(u, weight); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (m *Manager) upsertServers(ctx context.Context, lb healthcheck.BalancerHandler, servers []config.Server) error {
 	logger := log.FromContext(ctx)
 
@@ -221,7 +707,12 @@ func (m *Manager) upsertServers(ctx context.Context, lb healthcheck.BalancerHand
 
 		logger.WithField(log.ServerName, name).Debugf("Creating server %d %s", name, u)
 
-		if err := lb.UpsertServer(u, roundrobin.Weight(1)); err != nil {
+		weight := srv.Weight
+		if weight <= 0 {
+			weight = 1
+		}
+
+		if err := lb.UpsertServer(u, roundrobin.Weight(weight)); err != nil {
 			return fmt.Errorf("error adding server %s to load balancer: %v", srv.URL, err)
 		}
 
@@ -6,8 +6,10 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/containous/traefik/pkg/config"
+	"github.com/containous/traefik/pkg/metrics"
 	"github.com/containous/traefik/pkg/server/internal"
 	"github.com/containous/traefik/pkg/testhelpers"
 	"github.com/stretchr/testify/assert"
@@ -59,6 +61,77 @@ func TestGetLoadBalancer(t *testing.T) {
 			fwd:         &MockForwarder{},
 			expectError: false,
 		},
+		{
+			desc:        "Succeeds with the least connections strategy",
+			serviceName: "test",
+			service: &config.LoadBalancerService{
+				Strategy: config.StrategyLeastConnections,
+				Servers: []config.Server{
+					{
+						URL: "http://foo",
+					},
+				},
+			},
+			fwd:         &MockForwarder{},
+			expectError: false,
+		},
+		{
+			desc:        "Succeeds with the ewma strategy",
+			serviceName: "test",
+			service: &config.LoadBalancerService{
+				Strategy: config.StrategyEWMA,
+				Servers: []config.Server{
+					{
+						URL: "http://foo",
+					},
+				},
+			},
+			fwd:         &MockForwarder{},
+			expectError: false,
+		},
+		{
+			desc:        "Succeeds with the consistent hash strategy",
+			serviceName: "test",
+			service: &config.LoadBalancerService{
+				Strategy: config.StrategyConsistentHash,
+				ConsistentHash: &config.ConsistentHash{
+					IPStrategy: &config.IPStrategy{Depth: 1},
+				},
+			},
+			fwd:         &MockForwarder{},
+			expectError: false,
+		},
+		{
+			desc:        "Fails when the consistent hash strategy has no key",
+			serviceName: "test",
+			service: &config.LoadBalancerService{
+				Strategy: config.StrategyConsistentHash,
+			},
+			fwd:         &MockForwarder{},
+			expectError: true,
+		},
+		{
+			desc:        "Fails when the consistent hash strategy has several keys",
+			serviceName: "test",
+			service: &config.LoadBalancerService{
+				Strategy: config.StrategyConsistentHash,
+				ConsistentHash: &config.ConsistentHash{
+					Header: "X-Foo",
+					Path:   true,
+				},
+			},
+			fwd:         &MockForwarder{},
+			expectError: true,
+		},
+		{
+			desc:        "Fails when the strategy is unknown",
+			serviceName: "test",
+			service: &config.LoadBalancerService{
+				Strategy: "foo",
+			},
+			fwd:         &MockForwarder{},
+			expectError: true,
+		},
 	}
 
 	for _, test := range testCases {
@@ -79,7 +152,7 @@ func TestGetLoadBalancer(t *testing.T) {
 }
 
 func TestGetLoadBalancerServiceHandler(t *testing.T) {
-	sm := NewManager(nil, http.DefaultTransport)
+	sm := NewManager(nil, NewRoundTripperManager(http.DefaultTransport), NewStateManager(), metrics.NewVoidRegistry())
 
 	server1 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("X-From", "first")
@@ -91,6 +164,12 @@ func TestGetLoadBalancerServiceHandler(t *testing.T) {
 	}))
 	defer server2.Close()
 
+	serverFailing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-From", "failing")
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer serverFailing.Close()
+
 	serverPassHost := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("X-From", "passhost")
 		assert.Equal(t, "callme", r.Host)
@@ -195,6 +274,92 @@ func TestGetLoadBalancerServiceHandler(t *testing.T) {
 				},
 			},
 		},
+		{
+			desc:        "Load balances between the two servers with the least connections strategy",
+			serviceName: "test",
+			service: &config.LoadBalancerService{
+				Strategy: config.StrategyLeastConnections,
+				Servers: []config.Server{
+					{
+						URL: server1.URL,
+					},
+					{
+						URL: server2.URL,
+					},
+				},
+			},
+			expected: []ExpectedResult{
+				{
+					StatusCode: http.StatusOK,
+					XFrom:      "first",
+				},
+				{
+					StatusCode: http.StatusOK,
+					XFrom:      "second",
+				},
+			},
+		},
+		{
+			desc:        "Always call the same server when stickiness is true with the ewma strategy",
+			serviceName: "test",
+			service: &config.LoadBalancerService{
+				Strategy:   config.StrategyEWMA,
+				Stickiness: &config.Stickiness{},
+				Servers: []config.Server{
+					{
+						URL: server1.URL,
+					},
+					{
+						URL: server2.URL,
+					},
+				},
+			},
+			expected: []ExpectedResult{
+				{
+					StatusCode: http.StatusOK,
+					XFrom:      "first",
+				},
+				{
+					StatusCode: http.StatusOK,
+					XFrom:      "first",
+				},
+				{
+					StatusCode: http.StatusOK,
+					XFrom:      "first",
+				},
+			},
+		},
+		{
+			desc:        "Ejects the failing server with a passive health check",
+			serviceName: "test",
+			service: &config.LoadBalancerService{
+				PassiveHealthCheck: &config.PassiveHealthCheck{
+					MaxConsecutiveErrors: 1,
+				},
+				Servers: []config.Server{
+					{
+						URL: serverFailing.URL,
+					},
+					{
+						URL: server2.URL,
+					},
+				},
+			},
+			expected: []ExpectedResult{
+				{
+					StatusCode: http.StatusInternalServerError,
+					XFrom:      "failing",
+				},
+				{
+					StatusCode: http.StatusOK,
+					XFrom:      "second",
+				},
+				{
+					StatusCode: http.StatusOK,
+					XFrom:      "second",
+				},
+			},
+		},
 		{
 			desc:        "Sticky Cookie's options set correctly",
 			serviceName: "test",
@@ -324,6 +489,58 @@ func TestManager_Build(t *testing.T) {
 			},
 			providerName: "provider-1",
 		},
+		{
+			desc:        "Weighted service",
+			serviceName: "canary@provider-1",
+			configs: map[string]*config.ServiceInfo{
+				"canary@provider-1": {
+					Service: &config.Service{
+						Weighted: &config.WeightedService{
+							Services: []config.WeightedServiceRef{
+								{Name: "v1", Weight: 3},
+								{Name: "v2@provider-2", Weight: 1},
+							},
+						},
+					},
+				},
+				"v1@provider-1": {
+					Service: &config.Service{
+						LoadBalancer: &config.LoadBalancerService{},
+					},
+				},
+				"v2@provider-2": {
+					Service: &config.Service{
+						LoadBalancer: &config.LoadBalancerService{},
+					},
+				},
+			},
+		},
+		{
+			desc:        "Mirroring service",
+			serviceName: "mirrored@provider-1",
+			configs: map[string]*config.ServiceInfo{
+				"mirrored@provider-1": {
+					Service: &config.Service{
+						Mirroring: &config.MirroringService{
+							Service: "v1",
+							Mirrors: []config.MirrorService{
+								{Name: "v2", Percent: 10},
+							},
+						},
+					},
+				},
+				"v1@provider-1": {
+					Service: &config.Service{
+						LoadBalancer: &config.LoadBalancerService{},
+					},
+				},
+				"v2@provider-1": {
+					Service: &config.Service{
+						LoadBalancer: &config.LoadBalancerService{},
+					},
+				},
+			},
+		},
 	}
 
 	for _, test := range testCases {
@@ -331,7 +548,7 @@ func TestManager_Build(t *testing.T) {
 		t.Run(test.desc, func(t *testing.T) {
 			t.Parallel()
 
-			manager := NewManager(test.configs, http.DefaultTransport)
+			manager := NewManager(test.configs, NewRoundTripperManager(http.DefaultTransport), NewStateManager(), metrics.NewVoidRegistry())
 
 			ctx := context.Background()
 			if len(test.providerName) > 0 {
@@ -344,4 +561,381 @@ func TestManager_Build(t *testing.T) {
 	}
 }
 
+func TestManager_BuildWeighted(t *testing.T) {
+	server1 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-From", "first")
+	}))
+	defer server1.Close()
+
+	server2 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-From", "second")
+	}))
+	defer server2.Close()
+
+	configs := map[string]*config.ServiceInfo{
+		"canary@provider-1": {
+			Service: &config.Service{
+				Weighted: &config.WeightedService{
+					Services: []config.WeightedServiceRef{
+						{Name: "v1", Weight: 3},
+						{Name: "v2", Weight: 1},
+					},
+				},
+			},
+		},
+		"v1@provider-1": {
+			Service: &config.Service{
+				LoadBalancer: &config.LoadBalancerService{
+					Servers: []config.Server{{URL: server1.URL}},
+				},
+			},
+		},
+		"v2@provider-1": {
+			Service: &config.Service{
+				LoadBalancer: &config.LoadBalancerService{
+					Servers: []config.Server{{URL: server2.URL}},
+				},
+			},
+		},
+	}
+
+	manager := NewManager(configs, NewRoundTripperManager(http.DefaultTransport), NewStateManager(), metrics.NewVoidRegistry())
+
+	handler, err := manager.BuildHTTP(context.Background(), "canary@provider-1", nil)
+	require.NoError(t, err)
+
+	froms := make(map[string]int)
+	for i := 0; i < 8; i++ {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://callme", nil))
+		froms[recorder.Header().Get("X-From")]++
+	}
+
+	assert.Equal(t, map[string]int{"first": 6, "second": 2}, froms)
+}
+
+func TestManager_BuildMirroring(t *testing.T) {
+	mirrored := make(chan string, 1)
+
+	server1 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-From", "main")
+	}))
+	defer server1.Close()
+
+	server2 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mirrored <- r.URL.Path
+	}))
+	defer server2.Close()
+
+	configs := map[string]*config.ServiceInfo{
+		"mirrored@provider-1": {
+			Service: &config.Service{
+				Mirroring: &config.MirroringService{
+					Service: "main",
+					Mirrors: []config.MirrorService{
+						{Name: "mirror", Percent: 100},
+					},
+				},
+			},
+		},
+		"main@provider-1": {
+			Service: &config.Service{
+				LoadBalancer: &config.LoadBalancerService{
+					Servers: []config.Server{{URL: server1.URL}},
+				},
+			},
+		},
+		"mirror@provider-1": {
+			Service: &config.Service{
+				LoadBalancer: &config.LoadBalancerService{
+					Servers: []config.Server{{URL: server2.URL}},
+				},
+			},
+		},
+	}
+
+	manager := NewManager(configs, NewRoundTripperManager(http.DefaultTransport), NewStateManager(), metrics.NewVoidRegistry())
+
+	handler, err := manager.BuildHTTP(context.Background(), "mirrored@provider-1", nil)
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://callme/foo", nil))
+	assert.Equal(t, "main", recorder.Header().Get("X-From"))
+
+	select {
+	case path := <-mirrored:
+		assert.Equal(t, "/foo", path)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the request has not been mirrored")
+	}
+}
+
+func TestManager_BuildFailover(t *testing.T) {
+	server1 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-From", "main")
+		if r.URL.Path == "/error" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server1.Close()
+
+	server2 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-From", "fallback")
+	}))
+	defer server2.Close()
+
+	configs := map[string]*config.ServiceInfo{
+		"failover@provider-1": {
+			Service: &config.Service{
+				Failover: &config.FailoverService{
+					Service:     "main",
+					Fallback:    "fallback",
+					StatusCodes: []string{"500-599"},
+				},
+			},
+		},
+		"main@provider-1": {
+			Service: &config.Service{
+				LoadBalancer: &config.LoadBalancerService{
+					Servers: []config.Server{{URL: server1.URL}},
+				},
+			},
+		},
+		"fallback@provider-1": {
+			Service: &config.Service{
+				LoadBalancer: &config.LoadBalancerService{
+					Servers: []config.Server{{URL: server2.URL}},
+				},
+			},
+		},
+	}
+
+	manager := NewManager(configs, NewRoundTripperManager(http.DefaultTransport), NewStateManager(), metrics.NewVoidRegistry())
+
+	handler, err := manager.BuildHTTP(context.Background(), "failover@provider-1", nil)
+	require.NoError(t, err)
+
+	serve := func(path string) string {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://callme"+path, nil))
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		return recorder.Header().Get("X-From")
+	}
+
+	assert.Equal(t, "main", serve("/foo"))
+	assert.Equal(t, "fallback", serve("/error"))
+
+	// The main service has no healthy servers.
+	configs["main@provider-1"].UpdateStatus(server1.URL, "DOWN")
+	assert.Equal(t, "fallback", serve("/foo"))
+
+	// The main service has recovered.
+	configs["main@provider-1"].UpdateStatus(server1.URL, "UP")
+	assert.Equal(t, "main", serve("/foo"))
+}
+
+func TestManager_BuildErrors(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		configs map[string]*config.ServiceInfo
+	}{
+		{
+			desc: "Unknown service",
+			configs: map[string]*config.ServiceInfo{
+				"canary@provider-1": {
+					Service: &config.Service{
+						Weighted: &config.WeightedService{
+							Services: []config.WeightedServiceRef{{Name: "unknown", Weight: 1}},
+						},
+					},
+				},
+			},
+		},
+		{
+			desc: "Negative weight",
+			configs: map[string]*config.ServiceInfo{
+				"canary@provider-1": {
+					Service: &config.Service{
+						Weighted: &config.WeightedService{
+							Services: []config.WeightedServiceRef{{Name: "v1", Weight: -1}},
+						},
+					},
+				},
+				"v1@provider-1": {
+					Service: &config.Service{
+						LoadBalancer: &config.LoadBalancerService{},
+					},
+				},
+			},
+		},
+		{
+			desc: "Recursive services",
+			configs: map[string]*config.ServiceInfo{
+				"canary@provider-1": {
+					Service: &config.Service{
+						Weighted: &config.WeightedService{
+							Services: []config.WeightedServiceRef{{Name: "other", Weight: 1}},
+						},
+					},
+				},
+				"other@provider-1": {
+					Service: &config.Service{
+						Weighted: &config.WeightedService{
+							Services: []config.WeightedServiceRef{{Name: "canary", Weight: 1}},
+						},
+					},
+				},
+			},
+		},
+		{
+			desc: "Mirroring without main service",
+			configs: map[string]*config.ServiceInfo{
+				"canary@provider-1": {
+					Service: &config.Service{
+						Mirroring: &config.MirroringService{},
+					},
+				},
+			},
+		},
+		{
+			desc: "Mirror with an invalid percentage",
+			configs: map[string]*config.ServiceInfo{
+				"canary@provider-1": {
+					Service: &config.Service{
+						Mirroring: &config.MirroringService{
+							Service: "v1",
+							Mirrors: []config.MirrorService{{Name: "v1", Percent: 200}},
+						},
+					},
+				},
+				"v1@provider-1": {
+					Service: &config.Service{
+						LoadBalancer: &config.LoadBalancerService{},
+					},
+				},
+			},
+		},
+		{
+			desc: "Mirroring with an invalid timeout",
+			configs: map[string]*config.ServiceInfo{
+				"canary@provider-1": {
+					Service: &config.Service{
+						Mirroring: &config.MirroringService{
+							Service: "v1",
+							Timeout: "foo",
+						},
+					},
+				},
+				"v1@provider-1": {
+					Service: &config.Service{
+						LoadBalancer: &config.LoadBalancerService{},
+					},
+				},
+			},
+		},
+		{
+			desc: "Failover without fallback service",
+			configs: map[string]*config.ServiceInfo{
+				"canary@provider-1": {
+					Service: &config.Service{
+						Failover: &config.FailoverService{
+							Service: "v1",
+						},
+					},
+				},
+				"v1@provider-1": {
+					Service: &config.Service{
+						LoadBalancer: &config.LoadBalancerService{},
+					},
+				},
+			},
+		},
+		{
+			desc: "Failover with invalid status codes",
+			configs: map[string]*config.ServiceInfo{
+				"canary@provider-1": {
+					Service: &config.Service{
+						Failover: &config.FailoverService{
+							Service:     "v1",
+							Fallback:    "v1",
+							StatusCodes: []string{"foo"},
+						},
+					},
+				},
+				"v1@provider-1": {
+					Service: &config.Service{
+						LoadBalancer: &config.LoadBalancerService{},
+					},
+				},
+			},
+		},
+		{
+			desc: "Hedging with stickiness",
+			configs: map[string]*config.ServiceInfo{
+				"canary@provider-1": {
+					Service: &config.Service{
+						LoadBalancer: &config.LoadBalancerService{
+							Stickiness: &config.Stickiness{},
+							Hedging:    &config.Hedging{},
+						},
+					},
+				},
+			},
+		},
+		{
+			desc: "Hedging with the consistentHash strategy",
+			configs: map[string]*config.ServiceInfo{
+				"canary@provider-1": {
+					Service: &config.Service{
+						LoadBalancer: &config.LoadBalancerService{
+							Strategy:       config.StrategyConsistentHash,
+							ConsistentHash: &config.ConsistentHash{Header: "X-Key"},
+							Hedging:        &config.Hedging{},
+						},
+					},
+				},
+			},
+		},
+		{
+			desc: "Slow start with the consistentHash strategy",
+			configs: map[string]*config.ServiceInfo{
+				"canary@provider-1": {
+					Service: &config.Service{
+						LoadBalancer: &config.LoadBalancerService{
+							Strategy:       config.StrategyConsistentHash,
+							ConsistentHash: &config.ConsistentHash{Header: "X-Key"},
+							SlowStart:      "30s",
+						},
+					},
+				},
+			},
+		},
+		{
+			desc: "Service with several types",
+			configs: map[string]*config.ServiceInfo{
+				"canary@provider-1": {
+					Service: &config.Service{
+						LoadBalancer: &config.LoadBalancerService{},
+						Weighted:     &config.WeightedService{},
+					},
+				},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			manager := NewManager(test.configs, NewRoundTripperManager(http.DefaultTransport), NewStateManager(), metrics.NewVoidRegistry())
+
+			_, err := manager.BuildHTTP(context.Background(), "canary@provider-1", nil)
+			require.Error(t, err)
+			assert.Error(t, test.configs["canary@provider-1"].Err)
+		})
+	}
+}
+
 // FIXME Add healthcheck tests
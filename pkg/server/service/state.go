@@ -0,0 +1,54 @@
+package service
+
+import (
+	"sync"
+
+	"github.com/containous/traefik/pkg/config"
+	"github.com/containous/traefik/pkg/healthcheck"
+	"github.com/containous/traefik/pkg/server/service/loadbalancer/dnsdiscovery"
+	"github.com/containous/traefik/pkg/server/service/loadbalancer/slowstart"
+)
+
+// StateManager keeps the state of the services which outlives their handlers, rebuilt with each new configuration,
+// such as the servers discovered from the DNS, or the start times of the servers ramping up.
+type StateManager struct {
+	discoveries *dnsdiscovery.Store
+	startTimes  *slowstart.StartTimes
+
+	passiveHealthChecksMu sync.Mutex
+	passiveHealthChecks   map[*healthcheck.PassiveHealthCheck]struct{}
+}
+
+// NewStateManager creates a new StateManager.
+func NewStateManager() *StateManager {
+	return &StateManager{
+		discoveries: dnsdiscovery.NewStore(),
+		startTimes:  slowstart.NewStartTimes(),
+	}
+}
+
+// setPassiveHealthChecks sets the passive health checks of the current configuration,
+// and stops the ones of the former configuration, so that they do not return servers to the former balancers.
+func (s *StateManager) setPassiveHealthChecks(checks []*healthcheck.PassiveHealthCheck) {
+	s.passiveHealthChecksMu.Lock()
+	defer s.passiveHealthChecksMu.Unlock()
+
+	current := make(map[*healthcheck.PassiveHealthCheck]struct{}, len(checks))
+	for _, check := range checks {
+		current[check] = struct{}{}
+	}
+
+	for check := range s.passiveHealthChecks {
+		if _, ok := current[check]; !ok {
+			check.Stop()
+		}
+	}
+
+	s.passiveHealthChecks = current
+}
+
+// prune forgets the state of the services which are not part of the given configurations.
+func (s *StateManager) prune(configs map[string]*config.ServiceInfo) {
+	s.discoveries.Prune(configs)
+	s.startTimes.Prune(configs)
+}
@@ -2,24 +2,41 @@ package tcp
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
 	"net"
+	"time"
 
 	"github.com/containous/traefik/pkg/config"
+	"github.com/containous/traefik/pkg/healthcheck"
 	"github.com/containous/traefik/pkg/log"
+	"github.com/containous/traefik/pkg/metrics"
 	"github.com/containous/traefik/pkg/server/internal"
 	"github.com/containous/traefik/pkg/tcp"
 )
 
+const (
+	defaultHealthCheckInterval = 30 * time.Second
+	defaultHealthCheckTimeout  = 5 * time.Second
+)
+
 // Manager is the TCPHandlers factory
 type Manager struct {
-	configs map[string]*config.TCPServiceInfo
+	configs         map[string]*config.TCPServiceInfo
+	balancers       map[string][]healthcheck.TCPBalancerHandler
+	stateManager    *StateManager
+	metricsRegistry metrics.Registry
 }
 
 // NewManager creates a new manager
-func NewManager(conf *config.RuntimeConfiguration) *Manager {
+func NewManager(conf *config.RuntimeConfiguration, stateManager *StateManager, metricsRegistry metrics.Registry) *Manager {
 	return &Manager{
-		configs: conf.TCPServices,
+		configs:         conf.TCPServices,
+		balancers:       make(map[string][]healthcheck.TCPBalancerHandler),
+		stateManager:    stateManager,
+		metricsRegistry: metricsRegistry,
 	}
 }
 
@@ -41,7 +58,29 @@ func (m *Manager) BuildTCP(rootCtx context.Context, serviceName string) (tcp.Han
 
 	logger := log.FromContext(ctx)
 
-	loadBalancer := tcp.NewRRLoadBalancer()
+	conns := m.getConnCounts(serviceQualifiedName)
+	// The open connections of the servers are exposed by the API.
+	conf.SetConnCounter(conns)
+
+	loadBalancer, err := newLoadBalancer(conf.LoadBalancer.Strategy, conns)
+	if err != nil {
+		conf.Err = fmt.Errorf("the service %q has an invalid TCP load balancer: %v", serviceQualifiedName, err)
+		return nil, conf.Err
+	}
+
+	// The status of the servers is kept up to date in the service info, so that it is exposed by the API.
+	lbsu := healthcheck.NewTCPLBStatusUpdater(loadBalancer, conf)
+
+	terminationDelay, idleTimeout := buildProxyTimeouts(ctx, serviceQualifiedName, conf.LoadBalancer)
+
+	var tlsConfig *tls.Config
+	if conf.LoadBalancer.TLS != nil {
+		tlsConfig, err = createTLSConfig(conf.LoadBalancer.TLS)
+		if err != nil {
+			conf.Err = fmt.Errorf("the service %q has an invalid TLS configuration: %v", serviceQualifiedName, err)
+			return nil, conf.Err
+		}
+	}
 
 	for name, server := range conf.LoadBalancer.Servers {
 		if _, _, err := net.SplitHostPort(server.Address); err != nil {
@@ -49,14 +88,199 @@ func (m *Manager) BuildTCP(rootCtx context.Context, serviceName string) (tcp.Han
 			continue
 		}
 
-		handler, err := tcp.NewProxy(server.Address)
+		handler, err := tcp.NewProxy(server.Address, terminationDelay, idleTimeout, conf.LoadBalancer.ProxyProtocol, tlsConfig)
 		if err != nil {
 			logger.Errorf("In service %q server %q: %v", serviceQualifiedName, server.Address, err)
 			continue
 		}
 
-		loadBalancer.AddServer(handler)
-		logger.WithField(log.ServerName, name).Debugf("Creating TCP server %d at %s", name, server.Address)
+		lbsu.AddWeightedServer(server.Address, handler, server.Weight)
+		logger.WithField(log.ServerName, name).Debugf("Creating TCP server %d at %s with weight %d", name, server.Address, server.Weight)
+	}
+
+	if conf.LoadBalancer.HealthCheck != nil {
+		m.balancers[serviceQualifiedName] = append(m.balancers[serviceQualifiedName], lbsu)
+	}
+
+	return tcp.NewMetricsHandler(loadBalancer, tcp.ConnMetrics{
+		OpenConns: m.metricsRegistry.TCPServiceOpenConnsGauge().With("service", serviceQualifiedName),
+		Duration:  m.metricsRegistry.TCPServiceConnDurationHistogram().With("service", serviceQualifiedName),
+		BytesIn:   m.metricsRegistry.TCPServiceBytesInCounter().With("service", serviceQualifiedName),
+		BytesOut:  m.metricsRegistry.TCPServiceBytesOutCounter().With("service", serviceQualifiedName),
+	}), nil
+}
+
+// buildProxyTimeouts returns the termination delay and the idle timeout of the connections to the servers of the service.
+func buildProxyTimeouts(ctx context.Context, service string, lb *config.TCPLoadBalancerService) (time.Duration, time.Duration) {
+	logger := log.FromContext(ctx)
+
+	terminationDelay := tcp.DefaultTerminationDelay
+	if lb.TerminationDelay != "" {
+		terminationDelayOverride, err := time.ParseDuration(lb.TerminationDelay)
+		if err != nil {
+			logger.Errorf("Illegal termination delay for TCP service '%s': %s", service, err)
+		} else {
+			terminationDelay = terminationDelayOverride
+		}
+	}
+
+	var idleTimeout time.Duration
+	if lb.IdleTimeout != "" {
+		idleTimeoutOverride, err := time.ParseDuration(lb.IdleTimeout)
+		switch {
+		case err != nil:
+			logger.Errorf("Illegal idle timeout for TCP service '%s': %s", service, err)
+		case idleTimeoutOverride < 0:
+			logger.Errorf("Idle timeout smaller than zero for TCP service '%s'", service)
+		default:
+			idleTimeout = idleTimeoutOverride
+		}
+	}
+
+	return terminationDelay, idleTimeout
+}
+
+// getConnCounts returns the open connections of the servers of the service,
+// shared by all its load balancers.
+func (m *Manager) getConnCounts(serviceName string) *tcp.ConnCounts {
+	return m.stateManager.getConnCounts(serviceName, func() *tcp.ConnCounts {
+		return tcp.NewConnCounts(m.metricsRegistry.BackendServerOpenConnsGauge().With("backend", serviceName))
+	})
+}
+
+// createTLSConfig creates the configuration of the TLS connections to the servers.
+func createTLSConfig(cfg *config.TCPServersTLS) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		ServerName:         cfg.ServerName,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+	}
+
+	if len(cfg.RootCAs) > 0 {
+		roots := x509.NewCertPool()
+		for _, rootCA := range cfg.RootCAs {
+			content, err := rootCA.Read()
+			if err != nil {
+				return nil, fmt.Errorf("unable to read the root CA: %v", err)
+			}
+
+			if !roots.AppendCertsFromPEM(content) {
+				return nil, errors.New("invalid root CA: no PEM certificate found")
+			}
+		}
+		tlsConfig.RootCAs = roots
+	}
+
+	for _, certificate := range cfg.Certificates {
+		certContent, err := certificate.CertFile.Read()
+		if err != nil {
+			return nil, fmt.Errorf("unable to read the client certificate: %v", err)
+		}
+
+		keyContent, err := certificate.KeyFile.Read()
+		if err != nil {
+			return nil, fmt.Errorf("unable to read the client certificate key: %v", err)
+		}
+
+		cert, err := tls.X509KeyPair(certContent, keyContent)
+		if err != nil {
+			return nil, fmt.Errorf("invalid client certificate: %v", err)
+		}
+
+		tlsConfig.Certificates = append(tlsConfig.Certificates, cert)
+	}
+
+	return tlsConfig, nil
+}
+
+func newLoadBalancer(strategy string, conns *tcp.ConnCounts) (*tcp.LoadBalancer, error) {
+	switch strategy {
+	case "", config.StrategyRoundRobin:
+		return tcp.NewWRRLoadBalancer(conns), nil
+	case config.StrategyLeastConnections:
+		return tcp.NewLeastConnLoadBalancer(conns), nil
+	case config.StrategyRandomTwoChoices:
+		return tcp.NewRandomTwoChoicesLoadBalancer(conns), nil
+	default:
+		return nil, fmt.Errorf("unknown load-balancing strategy %q", strategy)
+	}
+}
+
+// LaunchHealthCheck Launches the health checks of the services built so far.
+func (m *Manager) LaunchHealthCheck(rootCtx context.Context) {
+	serviceConfigs := make(map[string]*healthcheck.TCPServiceConfig)
+
+	for serviceName, balancers := range m.balancers {
+		ctx := log.With(rootCtx, log.Str(log.ServiceName, serviceName))
+
+		service := m.configs[serviceName].LoadBalancer
+
+		var addresses []string
+		for _, server := range service.Servers {
+			addresses = append(addresses, server.Address)
+		}
+
+		hcOpts := buildHealthCheckOptions(ctx, serviceName, service.HealthCheck)
+
+		// The servers are checked over the same kind of connection as the proxied ones.
+		if service.ProxyProtocol != nil {
+			hcOpts.ProxyProtocolVersion = service.ProxyProtocol.GetVersion()
+		}
+		if service.TLS != nil {
+			tlsConfig, err := createTLSConfig(service.TLS)
+			if err != nil {
+				log.FromContext(ctx).Errorf("Invalid TLS configuration for the health check of TCP service %s: %v", serviceName, err)
+				continue
+			}
+			hcOpts.TLSConfig = tlsConfig
+		}
+
+		log.FromContext(ctx).Debugf("Setting up healthcheck for TCP service %s with %s", serviceName, hcOpts)
+
+		serviceConfigs[serviceName] = healthcheck.NewTCPServiceConfig(hcOpts, serviceName, addresses, balancers)
+	}
+
+	healthcheck.GetTCPHealthCheck().SetServicesConfiguration(rootCtx, serviceConfigs)
+
+	m.stateManager.prune(m.configs)
+}
+
+func buildHealthCheckOptions(ctx context.Context, service string, hc *config.TCPHealthCheck) healthcheck.TCPOptions {
+	logger := log.FromContext(ctx)
+
+	interval := defaultHealthCheckInterval
+	if hc.Interval != "" {
+		intervalOverride, err := time.ParseDuration(hc.Interval)
+		switch {
+		case err != nil:
+			logger.Errorf("Illegal health check interval for TCP service '%s': %s", service, err)
+		case intervalOverride <= 0:
+			logger.Errorf("Health check interval smaller than zero for TCP service '%s'", service)
+		default:
+			interval = intervalOverride
+		}
+	}
+
+	timeout := defaultHealthCheckTimeout
+	if hc.Timeout != "" {
+		timeoutOverride, err := time.ParseDuration(hc.Timeout)
+		switch {
+		case err != nil:
+			logger.Errorf("Illegal health check timeout for TCP service '%s': %s", service, err)
+		case timeoutOverride <= 0:
+			logger.Errorf("Health check timeout smaller than zero for TCP service '%s'", service)
+		default:
+			timeout = timeoutOverride
+		}
+	}
+
+	if timeout >= interval {
+		logger.Warnf("Health check timeout for TCP service '%s' should be lower than the health check interval (%s).", service, interval)
+	}
+
+	return healthcheck.TCPOptions{
+		Interval: interval,
+		Timeout:  timeout,
+		Send:     hc.Send,
+		Expect:   hc.Expect,
 	}
-	return loadBalancer, nil
 }
@@ -1,11 +1,24 @@
 package tcp
 
 import (
+	"bufio"
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
 	"testing"
+	"time"
 
 	"github.com/containous/traefik/pkg/config"
+	"github.com/containous/traefik/pkg/metrics"
 	"github.com/containous/traefik/pkg/server/internal"
+	traefiktls "github.com/containous/traefik/pkg/tls"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -170,6 +183,61 @@ func TestManager_BuildTCP(t *testing.T) {
 			},
 			providerName: "provider-1",
 		},
+		{
+			desc:        "least connections strategy",
+			serviceName: "serviceName",
+			configs: map[string]*config.TCPServiceInfo{
+				"serviceName@provider-1": {
+					TCPService: &config.TCPService{
+						LoadBalancer: &config.TCPLoadBalancerService{
+							Strategy: config.StrategyLeastConnections,
+							Servers: []config.TCPServer{
+								{Address: "192.168.0.12:80", Weight: 2},
+							},
+						},
+					},
+				},
+			},
+			providerName: "provider-1",
+		},
+		{
+			desc:        "unknown strategy",
+			serviceName: "serviceName",
+			configs: map[string]*config.TCPServiceInfo{
+				"serviceName@provider-1": {
+					TCPService: &config.TCPService{
+						LoadBalancer: &config.TCPLoadBalancerService{
+							Strategy: "foo",
+							Servers: []config.TCPServer{
+								{Address: "192.168.0.12:80"},
+							},
+						},
+					},
+				},
+			},
+			providerName:  "provider-1",
+			expectedError: `the service "serviceName@provider-1" has an invalid TCP load balancer: unknown load-balancing strategy "foo"`,
+		},
+		{
+			desc:        "invalid TLS root CA",
+			serviceName: "serviceName",
+			configs: map[string]*config.TCPServiceInfo{
+				"serviceName@provider-1": {
+					TCPService: &config.TCPService{
+						LoadBalancer: &config.TCPLoadBalancerService{
+							Servers: []config.TCPServer{
+								{Address: "192.168.0.12:443"},
+							},
+							TLS: &config.TCPServersTLS{
+								RootCAs: []traefiktls.FileOrContent{"not a certificate"},
+							},
+						},
+					},
+				},
+			},
+			providerName:  "provider-1",
+			expectedError: `the service "serviceName@provider-1" has an invalid TLS configuration: invalid root CA: no PEM certificate found`,
+		},
 	}
 
 	for _, test := range testCases {
@@ -179,7 +247,7 @@ func TestManager_BuildTCP(t *testing.T) {
 
 			manager := NewManager(&config.RuntimeConfiguration{
 				TCPServices: test.configs,
-			})
+			}, NewStateManager(), metrics.NewVoidRegistry())
 
 			ctx := context.Background()
 			if len(test.providerName) > 0 {
@@ -198,3 +266,187 @@ func TestManager_BuildTCP(t *testing.T) {
 		})
 	}
 }
+
+func TestManager_connCounts(t *testing.T) {
+	configs := map[string]*config.TCPServiceInfo{
+		"serviceName@provider-1": {
+			TCPService: &config.TCPService{
+				LoadBalancer: &config.TCPLoadBalancerService{
+					Servers: []config.TCPServer{{Address: "192.168.0.12:80"}},
+				},
+			},
+		},
+	}
+
+	stateManager := NewStateManager()
+	ctx := internal.AddProviderInContext(context.Background(), "foobar@provider-1")
+
+	manager := NewManager(&config.RuntimeConfiguration{TCPServices: configs}, stateManager, metrics.NewVoidRegistry())
+	_, err := manager.BuildTCP(ctx, "serviceName")
+	require.NoError(t, err)
+	conns := manager.getConnCounts("serviceName@provider-1")
+
+	// The open connections are shared by the load balancers of the following configurations.
+	manager = NewManager(&config.RuntimeConfiguration{TCPServices: configs}, stateManager, metrics.NewVoidRegistry())
+	_, err = manager.BuildTCP(ctx, "serviceName")
+	require.NoError(t, err)
+	assert.True(t, conns == manager.getConnCounts("serviceName@provider-1"))
+
+	// The open connections of the services removed from the configuration are forgotten.
+	manager = NewManager(&config.RuntimeConfiguration{}, stateManager, metrics.NewVoidRegistry())
+	manager.LaunchHealthCheck(context.Background())
+	assert.Empty(t, stateManager.connCounts)
+}
+
+func TestManager_BuildTCP_healthCheck(t *testing.T) {
+	configs := map[string]*config.TCPServiceInfo{
+		"serviceName@provider-1": {
+			TCPService: &config.TCPService{
+				LoadBalancer: &config.TCPLoadBalancerService{
+					Servers: []config.TCPServer{
+						{Address: "192.168.0.12:80"},
+						{Address: "192.168.0.13:80"},
+					},
+					HealthCheck: &config.TCPHealthCheck{Interval: "10s"},
+				},
+			},
+		},
+		"other@provider-1": {
+			TCPService: &config.TCPService{
+				LoadBalancer: &config.TCPLoadBalancerService{
+					Servers: []config.TCPServer{
+						{Address: "192.168.0.14:80"},
+					},
+				},
+			},
+		},
+	}
+
+	manager := NewManager(&config.RuntimeConfiguration{TCPServices: configs}, NewStateManager(), metrics.NewVoidRegistry())
+
+	ctx := internal.AddProviderInContext(context.Background(), "foobar@provider-1")
+	for _, serviceName := range []string{"serviceName", "serviceName", "other"} {
+		_, err := manager.BuildTCP(ctx, serviceName)
+		require.NoError(t, err)
+	}
+
+	// Only the services with a health check are checked, with all of their balancers.
+	require.Len(t, manager.balancers, 1)
+	assert.Len(t, manager.balancers["serviceName@provider-1"], 2)
+
+	expectedStatus := map[string]string{
+		"192.168.0.12:80": "UP",
+		"192.168.0.13:80": "UP",
+	}
+	assert.Equal(t, expectedStatus, configs["serviceName@provider-1"].GetAllStatus())
+	assert.Equal(t, map[string]string{"192.168.0.14:80": "UP"}, configs["other@provider-1"].GetAllStatus())
+}
+
+// generateCert generates a certificate and its key, in PEM, for the given DNS name,
+// signed by the given parent (self-signed if nil).
+func generateCert(t *testing.T, name string, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey, []byte, []byte) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+		DNSNames:     []string{name},
+	}
+
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage |= x509.KeyUsageCertSign
+		parent, parentKey = template, key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	return cert, key, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func TestManager_BuildTCP_TLS(t *testing.T) {
+	ca, caKey, caPEM, _ := generateCert(t, "ca", nil, nil)
+	_, _, serverCertPEM, serverKeyPEM := generateCert(t, "backend.example.com", ca, caKey)
+	_, _, clientCertPEM, clientKeyPEM := generateCert(t, "traefik", ca, caKey)
+
+	serverCert, err := tls.X509KeyPair(serverCertPEM, serverKeyPEM)
+	require.NoError(t, err)
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(ca)
+
+	backend, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{serverCert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    clientCAs,
+	})
+	require.NoError(t, err)
+	defer backend.Close()
+
+	go func() {
+		conn, err := backend.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		msg, err := bufio.NewReader(conn).ReadString('\n')
+		if err != nil {
+			return
+		}
+
+		state := conn.(*tls.Conn).ConnectionState()
+		_, _ = conn.Write([]byte(state.ServerName + " " + state.PeerCertificates[0].Subject.CommonName + " " + msg))
+	}()
+
+	configs := map[string]*config.TCPServiceInfo{
+		"serviceName@provider-1": {
+			TCPService: &config.TCPService{
+				LoadBalancer: &config.TCPLoadBalancerService{
+					Servers: []config.TCPServer{
+						{Address: backend.Addr().String()},
+					},
+					TLS: &config.TCPServersTLS{
+						ServerName: "backend.example.com",
+						RootCAs:    []traefiktls.FileOrContent{traefiktls.FileOrContent(caPEM)},
+						Certificates: traefiktls.Certificates{
+							{CertFile: traefiktls.FileOrContent(clientCertPEM), KeyFile: traefiktls.FileOrContent(clientKeyPEM)},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	manager := NewManager(&config.RuntimeConfiguration{TCPServices: configs}, NewStateManager(), metrics.NewVoidRegistry())
+
+	handler, err := manager.BuildTCP(internal.AddProviderInContext(context.Background(), "foobar@provider-1"), "serviceName")
+	require.NoError(t, err)
+
+	client, server := net.Pipe()
+	defer client.Close()
+
+	go handler.ServeTCP(server)
+
+	_, err = client.Write([]byte("ping\n"))
+	require.NoError(t, err)
+
+	reply, err := bufio.NewReader(client).ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, "backend.example.com traefik ping\n", reply)
+}
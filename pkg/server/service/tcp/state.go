@@ -0,0 +1,49 @@
+package tcp
+
+import (
+	"sync"
+
+	"github.com/containous/traefik/pkg/config"
+	"github.com/containous/traefik/pkg/tcp"
+)
+
+// StateManager keeps the state of the TCP services which outlives their load balancers, rebuilt with each new configuration,
+// such as the open connections of their servers, so that the connections opened with a former configuration are still counted.
+type StateManager struct {
+	connCountsMu sync.Mutex
+	connCounts   map[string]*tcp.ConnCounts
+}
+
+// NewStateManager creates a new StateManager.
+func NewStateManager() *StateManager {
+	return &StateManager{
+		connCounts: make(map[string]*tcp.ConnCounts),
+	}
+}
+
+// getConnCounts returns the open connections of the servers of the service, shared by all its load balancers,
+// creating them with newConnCounts if the service has none yet.
+func (s *StateManager) getConnCounts(serviceName string, newConnCounts func() *tcp.ConnCounts) *tcp.ConnCounts {
+	s.connCountsMu.Lock()
+	defer s.connCountsMu.Unlock()
+
+	conns, ok := s.connCounts[serviceName]
+	if !ok {
+		conns = newConnCounts()
+		s.connCounts[serviceName] = conns
+	}
+	return conns
+}
+
+// prune forgets the state of the services which are not part of the given configurations.
+// The connections still open on the servers of a removed service are counted until they are closed, but not exposed anymore.
+func (s *StateManager) prune(configs map[string]*config.TCPServiceInfo) {
+	s.connCountsMu.Lock()
+	defer s.connCountsMu.Unlock()
+
+	for serviceName := range s.connCounts {
+		if _, ok := configs[serviceName]; !ok {
+			delete(s.connCounts, serviceName)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package udp
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"github.com/containous/traefik/pkg/config"
+	"github.com/containous/traefik/pkg/log"
+	"github.com/containous/traefik/pkg/server/internal"
+	"github.com/containous/traefik/pkg/udp"
+)
+
+// Manager is the UDPHandlers factory
+type Manager struct {
+	configs map[string]*config.UDPServiceInfo
+}
+
+// NewManager creates a new manager
+func NewManager(conf *config.RuntimeConfiguration) *Manager {
+	return &Manager{
+		configs: conf.UDPServices,
+	}
+}
+
+// BuildUDP Creates a udp.Handler for a service configuration.
+func (m *Manager) BuildUDP(rootCtx context.Context, serviceName string) (udp.Handler, error) {
+	serviceQualifiedName := internal.GetQualifiedName(rootCtx, serviceName)
+	ctx := internal.AddProviderInContext(rootCtx, serviceQualifiedName)
+	ctx = log.With(ctx, log.Str(log.ServiceName, serviceName))
+
+	conf, ok := m.configs[serviceQualifiedName]
+	if !ok {
+		return nil, fmt.Errorf("the service %q does not exist", serviceQualifiedName)
+	}
+	if conf.LoadBalancer == nil {
+		conf.Err = fmt.Errorf("the service %q doesn't have any UDP load balancer", serviceQualifiedName)
+		return nil, conf.Err
+	}
+
+	logger := log.FromContext(ctx)
+
+	loadBalancer := udp.NewLoadBalancer()
+
+	for name, server := range conf.LoadBalancer.Servers {
+		if _, _, err := net.SplitHostPort(server.Address); err != nil {
+			logger.Errorf("In service %q: %v", serviceQualifiedName, err)
+			continue
+		}
+
+		handler, err := udp.NewProxy(server.Address)
+		if err != nil {
+			logger.Errorf("In service %q server %q: %v", serviceQualifiedName, server.Address, err)
+			continue
+		}
+
+		loadBalancer.AddServer(handler)
+		logger.WithField(log.ServerName, name).Debugf("Creating UDP server %d at %s", name, server.Address)
+	}
+
+	return loadBalancer, nil
+}
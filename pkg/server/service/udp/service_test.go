@@ -0,0 +1,110 @@
+package udp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/containous/traefik/pkg/config"
+	"github.com/containous/traefik/pkg/server/internal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManager_BuildUDP(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		serviceName   string
+		configs       map[string]*config.UDPServiceInfo
+		providerName  string
+		expectedError string
+	}{
+		{
+			desc:          "without configuration",
+			serviceName:   "test",
+			configs:       nil,
+			expectedError: `the service "test" does not exist`,
+		},
+		{
+			desc:        "missing lb configuration",
+			serviceName: "test",
+			configs: map[string]*config.UDPServiceInfo{
+				"test": {
+					UDPService: &config.UDPService{},
+				},
+			},
+			expectedError: `the service "test" doesn't have any UDP load balancer`,
+		},
+		{
+			desc:        "no such host, server is skipped, error is logged",
+			serviceName: "test",
+			configs: map[string]*config.UDPServiceInfo{
+				"test": {
+					UDPService: &config.UDPService{
+						LoadBalancer: &config.UDPLoadBalancerService{
+							Servers: []config.UDPServer{
+								{Address: "test:31"},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			desc:        "missing port in address, server is skipped, error is logged",
+			serviceName: "test",
+			configs: map[string]*config.UDPServiceInfo{
+				"test": {
+					UDPService: &config.UDPService{
+						LoadBalancer: &config.UDPLoadBalancerService{
+							Servers: []config.UDPServer{
+								{Address: "192.168.0.12"},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			desc:        "Service name with provider in context",
+			serviceName: "serviceName",
+			configs: map[string]*config.UDPServiceInfo{
+				"serviceName@provider-1": {
+					UDPService: &config.UDPService{
+						LoadBalancer: &config.UDPLoadBalancerService{
+							Servers: []config.UDPServer{
+								{Address: "192.168.0.12:53"},
+							},
+						},
+					},
+				},
+			},
+			providerName: "provider-1",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			manager := NewManager(&config.RuntimeConfiguration{
+				UDPServices: test.configs,
+			})
+
+			ctx := context.Background()
+			if len(test.providerName) > 0 {
+				ctx = internal.AddProviderInContext(ctx, "foobar@"+test.providerName)
+			}
+
+			handler, err := manager.BuildUDP(ctx, test.serviceName)
+
+			if test.expectedError != "" {
+				assert.EqualError(t, err, test.expectedError)
+				require.Nil(t, handler)
+			} else {
+				assert.Nil(t, err)
+				require.NotNil(t, handler)
+			}
+		})
+	}
+}
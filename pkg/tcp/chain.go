@@ -0,0 +1,44 @@
+package tcp
+
+import (
+	"errors"
+)
+
+// Constructor creates a Handler wrapping the next one.
+type Constructor func(next Handler) (Handler, error)
+
+// Chain is a stack of TCP middleware constructors, applied in order to a handler.
+type Chain struct {
+	constructors []Constructor
+}
+
+// NewChain creates a new Chain with the given constructors.
+func NewChain(constructors ...Constructor) Chain {
+	return Chain{constructors: append([]Constructor{}, constructors...)}
+}
+
+// Append returns a new Chain, with the given constructors appended to the ones of c.
+func (c Chain) Append(constructors ...Constructor) Chain {
+	newCons := make([]Constructor, 0, len(c.constructors)+len(constructors))
+	newCons = append(newCons, c.constructors...)
+	newCons = append(newCons, constructors...)
+
+	return Chain{constructors: newCons}
+}
+
+// Then wraps the handler with the middlewares of the chain, the first one being the outermost.
+func (c Chain) Then(handler Handler) (Handler, error) {
+	if handler == nil {
+		return nil, errors.New("cannot chain a nil handler")
+	}
+
+	for i := range c.constructors {
+		var err error
+		handler, err = c.constructors[len(c.constructors)-1-i](handler)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return handler, nil
+}
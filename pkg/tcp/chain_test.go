@@ -0,0 +1,46 @@
+package tcp
+
+import (
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func tagger(tags *[]string, tag string) Constructor {
+	return func(next Handler) (Handler, error) {
+		return HandlerFunc(func(conn net.Conn) {
+			*tags = append(*tags, tag)
+			next.ServeTCP(conn)
+		}), nil
+	}
+}
+
+func TestChain_Then(t *testing.T) {
+	var tags []string
+	final := HandlerFunc(func(conn net.Conn) {
+		tags = append(tags, "final")
+	})
+
+	chain := NewChain(tagger(&tags, "first")).Append(tagger(&tags, "second"))
+
+	handler, err := chain.Then(final)
+	require.NoError(t, err)
+
+	handler.ServeTCP(fakeConn{})
+	assert.Equal(t, []string{"first", "second", "final"}, tags)
+}
+
+func TestChain_Then_error(t *testing.T) {
+	failing := func(next Handler) (Handler, error) {
+		return nil, errors.New("boom")
+	}
+
+	_, err := NewChain(failing).Then(HandlerFunc(func(conn net.Conn) {}))
+	assert.EqualError(t, err, "boom")
+
+	_, err = NewChain().Then(nil)
+	assert.Error(t, err)
+}
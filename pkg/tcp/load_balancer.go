@@ -0,0 +1,278 @@
+package tcp
+
+import (
+	"fmt"
+	"math/rand"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/containous/traefik/pkg/log"
+	"github.com/go-kit/kit/metrics"
+)
+
+type server struct {
+	Handler
+	address string
+	weight  int
+	// current is the smooth weighted round robin state of the server.
+	current int
+}
+
+// picker picks a server among the available ones, which are never empty.
+type picker func(servers []*server) *server
+
+// LoadBalancer balances the connections of a TCP service between its servers, according to a strategy.
+type LoadBalancer struct {
+	conns *ConnCounts
+	pick  picker
+
+	lock    sync.Mutex
+	servers []*server
+	// removed are the servers taken out of the rotation, which can be restored.
+	removed []*server
+	// next is the index the least connections strategy starts from, so that the ties are broken in turn.
+	next int
+	rand *rand.Rand
+}
+
+// NewWRRLoadBalancer creates a new LoadBalancer, picking the servers in turn, proportionally to their weight.
+// The open connections of the servers are counted in conns, which can be nil.
+func NewWRRLoadBalancer(conns *ConnCounts) *LoadBalancer {
+	lb := newLoadBalancer(conns)
+	lb.pick = lb.pickWRR
+	return lb
+}
+
+// NewLeastConnLoadBalancer creates a new LoadBalancer, picking the server with the fewest open connections relative to its weight.
+// The open connections of the servers are counted in conns, which can be nil.
+func NewLeastConnLoadBalancer(conns *ConnCounts) *LoadBalancer {
+	lb := newLoadBalancer(conns)
+	lb.pick = lb.pickLeastConn
+	return lb
+}
+
+// NewRandomTwoChoicesLoadBalancer creates a new LoadBalancer, picking among two random servers
+// the one with the fewest open connections relative to its weight.
+// The open connections of the servers are counted in conns, which can be nil.
+func NewRandomTwoChoicesLoadBalancer(conns *ConnCounts) *LoadBalancer {
+	lb := newLoadBalancer(conns)
+	lb.pick = lb.pickRandomTwoChoices
+	return lb
+}
+
+func newLoadBalancer(conns *ConnCounts) *LoadBalancer {
+	if conns == nil {
+		conns = NewConnCounts(nil)
+	}
+
+	return &LoadBalancer{
+		conns: conns,
+		rand:  rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// ServeTCP forwards the connection to the picked server.
+func (b *LoadBalancer) ServeTCP(conn net.Conn) {
+	srv := b.acquire()
+	if srv == nil {
+		log.WithoutContext().Error("no available server")
+		RecordCloseReason(conn, CloseReasonNoServer)
+		conn.Close()
+		return
+	}
+	defer b.conns.add(srv.address, -1)
+
+	recordServer(conn, srv.address)
+
+	srv.ServeTCP(conn)
+}
+
+// AddServer appends a server, identified by its address, to the existing list.
+func (b *LoadBalancer) AddServer(address string, handler Handler) {
+	b.AddWeightedServer(address, handler, 1)
+}
+
+// AddWeightedServer appends a server, identified by its address, with the given weight, to the existing list.
+func (b *LoadBalancer) AddWeightedServer(address string, handler Handler, weight int) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if weight <= 0 {
+		weight = 1
+	}
+
+	b.servers = append(b.servers, &server{Handler: handler, address: address, weight: weight})
+}
+
+// RemoveServer takes the servers with the given address out of the rotation.
+// They are kept aside, so that they can be put back with RestoreServer.
+func (b *LoadBalancer) RemoveServer(address string) error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	var servers []*server
+	for _, srv := range b.servers {
+		if srv.address == address {
+			b.removed = append(b.removed, srv)
+			continue
+		}
+		servers = append(servers, srv)
+	}
+
+	if len(servers) == len(b.servers) {
+		return fmt.Errorf("server %s not found", address)
+	}
+
+	b.servers = servers
+	return nil
+}
+
+// RestoreServer puts back in the rotation the servers with the given address, removed with RemoveServer.
+func (b *LoadBalancer) RestoreServer(address string) error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	var removed []*server
+	for _, srv := range b.removed {
+		if srv.address == address {
+			srv.current = 0
+			b.servers = append(b.servers, srv)
+			continue
+		}
+		removed = append(removed, srv)
+	}
+
+	if len(removed) == len(b.removed) {
+		return fmt.Errorf("server %s not removed", address)
+	}
+
+	b.removed = removed
+	return nil
+}
+
+// acquire picks a server, and counts the connection forwarded to it.
+func (b *LoadBalancer) acquire() *server {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if len(b.servers) == 0 {
+		return nil
+	}
+
+	srv := b.pick(b.servers)
+	b.conns.add(srv.address, 1)
+	return srv
+}
+
+// pickWRR implements the smooth weighted round robin,
+// which interleaves the servers instead of picking a server as many times in a row as its weight.
+func (b *LoadBalancer) pickWRR(servers []*server) *server {
+	var selected *server
+	total := 0
+	for _, srv := range servers {
+		srv.current += srv.weight
+		total += srv.weight
+		if selected == nil || srv.current > selected.current {
+			selected = srv
+		}
+	}
+
+	selected.current -= total
+	return selected
+}
+
+func (b *LoadBalancer) pickLeastConn(servers []*server) *server {
+	start := b.next % len(servers)
+	b.next++
+
+	selected := servers[start]
+	for i := 1; i < len(servers); i++ {
+		srv := servers[(start+i)%len(servers)]
+		if b.lessLoaded(srv, selected) {
+			selected = srv
+		}
+	}
+	return selected
+}
+
+func (b *LoadBalancer) pickRandomTwoChoices(servers []*server) *server {
+	if len(servers) == 1 {
+		return servers[0]
+	}
+
+	i := b.rand.Intn(len(servers))
+	j := b.rand.Intn(len(servers) - 1)
+	if j >= i {
+		j++
+	}
+
+	if b.lessLoaded(servers[j], servers[i]) {
+		return servers[j]
+	}
+	return servers[i]
+}
+
+// lessLoaded tells whether the server x has fewer open connections than y, relative to their weight.
+func (b *LoadBalancer) lessLoaded(x, y *server) bool {
+	return b.conns.Get(x.address)*y.weight < b.conns.Get(y.address)*x.weight
+}
+
+// ConnCounts counts the open connections of the servers of a service, by address.
+// It can be shared by several load balancers, so that they balance the connections of the service as a whole.
+type ConnCounts struct {
+	gauge metrics.Gauge
+
+	lock   sync.RWMutex
+	counts map[string]int
+}
+
+// NewConnCounts creates a new ConnCounts, reporting the open connections of each server to the gauge, labeled with its address,
+// if the gauge is not nil.
+func NewConnCounts(gauge metrics.Gauge) *ConnCounts {
+	return &ConnCounts{
+		gauge:  gauge,
+		counts: make(map[string]int),
+	}
+}
+
+// Get returns the number of open connections of the server with the given address.
+func (c *ConnCounts) Get(address string) int {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.counts[address]
+}
+
+// Connections returns the number of open connections of each server with open connections.
+func (c *ConnCounts) Connections() map[string]int {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	if len(c.counts) == 0 {
+		return nil
+	}
+
+	counts := make(map[string]int, len(c.counts))
+	for address, count := range c.counts {
+		counts[address] = count
+	}
+	return counts
+}
+
+func (c *ConnCounts) add(address string, delta int) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	count := c.counts[address] + delta
+	if count <= 0 {
+		delete(c.counts, address)
+		count = 0
+	} else {
+		c.counts[address] = count
+	}
+
+	if c.gauge != nil {
+		c.gauge.With("url", address).Set(float64(count))
+	}
+}
@@ -0,0 +1,215 @@
+package tcp
+
+import (
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/go-kit/kit/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// holdHandler records the servers it is called for, and holds the connections until released.
+type holdHandler struct {
+	address string
+	served  chan<- string
+	release <-chan struct{}
+}
+
+func (h holdHandler) ServeTCP(conn net.Conn) {
+	h.served <- h.address
+	if h.release != nil {
+		<-h.release
+	}
+}
+
+// urlGauge records the values of the gauge by url label.
+type urlGauge struct {
+	mu     *sync.Mutex
+	values map[string]float64
+	url    string
+}
+
+func newURLGauge() urlGauge {
+	return urlGauge{mu: &sync.Mutex{}, values: make(map[string]float64)}
+}
+
+func (g urlGauge) With(labelValues ...string) metrics.Gauge {
+	for i := 0; i+1 < len(labelValues); i += 2 {
+		if labelValues[i] == "url" {
+			g.url = labelValues[i+1]
+		}
+	}
+	return g
+}
+
+func (g urlGauge) Set(value float64) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.values[g.url] = value
+}
+
+func (g urlGauge) Add(delta float64) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.values[g.url] += delta
+}
+
+type fakeConn struct {
+	net.Conn
+}
+
+func (fakeConn) Close() error { return nil }
+
+func addServers(lb *LoadBalancer, served chan<- string, release <-chan struct{}, weights map[string]int) {
+	for _, address := range []string{"a", "b", "c"} {
+		if weight, ok := weights[address]; ok {
+			lb.AddWeightedServer(address, holdHandler{address: address, served: served, release: release}, weight)
+		}
+	}
+}
+
+func TestLoadBalancer_wrr(t *testing.T) {
+	served := make(chan string, 10)
+	lb := NewWRRLoadBalancer(nil)
+	addServers(lb, served, nil, map[string]int{"a": 3, "b": 1, "c": 1})
+
+	var order []string
+	for i := 0; i < 10; i++ {
+		lb.ServeTCP(fakeConn{})
+		order = append(order, <-served)
+	}
+
+	// The servers are interleaved.
+	assert.Equal(t, []string{"a", "b", "a", "c", "a", "a", "b", "a", "c", "a"}, order)
+}
+
+func TestLoadBalancer_leastConn(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		newLB   func(conns *ConnCounts) *LoadBalancer
+		weights map[string]int
+		// expected is the number of connections of each server, once they are all opened.
+		expected map[string]int
+	}{
+		{
+			desc:     "least connections",
+			newLB:    NewLeastConnLoadBalancer,
+			weights:  map[string]int{"a": 1, "b": 1, "c": 1},
+			expected: map[string]int{"a": 4, "b": 4, "c": 4},
+		},
+		{
+			desc:     "weighted least connections",
+			newLB:    NewLeastConnLoadBalancer,
+			weights:  map[string]int{"a": 2, "b": 1},
+			expected: map[string]int{"a": 8, "b": 4},
+		},
+		{
+			desc:    "random two choices",
+			newLB:   NewRandomTwoChoicesLoadBalancer,
+			weights: map[string]int{"a": 1, "b": 1},
+			// With two servers, both of them are always compared.
+			expected: map[string]int{"a": 6, "b": 6},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			gauge := newURLGauge()
+			conns := NewConnCounts(gauge)
+
+			served := make(chan string, 12)
+			release := make(chan struct{})
+			lb := test.newLB(conns)
+			addServers(lb, served, release, test.weights)
+
+			var wg sync.WaitGroup
+			for i := 0; i < 12; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					lb.ServeTCP(fakeConn{})
+				}()
+
+				// The connections are opened one after the other, each one still open when the next one is balanced.
+				<-served
+			}
+
+			assert.Equal(t, test.expected, conns.Connections())
+
+			gauge.mu.Lock()
+			for address, count := range test.expected {
+				assert.Equal(t, float64(count), gauge.values[address])
+			}
+			gauge.mu.Unlock()
+
+			close(release)
+			wg.Wait()
+
+			assert.Empty(t, conns.Connections())
+
+			gauge.mu.Lock()
+			for address := range test.expected {
+				assert.Zero(t, gauge.values[address])
+			}
+			gauge.mu.Unlock()
+		})
+	}
+}
+
+func TestLoadBalancer_sharedConnCounts(t *testing.T) {
+	conns := NewConnCounts(nil)
+
+	served := make(chan string, 2)
+	release := make(chan struct{})
+
+	// A connection opened with the load balancer of a former configuration is still open.
+	former := NewLeastConnLoadBalancer(conns)
+	addServers(former, served, release, map[string]int{"a": 1})
+
+	done := make(chan struct{})
+	go func() {
+		former.ServeTCP(fakeConn{})
+		close(done)
+	}()
+	require.Equal(t, "a", <-served)
+
+	lb := NewLeastConnLoadBalancer(conns)
+	addServers(lb, served, nil, map[string]int{"a": 1, "b": 1})
+
+	lb.ServeTCP(fakeConn{})
+	assert.Equal(t, "b", <-served)
+
+	close(release)
+	<-done
+}
+
+func TestLoadBalancer_removeServer(t *testing.T) {
+	served := make(chan string, 10)
+	lb := NewWRRLoadBalancer(nil)
+	addServers(lb, served, nil, map[string]int{"a": 1, "b": 1})
+
+	require.NoError(t, lb.RemoveServer("a"))
+	assert.Error(t, lb.RemoveServer("a"))
+
+	for i := 0; i < 3; i++ {
+		lb.ServeTCP(fakeConn{})
+		assert.Equal(t, "b", <-served)
+	}
+
+	require.NoError(t, lb.RemoveServer("b"))
+
+	// Without any server, the connection is closed.
+	lb.ServeTCP(fakeConn{})
+	assert.Empty(t, served)
+
+	require.NoError(t, lb.RestoreServer("a"))
+	assert.Error(t, lb.RestoreServer("a"))
+
+	lb.ServeTCP(fakeConn{})
+	assert.Equal(t, "a", <-served)
+}
@@ -0,0 +1,79 @@
+package tcp
+
+import (
+	"net"
+	"time"
+
+	"github.com/go-kit/kit/metrics"
+)
+
+// ConnMetrics are the metrics recorded on the connections handled by a TCP router or service.
+type ConnMetrics struct {
+	OpenConns metrics.Gauge
+	Duration  metrics.Histogram
+	// BytesIn counts the bytes received from the clients, and BytesOut the bytes sent to them.
+	BytesIn  metrics.Counter
+	BytesOut metrics.Counter
+}
+
+// metricsHandler records the connections handled by the next handler.
+type metricsHandler struct {
+	next    Handler
+	metrics ConnMetrics
+}
+
+// NewMetricsHandler creates a handler recording, in the metrics, the connections handled by next:
+// how many of them are open, how long they last, and how many bytes are exchanged with the clients.
+func NewMetricsHandler(next Handler, metrics ConnMetrics) Handler {
+	return &metricsHandler{next: next, metrics: metrics}
+}
+
+// ServeTCP forwards the connection to the next handler, counting its bytes.
+func (h *metricsHandler) ServeTCP(conn net.Conn) {
+	h.metrics.OpenConns.Add(1)
+	start := time.Now()
+	defer func() {
+		h.metrics.OpenConns.Add(-1)
+		h.metrics.Duration.Observe(time.Since(start).Seconds())
+	}()
+
+	h.next.ServeTCP(&countingConn{Conn: conn, metrics: &h.metrics})
+}
+
+// countingConn counts the bytes read from and written to the connection.
+type countingConn struct {
+	net.Conn
+	metrics *ConnMetrics
+}
+
+func (c *countingConn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	if n > 0 {
+		c.metrics.BytesIn.Add(float64(n))
+	}
+	return n, err
+}
+
+func (c *countingConn) Write(p []byte) (int, error) {
+	n, err := c.Conn.Write(p)
+	if n > 0 {
+		c.metrics.BytesOut.Add(float64(n))
+	}
+	return n, err
+}
+
+// CloseWrite closes the writing half of the underlying connection,
+// or the whole connection if it cannot be half-closed.
+func (c *countingConn) CloseWrite() error {
+	return CloseWrite(c.Conn)
+}
+
+// RecordServer forwards the record to the underlying connection.
+func (c *countingConn) RecordServer(address string) {
+	recordServer(c.Conn, address)
+}
+
+// RecordCloseReason forwards the record to the underlying connection.
+func (c *countingConn) RecordCloseReason(reason string) {
+	RecordCloseReason(c.Conn, reason)
+}
@@ -0,0 +1,87 @@
+package tcp
+
+import (
+	"io"
+	"io/ioutil"
+	"net"
+	"testing"
+
+	"github.com/containous/traefik/pkg/testhelpers"
+	"github.com/go-kit/kit/metrics/generic"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricsHandler(t *testing.T) {
+	openConns := generic.NewGauge("open")
+	duration := &testhelpers.CollectingHistogram{}
+	bytesIn := generic.NewCounter("in")
+	bytesOut := generic.NewCounter("out")
+
+	var openConnsDuringConn float64
+	next := HandlerFunc(func(conn net.Conn) {
+		openConnsDuringConn = openConns.Value()
+
+		buf := make([]byte, 4)
+		_, err := io.ReadFull(conn, buf)
+		require.NoError(t, err)
+
+		_, err = conn.Write([]byte("pong pong"))
+		require.NoError(t, err)
+	})
+
+	handler := NewMetricsHandler(next, ConnMetrics{
+		OpenConns: openConns,
+		Duration:  duration,
+		BytesIn:   bytesIn,
+		BytesOut:  bytesOut,
+	})
+
+	client, server := net.Pipe()
+	defer client.Close()
+
+	go func() {
+		_, _ = client.Write([]byte("ping"))
+		_, _ = io.Copy(ioutil.Discard, client)
+	}()
+
+	handler.ServeTCP(server)
+
+	assert.Equal(t, float64(1), openConnsDuringConn)
+	assert.Equal(t, float64(0), openConns.Value())
+	assert.Equal(t, 1, duration.Observations)
+	assert.Equal(t, float64(4), bytesIn.Value())
+	assert.Equal(t, float64(9), bytesOut.Value())
+}
+
+func TestMetricsHandler_closeWrite(t *testing.T) {
+	handler := NewMetricsHandler(HandlerFunc(func(conn net.Conn) {
+		_, ok := conn.(WriteCloser)
+		assert.True(t, ok)
+	}), ConnMetrics{
+		OpenConns: generic.NewGauge("open"),
+		Duration:  &testhelpers.CollectingHistogram{},
+		BytesIn:   generic.NewCounter("in"),
+		BytesOut:  generic.NewCounter("out"),
+	})
+
+	handler.ServeTCP(fakeConn{})
+}
+
+func TestMetricsHandler_forwardRecords(t *testing.T) {
+	handler := NewMetricsHandler(HandlerFunc(func(conn net.Conn) {
+		recordServer(conn, "10.0.0.1:80")
+		RecordCloseReason(conn, CloseReasonClient)
+	}), ConnMetrics{
+		OpenConns: generic.NewGauge("open"),
+		Duration:  &testhelpers.CollectingHistogram{},
+		BytesIn:   generic.NewCounter("in"),
+		BytesOut:  generic.NewCounter("out"),
+	})
+
+	conn := &recordingConn{Conn: fakeConn{}}
+	handler.ServeTCP(conn)
+
+	assert.Equal(t, []string{"10.0.0.1:80"}, conn.servers)
+	assert.Equal(t, []string{CloseReasonClient}, conn.closeReasons)
+}
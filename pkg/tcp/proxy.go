@@ -1,25 +1,84 @@
 package tcp
 
 import (
+	"crypto/tls"
+	"fmt"
 	"io"
 	"net"
+	"sync/atomic"
+	"time"
 
+	"github.com/containous/traefik/pkg/config"
 	"github.com/containous/traefik/pkg/log"
+	"github.com/containous/traefik/pkg/proxyprotocol"
 )
 
+// DefaultTerminationDelay is how long a connection is kept open by default,
+// once one side closed its half of it, for the other side to close its half too.
+const DefaultTerminationDelay = 100 * time.Millisecond
+
+// defaultTLSHandshakeTimeout is how long the TLS handshake with the service can take.
+const defaultTLSHandshakeTimeout = 10 * time.Second
+
+// WriteCloser is a connection whose writing half can be closed, keeping the reading half open.
+type WriteCloser interface {
+	net.Conn
+	// CloseWrite closes the writing half of the connection.
+	CloseWrite() error
+}
+
 // Proxy forwards a TCP request to a TCP service
 type Proxy struct {
 	target *net.TCPAddr
+	// proxyProtocolVersion is the version of the PROXY protocol header sent to the service, 0 to send none.
+	proxyProtocolVersion int
+	// terminationDelay is how long the connection is kept open once one side closed its half, a negative duration meaning forever.
+	terminationDelay time.Duration
+	// idleTimeout is how long the connection can stay open without any byte exchanged, 0 meaning forever.
+	idleTimeout time.Duration
+	// tlsConfig, if not nil, is the configuration of the TLS connection to the service.
+	tlsConfig *tls.Config
+	// tlsHandshakeTimeout is how long the TLS handshake with the service can take.
+	tlsHandshakeTimeout time.Duration
 }
 
-// NewProxy creates a new Proxy
-func NewProxy(address string) (*Proxy, error) {
+// NewProxy creates a new Proxy.
+// If tlsConfig is not nil, the connection to the service is encrypted with TLS,
+// the server name defaulting to the host of the address.
+func NewProxy(address string, terminationDelay, idleTimeout time.Duration, proxyProtocol *config.ProxyProtocol, tlsConfig *tls.Config) (*Proxy, error) {
 	tcpAddr, err := net.ResolveTCPAddr("tcp", address)
 	if err != nil {
 		return nil, err
 	}
 
-	return &Proxy{target: tcpAddr}, nil
+	proxy := &Proxy{
+		target:              tcpAddr,
+		terminationDelay:    terminationDelay,
+		idleTimeout:         idleTimeout,
+		tlsHandshakeTimeout: defaultTLSHandshakeTimeout,
+	}
+
+	if proxyProtocol != nil {
+		proxy.proxyProtocolVersion = proxyProtocol.GetVersion()
+		if proxy.proxyProtocolVersion != 1 && proxy.proxyProtocolVersion != 2 {
+			return nil, fmt.Errorf("unsupported PROXY protocol version %d", proxyProtocol.Version)
+		}
+	}
+
+	if tlsConfig != nil {
+		proxy.tlsConfig = tlsConfig
+		if tlsConfig.ServerName == "" {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return nil, err
+			}
+
+			proxy.tlsConfig = tlsConfig.Clone()
+			proxy.tlsConfig.ServerName = host
+		}
+	}
+
+	return proxy, nil
 }
 
 // ServeTCP forwards the connection to a service
@@ -27,24 +86,134 @@ func (p *Proxy) ServeTCP(conn net.Conn) {
 	log.Debugf("Handling connection from %s", conn.RemoteAddr())
 	defer conn.Close()
 
-	connBackend, err := net.DialTCP("tcp", nil, p.target)
+	tcpConnBackend, err := net.DialTCP("tcp", nil, p.target)
 	if err != nil {
 		log.Errorf("Error while connection to backend: %v", err)
+		RecordCloseReason(conn, CloseReasonServerUnreachable)
 		return
 	}
-	defer connBackend.Close()
+	defer tcpConnBackend.Close()
+
+	// The PROXY protocol header precedes the TLS handshake.
+	if p.proxyProtocolVersion > 0 {
+		err = proxyprotocol.WriteHeader(tcpConnBackend, p.proxyProtocolVersion, conn.RemoteAddr(), conn.LocalAddr())
+		if err != nil {
+			log.Errorf("Error while writing the PROXY protocol header to backend: %v", err)
+			RecordCloseReason(conn, CloseReasonServerError)
+			return
+		}
+	}
 
-	errChan := make(chan error, 1)
-	go connCopy(conn, connBackend, errChan)
-	go connCopy(connBackend, conn, errChan)
+	var connBackend net.Conn = tcpConnBackend
+	if p.tlsConfig != nil {
+		tlsConnBackend, err := handshake(tcpConnBackend, p.tlsConfig, p.tlsHandshakeTimeout)
+		if err != nil {
+			log.Errorf("Error during the TLS handshake with backend: %v", err)
+			RecordCloseReason(conn, CloseReasonServerError)
+			return
+		}
+		connBackend = tlsConnBackend
+	}
 
-	err = <-errChan
-	if err != nil {
-		log.Errorf("Error during connection: %v", err)
+	var idleTimer *time.Timer
+	var idle int32
+	if p.idleTimeout > 0 {
+		idleTimer = time.AfterFunc(p.idleTimeout, func() {
+			atomic.StoreInt32(&idle, 1)
+			log.Debugf("Closing connection from %s, idle for %s", conn.RemoteAddr(), p.idleTimeout)
+			conn.Close()
+			connBackend.Close()
+		})
+		defer idleTimer.Stop()
+	}
+
+	results := make(chan copyResult)
+	go p.connCopy(conn, connBackend, idleTimer, CloseReasonServer, results)
+	go p.connCopy(connBackend, conn, idleTimer, CloseReasonClient, results)
+
+	// The first direction to end tells why the connection is closed.
+	result := <-results
+	switch {
+	case result.err != nil && atomic.LoadInt32(&idle) == 1:
+		RecordCloseReason(conn, CloseReasonIdleTimeout)
+	case result.err != nil:
+		log.Errorf("Error during connection: %v", result.err)
+		RecordCloseReason(conn, CloseReasonError)
+	default:
+		RecordCloseReason(conn, result.closeReason)
 	}
+
+	<-results
 }
 
-func connCopy(dst, src net.Conn, errCh chan error) {
-	_, err := io.Copy(dst, src)
-	errCh <- err
+// handshake performs the TLS handshake over conn, failing if it takes longer than timeout.
+func handshake(conn net.Conn, tlsConfig *tls.Config, timeout time.Duration) (*tls.Conn, error) {
+	if err := conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return nil, err
+	}
+
+	tlsConn := tls.Client(conn, tlsConfig)
+	if err := tlsConn.Handshake(); err != nil {
+		return nil, err
+	}
+
+	if err := conn.SetDeadline(time.Time{}); err != nil {
+		return nil, err
+	}
+
+	return tlsConn, nil
+}
+
+// copyResult is the outcome of the copy of one direction of the connection.
+type copyResult struct {
+	err error
+	// closeReason is why the connection is closed when this direction ends first.
+	closeReason string
+}
+
+// connCopy copies the bytes from src to dst until src is closed, then closes the writing half of dst,
+// and gives the other direction at most the termination delay to end too.
+func (p *Proxy) connCopy(dst, src net.Conn, idleTimer *time.Timer, closeReason string, results chan copyResult) {
+	var reader io.Reader = src
+	if idleTimer != nil {
+		reader = &idleReader{Reader: src, timer: idleTimer, timeout: p.idleTimeout}
+	}
+
+	_, err := io.Copy(dst, reader)
+	results <- copyResult{err: err, closeReason: closeReason}
+
+	if errClose := CloseWrite(dst); errClose != nil {
+		log.Debugf("Error while terminating connection: %v", errClose)
+		return
+	}
+
+	if p.terminationDelay >= 0 {
+		if errDeadline := dst.SetReadDeadline(time.Now().Add(p.terminationDelay)); errDeadline != nil {
+			log.Debugf("Error while setting deadline: %v", errDeadline)
+		}
+	}
+}
+
+// CloseWrite closes the writing half of the connection if it can be,
+// and otherwise the whole connection.
+func CloseWrite(conn net.Conn) error {
+	if wc, ok := conn.(WriteCloser); ok {
+		return wc.CloseWrite()
+	}
+	return conn.Close()
+}
+
+// idleReader resets the idle timer each time bytes are read.
+type idleReader struct {
+	io.Reader
+	timer   *time.Timer
+	timeout time.Duration
+}
+
+func (r *idleReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	if n > 0 {
+		r.timer.Reset(r.timeout)
+	}
+	return n, err
 }
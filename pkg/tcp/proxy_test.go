@@ -0,0 +1,266 @@
+package tcp
+
+import (
+	"bufio"
+	"crypto/tls"
+	"io"
+	"io/ioutil"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/armon/go-proxyproto"
+	"github.com/containous/traefik/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// proxyConn opens a connection to a listener served by the proxy, and returns the client side of the connection.
+func proxyConn(t *testing.T, proxy *Proxy) net.Conn {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		proxy.ServeTCP(conn)
+	}()
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	require.NoError(t, err)
+
+	return conn
+}
+
+func TestProxy_proxyProtocolV1(t *testing.T) {
+	backend, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer backend.Close()
+
+	remoteAddrs := make(chan string, 1)
+	go func() {
+		conn, err := (&proxyproto.Listener{Listener: backend}).Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		msg, err := bufio.NewReader(conn).ReadString('\n')
+		if err != nil {
+			return
+		}
+		remoteAddrs <- conn.RemoteAddr().String()
+		_, _ = conn.Write([]byte(msg))
+	}()
+
+	proxy, err := NewProxy(backend.Addr().String(), DefaultTerminationDelay, 0, &config.ProxyProtocol{Version: 1}, nil)
+	require.NoError(t, err)
+
+	conn := proxyConn(t, proxy)
+	defer conn.Close()
+
+	_, err = conn.Write([]byte("ping\n"))
+	require.NoError(t, err)
+
+	reply, err := bufio.NewReader(conn).ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, "ping\n", reply)
+
+	// The backend sees the address of the client, instead of the one of the proxy.
+	assert.Equal(t, conn.LocalAddr().String(), <-remoteAddrs)
+}
+
+func TestProxy_proxyProtocolV2(t *testing.T) {
+	backend, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer backend.Close()
+
+	headers := make(chan []byte, 1)
+	go func() {
+		conn, err := backend.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		// 16 bytes of signature, version, family and length, then 12 bytes of IPv4 addresses and ports.
+		header := make([]byte, 28)
+		if _, err := io.ReadFull(conn, header); err != nil {
+			return
+		}
+		headers <- header
+	}()
+
+	proxy, err := NewProxy(backend.Addr().String(), DefaultTerminationDelay, 0, &config.ProxyProtocol{}, nil)
+	require.NoError(t, err)
+
+	conn := proxyConn(t, proxy)
+	defer conn.Close()
+
+	header := <-headers
+	assert.Equal(t, []byte("\r\n\r\n\x00\r\nQUIT\n\x21\x11\x00\x0c"), header[:16])
+
+	clientAddr := conn.LocalAddr().(*net.TCPAddr)
+	assert.Equal(t, []byte(clientAddr.IP.To4()), header[16:20])
+	assert.Equal(t, []byte{byte(clientAddr.Port >> 8), byte(clientAddr.Port)}, header[24:26])
+}
+
+func TestNewProxy_invalidProxyProtocolVersion(t *testing.T) {
+	_, err := NewProxy("127.0.0.1:80", DefaultTerminationDelay, 0, &config.ProxyProtocol{Version: 3}, nil)
+	assert.Error(t, err)
+}
+
+func TestProxy_halfClose(t *testing.T) {
+	backend, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer backend.Close()
+
+	go func() {
+		conn, err := backend.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		// The backend replies only once the client is done writing.
+		msg, err := ioutil.ReadAll(conn)
+		if err != nil {
+			return
+		}
+		_, _ = conn.Write(append([]byte("got "), msg...))
+	}()
+
+	proxy, err := NewProxy(backend.Addr().String(), DefaultTerminationDelay, 0, nil, nil)
+	require.NoError(t, err)
+
+	conn := proxyConn(t, proxy)
+	defer conn.Close()
+
+	_, err = conn.Write([]byte("ping"))
+	require.NoError(t, err)
+	require.NoError(t, conn.(*net.TCPConn).CloseWrite())
+
+	reply, err := ioutil.ReadAll(conn)
+	require.NoError(t, err)
+	assert.Equal(t, "got ping", string(reply))
+}
+
+func TestProxy_terminationDelay(t *testing.T) {
+	backend, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer backend.Close()
+
+	go func() {
+		conn, err := backend.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		// The backend never closes its half of the connection.
+		_, _ = ioutil.ReadAll(conn)
+		time.Sleep(5 * time.Second)
+	}()
+
+	proxy, err := NewProxy(backend.Addr().String(), 100*time.Millisecond, 0, nil, nil)
+	require.NoError(t, err)
+
+	conn := proxyConn(t, proxy)
+	defer conn.Close()
+
+	require.NoError(t, conn.(*net.TCPConn).CloseWrite())
+
+	start := time.Now()
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(3*time.Second)))
+	_, err = ioutil.ReadAll(conn)
+	require.NoError(t, err)
+	assert.True(t, time.Since(start) < 3*time.Second)
+}
+
+func TestProxy_idleTimeout(t *testing.T) {
+	backend, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer backend.Close()
+
+	go func() {
+		conn, err := backend.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		_, _ = io.Copy(conn, conn)
+	}()
+
+	proxy, err := NewProxy(backend.Addr().String(), DefaultTerminationDelay, 500*time.Millisecond, nil, nil)
+	require.NoError(t, err)
+
+	conn := proxyConn(t, proxy)
+	defer conn.Close()
+
+	// The exchanged bytes keep the connection open past the idle timeout.
+	for i := 0; i < 4; i++ {
+		_, err = conn.Write([]byte("ping"))
+		require.NoError(t, err)
+
+		buf := make([]byte, 4)
+		_, err = io.ReadFull(conn, buf)
+		require.NoError(t, err)
+
+		time.Sleep(200 * time.Millisecond)
+	}
+
+	start := time.Now()
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(3*time.Second)))
+	_, err = ioutil.ReadAll(conn)
+	require.NoError(t, err)
+	assert.True(t, time.Since(start) < 3*time.Second)
+}
+
+func TestNewProxy_TLSServerName(t *testing.T) {
+	proxy, err := NewProxy("127.0.0.1:443", DefaultTerminationDelay, 0, nil, &tls.Config{})
+	require.NoError(t, err)
+	assert.Equal(t, "127.0.0.1", proxy.tlsConfig.ServerName)
+
+	proxy, err = NewProxy("127.0.0.1:443", DefaultTerminationDelay, 0, nil, &tls.Config{ServerName: "backend.example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, "backend.example.com", proxy.tlsConfig.ServerName)
+}
+
+func TestProxy_TLSHandshakeTimeout(t *testing.T) {
+	backend, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer backend.Close()
+
+	release := make(chan struct{})
+	defer close(release)
+
+	// The backend never answers the TLS handshake.
+	go func() {
+		conn, err := backend.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		<-release
+	}()
+
+	proxy, err := NewProxy(backend.Addr().String(), DefaultTerminationDelay, 0, nil, &tls.Config{})
+	require.NoError(t, err)
+	proxy.tlsHandshakeTimeout = 100 * time.Millisecond
+
+	conn := proxyConn(t, proxy)
+	defer conn.Close()
+
+	start := time.Now()
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(3*time.Second)))
+	_, err = ioutil.ReadAll(conn)
+	require.NoError(t, err)
+	assert.True(t, time.Since(start) < 3*time.Second)
+}
@@ -0,0 +1,46 @@
+package tcp
+
+import "net"
+
+// The reasons why the connections are closed.
+const (
+	// CloseReasonClient is when the client closes the connection first.
+	CloseReasonClient = "client_closed"
+	// CloseReasonServer is when the server closes the connection first.
+	CloseReasonServer = "server_closed"
+	// CloseReasonIdleTimeout is when no byte is exchanged for the idle timeout of the service.
+	CloseReasonIdleTimeout = "idle_timeout"
+	// CloseReasonError is when the bytes cannot be copied between the client and the server.
+	CloseReasonError = "error"
+	// CloseReasonRejected is when a middleware refuses the connection.
+	CloseReasonRejected = "rejected"
+	// CloseReasonNoServer is when the service does not have any available server.
+	CloseReasonNoServer = "no_available_server"
+	// CloseReasonServerUnreachable is when the connection to the server cannot be opened.
+	CloseReasonServerUnreachable = "server_unreachable"
+	// CloseReasonServerError is when the PROXY protocol header or the TLS handshake fails with the server.
+	CloseReasonServerError = "server_error"
+)
+
+// ConnRecorder is implemented by the connections recording how they are handled, like the ones of the TCP access log.
+// The connections wrapping another one forward the records to it.
+type ConnRecorder interface {
+	// RecordServer records the address of the server the connection is forwarded to.
+	RecordServer(address string)
+	// RecordCloseReason records why the connection is closed.
+	RecordCloseReason(reason string)
+}
+
+// RecordCloseReason records why the connection is closed, if the connection records it.
+func RecordCloseReason(conn net.Conn, reason string) {
+	if recorder, ok := conn.(ConnRecorder); ok {
+		recorder.RecordCloseReason(reason)
+	}
+}
+
+// recordServer records the address of the server the connection is forwarded to, if the connection records it.
+func recordServer(conn net.Conn, address string) {
+	if recorder, ok := conn.(ConnRecorder); ok {
+		recorder.RecordServer(address)
+	}
+}
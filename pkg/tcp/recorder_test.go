@@ -0,0 +1,165 @@
+package tcp
+
+import (
+	"io/ioutil"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingConn is a connection keeping the records made on it.
+type recordingConn struct {
+	net.Conn
+
+	mu           sync.Mutex
+	servers      []string
+	closeReasons []string
+}
+
+func (c *recordingConn) RecordServer(address string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.servers = append(c.servers, address)
+}
+
+func (c *recordingConn) RecordCloseReason(reason string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.closeReasons = append(c.closeReasons, reason)
+}
+
+// recordedProxyConn opens a connection to a listener served by the proxy, and returns the client side of the connection,
+// with the reasons recorded by the proxy once it is done with the connection.
+func recordedProxyConn(t *testing.T, proxy *Proxy) (net.Conn, <-chan []string) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	closeReasons := make(chan []string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+
+		recorder := &recordingConn{Conn: conn}
+		proxy.ServeTCP(recorder)
+		closeReasons <- recorder.closeReasons
+	}()
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	require.NoError(t, err)
+
+	return conn, closeReasons
+}
+
+func TestLoadBalancer_recordServer(t *testing.T) {
+	lb := NewWRRLoadBalancer(nil)
+	lb.AddServer("10.0.0.1:80", HandlerFunc(func(conn net.Conn) {}))
+
+	conn := &recordingConn{Conn: fakeConn{}}
+	lb.ServeTCP(conn)
+	assert.Equal(t, []string{"10.0.0.1:80"}, conn.servers)
+
+	require.NoError(t, lb.RemoveServer("10.0.0.1:80"))
+
+	conn = &recordingConn{Conn: fakeConn{}}
+	lb.ServeTCP(conn)
+	assert.Empty(t, conn.servers)
+	assert.Equal(t, []string{CloseReasonNoServer}, conn.closeReasons)
+}
+
+func TestProxy_closeReason(t *testing.T) {
+	t.Run("client closed", func(t *testing.T) {
+		backend, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer backend.Close()
+
+		go func() {
+			conn, err := backend.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+
+			_, _ = ioutil.ReadAll(conn)
+		}()
+
+		proxy, err := NewProxy(backend.Addr().String(), DefaultTerminationDelay, 0, nil, nil)
+		require.NoError(t, err)
+
+		conn, closeReasons := recordedProxyConn(t, proxy)
+		require.NoError(t, conn.Close())
+
+		assert.Equal(t, []string{CloseReasonClient}, <-closeReasons)
+	})
+
+	t.Run("server closed", func(t *testing.T) {
+		backend, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer backend.Close()
+
+		go func() {
+			conn, err := backend.Accept()
+			if err != nil {
+				return
+			}
+			_ = conn.Close()
+		}()
+
+		proxy, err := NewProxy(backend.Addr().String(), DefaultTerminationDelay, 0, nil, nil)
+		require.NoError(t, err)
+
+		conn, closeReasons := recordedProxyConn(t, proxy)
+		defer conn.Close()
+
+		assert.Equal(t, []string{CloseReasonServer}, <-closeReasons)
+	})
+
+	t.Run("idle timeout", func(t *testing.T) {
+		backend, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer backend.Close()
+
+		go func() {
+			conn, err := backend.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+
+			time.Sleep(5 * time.Second)
+		}()
+
+		proxy, err := NewProxy(backend.Addr().String(), DefaultTerminationDelay, 100*time.Millisecond, nil, nil)
+		require.NoError(t, err)
+
+		conn, closeReasons := recordedProxyConn(t, proxy)
+		defer conn.Close()
+
+		assert.Equal(t, []string{CloseReasonIdleTimeout}, <-closeReasons)
+	})
+
+	t.Run("server unreachable", func(t *testing.T) {
+		backend, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+
+		proxy, err := NewProxy(backend.Addr().String(), DefaultTerminationDelay, 0, nil, nil)
+		require.NoError(t, err)
+
+		require.NoError(t, backend.Close())
+
+		conn, closeReasons := recordedProxyConn(t, proxy)
+		defer conn.Close()
+
+		assert.Equal(t, []string{CloseReasonServerUnreachable}, <-closeReasons)
+	})
+}
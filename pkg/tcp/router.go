@@ -7,14 +7,39 @@ import (
 	"io"
 	"net"
 	"net/http"
+	"sort"
 	"strings"
 
 	"github.com/containous/traefik/pkg/log"
 )
 
+// ConnData holds the data of a connection used to match it against the routes.
+type ConnData struct {
+	// ServerName is the lower-cased SNI of the TLS connection, empty for non-TLS connections.
+	ServerName string
+	// RemoteIP is the IP address of the client.
+	RemoteIP string
+	// ALPNProtos are the application protocols offered by the client in the TLS ClientHello, in its order of preference.
+	ALPNProtos []string
+}
+
+// MatcherFunc reports whether a connection matches a route.
+type MatcherFunc func(ConnData) bool
+
+// route is a handler with the matcher of its connections.
+// The routes with the highest priority are tried first, and the ties are broken by name.
+type route struct {
+	name     string
+	priority int
+	matcher  MatcherFunc
+	handler  Handler
+}
+
 // Router is a TCP router
 type Router struct {
-	routingTable      map[string]Handler
+	routes            []route // TLS routes, sorted by priority
+	routesNoTLS       []route // non-TLS routes, sorted by priority
+	routingTableHTTP  map[string]Handler
 	httpForwarder     Handler
 	httpsForwarder    Handler
 	httpHandler       http.Handler
@@ -28,15 +53,28 @@ type Router struct {
 func (r *Router) ServeTCP(conn net.Conn) {
 	// FIXME -- Check if ProxyProtocol changes the first bytes of the request
 
-	if r.catchAllNoTLS != nil && len(r.routingTable) == 0 && r.httpsHandler == nil {
-		r.catchAllNoTLS.ServeTCP(conn)
-		return
+	connData := ConnData{RemoteIP: remoteIP(conn)}
+
+	// Without any TLS route, the non-TLS connections are routed without waiting for the first bytes from the client.
+	if len(r.routes) == 0 && len(r.routingTableHTTP) == 0 && r.httpsHandler == nil {
+		if target := match(r.routesNoTLS, connData); target != nil {
+			target.ServeTCP(conn)
+			return
+		}
+
+		if r.catchAllNoTLS != nil {
+			r.catchAllNoTLS.ServeTCP(conn)
+			return
+		}
 	}
 
 	br := bufio.NewReader(conn)
-	serverName, tls, peeked := clientHelloServerName(br)
-	if !tls {
-		switch {
+	hello := peekClientHello(br)
+	peeked := hello.peeked
+	if !hello.isTLS {
+		switch target := match(r.routesNoTLS, connData); {
+		case target != nil:
+			target.ServeTCP(r.GetConn(conn, peeked))
 		case r.catchAllNoTLS != nil:
 			r.catchAllNoTLS.ServeTCP(r.GetConn(conn, peeked))
 		case r.httpForwarder != nil:
@@ -47,44 +85,50 @@ func (r *Router) ServeTCP(conn net.Conn) {
 		return
 	}
 
-	// FIXME Optimize and test the routing table before helloServerName
-	serverName = strings.ToLower(serverName)
-	if r.routingTable != nil && serverName != "" {
-		if target, ok := r.routingTable[serverName]; ok {
-			target.ServeTCP(r.GetConn(conn, peeked))
-			return
-		}
+	connData.ServerName = strings.ToLower(hello.serverName)
+	connData.ALPNProtos = hello.protos
+	tlsConn := &Conn{
+		Peeked:     []byte(peeked),
+		ServerName: connData.ServerName,
+		Conn:       conn,
 	}
 
-	// FIXME Needs tests
-	if target, ok := r.routingTable["*"]; ok {
-		target.ServeTCP(r.GetConn(conn, peeked))
+	// The HTTPS routers with specific TLS options take precedence on the TCP routers.
+	if target, ok := r.routingTableHTTP[connData.ServerName]; ok {
+		target.ServeTCP(tlsConn)
+		return
+	}
+
+	if target := match(r.routes, connData); target != nil {
+		target.ServeTCP(tlsConn)
 		return
 	}
 
 	if r.httpsForwarder != nil {
-		r.httpsForwarder.ServeTCP(r.GetConn(conn, peeked))
+		r.httpsForwarder.ServeTCP(tlsConn)
 	} else {
 		conn.Close()
 	}
 }
 
-// AddRoute defines a handler for a given sniHost (* is the only valid option)
-func (r *Router) AddRoute(sniHost string, target Handler) {
-	if r.routingTable == nil {
-		r.routingTable = map[string]Handler{}
-	}
-	r.routingTable[strings.ToLower(sniHost)] = target
+// AddRoute defines a handler for the TLS connections matching the matcher, without terminating TLS.
+func (r *Router) AddRoute(name string, priority int, matcher MatcherFunc, target Handler) {
+	r.routes = addRoute(r.routes, route{name: name, priority: priority, matcher: matcher, handler: target})
 }
 
-// AddRouteTLS defines a handler for a given sniHost and sets the matching tlsConfig
-func (r *Router) AddRouteTLS(sniHost string, target Handler, config *tls.Config) {
-	r.AddRoute(sniHost, &TLSHandler{
+// AddRouteTLS defines a handler for the TLS connections matching the matcher, and sets the matching tlsConfig
+func (r *Router) AddRouteTLS(name string, priority int, matcher MatcherFunc, target Handler, config *tls.Config) {
+	r.AddRoute(name, priority, matcher, &TLSHandler{
 		Next:   target,
 		Config: config,
 	})
 }
 
+// AddRouteNoTLS defines a handler for the non-TLS connections matching the matcher.
+func (r *Router) AddRouteNoTLS(name string, priority int, matcher MatcherFunc, target Handler) {
+	r.routesNoTLS = addRoute(r.routesNoTLS, route{name: name, priority: priority, matcher: matcher, handler: target})
+}
+
 // AddRouteHTTPTLS defines a handler for a given sniHost and sets the matching tlsConfig
 func (r *Router) AddRouteHTTPTLS(sniHost string, config *tls.Config) {
 	if r.hostHTTPTLSConfig == nil {
@@ -99,6 +143,38 @@ func (r *Router) AddCatchAllNoTLS(handler Handler) {
 	r.catchAllNoTLS = handler
 }
 
+func addRoute(routes []route, rt route) []route {
+	routes = append(routes, rt)
+	sort.SliceStable(routes, func(i, j int) bool {
+		if routes[i].priority != routes[j].priority {
+			return routes[i].priority > routes[j].priority
+		}
+		return routes[i].name < routes[j].name
+	})
+	return routes
+}
+
+func match(routes []route, connData ConnData) Handler {
+	for _, rt := range routes {
+		if rt.matcher(connData) {
+			return rt.handler
+		}
+	}
+	return nil
+}
+
+func remoteIP(conn net.Conn) string {
+	if conn.RemoteAddr() == nil {
+		return ""
+	}
+
+	host, _, err := net.SplitHostPort(conn.RemoteAddr().String())
+	if err != nil {
+		return conn.RemoteAddr().String()
+	}
+	return host
+}
+
 // GetConn creates a connection proxy with a peeked string
 func (r *Router) GetConn(conn net.Conn, peeked string) net.Conn {
 	// FIXME should it really be on Router ?
@@ -126,8 +202,12 @@ func (r *Router) HTTPForwarder(handler Handler) {
 
 // HTTPSForwarder sets the tcp handler that will forward the TLS connections to an http handler
 func (r *Router) HTTPSForwarder(handler Handler) {
+	r.routingTableHTTP = make(map[string]Handler)
 	for sniHost, tlsConf := range r.hostHTTPTLSConfig {
-		r.AddRouteTLS(sniHost, handler, tlsConf)
+		r.routingTableHTTP[strings.ToLower(sniHost)] = &TLSHandler{
+			Next:   handler,
+			Config: tlsConf,
+		}
 	}
 
 	r.httpsForwarder = &TLSHandler{
@@ -154,6 +234,9 @@ type Conn struct {
 	// by Read calls. It set to nil by Read when fully consumed.
 	Peeked []byte
 
+	// ServerName is the lower-cased SNI of the TLS connection, empty for non-TLS connections.
+	ServerName string
+
 	// Conn is the underlying connection.
 	// It can be type asserted against *net.TCPConn or other types
 	// as needed. It should not be read from directly unless
@@ -174,44 +257,64 @@ func (c *Conn) Read(p []byte) (n int, err error) {
 	return c.Conn.Read(p)
 }
 
-// clientHelloServerName returns the SNI server name inside the TLS ClientHello,
+// CloseWrite closes the writing half of the underlying connection,
+// or the whole connection if it cannot be half-closed.
+func (c *Conn) CloseWrite() error {
+	return CloseWrite(c.Conn)
+}
+
+// clientHelloInfo is what the router reads from the first bytes of a connection.
+type clientHelloInfo struct {
+	// serverName is the SNI server name inside the TLS ClientHello.
+	serverName string
+	// protos are the ALPN protocols offered inside the TLS ClientHello.
+	protos []string
+	// isTLS tells whether the connection starts with a TLS handshake.
+	isTLS bool
+	// peeked are the bytes read from the connection, to be replayed to its handler.
+	peeked string
+}
+
+// peekClientHello returns the SNI server name and the ALPN protocols inside the TLS ClientHello,
 // without consuming any bytes from br.
-// On any error, the empty string is returned.
-func clientHelloServerName(br *bufio.Reader) (string, bool, string) {
+// On any error, the server name and the protocols are empty.
+func peekClientHello(br *bufio.Reader) clientHelloInfo {
 	hdr, err := br.Peek(1)
 	if err != nil {
 		if err != io.EOF {
 			log.Errorf("Error while Peeking first byte: %s", err)
 		}
-		return "", false, ""
+		return clientHelloInfo{}
 	}
 	const recordTypeHandshake = 0x16
 	if hdr[0] != recordTypeHandshake {
 		// log.Errorf("Error not tls")
-		return "", false, getPeeked(br) // Not TLS.
+		return clientHelloInfo{peeked: getPeeked(br)} // Not TLS.
 	}
 
 	const recordHeaderLen = 5
 	hdr, err = br.Peek(recordHeaderLen)
 	if err != nil {
 		log.Errorf("Error while Peeking hello: %s", err)
-		return "", false, getPeeked(br)
+		return clientHelloInfo{peeked: getPeeked(br)}
 	}
 	recLen := int(hdr[3])<<8 | int(hdr[4]) // ignoring version in hdr[1:3]
 	helloBytes, err := br.Peek(recordHeaderLen + recLen)
 	if err != nil {
 		log.Errorf("Error while Hello: %s", err)
-		return "", true, getPeeked(br)
+		return clientHelloInfo{isTLS: true, peeked: getPeeked(br)}
 	}
-	sni := ""
+	hello := clientHelloInfo{isTLS: true}
 	server := tls.Server(sniSniffConn{r: bytes.NewReader(helloBytes)}, &tls.Config{
-		GetConfigForClient: func(hello *tls.ClientHelloInfo) (*tls.Config, error) {
-			sni = hello.ServerName
+		GetConfigForClient: func(info *tls.ClientHelloInfo) (*tls.Config, error) {
+			hello.serverName = info.ServerName
+			hello.protos = info.SupportedProtos
 			return nil, nil
 		},
 	})
 	_ = server.Handshake()
-	return sni, true, getPeeked(br)
+	hello.peeked = getPeeked(br)
+	return hello
 }
 
 func getPeeked(br *bufio.Reader) string {
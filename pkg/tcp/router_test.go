@@ -0,0 +1,244 @@
+package tcp
+
+import (
+	"crypto/tls"
+	"io/ioutil"
+	"net"
+	"testing"
+
+	"github.com/containous/traefik/pkg/tls/generate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// remoteAddrConn is a connection with a fixed remote address.
+type remoteAddrConn struct {
+	net.Conn
+	remoteAddr net.Addr
+}
+
+func (c remoteAddrConn) RemoteAddr() net.Addr { return c.remoteAddr }
+
+// recordHandler records the name of the handler serving the connection, along with the bytes peeked by the router.
+type recordHandler struct {
+	name   string
+	served chan<- string
+}
+
+func (h recordHandler) ServeTCP(conn net.Conn) {
+	defer conn.Close()
+
+	var peeked string
+	if conn, ok := conn.(*Conn); ok {
+		peeked = string(conn.Peeked)
+	}
+	h.served <- h.name + ":" + peeked
+}
+
+func serverNameIs(name string) MatcherFunc {
+	return func(data ConnData) bool { return data.ServerName == name }
+}
+
+func remoteIPIs(ip string) MatcherFunc {
+	return func(data ConnData) bool { return data.RemoteIP == ip }
+}
+
+func matchAll(ConnData) bool { return true }
+
+// serve serves a connection from the remote IP with the router, the client writing with the given function,
+// and returns what the handler serving the connection recorded.
+func serve(router *Router, served <-chan string, remoteIP string, write func(client net.Conn)) string {
+	server, client := net.Pipe()
+	defer client.Close()
+
+	go func() {
+		if write != nil {
+			write(client)
+		}
+		_, _ = ioutil.ReadAll(client)
+	}()
+
+	router.ServeTCP(remoteAddrConn{Conn: server, remoteAddr: &net.TCPAddr{IP: net.ParseIP(remoteIP), Port: 4242}})
+
+	select {
+	case name := <-served:
+		return name
+	default:
+		return ""
+	}
+}
+
+func clientHello(serverName string, protos ...string) func(client net.Conn) {
+	return func(client net.Conn) {
+		_ = tls.Client(client, &tls.Config{ServerName: serverName, NextProtos: protos, InsecureSkipVerify: true}).Handshake()
+	}
+}
+
+func offers(proto string) MatcherFunc {
+	return func(data ConnData) bool {
+		for _, offered := range data.ALPNProtos {
+			if offered == proto {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func TestRouter_priority(t *testing.T) {
+	served := make(chan string, 1)
+	handler := func(name string) Handler {
+		return HandlerFunc(func(conn net.Conn) {
+			conn.Close()
+			served <- name
+		})
+	}
+
+	router := &Router{}
+	router.AddRoute("catch-all", 1, matchAll, handler("catch-all"))
+	router.AddRoute("tenant", 10, serverNameIs("tenant.example.com"), handler("tenant"))
+	router.AddRoute("internal", 20, func(data ConnData) bool {
+		return data.ServerName == "tenant.example.com" && data.RemoteIP == "10.0.0.1"
+	}, handler("internal"))
+	// The ties are broken by name.
+	router.AddRoute("b-other", 5, serverNameIs("other.example.com"), handler("b-other"))
+	router.AddRoute("a-other", 5, serverNameIs("other.example.com"), handler("a-other"))
+
+	testCases := []struct {
+		remoteIP   string
+		serverName string
+		expected   string
+	}{
+		{remoteIP: "10.0.0.1", serverName: "tenant.example.com", expected: "internal"},
+		{remoteIP: "10.0.0.2", serverName: "tenant.example.com", expected: "tenant"},
+		{remoteIP: "10.0.0.2", serverName: "Other.example.com", expected: "a-other"},
+		{remoteIP: "10.0.0.2", serverName: "foo.bar", expected: "catch-all"},
+	}
+
+	for _, test := range testCases {
+		actual := serve(router, served, test.remoteIP, clientHello(test.serverName))
+		assert.Equal(t, test.expected, actual, "%s from %s", test.serverName, test.remoteIP)
+	}
+}
+
+func TestRouter_noTLS(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		tls      bool
+		remoteIP string
+		data     string
+		expected string
+	}{
+		{
+			desc:     "route matching, without waiting for the client",
+			remoteIP: "10.0.0.1",
+			expected: "internal:",
+		},
+		{
+			desc:     "no route matching, forwarded to HTTP with the peeked bytes",
+			remoteIP: "10.0.0.2",
+			data:     "GET / HTTP/1.1\r\n\r\n",
+			expected: "http:GET / HTTP/1.1\r\n\r\n",
+		},
+		{
+			desc:     "route matching, along with TLS routes",
+			tls:      true,
+			remoteIP: "10.0.0.1",
+			data:     "foo",
+			expected: "internal:foo",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			served := make(chan string, 1)
+
+			router := &Router{}
+			router.AddRouteNoTLS("internal", 1, remoteIPIs("10.0.0.1"), recordHandler{name: "internal", served: served})
+			router.HTTPForwarder(recordHandler{name: "http", served: served})
+			if test.tls {
+				router.AddRoute("tls", 1, matchAll, recordHandler{name: "tls", served: served})
+			}
+
+			write := func(client net.Conn) {
+				if len(test.data) > 0 {
+					_, _ = client.Write([]byte(test.data))
+				}
+			}
+
+			assert.Equal(t, test.expected, serve(router, served, test.remoteIP, write))
+		})
+	}
+}
+
+func TestRouter_HTTPSForwarder(t *testing.T) {
+	served := make(chan string, 1)
+
+	router := &Router{}
+	router.AddRoute("catch-all", 1, matchAll, recordHandler{name: "catch-all", served: served})
+	router.AddRouteHTTPTLS("Foo.bar", &tls.Config{})
+	router.HTTPSForwarder(recordHandler{name: "https", served: served})
+
+	// The HTTPS routers with specific TLS options take precedence.
+	assert.Equal(t, "https:", serve(router, served, "10.0.0.1", clientHello("foo.bar")))
+
+	actual := serve(router, served, "10.0.0.1", clientHello("bar.foo"))
+	assert.Regexp(t, "^catch-all:\x16", actual)
+}
+
+func TestRouter_ALPN(t *testing.T) {
+	served := make(chan string, 1)
+
+	router := &Router{}
+	router.AddRoute("catch-all", 1, matchAll, recordHandler{name: "catch-all", served: served})
+	router.AddRoute("grpc", 10, offers("h2"), recordHandler{name: "grpc", served: served})
+	router.AddRoute("postgres", 10, offers("postgresql"), recordHandler{name: "postgres", served: served})
+
+	testCases := []struct {
+		protos   []string
+		expected string
+	}{
+		{protos: []string{"h2", "http/1.1"}, expected: "grpc"},
+		{protos: []string{"postgresql"}, expected: "postgres"},
+		{protos: []string{"http/1.1"}, expected: "catch-all"},
+		{expected: "catch-all"},
+	}
+
+	for _, test := range testCases {
+		// The whole ClientHello is peeked, to be replayed to the handler.
+		actual := serve(router, served, "10.0.0.1", clientHello("foo.bar", test.protos...))
+		assert.Regexp(t, "^"+test.expected+":\x16", actual, "%v", test.protos)
+	}
+}
+
+func TestRouter_ALPN_passthrough(t *testing.T) {
+	cert, err := generate.DefaultCertificate()
+	require.NoError(t, err)
+
+	// The handler terminates TLS itself, from the replayed ClientHello.
+	negotiated := make(chan string, 1)
+	router := &Router{}
+	router.AddRoute("postgres", 10, offers("postgresql"), HandlerFunc(func(conn net.Conn) {
+		defer conn.Close()
+
+		server := tls.Server(conn, &tls.Config{Certificates: []tls.Certificate{*cert}, NextProtos: []string{"postgresql"}})
+		if err := server.Handshake(); err != nil {
+			negotiated <- err.Error()
+			return
+		}
+		negotiated <- server.ConnectionState().NegotiatedProtocol
+	}))
+
+	server, client := net.Pipe()
+	defer client.Close()
+
+	go router.ServeTCP(server)
+
+	tlsClient := tls.Client(client, &tls.Config{ServerName: "db.example.com", NextProtos: []string{"postgresql"}, InsecureSkipVerify: true})
+	require.NoError(t, tlsClient.Handshake())
+	assert.Equal(t, "postgresql", tlsClient.ConnectionState().NegotiatedProtocol)
+	assert.Equal(t, "postgresql", <-negotiated)
+}
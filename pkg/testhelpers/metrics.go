@@ -41,9 +41,28 @@ func (g *CollectingGauge) Add(delta float64) {
 	g.GaugeValue = delta
 }
 
+// CollectingHistogram is a metrics.Histogram implementation that enables access to the number of observations and LastLabelValues.
+type CollectingHistogram struct {
+	Observations    int
+	LastLabelValues []string
+}
+
+// With is there to satisfy the metrics.Histogram interface.
+func (h *CollectingHistogram) With(labelValues ...string) metrics.Histogram {
+	h.LastLabelValues = labelValues
+	return h
+}
+
+// Observe is there to satisfy the metrics.Histogram interface.
+func (h *CollectingHistogram) Observe(value float64) {
+	h.Observations++
+}
+
 // CollectingHealthCheckMetrics can be used for testing the Metrics instrumentation of the HealthCheck package.
 type CollectingHealthCheckMetrics struct {
-	Gauge *CollectingGauge
+	Gauge     *CollectingGauge
+	Counter   *CollectingCounter
+	Histogram *CollectingHistogram
 }
 
 // BackendServerUpGauge is there to satisfy the healthcheck.metricsRegistry interface.
@@ -51,7 +70,21 @@ func (m *CollectingHealthCheckMetrics) BackendServerUpGauge() metrics.Gauge {
 	return m.Gauge
 }
 
+// BackendServerHealthCheckFailuresCounter is there to satisfy the healthcheck.metricsRegistry interface.
+func (m *CollectingHealthCheckMetrics) BackendServerHealthCheckFailuresCounter() metrics.Counter {
+	return m.Counter
+}
+
+// BackendServerHealthCheckDurationHistogram is there to satisfy the healthcheck.metricsRegistry interface.
+func (m *CollectingHealthCheckMetrics) BackendServerHealthCheckDurationHistogram() metrics.Histogram {
+	return m.Histogram
+}
+
 // NewCollectingHealthCheckMetrics creates a new CollectingHealthCheckMetrics instance.
 func NewCollectingHealthCheckMetrics() *CollectingHealthCheckMetrics {
-	return &CollectingHealthCheckMetrics{&CollectingGauge{}}
+	return &CollectingHealthCheckMetrics{
+		Gauge:     &CollectingGauge{},
+		Counter:   &CollectingCounter{},
+		Histogram: &CollectingHistogram{},
+	}
 }
@@ -0,0 +1,293 @@
+package udp
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/containous/traefik/pkg/log"
+)
+
+// receiveMTU is the maximum size of the datagrams read from the clients and from the servers,
+// which is the maximum size of a UDP datagram, so that no datagram is truncated.
+const receiveMTU = 65535
+
+// sessionQueueSize is the number of datagrams of a session waiting to be read, beyond which the datagrams are dropped.
+const sessionQueueSize = 64
+
+const shutdownPollInterval = 500 * time.Millisecond
+
+var errClosedListener = errors.New("udp: listener closed")
+
+// Listener demultiplexes the datagrams received on a UDP socket into sessions, one per client address.
+type Listener struct {
+	pConn *net.UDPConn
+	// timeout is the duration after which an idle session is closed.
+	timeout time.Duration
+
+	acceptCh chan *Conn
+
+	mu        sync.Mutex
+	conns     map[string]*Conn // keyed by client address
+	accepting bool
+}
+
+// Listen listens for the datagrams on the local address, and closes the sessions idle for longer than the timeout.
+func Listen(network string, laddr *net.UDPAddr, timeout time.Duration) (*Listener, error) {
+	if timeout <= 0 {
+		return nil, errors.New("timeout should be greater than zero")
+	}
+
+	pConn, err := net.ListenUDP(network, laddr)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &Listener{
+		pConn:     pConn,
+		timeout:   timeout,
+		acceptCh:  make(chan *Conn),
+		conns:     make(map[string]*Conn),
+		accepting: true,
+	}
+
+	go l.readLoop()
+
+	return l, nil
+}
+
+// Accept waits for and returns the session of the next new client.
+func (l *Listener) Accept() (*Conn, error) {
+	conn, ok := <-l.acceptCh
+	if !ok {
+		return nil, errClosedListener
+	}
+	return conn, nil
+}
+
+// Addr returns the local address of the listener.
+func (l *Listener) Addr() net.Addr {
+	return l.pConn.LocalAddr()
+}
+
+// Close closes the listener, along with all its sessions.
+func (l *Listener) Close() error {
+	err := l.pConn.Close()
+
+	l.mu.Lock()
+	l.accepting = false
+	conns := make([]*Conn, 0, len(l.conns))
+	for _, conn := range l.conns {
+		conns = append(conns, conn)
+	}
+	l.mu.Unlock()
+
+	for _, conn := range conns {
+		conn.Close()
+	}
+
+	return err
+}
+
+// Shutdown stops accepting new sessions, waits for the current ones to end or for the context to be done,
+// and closes the listener.
+func (l *Listener) Shutdown(ctx context.Context) error {
+	l.mu.Lock()
+	l.accepting = false
+	l.mu.Unlock()
+
+	ticker := time.NewTicker(shutdownPollInterval)
+	defer ticker.Stop()
+
+	for !l.isEmpty() {
+		select {
+		case <-ctx.Done():
+			if err := l.Close(); err != nil {
+				return err
+			}
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+
+	return l.Close()
+}
+
+func (l *Listener) isEmpty() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return len(l.conns) == 0
+}
+
+// readLoop reads the datagrams from the socket, and dispatches them to the sessions of their clients,
+// until the listener is closed.
+func (l *Listener) readLoop() {
+	defer close(l.acceptCh)
+
+	buf := make([]byte, receiveMTU)
+	for {
+		n, rAddr, err := l.pConn.ReadFromUDP(buf)
+		if err != nil {
+			return
+		}
+
+		conn, isNew := l.getConn(rAddr)
+		if conn == nil {
+			continue
+		}
+
+		if isNew {
+			l.acceptCh <- conn
+		}
+
+		// Each datagram has its own copy, as it is queued in its session.
+		msg := make([]byte, n)
+		copy(msg, buf[:n])
+		conn.receive(msg)
+	}
+}
+
+// getConn returns the session of the client, creating it if needed and if the listener still accepts new sessions.
+func (l *Listener) getConn(rAddr *net.UDPAddr) (*Conn, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if conn, ok := l.conns[rAddr.String()]; ok {
+		return conn, false
+	}
+
+	if !l.accepting {
+		return nil, false
+	}
+
+	conn := newConn(l, rAddr)
+	l.conns[rAddr.String()] = conn
+	return conn, true
+}
+
+func (l *Listener) remove(conn *Conn) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conns[conn.rAddr.String()] == conn {
+		delete(l.conns, conn.rAddr.String())
+	}
+}
+
+// Conn is the session of a client of a Listener.
+// It reads the datagrams sent by the client, and writes datagrams back to it.
+type Conn struct {
+	listener *Listener
+	rAddr    *net.UDPAddr
+
+	receiveCh chan []byte
+	doneCh    chan struct{}
+	doneOnce  sync.Once
+
+	activityMu   sync.Mutex
+	lastActivity time.Time
+}
+
+func newConn(listener *Listener, rAddr *net.UDPAddr) *Conn {
+	conn := &Conn{
+		listener:     listener,
+		rAddr:        rAddr,
+		receiveCh:    make(chan []byte, sessionQueueSize),
+		doneCh:       make(chan struct{}),
+		lastActivity: time.Now(),
+	}
+
+	go conn.closeOnIdle()
+
+	return conn
+}
+
+// Read reads the next datagram sent by the client.
+// As with a UDP socket, a datagram larger than p is truncated.
+func (c *Conn) Read(p []byte) (int, error) {
+	select {
+	case msg := <-c.receiveCh:
+		c.touch()
+		return copy(p, msg), nil
+	case <-c.doneCh:
+		return 0, io.EOF
+	}
+}
+
+// Write sends a datagram to the client.
+func (c *Conn) Write(p []byte) (int, error) {
+	select {
+	case <-c.doneCh:
+		return 0, io.ErrClosedPipe
+	default:
+	}
+
+	c.touch()
+	return c.listener.pConn.WriteToUDP(p, c.rAddr)
+}
+
+// Close ends the session. A new datagram from the client starts a new session.
+func (c *Conn) Close() error {
+	c.doneOnce.Do(func() {
+		close(c.doneCh)
+		c.listener.remove(c)
+	})
+	return nil
+}
+
+// LocalAddr returns the local address of the listener.
+func (c *Conn) LocalAddr() net.Addr {
+	return c.listener.Addr()
+}
+
+// RemoteAddr returns the address of the client.
+func (c *Conn) RemoteAddr() net.Addr {
+	return c.rAddr
+}
+
+func (c *Conn) receive(msg []byte) {
+	select {
+	case c.receiveCh <- msg:
+	case <-c.doneCh:
+	default:
+		log.WithoutContext().Debugf("Dropping a datagram from %s: too many datagrams waiting to be read", c.rAddr)
+	}
+}
+
+func (c *Conn) touch() {
+	c.activityMu.Lock()
+	defer c.activityMu.Unlock()
+
+	c.lastActivity = time.Now()
+}
+
+func (c *Conn) idleDuration() time.Duration {
+	c.activityMu.Lock()
+	defer c.activityMu.Unlock()
+
+	return time.Since(c.lastActivity)
+}
+
+// closeOnIdle closes the session once no datagram has been read or written for the timeout of the listener.
+func (c *Conn) closeOnIdle() {
+	timer := time.NewTimer(c.listener.timeout)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-c.doneCh:
+			return
+		case <-timer.C:
+			idle := c.idleDuration()
+			if idle >= c.listener.timeout {
+				c.Close()
+				return
+			}
+			timer.Reset(c.listener.timeout - idle)
+		}
+	}
+}
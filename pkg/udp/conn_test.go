@@ -0,0 +1,135 @@
+package udp
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// listen listens on a random local port, and serves the sessions with the handler.
+func listen(t *testing.T, timeout time.Duration, handler Handler) *Listener {
+	t.Helper()
+
+	listener, err := Listen("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1")}, timeout)
+	require.NoError(t, err)
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go handler.ServeUDP(conn)
+		}
+	}()
+
+	return listener
+}
+
+// echoSession replies to each datagram of the session with the datagram prefixed by the prefix.
+func echoSession(prefix string) HandlerFunc {
+	return func(conn *Conn) {
+		defer conn.Close()
+
+		buf := make([]byte, receiveMTU)
+		for {
+			n, err := conn.Read(buf)
+			if err != nil {
+				return
+			}
+
+			if _, err := conn.Write(append([]byte(prefix), buf[:n]...)); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func roundTrip(t *testing.T, conn net.Conn, msg string) string {
+	t.Helper()
+
+	_, err := conn.Write([]byte(msg))
+	require.NoError(t, err)
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+
+	buf := make([]byte, receiveMTU)
+	n, err := conn.Read(buf)
+	require.NoError(t, err)
+
+	return string(buf[:n])
+}
+
+func TestListener_sessions(t *testing.T) {
+	sessions := make(chan string, 10)
+	listener := listen(t, time.Second, HandlerFunc(func(conn *Conn) {
+		sessions <- conn.RemoteAddr().String()
+		echoSession("echo:").ServeUDP(conn)
+	}))
+	defer listener.Close()
+
+	client1, err := net.Dial("udp", listener.Addr().String())
+	require.NoError(t, err)
+	defer client1.Close()
+
+	client2, err := net.Dial("udp", listener.Addr().String())
+	require.NoError(t, err)
+	defer client2.Close()
+
+	assert.Equal(t, "echo:foo", roundTrip(t, client1, "foo"))
+	assert.Equal(t, "echo:bar", roundTrip(t, client2, "bar"))
+	assert.Equal(t, "echo:baz", roundTrip(t, client1, "baz"))
+
+	// Each client has its own session.
+	assert.Equal(t, client1.LocalAddr().String(), <-sessions)
+	assert.Equal(t, client2.LocalAddr().String(), <-sessions)
+	assert.Empty(t, sessions)
+}
+
+func TestListener_idleTimeout(t *testing.T) {
+	sessions := make(chan string, 10)
+	listener := listen(t, 100*time.Millisecond, HandlerFunc(func(conn *Conn) {
+		sessions <- conn.RemoteAddr().String()
+		echoSession("echo:").ServeUDP(conn)
+	}))
+	defer listener.Close()
+
+	client, err := net.Dial("udp", listener.Addr().String())
+	require.NoError(t, err)
+	defer client.Close()
+
+	assert.Equal(t, "echo:foo", roundTrip(t, client, "foo"))
+	<-sessions
+
+	// The session is closed once idle, and the next datagram starts a new one.
+	time.Sleep(200 * time.Millisecond)
+	assert.True(t, listener.isEmpty())
+
+	assert.Equal(t, "echo:bar", roundTrip(t, client, "bar"))
+	assert.Equal(t, client.LocalAddr().String(), <-sessions)
+}
+
+func TestListener_Shutdown(t *testing.T) {
+	listener := listen(t, time.Minute, echoSession("echo:"))
+
+	client, err := net.Dial("udp", listener.Addr().String())
+	require.NoError(t, err)
+	defer client.Close()
+
+	assert.Equal(t, "echo:foo", roundTrip(t, client, "foo"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	// The session is still open when the grace period is over.
+	err = listener.Shutdown(ctx)
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.True(t, listener.isEmpty())
+
+	_, err = listener.Accept()
+	assert.Error(t, err)
+}
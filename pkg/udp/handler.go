@@ -0,0 +1,15 @@
+package udp
+
+// Handler is the UDP Handlers interface
+type Handler interface {
+	ServeUDP(conn *Conn)
+}
+
+// The HandlerFunc type is an adapter to allow the use of
+// ordinary functions as handlers.
+type HandlerFunc func(conn *Conn)
+
+// ServeUDP serves udp
+func (f HandlerFunc) ServeUDP(conn *Conn) {
+	f(conn)
+}
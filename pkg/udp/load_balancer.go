@@ -0,0 +1,52 @@
+package udp
+
+import (
+	"sync"
+
+	"github.com/containous/traefik/pkg/log"
+)
+
+// LoadBalancer balances the UDP sessions in round robin between its servers.
+type LoadBalancer struct {
+	mu      sync.Mutex
+	servers []Handler
+	index   int
+}
+
+// NewLoadBalancer creates a new LoadBalancer
+func NewLoadBalancer() *LoadBalancer {
+	return &LoadBalancer{}
+}
+
+// AddServer adds a server to the load balancer.
+func (b *LoadBalancer) AddServer(server Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.servers = append(b.servers, server)
+}
+
+// ServeUDP forwards the session to the next server.
+func (b *LoadBalancer) ServeUDP(conn *Conn) {
+	server := b.next()
+	if server == nil {
+		log.WithoutContext().Error("no available server")
+		conn.Close()
+		return
+	}
+
+	server.ServeUDP(conn)
+}
+
+func (b *LoadBalancer) next() Handler {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if len(b.servers) == 0 {
+		return nil
+	}
+
+	server := b.servers[b.index%len(b.servers)]
+	b.index = (b.index + 1) % len(b.servers)
+	return server
+}
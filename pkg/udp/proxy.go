@@ -0,0 +1,63 @@
+package udp
+
+import (
+	"io"
+	"net"
+
+	"github.com/containous/traefik/pkg/log"
+)
+
+// Proxy forwards the datagrams of a UDP session to a UDP service, and the replies back to the client.
+type Proxy struct {
+	target *net.UDPAddr
+}
+
+// NewProxy creates a new Proxy
+func NewProxy(address string) (*Proxy, error) {
+	udpAddr, err := net.ResolveUDPAddr("udp", address)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Proxy{target: udpAddr}, nil
+}
+
+// ServeUDP forwards the session to a service, until it is closed for being idle.
+func (p *Proxy) ServeUDP(conn *Conn) {
+	log.Debugf("Handling session from %s", conn.RemoteAddr())
+	defer conn.Close()
+
+	// Each session has its own socket to the backend, so that the replies are sent back to the right client.
+	connBackend, err := net.DialUDP("udp", nil, p.target)
+	if err != nil {
+		log.Errorf("Error while connecting to backend: %v", err)
+		return
+	}
+	defer connBackend.Close()
+
+	errChan := make(chan error, 2)
+	go connCopy(conn, connBackend, errChan)
+	go connCopy(connBackend, conn, errChan)
+
+	err = <-errChan
+	if err != nil && err != io.EOF {
+		log.Errorf("Error during session: %v", err)
+	}
+}
+
+// connCopy copies the datagrams from src to dst, one datagram per write.
+func connCopy(dst io.Writer, src io.Reader, errCh chan error) {
+	buf := make([]byte, receiveMTU)
+	for {
+		n, err := src.Read(buf)
+		if err != nil {
+			errCh <- err
+			return
+		}
+
+		if _, err := dst.Write(buf[:n]); err != nil {
+			errCh <- err
+			return
+		}
+	}
+}
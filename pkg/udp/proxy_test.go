@@ -0,0 +1,68 @@
+package udp
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadBalancer_proxy(t *testing.T) {
+	backend1 := listen(t, time.Second, echoSession("backend1:"))
+	defer backend1.Close()
+
+	backend2 := listen(t, time.Second, echoSession("backend2:"))
+	defer backend2.Close()
+
+	lb := NewLoadBalancer()
+	for _, backend := range []*Listener{backend1, backend2} {
+		proxy, err := NewProxy(backend.Addr().String())
+		require.NoError(t, err)
+		lb.AddServer(proxy)
+	}
+
+	frontend := listen(t, time.Second, lb)
+	defer frontend.Close()
+
+	var clients []net.Conn
+	for i := 0; i < 3; i++ {
+		client, err := net.Dial("udp", frontend.Addr().String())
+		require.NoError(t, err)
+		defer client.Close()
+
+		clients = append(clients, client)
+	}
+
+	// The sessions are balanced in round robin, and the replies go back to the right client.
+	assert.Equal(t, "backend1:foo", roundTrip(t, clients[0], "foo"))
+	assert.Equal(t, "backend2:bar", roundTrip(t, clients[1], "bar"))
+	assert.Equal(t, "backend1:baz", roundTrip(t, clients[2], "baz"))
+
+	// The datagrams of a session go to the same server.
+	assert.Equal(t, "backend2:qux", roundTrip(t, clients[1], "qux"))
+	assert.Equal(t, "backend1:quux", roundTrip(t, clients[0], "quux"))
+}
+
+func TestLoadBalancer_proxyLargeDatagram(t *testing.T) {
+	backend := listen(t, time.Second, echoSession(""))
+	defer backend.Close()
+
+	lb := NewLoadBalancer()
+	proxy, err := NewProxy(backend.Addr().String())
+	require.NoError(t, err)
+	lb.AddServer(proxy)
+
+	frontend := listen(t, time.Second, lb)
+	defer frontend.Close()
+
+	client, err := net.Dial("udp", frontend.Addr().String())
+	require.NoError(t, err)
+	defer client.Close()
+
+	// A datagram larger than the usual MTUs is not truncated, in either direction.
+	msg := strings.Repeat("x", 60000)
+	assert.Equal(t, msg, roundTrip(t, client, msg))
+}
@@ -0,0 +1,26 @@
+package udp
+
+import (
+	"github.com/containous/traefik/pkg/safe"
+)
+
+// HandlerSwitcher is a UDP handler switcher
+type HandlerSwitcher struct {
+	handler safe.Safe
+}
+
+// ServeUDP forwards the UDP session to the current active handler
+func (s *HandlerSwitcher) ServeUDP(conn *Conn) {
+	handler := s.handler.Get()
+	h, ok := handler.(Handler)
+	if ok {
+		h.ServeUDP(conn)
+	} else {
+		conn.Close()
+	}
+}
+
+// Switch sets the new UDP handler to use for new sessions
+func (s *HandlerSwitcher) Switch(handler Handler) {
+	s.handler.Set(handler)
+}